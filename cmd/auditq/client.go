@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// queryClient sends natural language queries for processing, either to an
+// in-process GenAIProcessor or to a running server.
+type queryClient interface {
+	// Query processes a query within the session
+	Query(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error)
+
+	// Preview processes a query and includes a plain-English explanation
+	Preview(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error)
+}
+
+// localClient processes queries with an in-process GenAIProcessor.
+type localClient struct {
+	processor *processor.GenAIProcessor
+	userID    string
+}
+
+func (c *localClient) Query(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	return c.processor.ProcessQuery(c.withUser(ctx), &types.ProcessingRequest{Query: query, SessionID: sessionID})
+}
+
+func (c *localClient) Preview(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	return c.processor.PreviewQuery(c.withUser(ctx), &types.ProcessingRequest{Query: query, SessionID: sessionID})
+}
+
+func (c *localClient) withUser(ctx context.Context) context.Context {
+	if c.userID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, types.ContextKeyUserID, c.userID)
+}
+
+// remoteClient sends queries to the server's /query and /query/preview endpoints.
+type remoteClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func newRemoteClient(baseURL, token string) *remoteClient {
+	return &remoteClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (c *remoteClient) Query(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	return c.post(ctx, "/query", query, sessionID)
+}
+
+func (c *remoteClient) Preview(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	return c.post(ctx, "/query/preview", query, sessionID)
+}
+
+func (c *remoteClient) post(ctx context.Context, path, query, sessionID string) (*types.ProcessingResponse, error) {
+	body, err := json.Marshal(&types.ProcessingRequest{Query: query, SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", c.baseURL+path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			return &types.ProcessingResponse{Error: errResp.Error.Message}, nil
+		}
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	var out types.ProcessingResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &out, nil
+}
+
+// structuredQueryOf returns the response's structured query, decoding it when
+// it arrived over HTTP as a generic JSON object.
+func structuredQueryOf(resp *types.ProcessingResponse) *types.StructuredQuery {
+	if resp == nil || resp.StructuredQuery == nil {
+		return nil
+	}
+	if sq, ok := resp.StructuredQuery.(*types.StructuredQuery); ok {
+		return sq
+	}
+	var sq types.StructuredQuery
+	if !redecode(resp.StructuredQuery, &sq) {
+		return nil
+	}
+	return &sq
+}
+
+// validationOf returns the response's validation result, decoding it when
+// it arrived over HTTP as a generic JSON object.
+func validationOf(resp *types.ProcessingResponse) *interfaces.ValidationResult {
+	if resp == nil || resp.ValidationInfo == nil {
+		return nil
+	}
+	if vr, ok := resp.ValidationInfo.(*interfaces.ValidationResult); ok {
+		return vr
+	}
+	var vr interfaces.ValidationResult
+	if !redecode(resp.ValidationInfo, &vr) {
+		return nil
+	}
+	return &vr
+}
+
+func redecode(in, out interface{}) bool {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,79 @@
+// Command auditq is an interactive client for multi-turn audit log
+// investigations. Questions are processed in-process by default, or sent to
+// a running server with -server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"genai-processing/internal/config"
+	"genai-processing/internal/processor"
+)
+
+func main() {
+	serverURL := flag.String("server", os.Getenv("AUDITQ_SERVER"), "server base URL, e.g. http://localhost:8080; processes queries in-process when empty")
+	token := flag.String("token", os.Getenv("AUDITQ_TOKEN"), "bearer token sent to the server")
+	user := flag.String("user", os.Getenv("USER"), "user ID recorded with in-process sessions")
+	sessionID := flag.String("session", "", "resume an existing session ID")
+	verbose := flag.Bool("verbose", false, "show processor logs")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	var client queryClient
+	if *serverURL != "" {
+		client = newRemoteClient(*serverURL, *token)
+	} else {
+		p, err := initializeProcessor()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "auditq: %v\n", err)
+			os.Exit(1)
+		}
+		client = &localClient{processor: p, userID: *user}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	r := newREPL(client, os.Stdin, os.Stdout, *sessionID)
+	fmt.Printf("auditq session %s. Type :help for commands.\n", r.sessionID)
+	if err := r.run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "auditq: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// initializeProcessor builds a processor from CONFIG_DIR (or ./configs),
+// falling back to the default processor when configuration cannot be loaded.
+func initializeProcessor() (*processor.GenAIProcessor, error) {
+	configDir := os.Getenv("CONFIG_DIR")
+	if configDir == "" {
+		configDir = "configs"
+		if execPath, err := os.Executable(); err == nil {
+			if _, err := os.Stat(filepath.Join(filepath.Dir(execPath), "configs")); err == nil {
+				configDir = filepath.Join(filepath.Dir(execPath), "configs")
+			}
+		}
+	}
+
+	if appConfig, err := config.NewLoader(configDir).LoadConfig(); err == nil && appConfig.Validate().Valid {
+		if p, err := processor.NewGenAIProcessorFromConfig(appConfig); err == nil && p != nil {
+			return p, nil
+		}
+	}
+	p := processor.NewGenAIProcessor()
+	if p == nil {
+		return nil, fmt.Errorf("failed to create GenAI processor")
+	}
+	return p, nil
+}
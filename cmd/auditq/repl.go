@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const helpText = `Type an audit question, or one of:
+  :explain [question]  explain the last query, or preview a new question without running it
+  :history             list the questions asked in this session
+  :reset               start a new session and forget earlier questions
+  :session             show the current session ID
+  :help                show this help
+  :quit                exit`
+
+// turn is one question and the structured query it produced.
+type turn struct {
+	query    string
+	response *types.ProcessingResponse
+}
+
+// repl runs an interactive multi-turn session. Follow-up questions share the
+// session ID so that the processor can resolve references to earlier turns.
+type repl struct {
+	client    queryClient
+	in        io.Reader
+	out       io.Writer
+	prompt    string
+	sessionID string
+	history   []turn
+	timeout   time.Duration
+	newID     func() string
+}
+
+func newREPL(client queryClient, in io.Reader, out io.Writer, sessionID string) *repl {
+	r := &repl{
+		client:  client,
+		in:      in,
+		out:     out,
+		prompt:  "auditq> ",
+		timeout: 60 * time.Second,
+		newID:   uuid.NewString,
+
+		sessionID: sessionID,
+	}
+	if r.sessionID == "" {
+		r.sessionID = r.newID()
+	}
+	return r
+}
+
+// run reads lines until EOF or :quit.
+func (r *repl) run(ctx context.Context) error {
+	scanner := bufio.NewScanner(r.in)
+	fmt.Fprint(r.out, r.prompt)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			if quit := r.handle(ctx, line); quit {
+				return nil
+			}
+		}
+		fmt.Fprint(r.out, r.prompt)
+	}
+	fmt.Fprintln(r.out)
+	return scanner.Err()
+}
+
+// handle processes a single input line and reports whether to exit.
+func (r *repl) handle(ctx context.Context, line string) bool {
+	if !strings.HasPrefix(line, ":") {
+		r.ask(ctx, line)
+		return false
+	}
+
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch command {
+	case ":quit", ":exit", ":q":
+		return true
+	case ":help", ":h":
+		fmt.Fprintln(r.out, helpText)
+	case ":history":
+		r.printHistory()
+	case ":reset":
+		r.sessionID = r.newID()
+		r.history = nil
+		fmt.Fprintf(r.out, "Started new session %s\n", r.sessionID)
+	case ":session":
+		fmt.Fprintf(r.out, "Session %s (%d questions)\n", r.sessionID, len(r.history))
+	case ":explain":
+		r.explain(ctx, arg)
+	default:
+		fmt.Fprintf(r.out, "Unknown command %s; type :help for commands\n", command)
+	}
+	return false
+}
+
+// ask sends a question in the current session and renders the response.
+func (r *repl) ask(ctx context.Context, query string) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	resp, err := r.client.Query(ctx, query, r.sessionID)
+	if err != nil {
+		fmt.Fprintf(r.out, "Error: %v\n", err)
+		return
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(r.out, "Error: %s\n", resp.Error)
+		return
+	}
+	r.history = append(r.history, turn{query: query, response: resp})
+	r.render(resp)
+}
+
+// explain describes the last structured query, or previews a new question.
+func (r *repl) explain(ctx context.Context, query string) {
+	if query == "" {
+		if len(r.history) == 0 {
+			fmt.Fprintln(r.out, "Nothing to explain yet; ask a question first")
+			return
+		}
+		last := r.history[len(r.history)-1].response
+		if sq := structuredQueryOf(last); sq != nil {
+			fmt.Fprintln(r.out, generator.Explain(sq))
+		}
+		if last.Command != nil {
+			fmt.Fprintf(r.out, "Command:\n  %s\n", last.Command.Command)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	resp, err := r.client.Preview(ctx, query, r.sessionID)
+	if err != nil {
+		fmt.Fprintf(r.out, "Error: %v\n", err)
+		return
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(r.out, "Error: %s\n", resp.Error)
+		return
+	}
+	r.render(resp)
+}
+
+// render prints the structured query, confidence, validation outcome,
+// explanation and command of a response.
+func (r *repl) render(resp *types.ProcessingResponse) {
+	if sq := structuredQueryOf(resp); sq != nil {
+		if data, err := json.MarshalIndent(sq, "", "  "); err == nil {
+			fmt.Fprintf(r.out, "Structured query:\n%s\n", data)
+		}
+	}
+	fmt.Fprintf(r.out, "Confidence: %.2f\n", resp.Confidence)
+
+	if vr := validationOf(resp); vr != nil {
+		if vr.IsValid {
+			fmt.Fprintln(r.out, "Validation: passed")
+		} else {
+			fmt.Fprintf(r.out, "Validation: failed (%s)\n", vr.Message)
+		}
+		for _, e := range vr.Errors {
+			fmt.Fprintf(r.out, "  error: %s\n", e)
+		}
+		for _, w := range vr.Warnings {
+			fmt.Fprintf(r.out, "  warning: %s\n", w)
+		}
+	}
+	if resp.Explanation != "" {
+		fmt.Fprintf(r.out, "Explanation: %s\n", resp.Explanation)
+	}
+	if resp.Command != nil {
+		fmt.Fprintf(r.out, "Command:\n  %s\n", resp.Command.Command)
+	}
+}
+
+// printHistory lists the questions asked in this session.
+func (r *repl) printHistory() {
+	if len(r.history) == 0 {
+		fmt.Fprintln(r.out, "No questions in this session")
+		return
+	}
+	for i, t := range r.history {
+		fmt.Fprintf(r.out, "%d. %s\n", i+1, t.query)
+		if sq := structuredQueryOf(t.response); sq != nil {
+			fmt.Fprintf(r.out, "   %s\n", generator.Explain(sq))
+		}
+	}
+}
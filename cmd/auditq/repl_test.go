@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// fakeClient records the session ID of each call and answers follow-ups
+// with the user resolved from the previous turn.
+type fakeClient struct {
+	sessions []string
+	lastUser map[string]string
+}
+
+func (c *fakeClient) Query(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	c.sessions = append(c.sessions, sessionID)
+	if c.lastUser == nil {
+		c.lastUser = make(map[string]string)
+	}
+	sq := &types.StructuredQuery{LogSource: "kube-apiserver", Verb: *types.NewStringOrArray("delete"), Resource: *types.NewStringOrArray("customresourcedefinitions")}
+	if strings.Contains(query, " he ") {
+		if user, ok := c.lastUser[sessionID]; ok {
+			sq.User = *types.NewStringOrArray(user)
+		}
+	} else {
+		c.lastUser[sessionID] = "john.doe"
+	}
+	return &types.ProcessingResponse{
+		StructuredQuery: sq,
+		Confidence:      0.9,
+		ValidationInfo:  &interfaces.ValidationResult{IsValid: true, Warnings: []string{"broad time range"}},
+		Command:         &types.GeneratedCommand{Command: "oc adm node-logs --role=master --path=kube-apiserver/audit.log | jq ..."},
+	}, nil
+}
+
+func (c *fakeClient) Preview(ctx context.Context, query, sessionID string) (*types.ProcessingResponse, error) {
+	resp, err := c.Query(ctx, query, sessionID)
+	if err == nil {
+		resp.Explanation = "Searches kube-apiserver audit logs for delete on customresourcedefinitions."
+	}
+	return resp, err
+}
+
+func runREPL(t *testing.T, client queryClient, input string) (*repl, string) {
+	t.Helper()
+	var out bytes.Buffer
+	ids := []string{"session-2", "session-3"}
+	r := newREPL(client, strings.NewReader(input), &out, "session-1")
+	r.newID = func() string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+	if err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	return r, out.String()
+}
+
+func TestREPL_MultiTurnSession(t *testing.T) {
+	client := &fakeClient{}
+	r, out := runREPL(t, client, "who deleted the customer CRD\nwhen did he do it\n:history\n:explain\n")
+
+	if len(client.sessions) != 2 || client.sessions[0] != "session-1" || client.sessions[1] != "session-1" {
+		t.Errorf("follow-ups must share the session, got %v", client.sessions)
+	}
+	if len(r.history) != 2 {
+		t.Fatalf("expected 2 turns in history, got %d", len(r.history))
+	}
+	for _, want := range []string{"Structured query:", "Confidence: 0.90", "Validation: passed", "warning: broad time range", "1. who deleted the customer CRD", "2. when did he do it", "john.doe", "Command:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestREPL_Commands(t *testing.T) {
+	client := &fakeClient{}
+	r, out := runREPL(t, client, ":explain\nwho deleted the customer CRD\n:reset\n:history\n:explain delete crds\n:bogus\n:quit\nnever sent\n")
+
+	if r.sessionID != "session-2" || len(r.history) != 0 {
+		t.Errorf("reset should start a new empty session, got %s with %d turns", r.sessionID, len(r.history))
+	}
+	if len(client.sessions) != 2 || client.sessions[1] != "session-2" {
+		t.Errorf("unexpected calls after reset: %v", client.sessions)
+	}
+	for _, want := range []string{"Nothing to explain yet", "Started new session session-2", "No questions in this session", "Explanation: Searches kube-apiserver", "Unknown command :bogus"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRemoteClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer user:alice" {
+			t.Errorf("missing bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		var req types.ProcessingRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Query == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"Processing error","message":"unsupported query","code":400}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"structured_query":{"log_source":"kube-apiserver","verb":["get","list"]},"confidence":0.8,"validation_info":{"is_valid":false,"message":"blocked","errors":["forbidden namespace"]}}`))
+	}))
+	defer srv.Close()
+
+	c := newRemoteClient(srv.URL+"/", "user:alice")
+	resp, err := c.Query(context.Background(), "list pods", "s1")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	sq := structuredQueryOf(resp)
+	if sq == nil || sq.LogSource != "kube-apiserver" || len(sq.Verb.Values()) != 2 {
+		t.Errorf("structured query not decoded: %+v", sq)
+	}
+	if vr := validationOf(resp); vr == nil || vr.IsValid || len(vr.Errors) != 1 {
+		t.Errorf("validation not decoded: %+v", vr)
+	}
+
+	resp, err = c.Preview(context.Background(), "bad", "s1")
+	if err != nil || resp.Error != "unsupported query" {
+		t.Errorf("expected server error message, got %+v, %v", resp, err)
+	}
+}
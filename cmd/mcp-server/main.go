@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"genai-processing/internal/config"
+	"genai-processing/internal/executor"
+	"genai-processing/internal/generator"
+	"genai-processing/internal/mcp"
+	"genai-processing/internal/processor"
+	"genai-processing/internal/validator"
+)
+
+const (
+	serverName    = "audit-query-mcp"
+	serverVersion = "1.0.0"
+)
+
+const instructions = "Use generate_audit_query to turn an audit question into a validated, read-only oc/jq command, " +
+	"execute_audit_query to run it, and parse_audit_results to summarize raw output."
+
+func main() {
+	// stdout carries the protocol on the stdio transport, so all logs go to stderr
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	transport := flag.String("transport", envOrDefault("MCP_TRANSPORT", "stdio"), "MCP transport: stdio or http")
+	addr := flag.String("addr", envOrDefault("MCP_ADDR", "127.0.0.1:8090"), "listen address for the http transport")
+	auditLogDir := flag.String("audit-log-dir", os.Getenv("AUDIT_LOG_DIR"), "directory of audit logs for local execution")
+	allowClusterExec := flag.Bool("allow-cluster-exec", os.Getenv("MCP_ALLOW_CLUSTER_EXEC") == "true", "allow execute_audit_query to run oc adm node-logs against the current cluster")
+	noLLM := flag.Bool("no-llm", false, "disable natural language input; only structured_params are accepted")
+	flag.Parse()
+
+	log.Println("Starting Audit Query MCP Server")
+
+	tools := mcp.NewAuditTools(validator.NewSafetyValidator(), generator.NewCommandGenerator())
+
+	if !*noLLM {
+		appConfig, err := loadConfiguration()
+		if err != nil {
+			log.Printf("Warning: failed to load configuration: %v", err)
+		}
+		genaiProcessor, err := initializeProcessor(appConfig)
+		if err != nil {
+			log.Fatalf("Failed to initialize GenAI processor: %v", err)
+		}
+		tools.SetProcessor(genaiProcessor)
+		log.Println("✓ GenAI processor initialized; natural language queries enabled")
+	}
+
+	if *auditLogDir != "" {
+		tools.SetExecutor(executor.NewExecutor(*auditLogDir))
+		log.Printf("✓ Local execution enabled from %s", *auditLogDir)
+	}
+	if *allowClusterExec {
+		tools.SetClusterRunner(executor.NewClusterRunner())
+		log.Println("✓ Cluster execution enabled via oc adm node-logs")
+	}
+
+	server := mcp.NewServer(serverName, serverVersion)
+	server.SetInstructions(instructions)
+	if err := tools.Register(server); err != nil {
+		log.Fatalf("Failed to register tools: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch *transport {
+	case "stdio":
+		log.Println("✓ Serving MCP over stdio")
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+			log.Fatalf("stdio transport failed: %v", err)
+		}
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", server.HTTPHandler())
+		httpServer := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			log.Printf("✓ Serving MCP over streamable HTTP on http://%s/mcp", *addr)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("HTTP transport failed: %v", err)
+			}
+		}()
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Fatalf("Server forced to shutdown: %v", err)
+		}
+	default:
+		log.Fatalf("Unsupported transport '%s'; use stdio or http", *transport)
+	}
+
+	log.Println("MCP server exited")
+}
+
+// loadConfiguration loads the application configuration from CONFIG_DIR or
+// the configs directory next to the executable.
+func loadConfiguration() (*config.AppConfig, error) {
+	configDir := os.Getenv("CONFIG_DIR")
+	if configDir == "" {
+		execPath, err := os.Executable()
+		if err != nil {
+			configDir = "configs"
+		} else {
+			configDir = filepath.Join(filepath.Dir(execPath), "configs")
+		}
+	}
+
+	log.Printf("Loading configuration from: %s", configDir)
+	appConfig, err := config.NewLoader(configDir).LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if result := appConfig.Validate(); !result.Valid {
+		return nil, fmt.Errorf("configuration validation failed: %v", result.Errors)
+	}
+	return appConfig, nil
+}
+
+// initializeProcessor builds the processor from the loaded configuration; a
+// configuration that cannot be built is an error, as in the REST server. The
+// default processor is only used when no configuration could be loaded.
+func initializeProcessor(appConfig *config.AppConfig) (*processor.GenAIProcessor, error) {
+	if appConfig != nil {
+		return processor.NewGenAIProcessorFromConfig(appConfig)
+	}
+	p := processor.NewGenAIProcessor()
+	if p == nil {
+		return nil, fmt.Errorf("failed to create GenAI processor")
+	}
+	return p, nil
+}
+
+// envOrDefault returns the environment variable value or the fallback.
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
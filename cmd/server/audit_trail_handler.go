@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"genai-processing/internal/audittrail"
+	"genai-processing/internal/auth"
+	"genai-processing/internal/processor"
+)
+
+// maxAuditTrailLimit bounds the records returned by one /audit-trail request.
+const maxAuditTrailLimit = 1000
+
+// AuditTrailHandler handles GET /audit-trail requests for compliance reviews,
+// returning audit trail records newest first. Query parameters filter by
+// request_id, user, session_id, outcome, since and until (RFC 3339), and
+// limit the number of records (default 100, at most 1000). Callers in one of
+// reviewerGroups may read every user's records; other callers only their own.
+func AuditTrailHandler(genaiProcessor *processor.GenAIProcessor, reviewerGroups []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[AuditTrailHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		if r.Method != http.MethodGet {
+			log.Printf("[AuditTrailHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only GET method is supported")
+			return
+		}
+
+		identity := auth.IdentityFromContext(r.Context())
+		if identity == nil {
+			log.Printf("[AuditTrailHandler] Request is not authenticated")
+			writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "An authenticated user is required to read the audit trail")
+			return
+		}
+		store := genaiProcessor.AuditTrail()
+		if store == nil {
+			log.Printf("[AuditTrailHandler] Audit trail is not enabled")
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Service unavailable", "The audit trail is not enabled")
+			return
+		}
+
+		filter, err := parseAuditTrailFilter(r)
+		if err != nil {
+			log.Printf("[AuditTrailHandler] Invalid filter: %v", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+
+		// Only reviewers may read other users' records
+		if !inAnyGroup(identity, reviewerGroups) {
+			if filter.UserID != "" && filter.UserID != identity.UserID {
+				log.Printf("[AuditTrailHandler] User %s may not read records of user %s", identity.UserID, filter.UserID)
+				writeErrorResponse(w, http.StatusForbidden, "Forbidden", "Only audit trail reviewers may read other users' records")
+				return
+			}
+			filter.UserID = identity.UserID
+		}
+
+		records, err := store.Query(filter)
+		if err != nil {
+			log.Printf("[AuditTrailHandler] Failed to query audit trail: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Audit trail error", "Failed to query the audit trail")
+			return
+		}
+		if records == nil {
+			records = []*audittrail.Record{}
+		}
+
+		log.Printf("[AuditTrailHandler] Returned %d records to user %s", len(records), identity.UserID)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"records": records,
+			"count":   len(records),
+		})
+	}
+}
+
+// parseAuditTrailFilter reads an audit trail filter from query parameters.
+func parseAuditTrailFilter(r *http.Request) (audittrail.Filter, error) {
+	params := r.URL.Query()
+	filter := audittrail.Filter{
+		RequestID: params.Get("request_id"),
+		UserID:    params.Get("user"),
+		SessionID: params.Get("session_id"),
+		Outcome:   params.Get("outcome"),
+	}
+
+	switch filter.Outcome {
+	case "", audittrail.OutcomeSuccess, audittrail.OutcomeRejected, audittrail.OutcomeError:
+	default:
+		return filter, fmt.Errorf("outcome must be one of %s, %s or %s", audittrail.OutcomeSuccess, audittrail.OutcomeRejected, audittrail.OutcomeError)
+	}
+	for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*bound = t
+		}
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxAuditTrailLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxAuditTrailLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"genai-processing/internal/audittrail"
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/types"
+)
+
+func auditTrailRequest(t *testing.T, handler http.Handler, path, user string, groups ...string) (*httptest.ResponseRecorder, []*audittrail.Record) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if user != "" {
+		ctx := context.WithValue(req.Context(), types.ContextKeyUserID, user)
+		ctx = context.WithValue(ctx, types.ContextKeyGroups, groups)
+		req = req.WithContext(ctx)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var body struct {
+		Records []*audittrail.Record `json:"records"`
+		Count   int                  `json:"count"`
+	}
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return rr, body.Records
+}
+
+func TestAuditTrailHandler(t *testing.T) {
+	genaiProcessor := processor.NewGenAIProcessor()
+	if genaiProcessor == nil {
+		t.Skip("Skipping test - could not create GenAI processor")
+	}
+	handler := AuditTrailHandler(genaiProcessor, []string{"compliance"})
+
+	if rr, _ := auditTrailRequest(t, handler, "/audit-trail", "alice"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without an audit trail, got %d", rr.Code)
+	}
+
+	store, err := audittrail.NewFileStore(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+	genaiProcessor.SetAuditTrail(store)
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, record := range []*audittrail.Record{
+		{RequestID: "r1", UserID: "alice", Query: "who deleted pods", Outcome: audittrail.OutcomeSuccess},
+		{RequestID: "r2", UserID: "bob", Query: "list secrets", Outcome: audittrail.OutcomeRejected},
+		{RequestID: "r3", UserID: "alice", Query: "failed logins", Outcome: audittrail.OutcomeError},
+	} {
+		record.Timestamp = at.Add(time.Duration(i) * time.Hour)
+		if err := store.Append(record); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		user     string
+		groups   []string
+		wantCode int
+		wantIDs  []string
+	}{
+		{name: "anonymous", path: "/audit-trail", wantCode: http.StatusUnauthorized},
+		{name: "own records", path: "/audit-trail", user: "alice", wantCode: http.StatusOK, wantIDs: []string{"r3", "r1"}},
+		{name: "own records by user", path: "/audit-trail?user=alice&outcome=success", user: "alice", wantCode: http.StatusOK, wantIDs: []string{"r1"}},
+		{name: "other user's records", path: "/audit-trail?user=bob", user: "alice", wantCode: http.StatusForbidden},
+		{name: "reviewer", path: "/audit-trail", user: "carol", groups: []string{"compliance"}, wantCode: http.StatusOK, wantIDs: []string{"r3", "r2", "r1"}},
+		{name: "reviewer filters", path: "/audit-trail?user=bob", user: "carol", groups: []string{"compliance"}, wantCode: http.StatusOK, wantIDs: []string{"r2"}},
+		{name: "time window", path: "/audit-trail?since=2024-01-01T12:30:00Z&until=2024-01-01T14:00:00Z&limit=5", user: "carol", groups: []string{"compliance"}, wantCode: http.StatusOK, wantIDs: []string{"r2"}},
+		{name: "invalid limit", path: "/audit-trail?limit=0", user: "alice", wantCode: http.StatusBadRequest},
+		{name: "invalid since", path: "/audit-trail?since=yesterday", user: "alice", wantCode: http.StatusBadRequest},
+		{name: "invalid outcome", path: "/audit-trail?outcome=maybe", user: "alice", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr, records := auditTrailRequest(t, handler, tt.path, tt.user, tt.groups...)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var ids []string
+			for _, record := range records {
+				ids = append(ids, record.RequestID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("expected records %v, got %v", tt.wantIDs, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("expected records %v, got %v", tt.wantIDs, ids)
+				}
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/audit-trail", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
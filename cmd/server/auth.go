@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"genai-processing/internal/auth"
+	"genai-processing/internal/config"
+)
+
+// authExemptPaths are served without authentication so probes and metrics
+// scrapers keep working.
+var authExemptPaths = map[string]bool{
+	"/health":  true,
+	"/metrics": true,
+}
+
+// newAuthenticator builds the authenticator selected by the auth configuration.
+// It returns nil for the "none" mode, in which all requests are anonymous. The
+// mode has no default: the unverified dev mode, like none, must be chosen
+// explicitly, since sessions, namespace scoping, the audit trail and budgets
+// all trust the identity established here.
+func newAuthenticator(cfg config.AuthConfig) (auth.Authenticator, error) {
+	jwtConfig := auth.JWTConfig{
+		Issuer:        cfg.IssuerURL,
+		Audience:      cfg.Audience,
+		UsernameClaim: cfg.UsernameClaim,
+		GroupsClaim:   cfg.GroupsClaim,
+	}
+
+	switch cfg.Mode {
+	case "":
+		return nil, fmt.Errorf("auth mode is not set: set AUTH_MODE to jwt, oidc or tokenreview, or to dev or none for local use")
+	case "dev":
+		return auth.NewDevAuthenticator(), nil
+	case "none":
+		return nil, nil
+	case "jwt":
+		keys, err := auth.LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewJWTAuthenticator(keys, jwtConfig), nil
+	case "oidc":
+		return auth.NewJWTAuthenticator(auth.NewOIDCKeySet(cfg.IssuerURL, nil), jwtConfig), nil
+	case "tokenreview":
+		reviewConfig := auth.TokenReviewConfig{URL: cfg.TokenReviewURL, CacheTTL: cfg.CacheTTL}
+		if cfg.Audience != "" {
+			reviewConfig.Audiences = []string{cfg.Audience}
+		}
+		if cfg.TokenReviewTokenFile != "" {
+			token, err := auth.ReadTokenFile(cfg.TokenReviewTokenFile)
+			if err != nil {
+				return nil, err
+			}
+			reviewConfig.Token = token
+		}
+		client, err := auth.NewHTTPClient(cfg.TokenReviewCAFile)
+		if err != nil {
+			return nil, err
+		}
+		reviewConfig.HTTPClient = client
+		return auth.NewTokenReviewAuthenticator(reviewConfig), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth mode '%s'", cfg.Mode)
+	}
+}
+
+// authMiddleware verifies the bearer token on each request and stores the
+// caller's identity in the request context under types.ContextKeyUserID and
+// types.ContextKeyGroups. Requests without a token continue anonymously
+// unless required is set; requests with a rejected token always fail.
+func authMiddleware(authn auth.Authenticator, required bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authn == nil || authExemptPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := auth.BearerToken(r.Header.Get("Authorization"))
+		if token == "" {
+			if required {
+				log.Printf("[Auth] Missing bearer token for %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="genai-audit-query"`)
+				writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "A bearer token is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := authn.Authenticate(r.Context(), token)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			if errors.Is(err, auth.ErrInvalidToken) {
+				log.Printf("[Auth] Rejected bearer token from %s: %v", r.RemoteAddr, err)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="genai-audit-query", error="invalid_token"`)
+				writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "Invalid bearer token")
+				return
+			}
+			log.Printf("[Auth] Authentication unavailable: %v", err)
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Service unavailable", "Authentication is temporarily unavailable")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// inAnyGroup reports whether identity belongs to at least one of groups.
+func inAnyGroup(identity *auth.Identity, groups []string) bool {
+	for _, group := range identity.Groups {
+		for _, allowed := range groups {
+			if group == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"genai-processing/internal/auth"
+	"genai-processing/internal/auth/authtest"
+	"genai-processing/internal/config"
+	"genai-processing/pkg/types"
+)
+
+// identityEcho writes the identity found in the request context.
+var identityEcho = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(types.ContextKeyUserID).(string)
+	groups, _ := r.Context().Value(types.ContextKeyGroups).([]string)
+	json.NewEncoder(w).Encode(map[string]interface{}{"user_id": userID, "groups": groups})
+})
+
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) Authenticate(ctx context.Context, token string) (*auth.Identity, error) {
+	return nil, errors.New("identity provider unreachable")
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	issuer, err := authtest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, issuer.JWKS(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jwtAuth, err := newAuthenticator(config.AuthConfig{Mode: "jwt", JWKSFile: path, IssuerURL: issuer.URL()})
+	if err != nil {
+		t.Fatalf("newAuthenticator failed: %v", err)
+	}
+	token := issuer.Sign(map[string]interface{}{"sub": "alice", "groups": []string{"sre"}})
+
+	tests := []struct {
+		name     string
+		authn    auth.Authenticator
+		required bool
+		path     string
+		header   string
+		status   int
+		userID   string
+	}{
+		{name: "valid_jwt", authn: jwtAuth, required: true, path: "/query", header: "Bearer " + token, status: http.StatusOK, userID: "alice"},
+		{name: "missing_token_required", authn: jwtAuth, required: true, path: "/query", status: http.StatusUnauthorized},
+		{name: "missing_token_optional", authn: jwtAuth, path: "/query", status: http.StatusOK},
+		{name: "invalid_token", authn: jwtAuth, path: "/query", header: "Bearer user:alice", status: http.StatusUnauthorized},
+		{name: "health_exempt", authn: jwtAuth, required: true, path: "/health", status: http.StatusOK},
+		{name: "metrics_exempt", authn: jwtAuth, required: true, path: "/metrics", status: http.StatusOK},
+		{name: "dev_mode", authn: auth.NewDevAuthenticator(), path: "/query", header: "Bearer user:bob", status: http.StatusOK, userID: "bob"},
+		{name: "none_mode_ignores_header", authn: nil, path: "/query", header: "Bearer user:bob", status: http.StatusOK},
+		{name: "backend_failure", authn: failingAuthenticator{}, path: "/query", header: "Bearer abc", status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			authMiddleware(tt.authn, tt.required, identityEcho).ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if rr.Code == http.StatusUnauthorized && !strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), "Bearer") {
+				t.Errorf("missing WWW-Authenticate challenge")
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var body struct {
+				UserID string   `json:"user_id"`
+				Groups []string `json:"groups"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.UserID != tt.userID {
+				t.Errorf("user_id = %q, want %q", body.UserID, tt.userID)
+			}
+			if tt.name == "valid_jwt" && (len(body.Groups) != 1 || body.Groups[0] != "sre") {
+				t.Errorf("groups = %v, want [sre]", body.Groups)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator_Errors(t *testing.T) {
+	if _, err := newAuthenticator(config.AuthConfig{Mode: "jwt", JWKSFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected error for a missing JWKS file")
+	}
+	if _, err := newAuthenticator(config.AuthConfig{}); err == nil {
+		t.Error("expected error when no mode is configured")
+	}
+	if _, err := newAuthenticator(config.AuthConfig{Mode: "ldap"}); err == nil {
+		t.Error("expected error for an unsupported mode")
+	}
+	if authn, err := newAuthenticator(config.AuthConfig{Mode: "none"}); err != nil || authn != nil {
+		t.Errorf("none mode = %v, %v; want nil authenticator", authn, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"genai-processing/internal/metrics"
 	"genai-processing/internal/processor"
 	"genai-processing/pkg/types"
 )
@@ -53,13 +54,6 @@ func QueryHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 		defer cancel()
 
-		// Extract user ID from Authorization header (e.g., "Bearer <token>")
-		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
-			if userID := extractUserIDFromAuthHeader(authHeader); userID != "" {
-				ctx = context.WithValue(ctx, types.ContextKeyUserID, userID)
-			}
-		}
-
 		// Process the query using GenAIProcessor
 		response, err := genaiProcessor.ProcessQuery(ctx, &req)
 		if err != nil {
@@ -71,7 +65,7 @@ func QueryHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
 		// Check if processing resulted in an error response
 		if response.Error != "" {
 			log.Printf("[QueryHandler] Processing returned error: %s", response.Error)
-			writeErrorResponse(w, http.StatusBadRequest, "Processing error", response.Error)
+			writeErrorResponse(w, processingErrorStatus(response.Error), "Processing error", response.Error)
 			return
 		}
 
@@ -80,6 +74,7 @@ func QueryHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
 		log.Printf("[QueryHandler] Query processed successfully in %v", processingTime)
 
 		// Write successful response
+		setCacheHeader(w, response)
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			log.Printf("[QueryHandler] Failed to encode response: %v", err)
@@ -92,20 +87,68 @@ func QueryHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
 	}
 }
 
-// extractUserIDFromAuthHeader is a placeholder for extracting user identity from Authorization header.
-// In production, replace with proper JWT parsing and validation. For now, supports a simple scheme:
-// Authorization: Bearer user:<user-id>
-func extractUserIDFromAuthHeader(header string) string {
-	parts := strings.SplitN(header, " ", 2)
-	if len(parts) != 2 {
-		return ""
-	}
-	token := strings.TrimSpace(parts[1])
-	// Very basic demo format: user:<id>
-	if strings.HasPrefix(token, "user:") {
-		return strings.TrimSpace(strings.TrimPrefix(token, "user:"))
+// PreviewHandler handles POST /query/preview requests. It returns the structured query,
+// the compiled command and a plain-English explanation so reviewers can confirm intent
+// before anything runs against production audit logs.
+func PreviewHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[PreviewHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			log.Printf("[PreviewHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only POST method is supported")
+			return
+		}
+
+		// Parse request body
+		var req types.ProcessingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("[PreviewHandler] Failed to decode request body: %v", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request format", "Failed to parse JSON request body")
+			return
+		}
+
+		// Basic input validation
+		if err := validateProcessingRequest(&req); err != nil {
+			log.Printf("[PreviewHandler] Request validation failed: %v", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+
+		log.Printf("[PreviewHandler] Previewing query: %q, SessionID: %s", req.Query, req.SessionID)
+
+		// Create context with timeout
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		response, err := genaiProcessor.PreviewQuery(ctx, &req)
+		if err != nil {
+			log.Printf("[PreviewHandler] Preview failed: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Processing error", "Failed to preview query")
+			return
+		}
+
+		if response.Error != "" {
+			log.Printf("[PreviewHandler] Preview returned error: %s", response.Error)
+			writeErrorResponse(w, processingErrorStatus(response.Error), "Processing error", response.Error)
+			return
+		}
+
+		log.Printf("[PreviewHandler] Query previewed successfully in %v", time.Since(startTime))
+
+		setCacheHeader(w, response)
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("[PreviewHandler] Failed to encode response: %v", err)
+			return
+		}
 	}
-	return ""
 }
 
 // HealthHandler handles GET /health requests for health checks
@@ -143,6 +186,29 @@ func HealthHandler() http.HandlerFunc {
 	}
 }
 
+// SchemaHandler handles GET /schema requests, returning the JSON Schema of
+// the structured queries the processor produces
+func SchemaHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[SchemaHandler] Schema request from %s", r.RemoteAddr)
+
+		if r.Method != http.MethodGet {
+			log.Printf("[SchemaHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only GET method is supported")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(genaiProcessor.QuerySchema()); err != nil {
+			log.Printf("[SchemaHandler] Failed to encode schema: %v", err)
+			return
+		}
+	}
+}
+
 // validateProcessingRequest performs basic validation on the processing request
 func validateProcessingRequest(req *types.ProcessingRequest) error {
 	// Check if query is provided and not empty
@@ -177,6 +243,14 @@ func validateProcessingRequest(req *types.ProcessingRequest) error {
 	return nil
 }
 
+// setCacheHeader reports in the X-Cache header whether the structured query
+// was served from the response cache
+func setCacheHeader(w http.ResponseWriter, response *types.ProcessingResponse) {
+	if response.Cache != "" {
+		w.Header().Set("X-Cache", response.Cache)
+	}
+}
+
 // writeErrorResponse writes a standardized error response
 func writeErrorResponse(w http.ResponseWriter, statusCode int, errorType, message string) {
 	errorResponse := map[string]interface{}{
@@ -194,13 +268,33 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, errorType, messag
 	}
 }
 
-// setupRoutes configures the HTTP routes for the server
-func setupRoutes(genaiProcessor *processor.GenAIProcessor) *http.ServeMux {
+// processingErrorStatus returns the HTTP status for a processing error
+// response: 429 once the caller's usage budget is spent, 400 otherwise.
+func processingErrorStatus(message string) int {
+	if strings.HasPrefix(message, "budget_exceeded:") {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
+
+// setupRoutes configures the HTTP routes for the server. Members of
+// reviewerGroups may read every user's audit trail records and usage;
+// members of adminGroups may reload configuration.
+func setupRoutes(genaiProcessor *processor.GenAIProcessor, reviewerGroups, adminGroups []string) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Register handlers
 	mux.HandleFunc("/query", QueryHandler(genaiProcessor))
+	mux.HandleFunc("/query/preview", PreviewHandler(genaiProcessor))
+	mux.HandleFunc("/query/stream", StreamQueryHandler(genaiProcessor))
+	mux.HandleFunc("/sessions", SessionsHandler(genaiProcessor))
+	mux.HandleFunc(sessionsPathPrefix, SessionHandler(genaiProcessor))
+	mux.HandleFunc("/schema", SchemaHandler(genaiProcessor))
+	mux.HandleFunc("/audit-trail", AuditTrailHandler(genaiProcessor, reviewerGroups))
+	mux.HandleFunc("/usage", UsageHandler(genaiProcessor, reviewerGroups))
+	mux.HandleFunc("/admin/reload", ReloadHandler(func() error { return reloadConfiguration(genaiProcessor) }, adminGroups))
 	mux.HandleFunc("/health", HealthHandler())
+	mux.Handle("/metrics", metrics.Default.Handler())
 
 	// Add logging middleware
 	return mux
@@ -228,8 +322,9 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Cache")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
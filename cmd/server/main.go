@@ -8,10 +8,12 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"genai-processing/internal/config"
 	"genai-processing/internal/processor"
+	"genai-processing/internal/validator"
 )
 
 func main() {
@@ -40,12 +42,30 @@ func main() {
 
 	// Setup routes
 	log.Println("Setting up HTTP routes...")
-	mux := setupRoutes(genaiProcessor)
+	mux := setupRoutes(genaiProcessor, appConfig.AuditTrail.ReviewerGroups, appConfig.Reload.AdminGroups)
 	log.Println("✓ HTTP routes configured")
 
+	// Watch the configuration files for changes
+	var watcher *config.Watcher
+	if appConfig.Reload.WatchInterval > 0 {
+		watcher = config.NewWatcher(configFilePaths(), appConfig.Reload.WatchInterval, func() {
+			if err := reloadConfiguration(genaiProcessor); err != nil {
+				log.Printf("Configuration reload failed, keeping the current configuration: %v", err)
+				return
+			}
+			log.Println("✓ Configuration reloaded")
+		})
+		watcher.Start()
+		log.Printf("✓ Watching configuration files every %v", appConfig.Reload.WatchInterval)
+	}
+
 	// Add middleware
 	log.Println("Configuring middleware...")
-	handler := corsMiddleware(loggingMiddleware(mux))
+	authenticator, err := newAuthenticator(appConfig.Auth)
+	if err != nil {
+		log.Fatalf("Failed to initialize authentication: %v", err)
+	}
+	handler := corsMiddleware(loggingMiddleware(authMiddleware(authenticator, appConfig.Auth.Required, mux)))
 	log.Println("✓ Middleware configured")
 
 	// Configure server using loaded configuration
@@ -70,8 +90,20 @@ func main() {
 	log.Printf("✓ Server timeouts - Read: %v, Write: %v, Idle: %v",
 		appConfig.Server.ReadTimeout, appConfig.Server.WriteTimeout, appConfig.Server.IdleTimeout)
 	log.Printf("✓ Default LLM provider: %s", appConfig.Models.DefaultProvider)
+	if len(appConfig.Models.FallbackProviders) > 0 {
+		log.Printf("✓ Fallback LLM providers: %s", strings.Join(appConfig.Models.FallbackProviders, ", "))
+	}
 	log.Println("✓ POST /query - Process natural language audit queries")
+	log.Println("✓ POST /query/preview - Preview the generated query with an explanation")
+	log.Println("✓ POST /query/stream - Process a query, streaming progress as Server-Sent Events")
+	log.Println("✓ GET  /sessions - List your sessions; POST /sessions - Create a session")
+	log.Println("✓ GET  /sessions/{id} - Session history; DELETE /sessions/{id} - End a session")
+	log.Println("✓ GET  /schema - JSON Schema of structured queries")
+	log.Println("✓ GET  /audit-trail - Audit trail of processed queries")
+	log.Println("✓ GET  /usage - Token usage and cost")
+	log.Println("✓ POST /admin/reload - Reload models.yaml, prompts.yaml and rules.yaml")
 	log.Println("✓ GET  /health - Health check endpoint")
+	log.Println("✓ GET  /metrics - Prometheus metrics")
 	log.Println("Press Ctrl+C to shutdown gracefully")
 
 	// Wait for interrupt signal to gracefully shutdown the server
@@ -89,25 +121,17 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if watcher != nil {
+		watcher.Stop()
+	}
+	genaiProcessor.Close()
 
 	log.Println("Server exited gracefully")
 }
 
 // loadConfiguration loads the application configuration using config.LoadConfig()
 func loadConfiguration() (*config.AppConfig, error) {
-	// Determine config directory
-	configDir := os.Getenv("CONFIG_DIR")
-	if configDir == "" {
-		// Default to configs directory relative to executable
-		execPath, err := os.Executable()
-		if err != nil {
-			// Fallback to current directory
-			configDir = "configs"
-		} else {
-			configDir = filepath.Join(filepath.Dir(execPath), "configs")
-		}
-	}
-
+	configDir := configDirectory()
 	log.Printf("Loading configuration from: %s", configDir)
 
 	// Create configuration loader
@@ -127,6 +151,45 @@ func loadConfiguration() (*config.AppConfig, error) {
 	return appConfig, nil
 }
 
+// configDirectory returns the directory of models.yaml and prompts.yaml
+func configDirectory() string {
+	configDir := os.Getenv("CONFIG_DIR")
+	if configDir == "" {
+		// Default to configs directory relative to executable
+		execPath, err := os.Executable()
+		if err != nil {
+			// Fallback to current directory
+			configDir = "configs"
+		} else {
+			configDir = filepath.Join(filepath.Dir(execPath), "configs")
+		}
+	}
+	return configDir
+}
+
+// configFilePaths returns the configuration files that can be reloaded:
+// models.yaml, prompts.yaml, rules.yaml and the policy directory it names
+func configFilePaths() []string {
+	paths := config.NewLoader(configDirectory()).GetConfigFilePaths()
+	watched := []string{paths["models"], paths["prompts"], validator.ConfigPath()}
+	if rules, err := validator.LoadValidationConfig(validator.ConfigPath()); err == nil {
+		if dir := rules.PolicyDirectory(); dir != "" {
+			watched = append(watched, dir)
+		}
+	}
+	return watched
+}
+
+// reloadConfiguration loads the configuration files again and installs them
+// in the processor, which keeps its current configuration if they are invalid
+func reloadConfiguration(genaiProcessor *processor.GenAIProcessor) error {
+	appConfig, err := loadConfiguration()
+	if err != nil {
+		return err
+	}
+	return genaiProcessor.Reload(appConfig)
+}
+
 // logConfigurationStatus logs the configuration status during startup
 func logConfigurationStatus(appConfig *config.AppConfig) {
 	log.Println("=== Configuration Status ===")
@@ -141,6 +204,14 @@ func logConfigurationStatus(appConfig *config.AppConfig) {
 	log.Printf("  Shutdown Timeout: %v", appConfig.Server.ShutdownTimeout)
 	log.Printf("  Max Request Size: %d bytes", appConfig.Server.MaxRequestSize)
 
+	// Auth configuration
+	log.Printf("Auth Configuration:")
+	log.Printf("  Mode: %s", appConfig.Auth.Mode)
+	log.Printf("  Required: %v", appConfig.Auth.Required)
+	if appConfig.Auth.Mode == "dev" {
+		log.Printf("  Warning: dev mode trusts 'Bearer user:<id>' tokens without verification")
+	}
+
 	// Models configuration
 	log.Printf("Models Configuration:")
 	log.Printf("  Default Provider: %s", appConfig.Models.DefaultProvider)
@@ -177,26 +248,18 @@ func logConfigurationStatus(appConfig *config.AppConfig) {
 	log.Println("=== Configuration Status Complete ===")
 }
 
-// initializeProcessor initializes the GenAI processor with configuration
+// initializeProcessor initializes the GenAI processor with configuration. A
+// processor that cannot be built as configured is an error: falling back to
+// the default processor would silently drop namespace scoping, budgets, the
+// audit trail, policies and reloading.
 func initializeProcessor(appConfig *config.AppConfig) (*processor.GenAIProcessor, error) {
-	// Prefer config-driven initialization; fall back to default to preserve tests
-	if appConfig != nil {
-		p, err := processor.NewGenAIProcessorFromConfig(appConfig)
-		if err == nil && p != nil {
-			log.Printf("Processor initialized from config. Default provider: %s", appConfig.Models.DefaultProvider)
-			return p, nil
-		}
-		log.Printf("Warning: config-driven processor init failed, falling back to default: %v", err)
+	if appConfig == nil {
+		return nil, fmt.Errorf("configuration is required")
 	}
-
-	genaiProcessor := processor.NewGenAIProcessor()
-	if genaiProcessor == nil {
-		return nil, fmt.Errorf("failed to create GenAI processor")
-	}
-	if appConfig != nil {
-		log.Printf("Processor initialized with default model provider: %s", appConfig.Models.DefaultProvider)
-	} else {
-		log.Printf("Processor initialized with default configuration (no appConfig provided)")
+	genaiProcessor, err := processor.NewGenAIProcessorFromConfig(appConfig)
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("Processor initialized from config. Default provider: %s", appConfig.Models.DefaultProvider)
 	return genaiProcessor, nil
 }
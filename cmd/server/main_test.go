@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"genai-processing/internal/config"
+)
+
+func TestInitializeProcessor_FailsOnInvalidConfiguration(t *testing.T) {
+	if _, err := initializeProcessor(nil); err == nil {
+		t.Error("expected an error without a configuration")
+	}
+
+	appConfig := config.GetDefaultConfig()
+	appConfig.AuditTrail.Backend = "sql"
+	if p, err := initializeProcessor(appConfig); err == nil {
+		p.Close()
+		t.Error("expected an error instead of falling back to the default processor")
+	}
+}
+
+func TestConfigFilePaths_WatchesPolicyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	policies := filepath.Join(dir, "policies")
+	rulesPath := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rulesPath, []byte("safety_rules:\n  policies:\n    directory: "+policies+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_DIR", dir)
+	t.Setenv("VALIDATION_RULES_FILE", rulesPath)
+
+	paths := configFilePaths()
+	want := []string{filepath.Join(dir, "models.yaml"), filepath.Join(dir, "prompts.yaml"), rulesPath, policies}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, paths)
+			break
+		}
+	}
+}
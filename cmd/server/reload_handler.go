@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"genai-processing/internal/auth"
+)
+
+// ReloadHandler handles POST /admin/reload requests, reloading models.yaml,
+// prompts.yaml and rules.yaml without a restart by calling reload. Only
+// authenticated callers in one of adminGroups may reload. A configuration
+// that fails validation is rejected and the current one keeps serving.
+func ReloadHandler(reload func() error, adminGroups []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[ReloadHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		if r.Method != http.MethodPost {
+			log.Printf("[ReloadHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only POST method is supported")
+			return
+		}
+
+		identity := auth.IdentityFromContext(r.Context())
+		if identity == nil {
+			log.Printf("[ReloadHandler] Request is not authenticated")
+			writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "An authenticated user is required to reload configuration")
+			return
+		}
+		if !inAnyGroup(identity, adminGroups) {
+			log.Printf("[ReloadHandler] User %s is not an administrator", identity.UserID)
+			writeErrorResponse(w, http.StatusForbidden, "Forbidden", "Only administrators may reload configuration")
+			return
+		}
+
+		if err := reload(); err != nil {
+			log.Printf("[ReloadHandler] Reload requested by %s failed, keeping the current configuration: %v", identity.UserID, err)
+			writeErrorResponse(w, http.StatusUnprocessableEntity, "Reload failed", err.Error())
+			return
+		}
+
+		log.Printf("[ReloadHandler] Configuration reloaded by %s", identity.UserID)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":      "reloaded",
+			"reloaded_at": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestReloadHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		user       string
+		groups     []string
+		reloadErr  error
+		wantStatus int
+		wantReload bool
+	}{
+		{name: "wrong method", method: http.MethodGet, user: "root", groups: []string{"platform-admins"}, wantStatus: http.StatusMethodNotAllowed},
+		{name: "unauthenticated", method: http.MethodPost, wantStatus: http.StatusUnauthorized},
+		{name: "not an administrator", method: http.MethodPost, user: "alice", groups: []string{"developers"}, wantStatus: http.StatusForbidden},
+		{name: "reloaded", method: http.MethodPost, user: "root", groups: []string{"platform-admins"}, wantStatus: http.StatusOK, wantReload: true},
+		{
+			name:       "invalid configuration",
+			method:     http.MethodPost,
+			user:       "root",
+			groups:     []string{"platform-admins"},
+			reloadErr:  errors.New("invalid configuration: [default provider 'missing' not found in providers]"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantReload: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloaded := false
+			handler := ReloadHandler(func() error {
+				reloaded = true
+				return tt.reloadErr
+			}, []string{"platform-admins"})
+
+			req := httptest.NewRequest(tt.method, "/admin/reload", nil)
+			if tt.user != "" {
+				ctx := context.WithValue(req.Context(), types.ContextKeyUserID, tt.user)
+				ctx = context.WithValue(ctx, types.ContextKeyGroups, tt.groups)
+				req = req.WithContext(ctx)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if reloaded != tt.wantReload {
+				t.Errorf("expected reload called %v, got %v", tt.wantReload, reloaded)
+			}
+			if tt.reloadErr != nil && !strings.Contains(rr.Body.String(), "default provider") {
+				t.Errorf("expected the reload error in the response, got %s", rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	contextpkg "genai-processing/internal/context"
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// sessionsPathPrefix is the route prefix for a single session resource.
+const sessionsPathPrefix = "/sessions/"
+
+// sessionSummary is the list and create representation of a session.
+type sessionSummary struct {
+	SessionID    string    `json:"session_id"`
+	UserID       string    `json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActivity time.Time `json:"last_activity"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Turns        int       `json:"turns"`
+}
+
+// sessionDetail adds the conversation history to a session summary.
+type sessionDetail struct {
+	sessionSummary
+	ConversationHistory []types.ConversationEntry `json:"conversation_history"`
+}
+
+func summarizeSession(session *types.ConversationContext) sessionSummary {
+	return sessionSummary{
+		SessionID:    session.SessionID,
+		UserID:       session.UserID,
+		CreatedAt:    session.CreatedAt,
+		LastActivity: session.LastActivity,
+		ExpiresAt:    session.ExpiresAt,
+		Turns:        len(session.ConversationHistory),
+	}
+}
+
+// SessionsHandler handles /sessions requests. GET lists the caller's active
+// sessions and POST creates a new session owned by the caller.
+func SessionsHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[SessionsHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		sessions, userID, ok := sessionRequestContext(w, r, genaiProcessor, "SessionsHandler")
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			sessionIDs, err := sessions.ListActiveSessions(userID)
+			if err != nil {
+				log.Printf("[SessionsHandler] Failed to list sessions: %v", err)
+				writeErrorResponse(w, http.StatusInternalServerError, "Session error", "Failed to list sessions")
+				return
+			}
+			summaries := make([]sessionSummary, 0, len(sessionIDs))
+			for _, sessionID := range sessionIDs {
+				// Sessions may expire between listing and reading
+				if session, err := sessions.GetSession(sessionID); err == nil {
+					summaries = append(summaries, summarizeSession(session))
+				}
+			}
+			log.Printf("[SessionsHandler] Listed %d sessions for user %s", len(summaries), userID)
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"sessions": summaries,
+				"count":    len(summaries),
+			})
+
+		case http.MethodPost:
+			sessionID, err := sessions.CreateSession(userID)
+			if err != nil {
+				log.Printf("[SessionsHandler] Failed to create session: %v", err)
+				writeErrorResponse(w, http.StatusBadRequest, "Session error", err.Error())
+				return
+			}
+			session, err := sessions.GetSession(sessionID)
+			if err != nil {
+				log.Printf("[SessionsHandler] Failed to read created session: %v", err)
+				writeErrorResponse(w, http.StatusInternalServerError, "Session error", "Failed to create session")
+				return
+			}
+			log.Printf("[SessionsHandler] Created session %s for user %s", sessionID, userID)
+			writeJSON(w, http.StatusCreated, summarizeSession(session))
+
+		default:
+			log.Printf("[SessionsHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only GET and POST methods are supported")
+		}
+	}
+}
+
+// SessionHandler handles /sessions/{id} requests. GET returns the session
+// with its conversation history and DELETE ends it. Sessions owned by other
+// users are reported as not found so their IDs cannot be probed.
+func SessionHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[SessionHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+			log.Printf("[SessionHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only GET and DELETE methods are supported")
+			return
+		}
+
+		sessionID := strings.TrimPrefix(r.URL.Path, sessionsPathPrefix)
+		if sessionID == "" || strings.Contains(sessionID, "/") {
+			writeErrorResponse(w, http.StatusNotFound, "Not found", "Session not found")
+			return
+		}
+
+		sessions, userID, ok := sessionRequestContext(w, r, genaiProcessor, "SessionHandler")
+		if !ok {
+			return
+		}
+
+		session, err := sessions.GetSession(sessionID)
+		if err != nil || !session.AccessibleBy(userID) {
+			if err != nil && !errors.Is(err, contextpkg.ErrSessionNotFound) {
+				log.Printf("[SessionHandler] Failed to get session %s: %v", sessionID, err)
+			}
+			writeErrorResponse(w, http.StatusNotFound, "Not found", "Session not found")
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if err := sessions.DeleteSession(sessionID); err != nil && !errors.Is(err, contextpkg.ErrSessionNotFound) {
+				log.Printf("[SessionHandler] Failed to delete session %s: %v", sessionID, err)
+				writeErrorResponse(w, http.StatusInternalServerError, "Session error", "Failed to delete session")
+				return
+			}
+			log.Printf("[SessionHandler] Deleted session %s for user %s", sessionID, userID)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		history := session.ConversationHistory
+		if history == nil {
+			history = []types.ConversationEntry{}
+		}
+		writeJSON(w, http.StatusOK, sessionDetail{
+			sessionSummary:      summarizeSession(session),
+			ConversationHistory: history,
+		})
+	}
+}
+
+// sessionRequestContext resolves the session manager and the authenticated
+// user, writing an error response when either is unavailable.
+func sessionRequestContext(w http.ResponseWriter, r *http.Request, genaiProcessor *processor.GenAIProcessor, name string) (interfaces.SessionManager, string, bool) {
+	userID, _ := r.Context().Value(types.ContextKeyUserID).(string)
+	if userID == "" {
+		log.Printf("[%s] Request is not authenticated", name)
+		writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "An authenticated user is required to manage sessions")
+		return nil, "", false
+	}
+	sessions := genaiProcessor.SessionManager()
+	if sessions == nil {
+		log.Printf("[%s] Session management is not available", name)
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Service unavailable", "Session management is not available")
+		return nil, "", false
+	}
+	return sessions, userID, true
+}
+
+// writeJSON writes a JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/types"
+)
+
+// StreamQueryHandler handles POST /query/stream requests. It processes the query like
+// QueryHandler but answers with Server-Sent Events: one event per pipeline stage, the
+// provider's output as it is generated, and finally a "response" event carrying the
+// ProcessingResponse, or an "error" event if processing failed.
+func StreamQueryHandler(genaiProcessor *processor.GenAIProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+
+		// Errors before the stream starts are plain JSON responses
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[StreamQueryHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			log.Printf("[StreamQueryHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only POST method is supported")
+			return
+		}
+
+		// Parse request body
+		var req types.ProcessingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("[StreamQueryHandler] Failed to decode request body: %v", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request format", "Failed to parse JSON request body")
+			return
+		}
+
+		// Basic input validation
+		if err := validateProcessingRequest(&req); err != nil {
+			log.Printf("[StreamQueryHandler] Request validation failed: %v", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+
+		log.Printf("[StreamQueryHandler] Streaming query: %q, SessionID: %s", req.Query, req.SessionID)
+
+		// Start the event stream
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		stream := &eventStream{w: w, rc: http.NewResponseController(w)}
+
+		// Create context with timeout
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		response, err := genaiProcessor.ProcessQueryStream(ctx, &req, func(event types.StreamEvent) {
+			stream.send(event.Stage, event)
+		})
+		if err != nil {
+			log.Printf("[StreamQueryHandler] Processing failed: %v", err)
+			stream.sendError(http.StatusInternalServerError, "Processing error", "Failed to process query")
+			return
+		}
+		if response.Error != "" {
+			log.Printf("[StreamQueryHandler] Processing returned error: %s", response.Error)
+			stream.sendError(processingErrorStatus(response.Error), "Processing error", response.Error)
+			return
+		}
+
+		stream.send(types.StreamStageResponse, response)
+		if stream.err != nil {
+			log.Printf("[StreamQueryHandler] Failed to stream response: %v", stream.err)
+			return
+		}
+		log.Printf("[StreamQueryHandler] Query streamed successfully in %v", time.Since(startTime))
+	}
+}
+
+// eventStream writes Server-Sent Events, flushing each one to the client.
+// After a write fails, typically because the client went away, further
+// events are dropped.
+type eventStream struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	err error
+}
+
+// send writes one event with data encoded as JSON.
+func (s *eventStream) send(event string, data interface{}) {
+	if s.err != nil {
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.err = fmt.Errorf("failed to encode %s event: %w", event, err)
+		return
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		s.err = err
+		return
+	}
+	if err := s.rc.Flush(); err != nil {
+		s.err = err
+	}
+}
+
+// sendError writes an error event shaped like writeErrorResponse's body.
+func (s *eventStream) sendError(statusCode int, errorType, message string) {
+	s.send(types.StreamStageError, map[string]interface{}{
+		"error": map[string]interface{}{
+			"type":    errorType,
+			"message": message,
+			"code":    statusCode,
+		},
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/types"
+)
+
+// readEvents splits a Server-Sent Events body into event names and data
+func readEvents(t *testing.T, body string) (names []string, data []string) {
+	t.Helper()
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var name, payload string
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "event: ") {
+				name = strings.TrimPrefix(line, "event: ")
+			} else if strings.HasPrefix(line, "data: ") {
+				payload = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		names = append(names, name)
+		data = append(data, payload)
+	}
+	return names, data
+}
+
+func TestStreamQueryHandler_StreamsStagesThenError(t *testing.T) {
+	// Without an API key the provider call fails after the stream has started
+	genaiProcessor := processor.NewGenAIProcessor()
+	if genaiProcessor == nil {
+		t.Skip("Skipping test - could not create GenAI processor")
+	}
+
+	requestBody, err := json.Marshal(types.ProcessingRequest{
+		Query:     "Who deleted the customer CRD yesterday?",
+		SessionID: "stream-session-123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", "/query/stream", bytes.NewBuffer(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	StreamQueryHandler(genaiProcessor).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("handler returned wrong content type: got %v want text/event-stream", contentType)
+	}
+
+	names, data := readEvents(t, rr.Body.String())
+	want := []string{types.StreamStageContextResolved, types.StreamStageModelCalled, types.StreamStageError}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected events %v, want %v", names, want)
+	}
+
+	var modelCalled types.StreamEvent
+	if err := json.Unmarshal([]byte(data[1]), &modelCalled); err != nil {
+		t.Fatalf("failed to parse model_called event: %v", err)
+	}
+	if modelCalled.Stage != types.StreamStageModelCalled || modelCalled.Model == "" {
+		t.Errorf("unexpected model_called event %+v", modelCalled)
+	}
+
+	var errorEvent struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data[2]), &errorEvent); err != nil {
+		t.Fatalf("failed to parse error event: %v", err)
+	}
+	if !strings.Contains(errorEvent.Error.Message, "llm_processing_failed") {
+		t.Errorf("unexpected error event %v", errorEvent)
+	}
+}
+
+func TestStreamQueryHandler_RejectsBeforeStreaming(t *testing.T) {
+	genaiProcessor := processor.NewGenAIProcessor()
+	if genaiProcessor == nil {
+		t.Skip("Skipping test - could not create GenAI processor")
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{name: "wrong method", method: "GET", status: http.StatusMethodNotAllowed},
+		{name: "invalid JSON", method: "POST", body: "invalid json", status: http.StatusBadRequest},
+		{name: "missing session", method: "POST", body: `{"query": "who deleted pods"}`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "/query/stream", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			StreamQueryHandler(genaiProcessor).ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.status)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("handler returned wrong content type: got %v want application/json", contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"genai-processing/internal/auth"
+	"genai-processing/internal/processor"
+)
+
+// UsageHandler handles GET /usage requests, returning the token usage and
+// cost accounted since the server started together with the per-user budget.
+// Callers in one of reviewerGroups see the totals of every user, session and
+// provider; other callers only their own.
+func UsageHandler(genaiProcessor *processor.GenAIProcessor, reviewerGroups []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		log.Printf("[UsageHandler] Received %s request from %s", r.Method, r.RemoteAddr)
+
+		if r.Method != http.MethodGet {
+			log.Printf("[UsageHandler] Invalid method: %s", r.Method)
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "Only GET method is supported")
+			return
+		}
+
+		identity := auth.IdentityFromContext(r.Context())
+		if identity == nil {
+			log.Printf("[UsageHandler] Request is not authenticated")
+			writeErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "An authenticated user is required to read usage")
+			return
+		}
+		accountant := genaiProcessor.UsageAccountant()
+		if accountant == nil {
+			log.Printf("[UsageHandler] Usage accounting is not enabled")
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Service unavailable", "Usage accounting is not enabled")
+			return
+		}
+
+		if inAnyGroup(identity, reviewerGroups) {
+			log.Printf("[UsageHandler] Returned usage of all users to reviewer %s", identity.UserID)
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"usage":  accountant.Summary(),
+				"budget": accountant.Budget(),
+			})
+			return
+		}
+
+		log.Printf("[UsageHandler] Returned usage of user %s", identity.UserID)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"currency": accountant.Currency(),
+			"user_id":  identity.UserID,
+			"usage":    accountant.UserSummary(identity.UserID),
+			"budget":   accountant.Budget(),
+		})
+	}
+}
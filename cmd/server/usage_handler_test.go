@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"genai-processing/internal/processor"
+	"genai-processing/internal/usage"
+	"genai-processing/pkg/types"
+)
+
+func TestUsageHandler(t *testing.T) {
+	genaiProcessor := processor.NewGenAIProcessor()
+	if genaiProcessor == nil {
+		t.Skip("Skipping test - could not create GenAI processor")
+	}
+	handler := UsageHandler(genaiProcessor, []string{"compliance"})
+
+	request := func(user string, groups ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+		if user != "" {
+			ctx := context.WithValue(req.Context(), types.ContextKeyUserID, user)
+			ctx = context.WithValue(ctx, types.ContextKeyGroups, groups)
+			req = req.WithContext(ctx)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	genaiProcessor.SetUsageAccountant(nil)
+	if rr := request("alice"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without an accountant, got %d", rr.Code)
+	}
+
+	accountant := usage.NewAccountant(map[string]usage.Price{"gpt-4": {InputPerMillion: 30, OutputPerMillion: 60}}, "USD", usage.Budget{DailyCost: 5})
+	genaiProcessor.SetUsageAccountant(accountant)
+	tokens := func(prompt, completion int) *types.RawResponse {
+		return &types.RawResponse{Metadata: map[string]interface{}{
+			"token_usage": map[string]interface{}{"prompt_tokens": prompt, "completion_tokens": completion},
+		}}
+	}
+	accountant.Record("alice", "s1", "openai", "gpt-4", tokens(1000, 500))
+	accountant.Record("bob", "s2", "openai", "gpt-4", tokens(2000, 100))
+
+	if rr := request(""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for anonymous requests, got %d", rr.Code)
+	}
+
+	rr := request("alice")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var own struct {
+		UserID string           `json:"user_id"`
+		Usage  usage.UserTotals `json:"usage"`
+		Budget usage.Budget     `json:"budget"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &own); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if own.UserID != "alice" || own.Usage.Total.TotalTokens != 1500 || own.Budget.DailyCost != 5 {
+		t.Errorf("unexpected usage for alice: %+v", own)
+	}
+
+	rr = request("carol", "compliance")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var all struct {
+		Usage usage.Summary `json:"usage"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &all); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(all.Usage.Users) != 2 || all.Usage.Providers["openai"].Requests != 2 || all.Usage.Total.TotalTokens != 3600 {
+		t.Errorf("unexpected usage summary for reviewer: %+v", all.Usage)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/usage", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
+
+func TestProcessingErrorStatus(t *testing.T) {
+	if status := processingErrorStatus("budget_exceeded: usage budget exceeded: user alice has spent 5.0000 of the daily budget of 5.0000 USD"); status != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for a spent budget, got %d", status)
+	}
+	if status := processingErrorStatus("validation_error: query is not allowed"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for other errors, got %d", status)
+	}
+}
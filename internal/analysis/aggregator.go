@@ -0,0 +1,247 @@
+// Package analysis evaluates the aggregation fields of a StructuredQuery
+// (group_by, sort_by, sort_order, limit) and its AnalysisConfig against a
+// stream of audit events, producing grouped counts, top-N rankings and
+// threshold-triggered findings.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/types"
+)
+
+// Aggregator consumes audit events one at a time and accumulates the group
+// counts and per-analysis state needed to build an AnalysisResult. It is not
+// safe for concurrent use.
+type Aggregator struct {
+	groupBy []string
+	limit   int
+
+	sortField string
+	sortOrder string
+
+	groups map[string]*types.AggregateGroup
+	order  []string
+
+	detector detector
+	analyzed int
+}
+
+// NewAggregator creates an Aggregator for the query. The query's group_by
+// fields take precedence over analysis.group_by for grouping. defaultLimit is
+// used when the query does not set a limit.
+func NewAggregator(query *types.StructuredQuery, defaultLimit int) (*Aggregator, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+
+	names := query.GroupBy.Values()
+	sortBy, sortOrder := query.SortBy, query.SortOrder
+	if query.Analysis != nil {
+		if len(names) == 0 {
+			names = query.Analysis.GroupBy.Values()
+		}
+		if sortBy == "" {
+			sortBy, sortOrder = query.Analysis.SortBy, query.Analysis.SortOrder
+		}
+	}
+	groupBy, err := canonicalFields(names)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	a := &Aggregator{
+		groupBy:   groupBy,
+		limit:     limit,
+		sortField: sortBy,
+		sortOrder: sortOrder,
+		groups:    make(map[string]*types.AggregateGroup),
+	}
+	if query.Analysis != nil && strings.TrimSpace(query.Analysis.Type) != "" {
+		d, err := newDetector(query.Analysis)
+		if err != nil {
+			return nil, err
+		}
+		a.detector = d
+	}
+	return a, nil
+}
+
+// Active reports whether the aggregator has grouping or analysis to perform.
+func (a *Aggregator) Active() bool {
+	return len(a.groupBy) > 0 || a.detector != nil
+}
+
+// Add feeds one matching event into the aggregation.
+func (a *Aggregator) Add(ev *types.AuditEvent) {
+	a.analyzed++
+	if len(a.groupBy) > 0 {
+		key, values := groupKey(ev, a.groupBy)
+		g, ok := a.groups[key]
+		if !ok {
+			g = &types.AggregateGroup{Key: values}
+			a.groups[key] = g
+			a.order = append(a.order, key)
+		}
+		g.Count++
+		ts := ev.RequestReceivedTimestamp
+		if !ts.IsZero() {
+			if g.FirstSeen.IsZero() || ts.Before(g.FirstSeen) {
+				g.FirstSeen = ts
+			}
+			if ts.After(g.LastSeen) {
+				g.LastSeen = ts
+			}
+		}
+	}
+	if a.detector != nil {
+		a.detector.add(ev)
+	}
+}
+
+// Result returns the sorted and limited groups together with any findings.
+func (a *Aggregator) Result() *types.AnalysisResult {
+	result := &types.AnalysisResult{
+		GroupBy:        a.groupBy,
+		TotalGroups:    len(a.groups),
+		EventsAnalyzed: a.analyzed,
+	}
+
+	if len(a.groups) > 0 {
+		groups := make([]types.AggregateGroup, 0, len(a.order))
+		for _, key := range a.order {
+			groups = append(groups, *a.groups[key])
+		}
+		a.sortGroups(groups)
+		if len(groups) > a.limit {
+			groups = groups[:a.limit]
+		}
+		result.Groups = groups
+	}
+
+	if a.detector != nil {
+		result.AnalysisType = a.detector.analysisType()
+		result.Findings = a.detector.findings()
+		sortFindings(result.Findings, a.sortField, a.sortOrder)
+	}
+	return result
+}
+
+// sortGroups orders groups like the generated jq program: by count unless
+// sort_by names the timestamp (last seen) or one of the group fields. Ties
+// are broken by the group key for deterministic output.
+func (a *Aggregator) sortGroups(groups []types.AggregateGroup) {
+	sortField := a.sortField
+	if strings.TrimSpace(sortField) == "" {
+		sortField = "count"
+	}
+	field, _ := generator.CanonicalField(sortField)
+	if field != "timestamp" && !contains(a.groupBy, field) {
+		field = "count"
+	}
+	desc := generator.SortDirection(sortField, a.sortOrder) == "desc"
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		x, y := &groups[i], &groups[j]
+		if desc {
+			x, y = y, x
+		}
+		switch field {
+		case "count":
+			if x.Count != y.Count {
+				return x.Count < y.Count
+			}
+		case "timestamp":
+			if !x.LastSeen.Equal(y.LastSeen) {
+				return x.LastSeen.Before(y.LastSeen)
+			}
+		default:
+			if x.Key[field] != y.Key[field] {
+				return x.Key[field] < y.Key[field]
+			}
+		}
+		// Ties always ascend by key regardless of direction
+		return joinKey(groups[i].Key, a.groupBy) < joinKey(groups[j].Key, a.groupBy)
+	})
+}
+
+// Aggregate runs the aggregation over a slice of events.
+func Aggregate(query *types.StructuredQuery, events []types.AuditEvent, defaultLimit int) (*types.AnalysisResult, error) {
+	a, err := NewAggregator(query, defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		a.Add(&events[i])
+	}
+	return a.Result(), nil
+}
+
+// canonicalFields maps group_by values to canonical field names, rejecting
+// unknown fields and removing duplicates while preserving order.
+func canonicalFields(names []string) ([]string, error) {
+	var out []string
+	for _, n := range names {
+		f, ok := generator.CanonicalField(n)
+		if !ok || f == "timestamp" {
+			return nil, fmt.Errorf("unsupported group_by field '%s'", n)
+		}
+		if !contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// groupKey returns a map key and the field values of an event for the given fields.
+func groupKey(ev *types.AuditEvent, fields []string) (string, map[string]string) {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f] = ev.Field(f)
+	}
+	return joinKey(values, fields), values
+}
+
+// joinKey joins values in field order with a separator that cannot appear in audit fields.
+func joinKey(values map[string]string, fields []string) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = values[f]
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// windowBounds returns the earliest and latest non-zero timestamps.
+func windowBounds(times []time.Time) (time.Time, time.Time) {
+	var first, last time.Time
+	for _, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return first, last
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+var baseTime = time.Date(2025, 1, 28, 10, 0, 0, 0, time.UTC)
+
+func event(user, verb, resource, namespace string, offset time.Duration) types.AuditEvent {
+	return types.AuditEvent{
+		AuditID:                  fmt.Sprintf("%s-%s-%s-%d", user, verb, namespace, offset),
+		Verb:                     verb,
+		User:                     types.AuditUser{Username: user},
+		ObjectRef:                &types.AuditObjectRef{Resource: resource, Namespace: namespace},
+		ResponseStatus:           &types.AuditResponseStatus{Code: 200},
+		RequestReceivedTimestamp: baseTime.Add(offset),
+	}
+}
+
+func failed(ev types.AuditEvent) types.AuditEvent {
+	ev.ResponseStatus = &types.AuditResponseStatus{Code: 403}
+	ev.Annotations = map[string]string{types.AnnotationAuthorizationDecision: "forbid"}
+	return ev
+}
+
+func TestAggregate_GroupBySortLimit(t *testing.T) {
+	events := []types.AuditEvent{
+		event("alice", "get", "secrets", "prod", 0),
+		event("bob", "get", "secrets", "prod", time.Minute),
+		event("alice", "get", "secrets", "dev", 2*time.Minute),
+		event("carol", "delete", "pods", "dev", 3*time.Minute),
+		event("alice", "list", "pods", "dev", 4*time.Minute),
+		event("bob", "get", "secrets", "dev", 5*time.Minute),
+	}
+
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		GroupBy:   *types.NewStringOrArray("username"),
+		Limit:     2,
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if res.TotalGroups != 3 || len(res.Groups) != 2 {
+		t.Fatalf("expected top 2 of 3 groups, got %d of %d", len(res.Groups), res.TotalGroups)
+	}
+	if res.Groups[0].Key["user"] != "alice" || res.Groups[0].Count != 3 {
+		t.Errorf("expected alice with 3 events first, got %+v", res.Groups[0])
+	}
+	if res.Groups[1].Key["user"] != "bob" || res.Groups[1].Count != 2 {
+		t.Errorf("expected bob with 2 events second, got %+v", res.Groups[1])
+	}
+	if !res.Groups[0].FirstSeen.Equal(baseTime) || !res.Groups[0].LastSeen.Equal(baseTime.Add(4*time.Minute)) {
+		t.Errorf("unexpected first/last seen: %v/%v", res.Groups[0].FirstSeen, res.Groups[0].LastSeen)
+	}
+
+	query = &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		GroupBy:   *types.NewStringOrArray([]string{"user", "namespace"}),
+		SortBy:    "user",
+		SortOrder: "desc",
+	}
+	res, err = Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Groups) != 5 {
+		t.Fatalf("expected 5 user/namespace groups, got %d", len(res.Groups))
+	}
+	if res.Groups[0].Key["user"] != "carol" || res.Groups[len(res.Groups)-1].Key["user"] != "alice" {
+		t.Errorf("groups not sorted by user descending: %+v", res.Groups)
+	}
+}
+
+func TestAggregate_UnsupportedGroupBy(t *testing.T) {
+	query := &types.StructuredQuery{LogSource: "kube-apiserver", GroupBy: *types.NewStringOrArray("colour")}
+	if _, err := Aggregate(query, nil, 20); err == nil {
+		t.Error("expected error for unsupported group_by field")
+	}
+	query = &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "sentiment"}}
+	if _, err := Aggregate(query, nil, 20); err == nil {
+		t.Error("expected error for unsupported analysis type")
+	}
+	query = &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: TypeExcessiveReads, TimeWindow: "forever"}}
+	if _, err := Aggregate(query, nil, 20); err == nil {
+		t.Error("expected error for unsupported time window")
+	}
+}
+
+func TestAnalysis_MultiNamespaceAccess(t *testing.T) {
+	events := []types.AuditEvent{
+		event("alice", "get", "secrets", "prod", 0),
+		event("alice", "get", "secrets", "staging", 10*time.Minute),
+		event("alice", "get", "secrets", "dev", 20*time.Minute),
+		event("bob", "get", "secrets", "prod", 0),
+		event("bob", "get", "secrets", "dev", 2*time.Hour),
+		event("bob", "get", "secrets", "staging", 4*time.Hour),
+	}
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis:  &types.AnalysisConfig{Type: TypeMultiNamespaceAccess, Threshold: 3, TimeWindow: "short"},
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if res.AnalysisType != TypeMultiNamespaceAccess || len(res.Findings) != 1 {
+		t.Fatalf("expected one finding, got %+v", res.Findings)
+	}
+	f := res.Findings[0]
+	if f.Subject["user"] != "alice" || f.Count != 3 || len(f.Values) != 3 {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if len(res.Groups) != 0 {
+		t.Errorf("no groups expected without group_by, got %d", len(res.Groups))
+	}
+}
+
+func TestAnalysis_ExcessiveReads(t *testing.T) {
+	var events []types.AuditEvent
+	for i := 0; i < 5; i++ {
+		events = append(events, event("scanner", "list", "secrets", "prod", time.Duration(i)*time.Minute))
+	}
+	events = append(events, event("scanner", "delete", "secrets", "prod", 6*time.Minute))
+	events = append(events, event("alice", "get", "pods", "dev", 0))
+
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis:  &types.AnalysisConfig{Type: TypeExcessiveReads, Threshold: 5},
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].Subject["user"] != "scanner" || res.Findings[0].Count != 5 {
+		t.Fatalf("unexpected findings: %+v", res.Findings)
+	}
+	if len(res.Findings[0].AuditIDs) != 5 {
+		t.Errorf("expected 5 contributing audit IDs, got %d", len(res.Findings[0].AuditIDs))
+	}
+}
+
+func TestAnalysis_PrivilegeEscalation(t *testing.T) {
+	events := []types.AuditEvent{
+		event("mallory", "create", "clusterrolebindings", "", 0),
+		failed(event("eve", "create", "clusterrolebindings", "", 0)),
+		event("alice", "get", "clusterroles", "", 0),
+		event("bob", "bind", "roles", "dev", 0),
+	}
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis:  &types.AnalysisConfig{Type: TypePrivilegeEscalation},
+		SortBy:    "user",
+		SortOrder: "asc",
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Findings) != 2 {
+		t.Fatalf("expected findings for bob and mallory, got %+v", res.Findings)
+	}
+	if res.Findings[0].Subject["user"] != "bob" || res.Findings[1].Subject["user"] != "mallory" {
+		t.Errorf("findings not sorted by user: %+v", res.Findings)
+	}
+	if res.Findings[0].Severity != SeverityHigh {
+		t.Errorf("expected high severity, got %s", res.Findings[0].Severity)
+	}
+}
+
+func TestAnalysis_AnomalyDetection(t *testing.T) {
+	var events []types.AuditEvent
+	users := []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "u9"}
+	for _, u := range users {
+		events = append(events, event(u, "get", "pods", "dev", 0))
+	}
+	for i := 0; i < 40; i++ {
+		events = append(events, event("burst", "get", "pods", "dev", time.Duration(i)*time.Second))
+	}
+
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis:  &types.AnalysisConfig{Type: TypeAnomalyDetection, Threshold: 2, TimeWindow: "short"},
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].Subject["user"] != "burst" || res.Findings[0].Count != 40 {
+		t.Fatalf("expected a single anomaly for burst, got %+v", res.Findings)
+	}
+	if !res.Findings[0].WindowEnd.Equal(res.Findings[0].WindowStart.Add(time.Hour)) {
+		t.Errorf("anomaly window should span the analysis time window")
+	}
+}
+
+func TestAnalysis_Correlation(t *testing.T) {
+	events := []types.AuditEvent{
+		failed(event("mallory", "get", "secrets", "prod", 0)),
+		failed(event("mallory", "get", "secrets", "prod", time.Minute)),
+		failed(event("mallory", "create", "rolebindings", "prod", 2*time.Minute)),
+		event("mallory", "create", "rolebindings", "prod", 3*time.Minute),
+		failed(event("alice", "get", "secrets", "prod", 0)),
+		event("alice", "update", "configmaps", "prod", time.Minute),
+	}
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis:  &types.AnalysisConfig{Type: TypeCorrelation, Threshold: 3, TimeWindow: "short"},
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Findings) != 1 {
+		t.Fatalf("expected one correlation finding, got %+v", res.Findings)
+	}
+	f := res.Findings[0]
+	if f.Subject["user"] != "mallory" || f.Count != 3 || f.Severity != SeverityCritical {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if len(f.AuditIDs) != 4 {
+		t.Errorf("expected failures and the successful change as evidence, got %v", f.AuditIDs)
+	}
+}
+
+func TestAnalysis_GroupBySubject(t *testing.T) {
+	var events []types.AuditEvent
+	for i := 0; i < 3; i++ {
+		events = append(events, event("alice", "get", "secrets", "prod", time.Duration(i)*time.Minute))
+		events = append(events, event("bob", "get", "secrets", "prod", time.Duration(i)*time.Minute))
+	}
+	query := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Analysis: &types.AnalysisConfig{
+			Type:      TypeExcessiveReads,
+			Threshold: 5,
+			GroupBy:   types.NewStringOrArray("namespace"),
+		},
+	}
+	res, err := Aggregate(query, events, 20)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].Subject["namespace"] != "prod" || res.Findings[0].Count != 6 {
+		t.Fatalf("expected one finding per namespace, got %+v", res.Findings)
+	}
+	if len(res.Groups) != 1 || res.Groups[0].Count != 6 {
+		t.Errorf("analysis group_by should also produce groups, got %+v", res.Groups)
+	}
+}
@@ -0,0 +1,497 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/types"
+)
+
+// Analysis types accepted in AnalysisConfig.Type.
+const (
+	TypeMultiNamespaceAccess = "multi_namespace_access"
+	TypeExcessiveReads       = "excessive_reads"
+	TypePrivilegeEscalation  = "privilege_escalation"
+	TypeAnomalyDetection     = "anomaly_detection"
+	TypeCorrelation          = "correlation"
+)
+
+// Severity levels assigned to findings, matching severity_levels in rules.yaml.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+)
+
+// timeWindows maps AnalysisConfig.TimeWindow values to durations.
+var timeWindows = map[string]time.Duration{
+	"short":  time.Hour,
+	"medium": 24 * time.Hour,
+	"long":   7 * 24 * time.Hour,
+}
+
+// defaultTimeWindow is used when AnalysisConfig.TimeWindow is empty.
+const defaultTimeWindow = "medium"
+
+// defaultThresholds are used when AnalysisConfig.Threshold is unset. For
+// anomaly_detection the threshold is a z-score rather than an event count.
+var defaultThresholds = map[string]int{
+	TypeMultiNamespaceAccess: 3,
+	TypeExcessiveReads:       100,
+	TypePrivilegeEscalation:  1,
+	TypeAnomalyDetection:     3,
+	TypeCorrelation:          3,
+}
+
+// readVerbs are the verbs counted by excessive_reads.
+var readVerbs = map[string]bool{"get": true, "list": true, "watch": true}
+
+// mutatingVerbs are the verbs treated as changes by correlation.
+var mutatingVerbs = map[string]bool{"create": true, "update": true, "patch": true, "delete": true, "deletecollection": true}
+
+// rbacResources are the resources whose modification can grant privileges.
+var rbacResources = map[string]bool{
+	"roles":               true,
+	"rolebindings":        true,
+	"clusterroles":        true,
+	"clusterrolebindings": true,
+}
+
+// escalationVerbs are RBAC verbs that directly grant or assume privileges.
+var escalationVerbs = map[string]bool{"escalate": true, "bind": true, "impersonate": true}
+
+// TimeWindowDuration returns the duration of an analysis time window, and
+// false if the window name is not known.
+func TimeWindowDuration(name string) (time.Duration, bool) {
+	if strings.TrimSpace(name) == "" {
+		name = defaultTimeWindow
+	}
+	d, ok := timeWindows[strings.ToLower(strings.TrimSpace(name))]
+	return d, ok
+}
+
+// detector accumulates events for one analysis type and produces findings.
+type detector interface {
+	add(ev *types.AuditEvent)
+	findings() []types.AnalysisFinding
+	analysisType() string
+}
+
+// subjectEvent is the per-event state kept by detectors. Request and response
+// bodies are not retained.
+type subjectEvent struct {
+	at      time.Time
+	value   string
+	auditID string
+	failed  bool
+}
+
+// baseDetector groups events by subject and holds the common configuration.
+type baseDetector struct {
+	kind      string
+	threshold int
+	window    time.Duration
+	subject   []string
+
+	subjects map[string]map[string]string
+	events   map[string][]subjectEvent
+	order    []string
+}
+
+func newDetector(cfg *types.AnalysisConfig) (detector, error) {
+	kind := strings.ToLower(strings.TrimSpace(cfg.Type))
+	defaultThreshold, ok := defaultThresholds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported analysis type '%s'", cfg.Type)
+	}
+	window, ok := TimeWindowDuration(cfg.TimeWindow)
+	if !ok {
+		return nil, fmt.Errorf("unsupported analysis time_window '%s'", cfg.TimeWindow)
+	}
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = defaultThreshold
+	}
+
+	subject := []string{"user"}
+	if kind != TypeMultiNamespaceAccess {
+		fields, err := canonicalFields(cfg.GroupBy.Values())
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			subject = fields
+		}
+	}
+
+	base := &baseDetector{
+		kind:      kind,
+		threshold: threshold,
+		window:    window,
+		subject:   subject,
+		subjects:  make(map[string]map[string]string),
+		events:    make(map[string][]subjectEvent),
+	}
+	switch kind {
+	case TypeMultiNamespaceAccess:
+		return &multiNamespaceDetector{base}, nil
+	case TypeExcessiveReads:
+		return &excessiveReadsDetector{base}, nil
+	case TypePrivilegeEscalation:
+		return &privilegeEscalationDetector{base}, nil
+	case TypeAnomalyDetection:
+		return &anomalyDetector{base}, nil
+	default:
+		return &correlationDetector{base}, nil
+	}
+}
+
+func (b *baseDetector) analysisType() string { return b.kind }
+
+// record stores an event under its subject.
+func (b *baseDetector) record(ev *types.AuditEvent, value string, failed bool) {
+	key, values := groupKey(ev, b.subject)
+	if _, ok := b.subjects[key]; !ok {
+		b.subjects[key] = values
+		b.order = append(b.order, key)
+	}
+	b.events[key] = append(b.events[key], subjectEvent{
+		at:      ev.RequestReceivedTimestamp,
+		value:   value,
+		auditID: ev.AuditID,
+		failed:  failed,
+	})
+}
+
+// sortedEvents returns a subject's events in timestamp order.
+func (b *baseDetector) sortedEvents(key string) []subjectEvent {
+	events := b.events[key]
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+	return events
+}
+
+// subjectLabel renders a subject for descriptions, e.g. "user alice".
+func (b *baseDetector) subjectLabel(key string) string {
+	parts := make([]string, 0, len(b.subject))
+	for _, f := range b.subject {
+		v := b.subjects[key][f]
+		if v == "" {
+			v = "(none)"
+		}
+		parts = append(parts, strings.ReplaceAll(f, "_", " ")+" "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (b *baseDetector) finding(key string, severity string, count int, events []subjectEvent, description string) types.AnalysisFinding {
+	times := make([]time.Time, 0, len(events))
+	var ids []string
+	for _, e := range events {
+		times = append(times, e.at)
+		if e.auditID != "" {
+			ids = append(ids, e.auditID)
+		}
+	}
+	start, end := windowBounds(times)
+	return types.AnalysisFinding{
+		Type:        b.kind,
+		Severity:    severity,
+		Subject:     b.subjects[key],
+		Count:       count,
+		Threshold:   b.threshold,
+		Description: description,
+		WindowStart: start,
+		WindowEnd:   end,
+		AuditIDs:    ids,
+	}
+}
+
+// densestWindow returns the contiguous run of events within the window that
+// maximises score. score is evaluated over events[i:j+1].
+func (b *baseDetector) densestWindow(events []subjectEvent, score func([]subjectEvent) int) (int, []subjectEvent) {
+	best, bestStart, bestEnd := 0, 0, -1
+	start := 0
+	for end := range events {
+		for events[end].at.Sub(events[start].at) > b.window {
+			start++
+		}
+		if s := score(events[start : end+1]); s > best {
+			best, bestStart, bestEnd = s, start, end
+		}
+	}
+	return best, events[bestStart : bestEnd+1]
+}
+
+// multiNamespaceDetector flags users touching at least threshold distinct
+// namespaces within the time window.
+type multiNamespaceDetector struct{ *baseDetector }
+
+func (d *multiNamespaceDetector) add(ev *types.AuditEvent) {
+	if ns := ev.Field("namespace"); ns != "" {
+		d.record(ev, ns, false)
+	}
+}
+
+func (d *multiNamespaceDetector) findings() []types.AnalysisFinding {
+	var out []types.AnalysisFinding
+	for _, key := range d.order {
+		count, run := d.densestWindow(d.sortedEvents(key), distinctValues)
+		if count < d.threshold {
+			continue
+		}
+		f := d.finding(key, SeverityMedium, count, run,
+			fmt.Sprintf("%s accessed %d namespaces within %s", d.subjectLabel(key), count, d.window))
+		f.Values = uniqueValues(run)
+		out = append(out, f)
+	}
+	return out
+}
+
+// excessiveReadsDetector flags subjects issuing at least threshold read
+// requests within the time window.
+type excessiveReadsDetector struct{ *baseDetector }
+
+func (d *excessiveReadsDetector) add(ev *types.AuditEvent) {
+	if readVerbs[strings.ToLower(ev.Verb)] {
+		d.record(ev, ev.Field("resource"), false)
+	}
+}
+
+func (d *excessiveReadsDetector) findings() []types.AnalysisFinding {
+	var out []types.AnalysisFinding
+	for _, key := range d.order {
+		count, run := d.densestWindow(d.sortedEvents(key), eventCount)
+		if count < d.threshold {
+			continue
+		}
+		f := d.finding(key, SeverityMedium, count, run,
+			fmt.Sprintf("%s made %d read requests within %s", d.subjectLabel(key), count, d.window))
+		f.Values = uniqueValues(run)
+		out = append(out, f)
+	}
+	return out
+}
+
+// privilegeEscalationDetector flags subjects that modify RBAC objects or use
+// the escalate, bind or impersonate verbs at least threshold times within the
+// time window. Requests the API server rejected are not counted.
+type privilegeEscalationDetector struct{ *baseDetector }
+
+func (d *privilegeEscalationDetector) add(ev *types.AuditEvent) {
+	verb := strings.ToLower(ev.Verb)
+	resource := strings.ToLower(ev.Field("resource"))
+	sensitive := escalationVerbs[verb] ||
+		(rbacResources[resource] && mutatingVerbs[verb]) ||
+		ev.ImpersonatedUser != nil
+	if !sensitive || isFailure(ev) {
+		return
+	}
+	d.record(ev, verb+" "+resource, false)
+}
+
+func (d *privilegeEscalationDetector) findings() []types.AnalysisFinding {
+	var out []types.AnalysisFinding
+	for _, key := range d.order {
+		count, run := d.densestWindow(d.sortedEvents(key), eventCount)
+		if count < d.threshold {
+			continue
+		}
+		f := d.finding(key, SeverityHigh, count, run,
+			fmt.Sprintf("%s performed %d privilege-sensitive operations within %s", d.subjectLabel(key), count, d.window))
+		f.Values = uniqueValues(run)
+		out = append(out, f)
+	}
+	return out
+}
+
+// anomalyDetector buckets each subject's events into fixed time windows and
+// flags buckets whose count is at least threshold standard deviations above
+// the mean bucket count across all subjects.
+type anomalyDetector struct{ *baseDetector }
+
+func (d *anomalyDetector) add(ev *types.AuditEvent) {
+	if !ev.RequestReceivedTimestamp.IsZero() {
+		d.record(ev, "", false)
+	}
+}
+
+func (d *anomalyDetector) findings() []types.AnalysisFinding {
+	type bucket struct {
+		key    string
+		start  time.Time
+		events []subjectEvent
+	}
+	var buckets []*bucket
+	for _, key := range d.order {
+		index := make(map[int64]*bucket)
+		for _, e := range d.sortedEvents(key) {
+			start := e.at.Truncate(d.window)
+			b, ok := index[start.UnixNano()]
+			if !ok {
+				b = &bucket{key: key, start: start}
+				index[start.UnixNano()] = b
+				buckets = append(buckets, b)
+			}
+			b.events = append(b.events, e)
+		}
+	}
+	if len(buckets) < 2 {
+		return nil
+	}
+
+	var sum, sumSq float64
+	for _, b := range buckets {
+		n := float64(len(b.events))
+		sum += n
+		sumSq += n * n
+	}
+	mean := sum / float64(len(buckets))
+	stddev := math.Sqrt(sumSq/float64(len(buckets)) - mean*mean)
+	if stddev == 0 {
+		return nil
+	}
+
+	var out []types.AnalysisFinding
+	for _, b := range buckets {
+		z := (float64(len(b.events)) - mean) / stddev
+		if z < float64(d.threshold) {
+			continue
+		}
+		f := d.finding(b.key, SeverityMedium, len(b.events), b.events,
+			fmt.Sprintf("%s made %d requests in the %s window starting %s, %.1f standard deviations above the mean of %.1f",
+				d.subjectLabel(b.key), len(b.events), d.window, b.start.UTC().Format(time.RFC3339), z, mean))
+		f.WindowStart = b.start
+		f.WindowEnd = b.start.Add(d.window)
+		out = append(out, f)
+	}
+	return out
+}
+
+// correlationDetector links failed requests (4xx responses or forbid/error
+// authorization decisions) to a subsequent successful change by the same
+// subject. It flags subjects with at least threshold failures followed by a
+// successful create, update, patch or delete within the time window.
+type correlationDetector struct{ *baseDetector }
+
+func (d *correlationDetector) add(ev *types.AuditEvent) {
+	failed := isFailure(ev)
+	if !failed && !mutatingVerbs[strings.ToLower(ev.Verb)] {
+		return
+	}
+	d.record(ev, strings.ToLower(ev.Verb)+" "+ev.Field("resource"), failed)
+}
+
+func (d *correlationDetector) findings() []types.AnalysisFinding {
+	var out []types.AnalysisFinding
+	for _, key := range d.order {
+		events := d.sortedEvents(key)
+		for i, e := range events {
+			if e.failed {
+				continue
+			}
+			// Count failures preceding this successful change within the window
+			start := i
+			failures := 0
+			for j := i - 1; j >= 0 && e.at.Sub(events[j].at) <= d.window; j-- {
+				start = j
+				if events[j].failed {
+					failures++
+				}
+			}
+			if failures < d.threshold {
+				continue
+			}
+			run := events[start : i+1]
+			f := d.finding(key, SeverityCritical, failures, run,
+				fmt.Sprintf("%s had %d failed requests followed by a successful %s within %s", d.subjectLabel(key), failures, e.value, d.window))
+			f.Values = uniqueValues(run)
+			out = append(out, f)
+			break
+		}
+	}
+	return out
+}
+
+// isFailure reports whether the request was rejected by the API server.
+func isFailure(ev *types.AuditEvent) bool {
+	switch ev.Annotations[types.AnnotationAuthorizationDecision] {
+	case "forbid", "error":
+		return true
+	}
+	if ev.ResponseStatus != nil && ev.ResponseStatus.Code >= 400 && ev.ResponseStatus.Code < 500 {
+		return true
+	}
+	return false
+}
+
+func eventCount(events []subjectEvent) int { return len(events) }
+
+func distinctValues(events []subjectEvent) int { return len(uniqueValues(events)) }
+
+// uniqueValues returns the sorted distinct non-empty values of the events.
+func uniqueValues(events []subjectEvent) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, e := range events {
+		if e.value != "" && !seen[e.value] {
+			seen[e.value] = true
+			out = append(out, e.value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sortFindings orders findings by count (descending by default), or by
+// subject user or window end when sort_by asks for it.
+func sortFindings(findings []types.AnalysisFinding, sortBy, sortOrder string) {
+	field, ok := generator.CanonicalField(sortBy)
+	if !ok || (field != "user" && field != "timestamp") {
+		field = "count"
+	}
+	if strings.TrimSpace(sortBy) == "" {
+		sortBy = "count"
+	}
+	desc := generator.SortDirection(sortBy, sortOrder) == "desc"
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		x, y := &findings[i], &findings[j]
+		if desc {
+			x, y = y, x
+		}
+		switch field {
+		case "user":
+			if x.Subject["user"] != y.Subject["user"] {
+				return x.Subject["user"] < y.Subject["user"]
+			}
+		case "timestamp":
+			if !x.WindowEnd.Equal(y.WindowEnd) {
+				return x.WindowEnd.Before(y.WindowEnd)
+			}
+		default:
+			if x.Count != y.Count {
+				return x.Count < y.Count
+			}
+		}
+		return findingKey(&findings[i]) < findingKey(&findings[j])
+	})
+}
+
+// findingKey is a deterministic tie-breaker for findings.
+func findingKey(f *types.AnalysisFinding) string {
+	keys := make([]string, 0, len(f.Subject))
+	for k := range f.Subject {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, f.Subject[k])
+	}
+	parts = append(parts, f.WindowStart.UTC().Format(time.RFC3339Nano))
+	return strings.Join(parts, "\x00")
+}
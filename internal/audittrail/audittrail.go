@@ -0,0 +1,147 @@
+// Package audittrail keeps an append-only record of every query processed:
+// who asked what, how it was resolved, which provider answered and what was
+// returned. Records are written by the processor and read back for
+// compliance reviews.
+package audittrail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// Record outcomes
+const (
+	// OutcomeSuccess is a query that passed safety validation
+	OutcomeSuccess = "success"
+
+	// OutcomeRejected is a query that was generated but failed safety validation
+	OutcomeRejected = "rejected"
+
+	// OutcomeError is a request that failed before a query could be validated
+	OutcomeError = "error"
+)
+
+// DefaultQueryLimit bounds the records returned by a Query without a limit.
+const DefaultQueryLimit = 100
+
+// Record is the audit trail entry for one processed query.
+type Record struct {
+	// RequestID uniquely identifies the request, and is returned in its response
+	RequestID string `json:"request_id"`
+
+	// Timestamp is when processing started
+	Timestamp time.Time `json:"timestamp"`
+
+	// UserID is the authenticated caller; empty for anonymous requests
+	UserID string `json:"user_id,omitempty"`
+
+	// SessionID is the conversation session the query belongs to
+	SessionID string `json:"session_id,omitempty"`
+
+	// Query is the natural language query as received
+	Query string `json:"query"`
+
+	// ResolvedQuery is the query after conversation context resolution
+	ResolvedQuery string `json:"resolved_query,omitempty"`
+
+	// Provider and Model identify the LLM that produced the structured query
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	// RawOutputSHA256 is the hex SHA-256 of the LLM output; empty when no
+	// LLM was called
+	RawOutputSHA256 string `json:"raw_output_sha256,omitempty"`
+
+	// Usage is the priced token usage of the provider call; nil when no
+	// LLM was called
+	Usage *types.TokenUsage `json:"usage,omitempty"`
+
+	// Cache is the response cache outcome, HIT or MISS; empty when caching is disabled
+	Cache string `json:"cache,omitempty"`
+
+	// StructuredQuery is the query returned to the caller
+	StructuredQuery *types.StructuredQuery `json:"structured_query,omitempty"`
+
+	// Validation is the safety validation result
+	Validation *interfaces.ValidationResult `json:"validation,omitempty"`
+
+	// LatencyMS is the processing time in milliseconds
+	LatencyMS int64 `json:"latency_ms"`
+
+	// Outcome is OutcomeSuccess, OutcomeRejected or OutcomeError
+	Outcome string `json:"outcome"`
+
+	// Error is the error returned for OutcomeError
+	Error string `json:"error,omitempty"`
+}
+
+// Filter selects records. Empty fields match every record.
+type Filter struct {
+	RequestID string
+	UserID    string
+	SessionID string
+	Outcome   string
+
+	// Since and Until bound the record timestamp, inclusive and exclusive
+	Since time.Time
+	Until time.Time
+
+	// Limit is the maximum number of records returned; non-positive uses
+	// DefaultQueryLimit
+	Limit int
+}
+
+// Matches reports whether a record is selected by the filter.
+func (f Filter) Matches(r *Record) bool {
+	switch {
+	case f.RequestID != "" && r.RequestID != f.RequestID:
+		return false
+	case f.UserID != "" && r.UserID != f.UserID:
+		return false
+	case f.SessionID != "" && r.SessionID != f.SessionID:
+		return false
+	case f.Outcome != "" && r.Outcome != f.Outcome:
+		return false
+	case !f.Since.IsZero() && r.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Timestamp.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	return f.Limit
+}
+
+// Store persists audit trail records. Records are only ever appended.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Append writes a record
+	Append(record *Record) error
+
+	// Query returns the records matching the filter, newest first
+	Query(filter Filter) ([]*Record, error)
+
+	// Close releases the store's resources
+	Close() error
+}
+
+// Ensure the stores implement the interface
+var (
+	_ Store = (*FileStore)(nil)
+	_ Store = (*SQLStore)(nil)
+)
+
+// HashOutput returns the hex SHA-256 of raw LLM output.
+func HashOutput(output string) string {
+	sum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(sum[:])
+}
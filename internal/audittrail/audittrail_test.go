@@ -0,0 +1,412 @@
+package audittrail
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+func testRecord(id, user, outcome string, at time.Time) *Record {
+	return &Record{
+		RequestID:       id,
+		Timestamp:       at,
+		UserID:          user,
+		SessionID:       "session-" + user,
+		Query:           "who deleted pods today",
+		Provider:        "claude",
+		RawOutputSHA256: HashOutput(`{"log_source":"kube-apiserver"}`),
+		StructuredQuery: &types.StructuredQuery{LogSource: "kube-apiserver"},
+		LatencyMS:       12,
+		Outcome:         outcome,
+	}
+}
+
+func requestIDs(records []*Record) []string {
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.RequestID)
+	}
+	return ids
+}
+
+func TestFilter_Matches(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	record := testRecord("r1", "alice", OutcomeSuccess, at)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"user", Filter{UserID: "alice"}, true},
+		{"other user", Filter{UserID: "bob"}, false},
+		{"session", Filter{SessionID: "session-alice"}, true},
+		{"outcome", Filter{Outcome: OutcomeRejected}, false},
+		{"request", Filter{RequestID: "r2"}, false},
+		{"since inclusive", Filter{Since: at}, true},
+		{"until exclusive", Filter{Until: at}, false},
+		{"window", Filter{Since: at.Add(-time.Hour), Until: at.Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(record); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileStore_AppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trail", "audit.jsonl")
+	store, err := NewFileStore(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, user := range []string{"alice", "bob", "alice"} {
+		if err := store.Append(testRecord(fmt.Sprintf("r%d", i+1), user, OutcomeSuccess, at.Add(time.Duration(i)*time.Minute))); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r3", "r2", "r1"}) {
+		t.Errorf("expected newest first, got %v", got)
+	}
+	if records[0].StructuredQuery.LogSource != "kube-apiserver" || records[0].RawOutputSHA256 == "" {
+		t.Errorf("record not round-tripped: %+v", records[0])
+	}
+
+	records, _ = store.Query(Filter{UserID: "alice", Limit: 1})
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r3"}) {
+		t.Errorf("unexpected filtered records %v", got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a private audit trail file, got %v, %v", info, err)
+	}
+}
+
+func TestFileStore_QueryReadsNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// An undecodable oldest line shows when a query reads the whole file
+	if err := os.WriteFile(path, []byte("not a record\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	// Records longer than a read chunk span several reads
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		record := testRecord(fmt.Sprintf("r%d", i), "alice", OutcomeSuccess, at.Add(time.Duration(i)*time.Minute))
+		record.Query = strings.Repeat(fmt.Sprint(i), scanChunkSize+100)
+		if err := store.Append(record); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	records, err := store.Query(Filter{Limit: 3})
+	if err != nil {
+		t.Fatalf("expected the query to stop at its limit before the undecodable line, got %v", err)
+	}
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r3", "r2", "r1"}) {
+		t.Errorf("expected newest first, got %v", got)
+	}
+	if records[1].Query != strings.Repeat("2", scanChunkSize+100) {
+		t.Error("expected a long record to be read whole")
+	}
+	if _, err := store.Query(Filter{Limit: 4}); err == nil {
+		t.Error("expected a query past the newest records to reach the undecodable line")
+	}
+}
+
+func TestFileStore_QueryDuringAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	store, err := NewFileStore(path, 2048, 3)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	done := make(chan error, 1)
+	go func() {
+		for i := 1; i <= 200; i++ {
+			if err := store.Append(testRecord(fmt.Sprintf("r%d", i), "alice", OutcomeSuccess, at.Add(time.Duration(i)*time.Second))); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	for finished := false; !finished; {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Append returned error: %v", err)
+			}
+			finished = true
+		default:
+		}
+		records, err := store.Query(Filter{Limit: 5})
+		if err != nil {
+			t.Fatalf("Query returned error during appends: %v", err)
+		}
+		for i := 1; i < len(records); i++ {
+			if !records[i].Timestamp.Before(records[i-1].Timestamp) {
+				t.Fatalf("expected newest first, got %v", requestIDs(records))
+			}
+		}
+	}
+}
+
+func TestFileStore_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	store, err := NewFileStore(path, 1, 2)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return current }
+
+	// A one-byte limit rotates before every record after the first
+	for i := 1; i <= 5; i++ {
+		current = current.Add(time.Second)
+		if err := store.Append(testRecord(fmt.Sprintf("r%d", i), "alice", OutcomeSuccess, current)); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + "-*")
+	if len(backups) != 2 {
+		t.Errorf("expected 2 rotated files, got %v", backups)
+	}
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r5", "r4", "r3"}) {
+		t.Errorf("expected records from the current and kept files, got %v", got)
+	}
+}
+
+func TestFileStore_RotationKeepsUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	unrelated := []string{path + "-old", path + "-copy.bak"}
+	for _, name := range unrelated {
+		if err := os.WriteFile(name, []byte("not an audit trail\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := NewFileStore(path, 1, 1)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return current }
+	for i := 1; i <= 4; i++ {
+		current = current.Add(time.Second)
+		if err := store.Append(testRecord(fmt.Sprintf("r%d", i), "alice", OutcomeSuccess, current)); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+
+	for _, name := range unrelated {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expected %s to survive pruning, got %v", name, err)
+		}
+	}
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r4", "r3"}) {
+		t.Errorf("expected records from the current and kept files only, got %v", got)
+	}
+}
+
+func TestFileStore_FailedRotationKeepsWriting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	store, err := NewFileStore(path, 1, 5)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return current }
+
+	if err := store.Append(testRecord("r1", "alice", OutcomeSuccess, current)); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	// A non-empty directory where the backup would go makes the rename fail
+	blocker := path + "-" + current.Format(backupTimeFormat)
+	if err := os.MkdirAll(filepath.Join(blocker, "occupied"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(testRecord("r2", "alice", OutcomeSuccess, current)); err == nil || !strings.Contains(err.Error(), "rotate") {
+		t.Fatalf("expected a rotation error, got %v", err)
+	}
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+
+	current = current.Add(time.Second)
+	if err := store.Append(testRecord("r3", "alice", OutcomeSuccess, current)); err != nil {
+		t.Fatalf("expected the store to recover after a failed rotation, got %v", err)
+	}
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r3", "r2", "r1"}) {
+		t.Errorf("expected no records to be lost, got %v", got)
+	}
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	store, _ := NewFileStore(path, 0, 0)
+	_ = store.Append(testRecord("r1", "alice", OutcomeSuccess, time.Now()))
+	store.Close()
+	if err := store.Append(testRecord("r2", "alice", OutcomeSuccess, time.Now())); err == nil {
+		t.Error("expected error appending to a closed store")
+	}
+
+	reopened, err := NewFileStore(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer reopened.Close()
+	_ = reopened.Append(testRecord("r2", "alice", OutcomeSuccess, time.Now()))
+	records, _ := reopened.Query(Filter{})
+	if got := requestIDs(records); !reflect.DeepEqual(got, []string{"r2", "r1"}) {
+		t.Errorf("expected records to be appended across restarts, got %v", got)
+	}
+}
+
+func TestSQLStore_SelectSQL(t *testing.T) {
+	since := time.Unix(100, 0)
+	filter := Filter{UserID: "alice", Outcome: OutcomeRejected, Since: since, Limit: 5}
+
+	postgres := &SQLStore{table: "trail", dollarStyle: true}
+	query, args := postgres.selectSQL(filter)
+	want := "SELECT record FROM trail WHERE user_id = $1 AND outcome = $2 AND timestamp_unix_nano >= $3 ORDER BY timestamp_unix_nano DESC LIMIT 5"
+	if query != want {
+		t.Errorf("postgres query = %q, want %q", query, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"alice", OutcomeRejected, since.UnixNano()}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	mysql := &SQLStore{table: "trail"}
+	query, _ = mysql.selectSQL(Filter{SessionID: "s1"})
+	if query != "SELECT record FROM trail WHERE session_id = ? ORDER BY timestamp_unix_nano DESC LIMIT 100" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
+
+func TestSQLStore_AppendAndQuery(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{})
+	store, err := NewSQLStore(db, "fake", "")
+	if err != nil {
+		t.Fatalf("NewSQLStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	record := testRecord("r1", "alice", OutcomeSuccess, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err := store.Append(record); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	records, err := store.Query(Filter{UserID: "alice"})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if len(records) != 1 || records[0].RequestID != "r1" || !records[0].Timestamp.Equal(record.Timestamp) {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	if _, err := NewSQLStore(db, "fake", "trail; DROP TABLE users"); err == nil {
+		t.Error("expected error for an invalid table name")
+	}
+}
+
+// fakeConnector is a database/sql driver that keeps inserted records in
+// memory and returns all of them, newest first, for any SELECT.
+type fakeConnector struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{ c *fakeConnector }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.c, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, fmt.Errorf("not supported") }
+
+type fakeStmt struct {
+	c     *fakeConnector
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(s.query, "INSERT") {
+		s.c.mu.Lock()
+		s.c.records = append(s.c.records, args[len(args)-1].(string))
+		s.c.mu.Unlock()
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	rows := &fakeRows{}
+	for i := len(s.c.records) - 1; i >= 0; i-- {
+		rows.values = append(rows.values, s.c.records[i])
+	}
+	return rows, nil
+}
+
+type fakeRows struct{ values []string }
+
+func (r *fakeRows) Columns() []string { return []string{"record"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
@@ -0,0 +1,307 @@
+package audittrail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File store defaults
+const (
+	DefaultMaxFileSize = 100 << 20
+	DefaultMaxBackups  = 10
+)
+
+// backupTimeFormat names rotated files so they sort chronologically.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// FileStore appends records as JSON lines to a file. When the file would
+// grow past its size limit it is renamed with a timestamp suffix and a new
+// file started; the oldest rotated files beyond the backup limit are removed.
+type FileStore struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	now        func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileStore opens, or creates, the audit trail file at path. A
+// non-positive maxSize uses DefaultMaxFileSize and a non-positive maxBackups
+// uses DefaultMaxBackups.
+func NewFileStore(path string, maxSize int64, maxBackups int) (*FileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit trail path cannot be empty")
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFileSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit trail directory: %w", err)
+	}
+	s := &FileStore{path: path, maxSize: maxSize, maxBackups: maxBackups, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) open() error {
+	file, size, err := openAppend(s.path)
+	if err != nil {
+		return err
+	}
+	s.file, s.size = file, size
+	return nil
+}
+
+// openAppend opens, or creates, a file for appending and returns its size.
+func openAppend(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open audit trail: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to open audit trail: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// Append writes a record as one line, rotating the file first when needed.
+// When rotation fails the record is still written to the current file and
+// the rotation error returned; rotation is retried on the next append.
+func (s *FileStore) Append(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("audit trail is closed")
+	}
+	var rotateErr error
+	if s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		rotateErr = s.rotate()
+	}
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit trail: %w", err)
+	}
+	return rotateErr
+}
+
+// rotate renames the current file and starts a new one. The current file
+// stays open until the new one is, so a failed rotation leaves the store
+// writing to the current file. Callers hold s.mu.
+func (s *FileStore) rotate() error {
+	backup := s.path + "-" + s.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(s.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate audit trail: %w", err)
+	}
+	file, size, err := openAppend(s.path)
+	if err != nil {
+		// Put the current file back so the store keeps a single live file
+		if restoreErr := os.Rename(backup, s.path); restoreErr != nil {
+			return fmt.Errorf("failed to rotate audit trail: %v; failed to restore %s: %w", err, s.path, restoreErr)
+		}
+		return fmt.Errorf("failed to rotate audit trail: %w", err)
+	}
+	if err := s.file.Close(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to close audit trail: %w", err)
+	}
+	s.file, s.size = file, size
+
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove rotated audit trail: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backups returns the rotated files, oldest first. Only names with a
+// rotation timestamp suffix count, so other files sharing the prefix are
+// never read or pruned.
+func (s *FileStore) backups() ([]string, error) {
+	matches, err := filepath.Glob(s.path + "-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated audit trails: %w", err)
+	}
+	var backups []string
+	prefix := s.path + "-"
+	for _, match := range matches {
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(match, prefix)); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Query reads the current and rotated files, newest record first, and
+// returns the newest matching records. The files are opened under the lock
+// and read without it, so appends are not held up by a long query; the open
+// files stay readable when a concurrent rotation renames or prunes them.
+func (s *FileStore) Query(filter Filter) ([]*Record, error) {
+	files, err := s.openFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFiles(files)
+
+	limit := filter.limit()
+	var records []*Record
+	for i := len(files) - 1; i >= 0 && len(records) < limit; i-- {
+		err := scanLinesBackward(files[i].file, files[i].size, func(line []byte) (bool, error) {
+			var record Record
+			if err := json.Unmarshal(line, &record); err != nil {
+				return false, fmt.Errorf("failed to decode audit record in %s: %w", files[i].path, err)
+			}
+			if filter.Matches(&record) {
+				records = append(records, &record)
+			}
+			return len(records) < limit, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// snapshotFile is a file opened for a query and the size to read from it.
+type snapshotFile struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// openFiles opens the rotated files, oldest first, and then the current
+// file. The current file is read up to the size written so far, so a record
+// appended while the query runs is never read half written.
+func (s *FileStore) openFiles() ([]snapshotFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := s.backups()
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, s.path)
+
+	files := make([]snapshotFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		var info os.FileInfo
+		if err == nil {
+			if info, err = file.Stat(); err != nil {
+				file.Close()
+			}
+		}
+		if err != nil {
+			closeFiles(files)
+			return nil, fmt.Errorf("failed to read audit trail: %w", err)
+		}
+		files = append(files, snapshotFile{path: path, file: file, size: info.Size()})
+	}
+	if last := len(files) - 1; last >= 0 && files[last].path == s.path && s.file != nil && s.size < files[last].size {
+		files[last].size = s.size
+	}
+	return files, nil
+}
+
+func closeFiles(files []snapshotFile) {
+	for _, f := range files {
+		f.file.Close()
+	}
+}
+
+// Limits for reading a file backward
+const (
+	scanChunkSize = 64 << 10
+	maxLineSize   = 16 << 20
+)
+
+// scanLinesBackward calls fn with each non-empty line of the first size
+// bytes of r, last line first, until fn returns false or an error.
+func scanLinesBackward(r io.ReaderAt, size int64, fn func(line []byte) (bool, error)) error {
+	var partial []byte // the start of the line being read, which ends the data read so far
+	for pos := size; pos > 0; {
+		n := int64(scanChunkSize)
+		if n > pos {
+			n = pos
+		}
+		pos -= n
+		chunk := make([]byte, n, n+int64(len(partial)))
+		if _, err := r.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read audit trail: %w", err)
+		}
+		chunk = append(chunk, partial...)
+
+		for {
+			i := bytes.LastIndexByte(chunk, '\n')
+			if i < 0 {
+				break
+			}
+			if line := chunk[i+1:]; len(line) > 0 {
+				if more, err := fn(line); err != nil || !more {
+					return err
+				}
+			}
+			chunk = chunk[:i]
+		}
+		if len(chunk) > maxLineSize {
+			return fmt.Errorf("failed to read audit trail: line longer than %d bytes", maxLineSize)
+		}
+		partial = chunk
+	}
+	if len(partial) > 0 {
+		_, err := fn(partial)
+		return err
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,165 @@
+package audittrail
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	// The postgres driver is linked so the server's sql backend works
+	// without a custom build
+	_ "github.com/lib/pq"
+)
+
+// DefaultSQLTable is the table a SQLStore created without one writes to.
+const DefaultSQLTable = "query_audit_trail"
+
+// validTableName guards the table name, which cannot be a query parameter.
+var validTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLStore appends records to a database table through database/sql. The
+// filterable fields are stored as columns and the full record as JSON text.
+// The postgres driver is linked into every binary using this package; other
+// drivers must be linked by the program. Drivers named "postgres" or "pgx"
+// use $n placeholders and all others use ?.
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	dollarStyle bool
+}
+
+// OpenSQLStore opens the database with the named driver and DSN, creating
+// the table if it does not exist.
+func OpenSQLStore(driver, dsn, table string) (*SQLStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit trail database: %w", err)
+	}
+	s, err := NewSQLStore(db, driver, table)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSQLStore uses an open database, creating the table if it does not
+// exist. A blank table uses DefaultSQLTable.
+func NewSQLStore(db *sql.DB, driver, table string) (*SQLStore, error) {
+	if table == "" {
+		table = DefaultSQLTable
+	}
+	if !validTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit trail table name '%s'", table)
+	}
+	s := &SQLStore{
+		db:          db,
+		table:       table,
+		dollarStyle: driver == "postgres" || driver == "pgx",
+	}
+	if _, err := db.Exec(s.createTableSQL()); err != nil {
+		return nil, fmt.Errorf("failed to create audit trail table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLStore) createTableSQL() string {
+	return "CREATE TABLE IF NOT EXISTS " + s.table + ` (
+	request_id VARCHAR(64) PRIMARY KEY,
+	timestamp_unix_nano BIGINT NOT NULL,
+	user_id VARCHAR(255) NOT NULL,
+	session_id VARCHAR(255) NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	record TEXT NOT NULL
+)`
+}
+
+// placeholder returns the nth (1-based) query parameter placeholder.
+func (s *SQLStore) placeholder(n int) string {
+	if s.dollarStyle {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Append inserts a record.
+func (s *SQLStore) Append(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (request_id, timestamp_unix_nano, user_id, session_id, outcome, record) VALUES (%s, %s, %s, %s, %s, %s)",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5), s.placeholder(6))
+	if _, err := s.db.Exec(query, record.RequestID, record.Timestamp.UnixNano(), record.UserID, record.SessionID, record.Outcome, string(data)); err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
+
+// Query returns the newest records matching the filter.
+func (s *SQLStore) Query(filter Filter) ([]*Record, error) {
+	query, args := s.selectSQL(filter)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit trail: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*Record
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read audit record: %w", err)
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode audit record: %w", err)
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query audit trail: %w", err)
+	}
+	return records, nil
+}
+
+// selectSQL builds the query and arguments selecting the filter's records.
+func (s *SQLStore) selectSQL(filter Filter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" "+s.placeholder(len(args)))
+	}
+	if filter.RequestID != "" {
+		add("request_id =", filter.RequestID)
+	}
+	if filter.UserID != "" {
+		add("user_id =", filter.UserID)
+	}
+	if filter.SessionID != "" {
+		add("session_id =", filter.SessionID)
+	}
+	if filter.Outcome != "" {
+		add("outcome =", filter.Outcome)
+	}
+	if !filter.Since.IsZero() {
+		add("timestamp_unix_nano >=", filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		add("timestamp_unix_nano <", filter.Until.UnixNano())
+	}
+
+	query := "SELECT record FROM " + s.table
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY timestamp_unix_nano DESC LIMIT %d", filter.limit())
+	return query, args
+}
+
+// Close closes the database.
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
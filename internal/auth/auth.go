@@ -0,0 +1,128 @@
+// Package auth verifies bearer tokens presented to the API and maps them to
+// an Identity. Authenticators are pluggable: JWTs checked against a local
+// JWKS file or an OIDC issuer's published keys, Kubernetes TokenReview, or
+// the insecure development scheme used before real authentication existed.
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// ErrNoCredentials is returned when a request carries no bearer token.
+var ErrNoCredentials = errors.New("no bearer token provided")
+
+// ErrInvalidToken is wrapped by every authenticator when a token is
+// rejected, so callers can tell bad credentials from backend failures.
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+// Identity is a verified caller.
+type Identity struct {
+	// UserID is the authenticated user name or subject
+	UserID string `json:"user_id"`
+
+	// Groups are the groups the user belongs to, as asserted by the issuer
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Authenticator verifies a bearer token and returns the caller's identity.
+// Implementations return an error wrapping ErrInvalidToken for tokens they
+// reject and other errors when verification itself could not be performed.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header value. It returns "" for other schemes or an empty token.
+func BearerToken(header string) string {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// WithIdentity stores the identity in ctx under types.ContextKeyUserID and
+// types.ContextKeyGroups.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	if identity == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, types.ContextKeyUserID, identity.UserID)
+	if len(identity.Groups) > 0 {
+		ctx = context.WithValue(ctx, types.ContextKeyGroups, append([]string(nil), identity.Groups...))
+	}
+	return ctx
+}
+
+// IdentityFromContext returns the identity stored by WithIdentity, or nil
+// for anonymous requests.
+func IdentityFromContext(ctx context.Context) *Identity {
+	userID, _ := ctx.Value(types.ContextKeyUserID).(string)
+	if userID == "" {
+		return nil
+	}
+	groups, _ := ctx.Value(types.ContextKeyGroups).([]string)
+	return &Identity{UserID: userID, Groups: groups}
+}
+
+// DevAuthenticator accepts tokens of the form "user:<id>" without any
+// verification. It exists for local development and tests only: anyone can
+// claim any identity with it.
+type DevAuthenticator struct{}
+
+// NewDevAuthenticator creates a DevAuthenticator.
+func NewDevAuthenticator() *DevAuthenticator {
+	return &DevAuthenticator{}
+}
+
+// Authenticate returns the user named in the token.
+func (a *DevAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if !strings.HasPrefix(token, "user:") {
+		return nil, ErrInvalidToken
+	}
+	userID := strings.TrimSpace(strings.TrimPrefix(token, "user:"))
+	if userID == "" {
+		return nil, ErrInvalidToken
+	}
+	return &Identity{UserID: userID}, nil
+}
+
+// NewHTTPClient returns an HTTP client for calls to identity providers and
+// the Kubernetes API. When caFile is set, only certificates signed by the
+// CAs in that PEM file are trusted.
+func NewHTTPClient(caFile string) (*http.Client, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if caFile == "" {
+		return client, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file '%s': %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file '%s'", caFile)
+	}
+	client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	return client, nil
+}
+
+// ReadTokenFile reads a bearer token, such as a mounted service account
+// token, from disk.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file '%s': %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
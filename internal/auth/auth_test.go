@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "bearer", header: "Bearer abc.def.ghi", expected: "abc.def.ghi"},
+		{name: "lowercase_scheme", header: "bearer abc", expected: "abc"},
+		{name: "missing_scheme", header: "abc", expected: ""},
+		{name: "empty_header", header: "", expected: ""},
+		{name: "different_scheme", header: "Basic abc", expected: ""},
+		{name: "bearer_with_space_only", header: "Bearer ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BearerToken(tt.header); got != tt.expected {
+				t.Fatalf("BearerToken(%q) = %q, want %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDevAuthenticator(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{name: "user_prefix", token: "user:alice", expected: "alice"},
+		{name: "jwt", token: "abc.def", expected: ""},
+		{name: "empty_user", token: "user: ", expected: ""},
+	}
+
+	authn := NewDevAuthenticator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authn.Authenticate(context.Background(), tt.token)
+			if tt.expected == "" {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+			if err != nil || identity.UserID != tt.expected {
+				t.Fatalf("Authenticate(%q) = %+v, %v; want %q", tt.token, identity, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithIdentity(t *testing.T) {
+	ctx := WithIdentity(context.Background(), &Identity{UserID: "alice", Groups: []string{"sre", "dev"}})
+
+	if got := ctx.Value(types.ContextKeyUserID); got != "alice" {
+		t.Errorf("user ID = %v, want alice", got)
+	}
+	if got := ctx.Value(types.ContextKeyGroups); !reflect.DeepEqual(got, []string{"sre", "dev"}) {
+		t.Errorf("groups = %v, want [sre dev]", got)
+	}
+	if identity := IdentityFromContext(ctx); identity == nil || identity.UserID != "alice" || len(identity.Groups) != 2 {
+		t.Errorf("IdentityFromContext = %+v", identity)
+	}
+	if identity := IdentityFromContext(context.Background()); identity != nil {
+		t.Errorf("expected nil identity for anonymous context, got %+v", identity)
+	}
+}
@@ -0,0 +1,120 @@
+// Package authtest provides an in-process OIDC issuer that publishes a
+// discovery document and JWKS and signs tokens, for tests.
+package authtest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Issuer is an OIDC issuer backed by an httptest server and a fresh RSA key.
+type Issuer struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	key          *rsa.PrivateKey
+	kid          string
+	jwksRequests int
+}
+
+// NewIssuer starts an issuer on a random local port.
+func NewIssuer() (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	i := &Issuer{key: key, kid: "test-key-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   i.URL(),
+			"jwks_uri": i.URL() + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		i.mu.Lock()
+		i.jwksRequests++
+		i.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(i.JWKS())
+	})
+	i.server = httptest.NewServer(mux)
+	return i, nil
+}
+
+// URL returns the issuer URL, which is also the expected iss claim.
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+// JWKSRequests returns how many times the JWKS has been fetched.
+func (i *Issuer) JWKSRequests() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.jwksRequests
+}
+
+// Close stops the server.
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// RotateKey replaces the signing key with a new one under a new key ID.
+func (i *Issuer) RotateKey(kid string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.key, i.kid = key, kid
+	return nil
+}
+
+// JWKS returns the issuer's public key set.
+func (i *Issuer) JWKS() []byte {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	data, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": i.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+	return data
+}
+
+// Sign returns an RS256 token for the claims. iss, iat and exp default to
+// the issuer URL, now and one hour from now.
+func (i *Issuer) Sign(claims map[string]interface{}) string {
+	all := map[string]interface{}{
+		"iss": i.URL(),
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": i.kid})
+	payload, _ := json.Marshal(all)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeyRefreshInterval is the minimum time between JWKS refetches
+// triggered by tokens signed with an unknown key ID.
+const DefaultKeyRefreshInterval = 30 * time.Second
+
+// maxResponseSize bounds documents read from identity providers.
+const maxResponseSize = 1 << 20
+
+// KeySet resolves the public key that signed a token.
+type KeySet interface {
+	// Key returns the key with the given ID. An empty kid selects the only
+	// key when the set holds exactly one.
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and EC keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// StaticKeySet is a fixed set of keys, typically loaded from a JWKS file.
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// ParseJWKS parses a JWKS document. Keys that are not signing keys or use an
+// unsupported type are skipped; a document with no usable keys is an error.
+func ParseJWKS(data []byte) (*StaticKeySet, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no usable signing keys")
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+// LoadJWKSFile reads a JWKS document from disk.
+func LoadJWKSFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file '%s': %w", path, err)
+	}
+	return ParseJWKS(data)
+}
+
+// Key returns the key with the given ID.
+func (s *StaticKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown signing key '%s'", ErrInvalidToken, kid)
+}
+
+// RemoteKeySet fetches keys from a JWKS URL, or from the jwks_uri published
+// by an OIDC issuer's discovery document, on first use. Keys are refetched
+// when a token names an unknown key ID, at most once per refresh interval,
+// so issuer key rotation is picked up without a restart.
+type RemoteKeySet struct {
+	client    *http.Client
+	issuerURL string
+	jwksURL   string
+
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	keys      *StaticKeySet
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySet creates a key set backed by a JWKS URL.
+func NewRemoteKeySet(jwksURL string, client *http.Client) *RemoteKeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &RemoteKeySet{client: client, jwksURL: jwksURL, refreshInterval: DefaultKeyRefreshInterval, now: time.Now}
+}
+
+// NewOIDCKeySet creates a key set whose JWKS URL is discovered from
+// <issuerURL>/.well-known/openid-configuration.
+func NewOIDCKeySet(issuerURL string, client *http.Client) *RemoteKeySet {
+	s := NewRemoteKeySet("", client)
+	s.issuerURL = strings.TrimSuffix(issuerURL, "/")
+	return s
+}
+
+// SetRefreshInterval changes the minimum time between refetches.
+func (s *RemoteKeySet) SetRefreshInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshInterval = d
+}
+
+// Key returns the key with the given ID, fetching or refetching the JWKS as
+// needed.
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys != nil {
+		key, err := s.keys.Key(ctx, kid)
+		if err == nil || s.now().Sub(s.fetchedAt) < s.refreshInterval {
+			return key, err
+		}
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s.keys.Key(ctx, kid)
+}
+
+// refresh fetches the JWKS, running OIDC discovery first if needed. Callers
+// hold s.mu.
+func (s *RemoteKeySet) refresh(ctx context.Context) error {
+	if s.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := s.getJSON(ctx, s.issuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != s.issuerURL {
+			return fmt.Errorf("OIDC discovery returned issuer '%s', expected '%s'", discovery.Issuer, s.issuerURL)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("OIDC discovery document has no jwks_uri")
+		}
+		s.jwksURL = discovery.JWKSURI
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.jwksURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	data, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.fetchedAt = s.now()
+	return nil
+}
+
+func (s *RemoteKeySet) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	data, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s *RemoteKeySet) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", req.URL, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash.New
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash.New
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DefaultClockSkew is the leeway applied to exp, nbf and iat checks.
+const DefaultClockSkew = time.Minute
+
+// JWTConfig configures claim validation for JWTAuthenticator.
+type JWTConfig struct {
+	// Issuer, when set, must equal the token's iss claim
+	Issuer string
+
+	// Audience, when set, must appear in the token's aud claim
+	Audience string
+
+	// UsernameClaim names the claim holding the user ID (default "sub")
+	UsernameClaim string
+
+	// GroupsClaim names the claim holding the user's groups (default "groups")
+	GroupsClaim string
+
+	// ClockSkew is the leeway for time-based claims (default DefaultClockSkew)
+	ClockSkew time.Duration
+}
+
+// JWTAuthenticator verifies signed JWTs (RS256/384/512, PS256/384/512 and
+// ES256/384/512) against a KeySet and validates their time, issuer and
+// audience claims. Unsigned and HMAC tokens are always rejected.
+type JWTAuthenticator struct {
+	keys   KeySet
+	config JWTConfig
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator creates a JWTAuthenticator.
+func NewJWTAuthenticator(keys KeySet, config JWTConfig) *JWTAuthenticator {
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.ClockSkew == 0 {
+		config.ClockSkew = DefaultClockSkew
+	}
+	return &JWTAuthenticator{keys: keys, config: config, now: time.Now}
+}
+
+// Authenticate verifies the token and returns the identity in its claims.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT signature", ErrInvalidToken)
+	}
+	key, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT claims", ErrInvalidToken)
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, _ := claims[a.config.UsernameClaim].(string)
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("%w: missing '%s' claim", ErrInvalidToken, a.config.UsernameClaim)
+	}
+	return &Identity{UserID: userID, Groups: stringsClaim(claims[a.config.GroupsClaim])}, nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+	skew := a.config.ClockSkew
+
+	exp, ok := numericClaim(claims["exp"])
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.After(exp.Add(skew)) {
+		return fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := numericClaim(claims["nbf"]); ok && now.Add(skew).Before(nbf) {
+		return fmt.Errorf("token not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+	if iat, ok := numericClaim(claims["iat"]); ok && now.Add(skew).Before(iat) {
+		return fmt.Errorf("token issued in the future")
+	}
+
+	if a.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(a.config.Issuer, "/") {
+			return fmt.Errorf("unexpected issuer '%s'", iss)
+		}
+	}
+	if a.config.Audience != "" {
+		found := false
+		for _, aud := range stringsClaim(claims["aud"]) {
+			if aud == a.config.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("token audience does not include '%s'", a.config.Audience)
+		}
+	}
+	return nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm '%s' does not match the signing key", alg)
+		}
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm '%s' does not match the signing key", alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericClaim converts a NumericDate claim to a time.
+func numericClaim(v interface{}) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// stringsClaim accepts a claim that is either a string or a list of strings.
+func stringsClaim(v interface{}) []string {
+	switch value := v.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []interface{}:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
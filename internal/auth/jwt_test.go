@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/internal/auth/authtest"
+)
+
+func newTestIssuer(t *testing.T) *authtest.Issuer {
+	t.Helper()
+	issuer, err := authtest.NewIssuer()
+	if err != nil {
+		t.Fatalf("failed to start issuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func TestJWTAuthenticator_JWKSFile(t *testing.T) {
+	issuer := newTestIssuer(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, issuer.JWKS(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("LoadJWKSFile failed: %v", err)
+	}
+	authn := NewJWTAuthenticator(keys, JWTConfig{Issuer: issuer.URL(), Audience: "audit-query"})
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": "audit-query", "groups": []string{"sre"}})},
+		{name: "audience_list", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": []string{"other", "audit-query"}})},
+		{name: "wrong_audience", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": "other"}), wantErr: true},
+		{name: "wrong_issuer", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": "audit-query", "iss": "https://evil.example.com"}), wantErr: true},
+		{name: "expired", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": "audit-query", "exp": now.Add(-time.Hour).Unix()}), wantErr: true},
+		{name: "not_yet_valid", token: issuer.Sign(map[string]interface{}{"sub": "alice", "aud": "audit-query", "nbf": now.Add(time.Hour).Unix()}), wantErr: true},
+		{name: "missing_subject", token: issuer.Sign(map[string]interface{}{"aud": "audit-query"}), wantErr: true},
+		{name: "malformed", token: "not-a-jwt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authn.Authenticate(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if identity.UserID != "alice" {
+				t.Errorf("UserID = %q, want alice", identity.UserID)
+			}
+		})
+	}
+
+	identity, _ := authn.Authenticate(context.Background(), tests[0].token)
+	if !reflect.DeepEqual(identity.Groups, []string{"sre"}) {
+		t.Errorf("Groups = %v, want [sre]", identity.Groups)
+	}
+}
+
+func TestJWTAuthenticator_RejectsTamperedAndUnsignedTokens(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys, err := ParseJWKS(issuer.JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	authn := NewJWTAuthenticator(keys, JWTConfig{})
+
+	token := issuer.Sign(map[string]interface{}{"sub": "alice"})
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(map[string]interface{}{"sub": "admin", "exp": time.Now().Add(time.Hour).Unix()})
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+	if _, err := authn.Authenticate(context.Background(), tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token: expected ErrInvalidToken, got %v", err)
+	}
+
+	for _, alg := range []string{"none", "HS256"} {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "kid": "test-key-1"})
+		unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + parts[1] + "."
+		if _, err := authn.Authenticate(context.Background(), unsigned); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("alg %s: expected ErrInvalidToken, got %v", alg, err)
+		}
+	}
+}
+
+func TestJWTAuthenticator_ECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "EC", "kid": "ec-1", "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y": base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}}})
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": "ec-1"})
+	claims, _ := json.Marshal(map[string]interface{}{"sub": "bob", "exp": time.Now().Add(time.Hour).Unix()})
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	token := input + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	identity, err := NewJWTAuthenticator(keys, JWTConfig{}).Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if identity.UserID != "bob" {
+		t.Errorf("UserID = %q, want bob", identity.UserID)
+	}
+}
+
+func TestJWTAuthenticator_OIDCDiscovery(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys := NewOIDCKeySet(issuer.URL(), nil)
+	keys.SetRefreshInterval(0)
+	authn := NewJWTAuthenticator(keys, JWTConfig{Issuer: issuer.URL(), UsernameClaim: "preferred_username"})
+
+	token := issuer.Sign(map[string]interface{}{"sub": "1234", "preferred_username": "alice"})
+	identity, err := authn.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if identity.UserID != "alice" {
+		t.Errorf("UserID = %q, want alice", identity.UserID)
+	}
+	if _, err := authn.Authenticate(context.Background(), token); err != nil {
+		t.Fatalf("second Authenticate failed: %v", err)
+	}
+	if got := issuer.JWKSRequests(); got != 1 {
+		t.Errorf("JWKS fetched %d times, want 1 (cached)", got)
+	}
+
+	// A rotated key is picked up by refetching the JWKS
+	if err := issuer.RotateKey("test-key-2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authn.Authenticate(context.Background(), issuer.Sign(map[string]interface{}{"preferred_username": "alice"})); err != nil {
+		t.Fatalf("Authenticate after rotation failed: %v", err)
+	}
+	if got := issuer.JWKSRequests(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2 after rotation", got)
+	}
+}
+
+func TestOIDCKeySet_IssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys := NewOIDCKeySet(issuer.URL()+"/other", nil)
+	if _, err := keys.Key(context.Background(), "test-key-1"); err == nil {
+		t.Fatal("expected discovery to fail for a mismatched issuer")
+	}
+}
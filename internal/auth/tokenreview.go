@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenReviewCacheTTL is how long successful TokenReview results are reused.
+const DefaultTokenReviewCacheTTL = 10 * time.Second
+
+// maxTokenReviewCacheEntries bounds the TokenReview cache.
+const maxTokenReviewCacheEntries = 1024
+
+// tokenReviewPath is the TokenReview endpoint relative to the API server URL.
+const tokenReviewPath = "/apis/authentication.k8s.io/v1/tokenreviews"
+
+// TokenReviewConfig configures TokenReviewAuthenticator.
+type TokenReviewConfig struct {
+	// URL is the Kubernetes API server base URL, e.g. https://kubernetes.default.svc
+	URL string
+
+	// Token authenticates this server to the API server; it needs permission
+	// to create tokenreviews
+	Token string
+
+	// Audiences, when set, are sent with each review and must be acknowledged
+	Audiences []string
+
+	// CacheTTL is how long successful reviews are cached (default DefaultTokenReviewCacheTTL)
+	CacheTTL time.Duration
+
+	// HTTPClient is used for API calls; it carries the cluster CA
+	HTTPClient *http.Client
+}
+
+// tokenReview is the subset of authentication.k8s.io/v1 TokenReview used here.
+type tokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       tokenReviewSpec   `json:"spec"`
+	Status     tokenReviewStatus `json:"status,omitempty"`
+}
+
+type tokenReviewSpec struct {
+	Token     string   `json:"token"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+type tokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	User          struct {
+		Username string   `json:"username"`
+		Groups   []string `json:"groups"`
+	} `json:"user"`
+}
+
+type cachedIdentity struct {
+	identity  Identity
+	expiresAt time.Time
+}
+
+// TokenReviewAuthenticator delegates token validation to a Kubernetes API
+// server with a TokenReview, which accepts OpenShift OAuth tokens, service
+// account tokens and anything else the cluster's authenticators accept.
+// Successful reviews are cached briefly, keyed by a hash of the token.
+type TokenReviewAuthenticator struct {
+	config TokenReviewConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedIdentity
+}
+
+// NewTokenReviewAuthenticator creates a TokenReviewAuthenticator.
+func NewTokenReviewAuthenticator(config TokenReviewConfig) *TokenReviewAuthenticator {
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultTokenReviewCacheTTL
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &TokenReviewAuthenticator{
+		config: config,
+		client: client,
+		now:    time.Now,
+		cache:  make(map[[sha256.Size]byte]cachedIdentity),
+	}
+}
+
+// Authenticate submits a TokenReview for the token.
+func (a *TokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	key := sha256.Sum256([]byte(token))
+	if identity, ok := a.cached(key); ok {
+		return identity, nil
+	}
+
+	body, err := json.Marshal(tokenReview{
+		APIVersion: "authentication.k8s.io/v1",
+		Kind:       "TokenReview",
+		Spec:       tokenReviewSpec{Token: token, Audiences: a.config.Audiences},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode TokenReview: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.URL+tokenReviewPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TokenReview request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if a.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.config.Token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("TokenReview request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TokenReview response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("TokenReview returned status %d", resp.StatusCode)
+	}
+
+	var review tokenReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("failed to decode TokenReview response: %w", err)
+	}
+	status := review.Status
+	if !status.Authenticated {
+		if status.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, status.Error)
+		}
+		return nil, ErrInvalidToken
+	}
+	if status.User.Username == "" {
+		return nil, fmt.Errorf("%w: TokenReview returned no username", ErrInvalidToken)
+	}
+	if len(a.config.Audiences) > 0 && !intersects(a.config.Audiences, status.Audiences) {
+		return nil, fmt.Errorf("%w: token is not valid for the configured audiences", ErrInvalidToken)
+	}
+
+	identity := Identity{UserID: status.User.Username, Groups: status.User.Groups}
+	a.store(key, identity)
+	return &identity, nil
+}
+
+func (a *TokenReviewAuthenticator) cached(key [sha256.Size]byte) (*Identity, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if a.now().After(entry.expiresAt) {
+		delete(a.cache, key)
+		return nil, false
+	}
+	identity := entry.identity
+	return &identity, true
+}
+
+func (a *TokenReviewAuthenticator) store(key [sha256.Size]byte, identity Identity) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if len(a.cache) >= maxTokenReviewCacheEntries {
+		for k, entry := range a.cache {
+			if now.After(entry.expiresAt) {
+				delete(a.cache, k)
+			}
+		}
+		if len(a.cache) >= maxTokenReviewCacheEntries {
+			a.cache = make(map[[sha256.Size]byte]cachedIdentity)
+		}
+	}
+	a.cache[key] = cachedIdentity{identity: identity, expiresAt: now.Add(a.config.CacheTTL)}
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
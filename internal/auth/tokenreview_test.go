@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTokenReviewServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.Method != http.MethodPost || r.URL.Path != tokenReviewPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer reviewer-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var review tokenReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch review.Spec.Token {
+		case "sha256~alice":
+			review.Status.Authenticated = true
+			review.Status.Audiences = review.Spec.Audiences
+			review.Status.User.Username = "alice"
+			review.Status.User.Groups = []string{"system:authenticated", "sre"}
+		default:
+			review.Status.Error = "token lookup failed"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	requests := 0
+	server := newTokenReviewServer(t, &requests)
+	authn := NewTokenReviewAuthenticator(TokenReviewConfig{
+		URL:       server.URL,
+		Token:     "reviewer-token",
+		Audiences: []string{"audit-query"},
+	})
+
+	identity, err := authn.Authenticate(context.Background(), "sha256~alice")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if identity.UserID != "alice" || !reflect.DeepEqual(identity.Groups, []string{"system:authenticated", "sre"}) {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	if _, err := authn.Authenticate(context.Background(), "sha256~alice"); err != nil {
+		t.Fatalf("cached Authenticate failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 TokenReview request with caching, got %d", requests)
+	}
+
+	authn.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if _, err := authn.Authenticate(context.Background(), "sha256~alice"); err != nil {
+		t.Fatalf("Authenticate after cache expiry failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected a new TokenReview after cache expiry, got %d requests", requests)
+	}
+
+	if _, err := authn.Authenticate(context.Background(), "sha256~mallory"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for rejected token, got %v", err)
+	}
+}
+
+func TestTokenReviewAuthenticator_BackendErrors(t *testing.T) {
+	requests := 0
+	server := newTokenReviewServer(t, &requests)
+
+	// The reviewer's own credentials are rejected: a server problem, not a bad user token
+	authn := NewTokenReviewAuthenticator(TokenReviewConfig{URL: server.URL, Token: "wrong"})
+	_, err := authn.Authenticate(context.Background(), "sha256~alice")
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected a backend error, got %v", err)
+	}
+
+	// The API server does not confirm the requested audience
+	authn = NewTokenReviewAuthenticator(TokenReviewConfig{URL: server.URL, Token: "reviewer-token"})
+	authn.config.Audiences = []string{"audit-query"}
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": map[string]interface{}{"authenticated": true, "user": map[string]interface{}{"username": "alice"}},
+		})
+	})
+	if _, err := authn.Authenticate(context.Background(), "sha256~alice"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for unconfirmed audience, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package authz limits audit queries to the namespaces the caller may access
+// (FR-019). A NamespaceAccess policy answers access questions for an
+// identity, and a Scoper applies it to a structured query, either rejecting
+// it or narrowing its namespace filter to what the caller may read.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"genai-processing/internal/auth"
+	"genai-processing/pkg/types"
+)
+
+// ErrAccessDenied is wrapped by Scoper when a query cannot be limited to
+// namespaces the caller may access.
+var ErrAccessDenied = errors.New("namespace access denied")
+
+// ErrNotEnumerable is returned by policies that can check a namespace but
+// cannot list every namespace a caller may access.
+var ErrNotEnumerable = errors.New("accessible namespaces cannot be enumerated")
+
+// Well-known Kubernetes identities applied to callers.
+const (
+	AnonymousUser        = "system:anonymous"
+	UnauthenticatedGroup = "system:unauthenticated"
+	AuthenticatedGroup   = "system:authenticated"
+)
+
+// NamespaceAccess decides which namespaces' audit events an identity may read.
+type NamespaceAccess interface {
+	// CanAccess reports whether identity may read audit events in namespace.
+	// An empty namespace asks about cluster-wide access, which also covers
+	// events for cluster-scoped resources.
+	CanAccess(ctx context.Context, identity *auth.Identity, namespace string) (bool, error)
+
+	// AccessibleNamespaces lists the namespaces identity may read, or returns
+	// ErrNotEnumerable when the policy cannot list them.
+	AccessibleNamespaces(ctx context.Context, identity *auth.Identity) ([]string, error)
+}
+
+// Mode selects what Scoper does with a query that reaches beyond the
+// caller's namespaces.
+type Mode string
+
+const (
+	// ModeNarrow rewrites the namespace filter to the accessible namespaces
+	ModeNarrow Mode = "narrow"
+
+	// ModeReject refuses the query
+	ModeReject Mode = "reject"
+)
+
+// ScopeResult describes how a query was scoped.
+type ScopeResult struct {
+	// Narrowed reports whether the query's namespace filter was rewritten
+	Narrowed bool `json:"narrowed"`
+
+	// ClusterWide reports that the caller may read every namespace
+	ClusterWide bool `json:"cluster_wide"`
+
+	// Namespaces is the namespace filter after scoping
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Removed lists requested namespaces the caller may not access
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Message describes the narrowing for validation warnings.
+func (r *ScopeResult) Message() string {
+	if r == nil || !r.Narrowed {
+		return ""
+	}
+	if len(r.Removed) > 0 {
+		return fmt.Sprintf("namespace scope narrowed to [%s]; no access to [%s]",
+			strings.Join(r.Namespaces, ", "), strings.Join(r.Removed, ", "))
+	}
+	return fmt.Sprintf("namespace scope narrowed to the namespaces you can access: [%s]", strings.Join(r.Namespaces, ", "))
+}
+
+// Scoper applies a NamespaceAccess policy to structured queries.
+type Scoper struct {
+	access NamespaceAccess
+	mode   Mode
+}
+
+// NewScoper creates a Scoper. An empty mode defaults to ModeNarrow.
+func NewScoper(access NamespaceAccess, mode Mode) (*Scoper, error) {
+	if access == nil {
+		return nil, fmt.Errorf("namespace access policy cannot be nil")
+	}
+	switch mode {
+	case "":
+		mode = ModeNarrow
+	case ModeNarrow, ModeReject:
+	default:
+		return nil, fmt.Errorf("unsupported scoping mode '%s'", mode)
+	}
+	return &Scoper{access: access, mode: mode}, nil
+}
+
+// Mode returns the scoping mode.
+func (s *Scoper) Mode() Mode {
+	return s.mode
+}
+
+// Scope limits query to the namespaces identity may access, rewriting
+// query.Namespace in place when narrowing. A nil identity is treated as the
+// anonymous user. The returned error wraps ErrAccessDenied when the query
+// must be refused.
+func (s *Scoper) Scope(ctx context.Context, identity *auth.Identity, query *types.StructuredQuery) (*ScopeResult, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+	identity = normalizeIdentity(identity)
+
+	clusterWide, err := s.access.CanAccess(ctx, identity, "")
+	if err != nil {
+		return nil, fmt.Errorf("namespace access check failed: %w", err)
+	}
+	if clusterWide {
+		return &ScopeResult{ClusterWide: true, Namespaces: query.Namespace.Values()}, nil
+	}
+
+	if requested := query.Namespace.Values(); len(requested) > 0 {
+		var allowed, denied []string
+		for _, namespace := range requested {
+			ok, err := s.access.CanAccess(ctx, identity, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("namespace access check failed: %w", err)
+			}
+			if ok {
+				allowed = append(allowed, namespace)
+			} else {
+				denied = append(denied, namespace)
+			}
+		}
+		if len(denied) == 0 {
+			return &ScopeResult{Namespaces: allowed}, nil
+		}
+		if len(allowed) == 0 || s.mode == ModeReject {
+			return nil, fmt.Errorf("%w: %s cannot read audit events in namespace(s) %s",
+				ErrAccessDenied, identity.UserID, strings.Join(denied, ", "))
+		}
+		query.Namespace = *types.NewStringOrArray(allowed)
+		return &ScopeResult{Narrowed: true, Namespaces: allowed, Removed: denied}, nil
+	}
+
+	// No namespace filter: the query spans the whole cluster
+	if s.mode == ModeReject {
+		return nil, fmt.Errorf("%w: %s does not have cluster-wide access; name the namespace(s) to query",
+			ErrAccessDenied, identity.UserID)
+	}
+	namespaces, err := s.access.AccessibleNamespaces(ctx, identity)
+	if errors.Is(err, ErrNotEnumerable) {
+		return nil, fmt.Errorf("%w: %s does not have cluster-wide access; name the namespace(s) to query",
+			ErrAccessDenied, identity.UserID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("namespace access check failed: %w", err)
+	}
+	if pattern := strings.TrimSpace(query.NamespacePattern); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace_pattern '%s': %w", pattern, err)
+		}
+		matched := namespaces[:0:0]
+		for _, namespace := range namespaces {
+			if re.MatchString(namespace) {
+				matched = append(matched, namespace)
+			}
+		}
+		namespaces = matched
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("%w: %s cannot read audit events in any matching namespace", ErrAccessDenied, identity.UserID)
+	}
+	query.Namespace = *types.NewStringOrArray(namespaces)
+	return &ScopeResult{Narrowed: true, Namespaces: namespaces}, nil
+}
+
+// normalizeIdentity maps a missing identity to the anonymous user and adds
+// the implicit authenticated group, mirroring Kubernetes.
+func normalizeIdentity(identity *auth.Identity) *auth.Identity {
+	if identity == nil || identity.UserID == "" {
+		return &auth.Identity{UserID: AnonymousUser, Groups: []string{UnauthenticatedGroup}}
+	}
+	for _, group := range identity.Groups {
+		if group == AuthenticatedGroup {
+			return identity
+		}
+	}
+	groups := append(append([]string(nil), identity.Groups...), AuthenticatedGroup)
+	return &auth.Identity{UserID: identity.UserID, Groups: groups}
+}
@@ -0,0 +1,175 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"genai-processing/internal/auth"
+	"genai-processing/pkg/types"
+)
+
+const testPolicy = `
+rules:
+  - groups: ["system:cluster-admins"]
+    namespaces: ["*"]
+  - groups: ["team-payments"]
+    namespaces: ["payments", "payments-staging"]
+  - users: ["alice"]
+    namespaces: ["shared-tools"]
+  - groups: ["system:authenticated"]
+    namespaces: ["public"]
+`
+
+func newTestScoper(t *testing.T, mode Mode) *Scoper {
+	t.Helper()
+	policy, err := ParseStaticPolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParseStaticPolicy failed: %v", err)
+	}
+	scoper, err := NewScoper(policy, mode)
+	if err != nil {
+		t.Fatalf("NewScoper failed: %v", err)
+	}
+	return scoper
+}
+
+func queryWith(namespaces []string, pattern string) *types.StructuredQuery {
+	q := &types.StructuredQuery{LogSource: "kube-apiserver", NamespacePattern: pattern}
+	if namespaces != nil {
+		q.Namespace = *types.NewStringOrArray(namespaces)
+	}
+	return q
+}
+
+func TestScoper_Narrow(t *testing.T) {
+	alice := &auth.Identity{UserID: "alice", Groups: []string{"team-payments"}}
+	admin := &auth.Identity{UserID: "root", Groups: []string{"system:cluster-admins"}}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		query          *types.StructuredQuery
+		wantNamespaces []string
+		wantRemoved    []string
+		wantNarrowed   bool
+		wantDenied     bool
+	}{
+		{name: "cluster_admin_unchanged", identity: admin, query: queryWith(nil, ""), wantNamespaces: nil},
+		{name: "allowed_namespace_unchanged", identity: alice, query: queryWith([]string{"payments"}, ""), wantNamespaces: []string{"payments"}},
+		{name: "partial_access_narrowed", identity: alice, query: queryWith([]string{"payments", "production"}, ""),
+			wantNamespaces: []string{"payments"}, wantRemoved: []string{"production"}, wantNarrowed: true},
+		{name: "no_access_denied", identity: alice, query: queryWith([]string{"production"}, ""), wantDenied: true},
+		{name: "cluster_wide_narrowed", identity: alice, query: queryWith(nil, ""),
+			wantNamespaces: []string{"payments", "payments-staging", "public", "shared-tools"}, wantNarrowed: true},
+		{name: "pattern_narrowed", identity: alice, query: queryWith(nil, "^payments"),
+			wantNamespaces: []string{"payments", "payments-staging"}, wantNarrowed: true},
+		{name: "pattern_without_matches_denied", identity: alice, query: queryWith(nil, "^kube-"), wantDenied: true},
+		{name: "anonymous_denied", identity: nil, query: queryWith(nil, ""), wantDenied: true},
+		{name: "authenticated_group_implicit", identity: &auth.Identity{UserID: "bob"}, query: queryWith([]string{"public"}, ""), wantNamespaces: []string{"public"}},
+	}
+
+	scoper := newTestScoper(t, ModeNarrow)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := scoper.Scope(context.Background(), tt.identity, tt.query)
+			if tt.wantDenied {
+				if !errors.Is(err, ErrAccessDenied) {
+					t.Fatalf("expected ErrAccessDenied, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scope failed: %v", err)
+			}
+			if result.Narrowed != tt.wantNarrowed {
+				t.Errorf("Narrowed = %v, want %v", result.Narrowed, tt.wantNarrowed)
+			}
+			if got := tt.query.Namespace.Values(); !reflect.DeepEqual(got, tt.wantNamespaces) {
+				t.Errorf("query namespaces = %v, want %v", got, tt.wantNamespaces)
+			}
+			if !reflect.DeepEqual(result.Removed, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", result.Removed, tt.wantRemoved)
+			}
+			if tt.wantNarrowed && result.Message() == "" {
+				t.Error("expected a narrowing message")
+			}
+		})
+	}
+}
+
+func TestScoper_Reject(t *testing.T) {
+	scoper := newTestScoper(t, ModeReject)
+	alice := &auth.Identity{UserID: "alice", Groups: []string{"team-payments"}}
+
+	if _, err := scoper.Scope(context.Background(), alice, queryWith([]string{"payments", "production"}, "")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected partial access to be rejected, got %v", err)
+	}
+	if _, err := scoper.Scope(context.Background(), alice, queryWith(nil, "")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected cluster-wide query to be rejected, got %v", err)
+	}
+	query := queryWith([]string{"payments"}, "")
+	if result, err := scoper.Scope(context.Background(), alice, query); err != nil || result.Narrowed {
+		t.Errorf("expected allowed query to pass unchanged, got %+v, %v", result, err)
+	}
+}
+
+func TestScoper_ReviewFunc(t *testing.T) {
+	var asked []string
+	review := ReviewFunc(func(ctx context.Context, identity *auth.Identity, namespace string) (bool, error) {
+		asked = append(asked, namespace)
+		return namespace == "payments", nil
+	})
+	scoper, err := NewScoper(review, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &auth.Identity{UserID: "alice"}
+
+	query := queryWith([]string{"payments", "production"}, "")
+	if _, err := scoper.Scope(context.Background(), alice, query); err != nil {
+		t.Fatalf("Scope failed: %v", err)
+	}
+	if got := query.Namespace.Values(); !reflect.DeepEqual(got, []string{"payments"}) {
+		t.Errorf("query namespaces = %v, want [payments]", got)
+	}
+	if !reflect.DeepEqual(asked, []string{"", "payments", "production"}) {
+		t.Errorf("reviewed namespaces = %q", asked)
+	}
+
+	// A callback cannot list namespaces, so cluster-wide queries must name one
+	if _, err := scoper.Scope(context.Background(), alice, queryWith(nil, "")); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected ErrAccessDenied, got %v", err)
+	}
+
+	failing := ReviewFunc(func(ctx context.Context, identity *auth.Identity, namespace string) (bool, error) {
+		return false, errors.New("api server unavailable")
+	})
+	scoper, _ = NewScoper(failing, ModeNarrow)
+	if _, err := scoper.Scope(context.Background(), alice, queryWith([]string{"payments"}, "")); err == nil || errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected a backend error, got %v", err)
+	}
+}
+
+func TestParseStaticPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{name: "no_subjects", policy: "rules:\n  - namespaces: [a]\n"},
+		{name: "no_namespaces", policy: "rules:\n  - users: [alice]\n"},
+		{name: "empty_namespace", policy: "rules:\n  - users: [alice]\n    namespaces: [\"\"]\n"},
+		{name: "invalid_yaml", policy: "rules: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseStaticPolicy([]byte(tt.policy)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := NewScoper(&StaticPolicy{}, "audit"); err == nil {
+		t.Error("expected an error for an unsupported mode")
+	}
+}
@@ -0,0 +1,198 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"genai-processing/internal/auth"
+)
+
+// DefaultReviewCacheTTL is how long SubjectAccessReview decisions are reused.
+const DefaultReviewCacheTTL = 30 * time.Second
+
+// maxReviewCacheEntries bounds the SubjectAccessReview decision cache.
+const maxReviewCacheEntries = 4096
+
+// subjectAccessReviewPath is the SubjectAccessReview endpoint relative to the API server URL.
+const subjectAccessReviewPath = "/apis/authorization.k8s.io/v1/subjectaccessreviews"
+
+// ReviewFunc decides access to a single namespace, in the style of a
+// Kubernetes SubjectAccessReview. An empty namespace asks about
+// cluster-wide access.
+type ReviewFunc func(ctx context.Context, identity *auth.Identity, namespace string) (bool, error)
+
+// CanAccess calls the function.
+func (f ReviewFunc) CanAccess(ctx context.Context, identity *auth.Identity, namespace string) (bool, error) {
+	return f(ctx, identity, namespace)
+}
+
+// AccessibleNamespaces returns ErrNotEnumerable: a review callback can only
+// answer questions about namespaces it is asked about.
+func (f ReviewFunc) AccessibleNamespaces(ctx context.Context, identity *auth.Identity) ([]string, error) {
+	return nil, ErrNotEnumerable
+}
+
+// SubjectAccessReviewConfig configures SubjectAccessReviewer.
+type SubjectAccessReviewConfig struct {
+	// URL is the Kubernetes API server base URL
+	URL string
+
+	// Token authenticates this server to the API server; it needs permission
+	// to create subjectaccessreviews
+	Token string
+
+	// Verb, Group and Resource describe the permission that grants audit
+	// access to a namespace (default: get namespaces)
+	Verb     string
+	Group    string
+	Resource string
+
+	// CacheTTL is how long decisions are cached (default DefaultReviewCacheTTL)
+	CacheTTL time.Duration
+
+	// HTTPClient is used for API calls; it carries the cluster CA
+	HTTPClient *http.Client
+}
+
+type reviewDecision struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// SubjectAccessReviewer checks namespace access with Kubernetes
+// SubjectAccessReviews, so callers see exactly the namespaces their
+// OpenShift permissions already grant. By default a caller may read a
+// namespace's audit events when they may get that namespace, and the
+// whole cluster's when they may get namespaces cluster-wide.
+type SubjectAccessReviewer struct {
+	config SubjectAccessReviewConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]reviewDecision
+}
+
+// NewSubjectAccessReviewer creates a SubjectAccessReviewer.
+func NewSubjectAccessReviewer(config SubjectAccessReviewConfig) *SubjectAccessReviewer {
+	if config.Verb == "" {
+		config.Verb = "get"
+	}
+	if config.Resource == "" {
+		config.Resource = "namespaces"
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultReviewCacheTTL
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &SubjectAccessReviewer{
+		config: config,
+		client: client,
+		now:    time.Now,
+		cache:  make(map[string]reviewDecision),
+	}
+}
+
+// CanAccess submits a SubjectAccessReview for the namespace.
+func (r *SubjectAccessReviewer) CanAccess(ctx context.Context, identity *auth.Identity, namespace string) (bool, error) {
+	key := identity.UserID + "\x00" + strings.Join(identity.Groups, "\x00") + "\x00\x00" + namespace
+	if allowed, ok := r.cached(key); ok {
+		return allowed, nil
+	}
+
+	attributes := map[string]string{
+		"verb":     r.config.Verb,
+		"group":    r.config.Group,
+		"resource": r.config.Resource,
+	}
+	if namespace != "" {
+		attributes["namespace"] = namespace
+		if r.config.Resource == "namespaces" && r.config.Group == "" {
+			attributes["name"] = namespace
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SubjectAccessReview",
+		"spec": map[string]interface{}{
+			"user":               identity.UserID,
+			"groups":             identity.Groups,
+			"resourceAttributes": attributes,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode SubjectAccessReview: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.URL+subjectAccessReviewPath, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create SubjectAccessReview request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if r.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.Token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("SubjectAccessReview request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return false, fmt.Errorf("failed to read SubjectAccessReview response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return false, fmt.Errorf("SubjectAccessReview returned status %d", resp.StatusCode)
+	}
+	var review struct {
+		Status struct {
+			Allowed         bool   `json:"allowed"`
+			Denied          bool   `json:"denied"`
+			EvaluationError string `json:"evaluationError"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &review); err != nil {
+		return false, fmt.Errorf("failed to decode SubjectAccessReview response: %w", err)
+	}
+
+	allowed := review.Status.Allowed && !review.Status.Denied
+	r.store(key, allowed)
+	return allowed, nil
+}
+
+// AccessibleNamespaces returns ErrNotEnumerable: SubjectAccessReview answers
+// questions about one namespace at a time.
+func (r *SubjectAccessReviewer) AccessibleNamespaces(ctx context.Context, identity *auth.Identity) ([]string, error) {
+	return nil, ErrNotEnumerable
+}
+
+func (r *SubjectAccessReviewer) cached(key string) (bool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	decision, ok := r.cache[key]
+	if !ok || r.now().After(decision.expiresAt) {
+		return false, false
+	}
+	return decision.allowed, true
+}
+
+func (r *SubjectAccessReviewer) store(key string, allowed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= maxReviewCacheEntries {
+		r.cache = make(map[string]reviewDecision)
+	}
+	r.cache[key] = reviewDecision{allowed: allowed, expiresAt: r.now().Add(r.config.CacheTTL)}
+}
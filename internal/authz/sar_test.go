@@ -0,0 +1,65 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"genai-processing/internal/auth"
+)
+
+func TestSubjectAccessReviewer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != subjectAccessReviewPath || r.Header.Get("Authorization") != "Bearer reviewer-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var review struct {
+			Spec struct {
+				User               string            `json:"user"`
+				Groups             []string          `json:"groups"`
+				ResourceAttributes map[string]string `json:"resourceAttributes"`
+			} `json:"spec"`
+		}
+		json.NewDecoder(r.Body).Decode(&review)
+		attrs := review.Spec.ResourceAttributes
+		allowed := review.Spec.User == "alice" && attrs["verb"] == "get" && attrs["resource"] == "namespaces" &&
+			attrs["namespace"] == "payments" && attrs["name"] == "payments"
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": map[string]interface{}{"allowed": allowed}})
+	}))
+	defer server.Close()
+
+	reviewer := NewSubjectAccessReviewer(SubjectAccessReviewConfig{URL: server.URL, Token: "reviewer-token"})
+	alice := &auth.Identity{UserID: "alice", Groups: []string{"system:authenticated"}}
+
+	tests := []struct {
+		namespace string
+		want      bool
+	}{
+		{namespace: "payments", want: true},
+		{namespace: "production", want: false},
+		{namespace: "", want: false},
+		{namespace: "payments", want: true},
+	}
+	for _, tt := range tests {
+		got, err := reviewer.CanAccess(context.Background(), alice, tt.namespace)
+		if err != nil {
+			t.Fatalf("CanAccess(%q) failed: %v", tt.namespace, err)
+		}
+		if got != tt.want {
+			t.Errorf("CanAccess(%q) = %v, want %v", tt.namespace, got, tt.want)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 reviews with caching, got %d", requests)
+	}
+
+	reviewer = NewSubjectAccessReviewer(SubjectAccessReviewConfig{URL: server.URL, Token: "wrong"})
+	if _, err := reviewer.CanAccess(context.Background(), alice, "payments"); err == nil {
+		t.Error("expected an error when the review is refused")
+	}
+}
@@ -0,0 +1,129 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"genai-processing/internal/auth"
+)
+
+// AllNamespaces grants cluster-wide access in a static policy.
+const AllNamespaces = "*"
+
+// StaticRule grants the listed users and groups access to namespaces.
+type StaticRule struct {
+	// Users are user IDs the rule applies to
+	Users []string `yaml:"users,omitempty"`
+
+	// Groups are group names the rule applies to
+	Groups []string `yaml:"groups,omitempty"`
+
+	// Namespaces are the namespaces granted, or "*" for cluster-wide access
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// StaticPolicy is an RBAC-like namespace access policy loaded from YAML:
+//
+//	rules:
+//	  - groups: ["system:cluster-admins"]
+//	    namespaces: ["*"]
+//	  - groups: ["team-payments"]
+//	    namespaces: ["payments", "payments-staging"]
+//	  - users: ["alice"]
+//	    namespaces: ["shared-tools"]
+//
+// Access is the union of every rule matching the user or one of their
+// groups. Anything not granted is denied.
+type StaticPolicy struct {
+	Rules []StaticRule `yaml:"rules"`
+}
+
+// ParseStaticPolicy parses and validates a YAML policy.
+func ParseStaticPolicy(data []byte) (*StaticPolicy, error) {
+	var policy StaticPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace access policy: %w", err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Users) == 0 && len(rule.Groups) == 0 {
+			return nil, fmt.Errorf("namespace access rule %d has no users or groups", i)
+		}
+		if len(rule.Namespaces) == 0 {
+			return nil, fmt.Errorf("namespace access rule %d grants no namespaces", i)
+		}
+		for _, namespace := range rule.Namespaces {
+			if strings.TrimSpace(namespace) == "" {
+				return nil, fmt.Errorf("namespace access rule %d has an empty namespace", i)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// LoadStaticPolicy reads a YAML policy from disk.
+func LoadStaticPolicy(path string) (*StaticPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read namespace access policy '%s': %w", path, err)
+	}
+	return ParseStaticPolicy(data)
+}
+
+// CanAccess reports whether a rule grants identity the namespace.
+func (p *StaticPolicy) CanAccess(ctx context.Context, identity *auth.Identity, namespace string) (bool, error) {
+	for _, granted := range p.granted(identity) {
+		if granted == AllNamespaces || (namespace != "" && granted == namespace) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AccessibleNamespaces lists the namespaces granted to identity, sorted.
+func (p *StaticPolicy) AccessibleNamespaces(ctx context.Context, identity *auth.Identity) ([]string, error) {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, granted := range p.granted(identity) {
+		if granted != AllNamespaces && !seen[granted] {
+			seen[granted] = true
+			namespaces = append(namespaces, granted)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// granted returns the namespaces from every rule matching identity.
+func (p *StaticPolicy) granted(identity *auth.Identity) []string {
+	if identity == nil {
+		return nil
+	}
+	var namespaces []string
+	for _, rule := range p.Rules {
+		if ruleMatches(rule, identity) {
+			namespaces = append(namespaces, rule.Namespaces...)
+		}
+	}
+	return namespaces
+}
+
+func ruleMatches(rule StaticRule, identity *auth.Identity) bool {
+	for _, user := range rule.Users {
+		if user == identity.UserID {
+			return true
+		}
+	}
+	for _, group := range rule.Groups {
+		for _, member := range identity.Groups {
+			if group == member {
+				return true
+			}
+		}
+	}
+	return false
+}
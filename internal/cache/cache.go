@@ -0,0 +1,56 @@
+// Package cache stores validated structured queries keyed by the natural
+// language query that produced them, so repeated questions are answered
+// without another LLM call.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"genai-processing/pkg/types"
+)
+
+// ErrMiss is returned by a Cache when a key is absent or has expired.
+var ErrMiss = errors.New("cache miss")
+
+// Entry is a cached result: the validated query and the provider that
+// produced it.
+type Entry struct {
+	// Query is the structured query after normalization and safety validation,
+	// before it was scoped to the caller's namespaces
+	Query *types.StructuredQuery `json:"query"`
+
+	// Provider is the models.yaml name of the provider that answered
+	Provider string `json:"provider,omitempty"`
+}
+
+// Cache stores entries for a limited time. Implementations must be safe for
+// concurrent use and must return copies, so callers may modify what Get
+// returns without affecting the cache.
+type Cache interface {
+	// Get returns the entry for key, or ErrMiss
+	Get(key string) (*Entry, error)
+
+	// Set stores an entry under key, replacing any existing entry
+	Set(key string, entry *Entry) error
+
+	// Close releases resources held by the cache
+	Close() error
+}
+
+// Key derives a cache key from a query, after context resolution, and the
+// provider and model it is sent to. Queries differing only in case,
+// whitespace or trailing punctuation share a key.
+func Key(query, provider, model string) string {
+	sum := sha256.Sum256([]byte(NormalizeQuery(query) + "\x00" + provider + "\x00" + model))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeQuery lowercases a query, collapses runs of whitespace and trims
+// trailing punctuation.
+func NormalizeQuery(query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return strings.TrimRight(normalized, "?.! ")
+}
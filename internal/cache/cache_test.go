@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"genai-processing/internal/redisclient"
+	"genai-processing/internal/redisclient/redistest"
+	"genai-processing/pkg/types"
+)
+
+func testEntry(logSource string) *Entry {
+	return &Entry{
+		Query: &types.StructuredQuery{
+			LogSource: logSource,
+			Verb:      *types.NewStringOrArray([]string{"get", "list"}),
+			Timeframe: "today",
+		},
+		Provider: "claude",
+	}
+}
+
+// cacheFactories builds each Cache implementation with a shared clock.
+func cacheFactories(t *testing.T, now func() time.Time, ttl time.Duration) map[string]func() Cache {
+	t.Helper()
+	srv, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start redis stand-in: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	srv.SetClock(now)
+
+	return map[string]func() Cache{
+		"memory": func() Cache {
+			c := NewMemoryCache(ttl, 10)
+			c.now = now
+			return c
+		},
+		"redis": func() Cache {
+			c, err := NewRedisCache(redisclient.Config{Addr: srv.Addr()}, "test:", ttl)
+			if err != nil {
+				t.Fatalf("NewRedisCache failed: %v", err)
+			}
+			return c
+		},
+	}
+}
+
+func TestCaches_GetSetAndExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 29, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	for name, newCache := range cacheFactories(t, clock, time.Minute) {
+		t.Run(name, func(t *testing.T) {
+			now = time.Date(2025, 1, 29, 12, 0, 0, 0, time.UTC)
+			c := newCache()
+			defer c.Close()
+
+			if _, err := c.Get("missing"); err != ErrMiss {
+				t.Fatalf("expected ErrMiss, got %v", err)
+			}
+			if err := c.Set("k", testEntry("kube-apiserver")); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+
+			entry, err := c.Get("k")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if entry.Provider != "claude" || entry.Query.LogSource != "kube-apiserver" {
+				t.Errorf("unexpected entry %+v", entry)
+			}
+			if verbs := entry.Query.Verb.GetArray(); len(verbs) != 2 || verbs[1] != "list" {
+				t.Errorf("expected verbs to round-trip, got %v", verbs)
+			}
+
+			// Entries are copies
+			entry.Query.LogSource = "oauth-server"
+			if again, _ := c.Get("k"); again.Query.LogSource != "kube-apiserver" {
+				t.Error("modifying a returned entry changed the cache")
+			}
+
+			now = now.Add(time.Minute)
+			if _, err := c.Get("k"); err != ErrMiss {
+				t.Errorf("expected entry to expire, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCaches_RejectEmptyEntry(t *testing.T) {
+	for name, newCache := range cacheFactories(t, time.Now, time.Minute) {
+		t.Run(name, func(t *testing.T) {
+			c := newCache()
+			defer c.Close()
+			if err := c.Set("k", &Entry{}); err == nil {
+				t.Error("expected error for entry without a query")
+			}
+		})
+	}
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(time.Minute, 2)
+	_ = c.Set("a", testEntry("kube-apiserver"))
+	_ = c.Set("b", testEntry("oauth-server"))
+	if _, err := c.Get("a"); err != nil {
+		t.Fatalf("Get(a) failed: %v", err)
+	}
+	_ = c.Set("c", testEntry("openshift-apiserver"))
+
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+	if _, err := c.Get("b"); err != ErrMiss {
+		t.Errorf("expected least recently used entry to be evicted, got %v", err)
+	}
+	if _, err := c.Get("a"); err != nil {
+		t.Errorf("expected recently used entry to remain, got %v", err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := Key("Show daily authentication failures?", "claude", "claude-3-5-sonnet")
+	if Key("  show   DAILY authentication failures ", "claude", "claude-3-5-sonnet") != base {
+		t.Error("expected queries differing in case, whitespace and punctuation to share a key")
+	}
+	if Key("Show daily authentication failures?", "openai", "gpt-4") == base {
+		t.Error("expected different providers to have different keys")
+	}
+	if Key("Show weekly authentication failures?", "claude", "claude-3-5-sonnet") == base {
+		t.Error("expected different queries to have different keys")
+	}
+}
+
+func TestNewRedisCache_Errors(t *testing.T) {
+	if _, err := NewRedisCache(redisclient.Config{Addr: "127.0.0.1:1"}, "", 0); err == nil {
+		t.Error("expected error for non-positive ttl")
+	}
+	if _, err := NewRedisCache(redisclient.Config{Addr: "127.0.0.1:1", Timeout: 100 * time.Millisecond}, "", time.Minute); err == nil {
+		t.Error("expected error for unreachable server")
+	}
+}
@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Embedder turns text into a vector whose cosine similarity with the vectors
+// of other texts reflects how alike their meanings are.
+type Embedder interface {
+	// Embed returns the embedding of text
+	Embed(ctx context.Context, text string) ([]float64, error)
+
+	// Name identifies the embedder, and its model where it has one
+	Name() string
+}
+
+// DefaultHashingDimensions is the vector size of a HashingEmbedder created
+// without one.
+const DefaultHashingDimensions = 1024
+
+// HashingEmbedder embeds text offline by hashing its terms and adjacent term
+// pairs into a fixed-size vector. Terms are lowercased, stripped of plural
+// endings and mapped to a canonical word for common audit-query synonyms, so
+// "who removed" and "which user deleted" embed alike; question words and
+// other filler are dropped.
+type HashingEmbedder struct {
+	dimensions int
+}
+
+// NewHashingEmbedder creates a HashingEmbedder producing vectors of the given
+// size. A non-positive size uses DefaultHashingDimensions.
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashingDimensions
+	}
+	return &HashingEmbedder{dimensions: dimensions}
+}
+
+// Name returns "hashing".
+func (e *HashingEmbedder) Name() string {
+	return "hashing"
+}
+
+// Embed returns the unit-length hashed term vector of text; text without
+// meaningful terms embeds as the zero vector.
+func (e *HashingEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	vector := make([]float64, e.dimensions)
+	terms := queryTerms(text)
+	for i, term := range terms {
+		e.add(vector, term)
+		if i > 0 {
+			e.add(vector, terms[i-1]+" "+term)
+		}
+	}
+	normalize(vector)
+	return vector, nil
+}
+
+// add hashes a feature into the vector. One bit of the hash chooses the sign,
+// so collisions cancel out rather than accumulate.
+func (e *HashingEmbedder) add(vector []float64, feature string) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	sign := 1.0
+	if sum&(1<<63) != 0 {
+		sign = -1.0
+	}
+	vector[sum%uint64(len(vector))] += sign
+}
+
+// stopWords carry no meaning for audit queries.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "which": true, "what": true, "whom": true,
+	"me": true, "i": true, "my": true, "you": true, "can": true, "please": true,
+	"show": true, "find": true, "give": true, "tell": true, "display": true,
+	"did": true, "do": true, "does": true, "is": true, "are": true, "was": true, "were": true, "has": true, "have": true, "been": true,
+	"in": true, "on": true, "of": true, "for": true, "to": true, "by": true, "from": true, "with": true, "at": true,
+	"all": true, "any": true, "there": true, "that": true, "this": true,
+}
+
+// synonyms map words, after plural stripping, to a canonical word. Stop
+// words are dropped both before and after.
+var synonyms = map[string]string{
+	"who": "user", "someone": "user", "somebody": "user", "person": "user", "account": "user",
+	"deleted": "delete", "deleting": "delete", "remove": "delete", "removed": "delete", "removing": "delete",
+	"erase": "delete", "erased": "delete", "destroy": "delete", "destroyed": "delete", "deletion": "delete",
+	"created": "create", "creating": "create", "creation": "create", "made": "create", "make": "create", "add": "create", "added": "create",
+	"updated": "update", "updating": "update", "modify": "update", "modified": "update", "change": "update", "changed": "update", "edit": "update", "edited": "update",
+	"read": "get", "viewed": "get", "view": "get", "accessed": "get", "access": "get", "fetched": "get", "fetch": "get", "retrieved": "get",
+	"listed": "list", "listing": "list",
+	"patched": "patch", "patching": "patch",
+	"failure": "fail", "failed": "fail", "failing": "fail", "unsuccessful": "fail", "denied": "forbid", "forbidden": "forbid", "rejected": "forbid",
+	"login": "authentication", "logon": "authentication", "signin": "authentication", "auth": "authentication",
+	"customresourcedefinition": "crd", "serviceaccount": "sa", "persistentvolumeclaim": "pvc", "namespace": "ns", "project": "ns",
+}
+
+// queryTerms splits text into canonical terms.
+func queryTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != ':'
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.Trim(word, "-_:")
+		if word == "" || stopWords[word] {
+			continue
+		}
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		if canonical, ok := synonyms[word]; ok {
+			word = canonical
+		}
+		if !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// timeUnits map time words to a canonical unit. Single letters only count
+// as units after a number, as in 24h.
+var timeUnits = map[string]string{
+	"s": "second", "sec": "second", "secs": "second", "second": "second", "seconds": "second",
+	"m": "minute", "min": "minute", "mins": "minute", "minute": "minute", "minutes": "minute",
+	"h": "hour", "hr": "hour", "hrs": "hour", "hour": "hour", "hours": "hour", "hourly": "hour",
+	"d": "day", "day": "day", "days": "day", "daily": "day", "today": "today", "yesterday": "yesterday", "tonight": "tonight",
+	"w": "week", "wk": "week", "wks": "week", "week": "week", "weeks": "week", "weekly": "week",
+	"month": "month", "months": "month", "monthly": "month", "year": "year", "years": "year",
+}
+
+// entityCues introduce the name of a namespace, user, group or service
+// account in the following word; namespace cues also follow the name.
+var entityCues = map[string]bool{
+	"namespace": true, "namespaces": true, "ns": true, "project": true, "projects": true,
+	"user": true, "users": true, "by": true, "account": true, "serviceaccount": true, "sa": true, "group": true, "groups": true,
+}
+
+var namespaceCues = map[string]bool{"namespace": true, "namespaces": true, "ns": true, "project": true, "projects": true}
+
+// queryEntities returns the sorted entity-like tokens of text, which two
+// queries must share before one reuses the other's answer, whatever their
+// similarity: numbers and other tokens with digits, time units, names
+// containing - _ : . @ or /, and the words naming a namespace, user, group
+// or service account ("namespace payments", "payments namespace", "by alice").
+// Numbers written together with a unit ("24h") are split into both.
+func queryEntities(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_:.@/", r)
+	})
+	for i, word := range words {
+		words[i] = strings.Trim(word, "-_:.@/")
+	}
+
+	// name reports whether a word next to a cue names something rather than
+	// being part of the question
+	name := func(word string) bool {
+		if word == "" || stopWords[word] || entityCues[word] || len(word) > 1 && timeUnits[word] != "" {
+			return false
+		}
+		_, known := synonyms[word]
+		return !known
+	}
+
+	seen := make(map[string]bool)
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		if unit, ok := timeUnits[word]; ok && len(word) > 1 {
+			seen[unit] = true
+			continue
+		}
+		if unit := strings.TrimLeftFunc(word, unicode.IsDigit); unit != word && timeUnits[unit] != "" {
+			seen[word[:len(word)-len(unit)]] = true
+			seen[timeUnits[unit]] = true
+			continue
+		}
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 || strings.ContainsAny(word, "-_:.@/") {
+			seen[word] = true
+			continue
+		}
+		if !entityCues[word] {
+			continue
+		}
+		if i+1 < len(words) && name(words[i+1]) {
+			seen[words[i+1]] = true
+		}
+		if namespaceCues[word] && i > 0 && name(words[i-1]) {
+			seen[words[i-1]] = true
+		}
+	}
+
+	entities := make([]string, 0, len(seen))
+	for entity := range seen {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// ProviderEmbedder embeds text with an embeddings API: OpenAI and compatible
+// servers such as vLLM (POST /v1/embeddings), or Ollama (POST /api/embed).
+type ProviderEmbedder struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewProviderEmbedder creates a ProviderEmbedder posting to endpoint, the
+// full URL of the embeddings API.
+func NewProviderEmbedder(endpoint, model, apiKey string) (*ProviderEmbedder, error) {
+	if strings.TrimSpace(endpoint) == "" {
+		return nil, fmt.Errorf("embedding endpoint cannot be empty")
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("embedding model cannot be empty")
+	}
+	return &ProviderEmbedder{
+		endpoint: endpoint,
+		model:    model,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns "provider:" followed by the model.
+func (e *ProviderEmbedder) Name() string {
+	return "provider:" + e.model
+}
+
+// embeddingResponse accepts the OpenAI ("data") and Ollama ("embeddings")
+// response shapes.
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Embed requests the embedding of text.
+func (e *ProviderEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	var vector []float64
+	switch {
+	case len(parsed.Data) > 0:
+		vector = parsed.Data[0].Embedding
+	case len(parsed.Embeddings) > 0:
+		vector = parsed.Embeddings[0]
+	}
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("embedding response contained no embedding")
+	}
+	return vector, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when either is zero or their sizes differ.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// normalize scales a vector to unit length in place.
+func normalize(vector []float64) {
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
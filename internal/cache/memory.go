@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries bounds a MemoryCache created without a size limit.
+const DefaultMaxEntries = 1000
+
+type memoryItem struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process LRU cache. Entries expire after the TTL, and
+// the least recently used entry is evicted when the cache is full.
+type MemoryCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxEntries entries for
+// ttl each. A non-positive maxEntries uses DefaultMaxEntries.
+func NewMemoryCache(ttl time.Duration, maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for key and marks it recently used.
+func (c *MemoryCache) Get(key string) (*Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	item := element.Value.(*memoryItem)
+	if !c.now().Before(item.expiresAt) {
+		c.remove(element)
+		return nil, ErrMiss
+	}
+	c.order.MoveToFront(element)
+	return decodeEntry(item.data)
+}
+
+// Set stores an entry, evicting the least recently used entry when full.
+func (c *MemoryCache) Set(key string, entry *Entry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*memoryItem)
+		item.data, item.expiresAt = data, expiresAt
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&memoryItem{key: key, data: data, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Close is a no-op.
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*memoryItem).key)
+}
+
+// encodeEntry encodes an entry as stored by the cache backends.
+func encodeEntry(entry *Entry) ([]byte, error) {
+	if entry == nil || entry.Query == nil {
+		return nil, fmt.Errorf("cache entry must have a query")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return data, nil
+}
+
+// decodeEntry decodes an entry written by encodeEntry.
+func decodeEntry(data []byte) (*Entry, error) {
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return &entry, nil
+}
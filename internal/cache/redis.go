@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"genai-processing/internal/redisclient"
+)
+
+// DefaultRedisKeyPrefix namespaces cache keys written by RedisCache.
+const DefaultRedisKeyPrefix = "genai:cache:"
+
+// RedisCache keeps entries in any server speaking the Redis protocol, so
+// several server replicas share one cache. Entries are stored as JSON strings
+// with the TTL set on the key; the number of entries is bounded by the
+// server's own maxmemory policy rather than by the cache.
+type RedisCache struct {
+	client    *redisclient.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewRedisCache connects to a Redis-protocol server. An empty keyPrefix uses
+// DefaultRedisKeyPrefix.
+func NewRedisCache(config redisclient.Config, keyPrefix string, ttl time.Duration) (*RedisCache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("cache ttl must be positive")
+	}
+	client, err := redisclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	if keyPrefix == "" {
+		keyPrefix = DefaultRedisKeyPrefix
+	}
+	return &RedisCache{client: client, keyPrefix: keyPrefix, ttl: ttl}, nil
+}
+
+// Get returns the entry for key. Expired keys are removed by the server.
+func (c *RedisCache) Get(key string) (*Entry, error) {
+	reply, err := c.client.Do("GET", c.keyPrefix+key)
+	if err == redisclient.ErrNil {
+		return nil, ErrMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected redis reply for cache entry")
+	}
+	return decodeEntry([]byte(data))
+}
+
+// Set writes an entry with the cache TTL.
+func (c *RedisCache) Set(key string, entry *Entry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	ttl := strconv.FormatInt(c.ttl.Milliseconds(), 10)
+	if _, err := c.client.Do("SET", c.keyPrefix+key, string(data), "PX", ttl); err != nil {
+		return fmt.Errorf("failed to set cache entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSimilarityThreshold is the cosine similarity a SemanticCache
+// created without a threshold requires before reusing a query.
+const DefaultSimilarityThreshold = 0.92
+
+// SemanticMatch is a cached entry reused for a query similar to, but not the
+// same as, the one it was generated for.
+type SemanticMatch struct {
+	// Entry is the reused entry
+	Entry *Entry `json:"-"`
+
+	// Query is the query the entry was generated for
+	Query string `json:"matched_query"`
+
+	// Similarity is the cosine similarity between the two queries
+	Similarity float64 `json:"similarity"`
+
+	// Threshold is the similarity the match had to reach
+	Threshold float64 `json:"threshold"`
+
+	// Embedder names the embedder that compared the queries
+	Embedder string `json:"embedder"`
+}
+
+type semanticItem struct {
+	scope     string
+	query     string
+	entities  string
+	vector    []float64
+	data      []byte
+	expiresAt time.Time
+}
+
+// SemanticCache reuses entries for near-duplicate queries. Queries are
+// embedded, and a lookup returns the entry of the most similar unexpired
+// query for the same provider and model, if its similarity reaches the
+// threshold. Only queries naming the same entities (see queryEntities) are
+// compared, so "pods deleted in the last 2 hours" never reuses the answer
+// for the last 24 hours, however close the embeddings. The oldest entry is
+// evicted when the cache is full.
+type SemanticCache struct {
+	embedder   Embedder
+	threshold  float64
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu    sync.Mutex
+	items []*semanticItem // oldest first
+}
+
+// NewSemanticCache creates a SemanticCache holding at most maxEntries entries
+// for ttl each. A non-positive threshold uses DefaultSimilarityThreshold and
+// a non-positive maxEntries uses DefaultMaxEntries.
+func NewSemanticCache(embedder Embedder, threshold float64, ttl time.Duration, maxEntries int) (*SemanticCache, error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("semantic cache requires an embedder")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("semantic cache ttl must be positive")
+	}
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	if threshold > 1 {
+		return nil, fmt.Errorf("similarity threshold must be at most 1, got %v", threshold)
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &SemanticCache{
+		embedder:   embedder,
+		threshold:  threshold,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}, nil
+}
+
+// Embed returns the embedding of a query, for use with Lookup and Add.
+func (c *SemanticCache) Embed(ctx context.Context, query string) ([]float64, error) {
+	vector, err := c.embedder.Embed(ctx, NormalizeQuery(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return vector, nil
+}
+
+// Lookup returns the closest match for an embedded query answered by the given
+// provider and model, or ErrMiss when none naming the same entities reaches
+// the threshold.
+func (c *SemanticCache) Lookup(vector []float64, query, provider, model string) (*SemanticMatch, error) {
+	scope := semanticScope(provider, model)
+	entities := strings.Join(queryEntities(query), " ")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire()
+	var best *semanticItem
+	bestSimilarity := 0.0
+	for _, item := range c.items {
+		if item.scope != scope || item.entities != entities {
+			continue
+		}
+		if similarity := cosineSimilarity(vector, item.vector); similarity > bestSimilarity {
+			best, bestSimilarity = item, similarity
+		}
+	}
+	if best == nil || bestSimilarity < c.threshold {
+		return nil, ErrMiss
+	}
+
+	entry, err := decodeEntry(best.data)
+	if err != nil {
+		return nil, err
+	}
+	return &SemanticMatch{
+		Entry:      entry,
+		Query:      best.query,
+		Similarity: bestSimilarity,
+		Threshold:  c.threshold,
+		Embedder:   c.embedder.Name(),
+	}, nil
+}
+
+// Add stores the entry for an embedded query answered by the given provider
+// and model, replacing any entry for the same query.
+func (c *SemanticCache) Add(vector []float64, query, provider, model string, entry *Entry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	item := &semanticItem{
+		scope:     semanticScope(provider, model),
+		query:     query,
+		entities:  strings.Join(queryEntities(query), " "),
+		vector:    vector,
+		data:      data,
+		expiresAt: c.now().Add(c.ttl),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire()
+	normalized := NormalizeQuery(query)
+	for i, existing := range c.items {
+		if existing.scope == item.scope && NormalizeQuery(existing.query) == normalized {
+			c.items = append(c.items[:i], c.items[i+1:]...)
+			break
+		}
+	}
+	if len(c.items) >= c.maxEntries {
+		c.items = c.items[len(c.items)-c.maxEntries+1:]
+	}
+	c.items = append(c.items, item)
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *SemanticCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// expire drops expired entries. Entries share a TTL, so they expire in the
+// order they were added. Callers hold c.mu.
+func (c *SemanticCache) expire() {
+	now := c.now()
+	n := 0
+	for n < len(c.items) && !now.Before(c.items[n].expiresAt) {
+		n++
+	}
+	if n > 0 {
+		c.items = append([]*semanticItem(nil), c.items[n:]...)
+	}
+}
+
+func semanticScope(provider, model string) string {
+	return provider + "\x00" + model
+}
@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashingEmbedder_Similarity(t *testing.T) {
+	embedder := NewHashingEmbedder(0)
+	similarity := func(a, b string) float64 {
+		va, _ := embedder.Embed(context.Background(), a)
+		vb, _ := embedder.Embed(context.Background(), b)
+		return cosineSimilarity(va, vb)
+	}
+
+	tests := []struct {
+		a, b    string
+		similar bool
+	}{
+		{"who deleted the customer CRD yesterday", "which user removed the customer CRD yesterday", true},
+		{"show failed logins today", "failed login attempts today", false},
+		{"find pods deleted today", "show me the pods removed today", true},
+		{"who deleted pods yesterday", "who deleted pods today", false},
+		{"who deleted the customer CRD yesterday", "who created secrets in namespace payments", false},
+	}
+	for _, tt := range tests {
+		got := similarity(tt.a, tt.b)
+		if (got >= DefaultSimilarityThreshold) != tt.similar {
+			t.Errorf("similarity(%q, %q) = %.3f, want similar=%v", tt.a, tt.b, got, tt.similar)
+		}
+	}
+}
+
+func TestProviderEmbedder_ResponseShapes(t *testing.T) {
+	for name, body := range map[string]string{
+		"openai": `{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2,0.3]}]}`,
+		"ollama": `{"model":"nomic-embed-text","embeddings":[[0.1,0.2,0.3]]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var request map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer key" {
+					t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+				}
+				_ = json.NewDecoder(r.Body).Decode(&request)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			embedder, err := NewProviderEmbedder(server.URL, "embed-model", "key")
+			if err != nil {
+				t.Fatalf("NewProviderEmbedder returned error: %v", err)
+			}
+			vector, err := embedder.Embed(context.Background(), "who deleted pods")
+			if err != nil {
+				t.Fatalf("Embed returned error: %v", err)
+			}
+			if len(vector) != 3 || vector[2] != 0.3 {
+				t.Errorf("unexpected vector %v", vector)
+			}
+			if request["model"] != "embed-model" || request["input"] != "who deleted pods" {
+				t.Errorf("unexpected request %v", request)
+			}
+		})
+	}
+}
+
+func TestProviderEmbedder_Errors(t *testing.T) {
+	if _, err := NewProviderEmbedder("", "model", ""); err == nil {
+		t.Error("expected error for empty endpoint")
+	}
+	if _, err := NewProviderEmbedder("http://localhost", "", ""); err == nil {
+		t.Error("expected error for empty model")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+	embedder, _ := NewProviderEmbedder(server.URL, "model", "")
+	if _, err := embedder.Embed(context.Background(), "query"); err == nil {
+		t.Error("expected error for failed request")
+	}
+}
+
+func newTestSemanticCache(t *testing.T, now func() time.Time, maxEntries int) *SemanticCache {
+	t.Helper()
+	c, err := NewSemanticCache(NewHashingEmbedder(0), 0, time.Minute, maxEntries)
+	if err != nil {
+		t.Fatalf("NewSemanticCache returned error: %v", err)
+	}
+	c.now = now
+	return c
+}
+
+func TestSemanticCache_LookupAndExpiry(t *testing.T) {
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newTestSemanticCache(t, func() time.Time { return current }, 10)
+	ctx := context.Background()
+
+	stored, _ := c.Embed(ctx, "who deleted the customer CRD yesterday")
+	if err := c.Add(stored, "who deleted the customer CRD yesterday", "claude", "model", testEntry("kube-apiserver")); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	similar, _ := c.Embed(ctx, "Which user removed the customer CRD yesterday?")
+	match, err := c.Lookup(similar, "Which user removed the customer CRD yesterday?", "claude", "model")
+	if err != nil {
+		t.Fatalf("expected a match, got %v", err)
+	}
+	if match.Query != "who deleted the customer CRD yesterday" || match.Entry.Query.LogSource != "kube-apiserver" {
+		t.Errorf("unexpected match %+v", match)
+	}
+	if match.Similarity < match.Threshold || match.Threshold != DefaultSimilarityThreshold || match.Embedder != "hashing" {
+		t.Errorf("unexpected match details %+v", match)
+	}
+
+	if _, err := c.Lookup(similar, "Which user removed the customer CRD yesterday?", "openai", "model"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expected a miss for another provider, got %v", err)
+	}
+	different, _ := c.Embed(ctx, "who created secrets in namespace payments")
+	if _, err := c.Lookup(different, "who created secrets in namespace payments", "claude", "model"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expected a miss for a different query, got %v", err)
+	}
+
+	current = current.Add(time.Minute)
+	if _, err := c.Lookup(similar, "Which user removed the customer CRD yesterday?", "claude", "model"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expected a miss after expiry, got %v", err)
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired entries to be removed, got %d", c.Len())
+	}
+}
+
+func TestSemanticCache_EvictsOldest(t *testing.T) {
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newTestSemanticCache(t, func() time.Time { return current }, 2)
+	ctx := context.Background()
+
+	queries := []string{"who deleted pods", "who created secrets", "who patched deployments"}
+	for _, q := range queries {
+		vector, _ := c.Embed(ctx, q)
+		if err := c.Add(vector, q, "claude", "model", testEntry("kube-apiserver")); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+	first, _ := c.Embed(ctx, queries[0])
+	if _, err := c.Lookup(first, queries[0], "claude", "model"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expected the oldest entry to be evicted, got %v", err)
+	}
+
+	// Re-adding a query replaces its entry rather than duplicating it
+	last, _ := c.Embed(ctx, queries[2])
+	if err := c.Add(last, queries[2], "claude", "model", testEntry("oauth-server")); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	match, err := c.Lookup(last, queries[2], "claude", "model")
+	if err != nil || match.Entry.Query.LogSource != "oauth-server" || c.Len() != 2 {
+		t.Errorf("expected the replaced entry, got %+v, %v (len %d)", match, err, c.Len())
+	}
+}
+
+// constantEmbedder embeds every text alike, as an embeddings model might
+// for queries differing in a single name or number.
+type constantEmbedder struct{}
+
+func (constantEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	return []float64{1, 0}, nil
+}
+
+func (constantEmbedder) Name() string { return "constant" }
+
+func TestQueryEntities(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"pods deleted in the last 2 hours", "2 hour"},
+		{"pods removed in the past 24h", "24 hour"},
+		{"who deleted the customer CRD yesterday", "yesterday"},
+		{"secrets read in namespace payments", "payments"},
+		{"secrets read in the payments namespace", "payments"},
+		{"what did user alice delete? what's new", "alice"},
+		{"configmaps changed by system:serviceaccount:ci:deployer", "system:serviceaccount:ci:deployer"},
+		{"which user deleted pods in kube-system", "kube-system"},
+		{"who deleted pods", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(queryEntities(tt.query), " "); got != tt.want {
+			t.Errorf("queryEntities(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSemanticCache_EntitiesMustAgree(t *testing.T) {
+	c, err := NewSemanticCache(constantEmbedder{}, 0, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("NewSemanticCache returned error: %v", err)
+	}
+	ctx := context.Background()
+	add := func(query string) {
+		vector, _ := c.Embed(ctx, query)
+		if err := c.Add(vector, query, "claude", "model", testEntry("kube-apiserver")); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+	add("pods deleted in the last 2 hours")
+	add("secrets read in namespace payments")
+	add("what did user alice delete yesterday")
+
+	tests := []struct {
+		query string
+		match string
+	}{
+		{"pods deleted in the last 24 hours", ""},
+		{"pods deleted in the last 2 days", ""},
+		{"secrets read in namespace billing", ""},
+		{"what did user bob delete yesterday", ""},
+		{"what did user alice delete today", ""},
+		{"pods removed during the last 2 hours", "pods deleted in the last 2 hours"},
+		{"secrets accessed in the payments namespace", "secrets read in namespace payments"},
+	}
+	for _, tt := range tests {
+		vector, _ := c.Embed(ctx, tt.query)
+		match, err := c.Lookup(vector, tt.query, "claude", "model")
+		if tt.match == "" {
+			if !errors.Is(err, ErrMiss) {
+				t.Errorf("expected %q to miss, got %+v", tt.query, match)
+			}
+			continue
+		}
+		if err != nil || match.Query != tt.match || match.Similarity < DefaultSimilarityThreshold {
+			t.Errorf("expected %q to reuse %q, got %+v, %v", tt.query, tt.match, match, err)
+		}
+	}
+}
+
+func TestNewSemanticCache_Errors(t *testing.T) {
+	if _, err := NewSemanticCache(nil, 0.9, time.Minute, 0); err == nil {
+		t.Error("expected error without an embedder")
+	}
+	if _, err := NewSemanticCache(NewHashingEmbedder(0), 0.9, 0, 0); err == nil {
+		t.Error("expected error for zero ttl")
+	}
+	if _, err := NewSemanticCache(NewHashingEmbedder(0), 1.5, time.Minute, 0); err == nil {
+		t.Error("expected error for a threshold above 1")
+	}
+}
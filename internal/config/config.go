@@ -12,9 +12,16 @@ import (
 
 // AppConfig represents the main application configuration
 type AppConfig struct {
-	Server  ServerConfig  `yaml:"server" validate:"required"`
-	Models  ModelsConfig  `yaml:"models" validate:"required"`
-	Prompts PromptsConfig `yaml:"prompts" validate:"required"`
+	Server     ServerConfig     `yaml:"server" validate:"required"`
+	Models     ModelsConfig     `yaml:"models" validate:"required"`
+	Prompts    PromptsConfig    `yaml:"prompts" validate:"required"`
+	Sessions   SessionsConfig   `yaml:"sessions"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Authz      AuthzConfig      `yaml:"authorization"`
+	Cache      CacheConfig      `yaml:"cache"`
+	AuditTrail AuditTrailConfig `yaml:"audit_trail"`
+	Usage      UsageConfig      `yaml:"usage"`
+	Reload     ReloadConfig     `yaml:"reload"`
 }
 
 // ServerConfig defines server-related configuration
@@ -28,10 +35,40 @@ type ServerConfig struct {
 	MaxRequestSize  int64         `yaml:"max_request_size" default:"1048576"` // 1MB
 }
 
+// SessionsConfig defines where conversation sessions are persisted
+type SessionsConfig struct {
+	Store         string `yaml:"store" default:"memory"` // memory, file or redis
+	Path          string `yaml:"path,omitempty"`         // directory for the file store
+	RedisAddr     string `yaml:"redis_addr,omitempty"`
+	RedisPassword string `yaml:"redis_password,omitempty"`
+	RedisDB       int    `yaml:"redis_db,omitempty"`
+	RedisPrefix   string `yaml:"redis_prefix,omitempty"`
+}
+
 // ModelsConfig defines model-related configuration
 type ModelsConfig struct {
 	DefaultProvider string                 `yaml:"default_provider" default:"claude"`
 	Providers       map[string]ModelConfig `yaml:"providers" validate:"required"`
+	// FallbackProviders are tried in order when the default provider is
+	// unhealthy or fails with a retryable error; empty disables failover
+	FallbackProviders   []string      `yaml:"fallback_providers,omitempty"`
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" default:"5m"`
+	// Pricing prices provider token usage for cost accounting and budgets
+	Pricing PricingConfig `yaml:"pricing,omitempty"`
+}
+
+// PricingConfig is the price table for provider token usage
+type PricingConfig struct {
+	Currency string `yaml:"currency" default:"USD"`
+	// Models maps a provider's model_name to its token prices; models
+	// without an entry cost nothing
+	Models map[string]ModelPrice `yaml:"models,omitempty"`
+}
+
+// ModelPrice is the price of a model's tokens per million
+type ModelPrice struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
 }
 
 // ModelConfig defines configuration for a specific model provider
@@ -91,6 +128,88 @@ type ValidationResult struct {
 	Warnings []string `json:"warnings,omitempty"`
 }
 
+// AuthConfig defines how API callers are authenticated
+type AuthConfig struct {
+	Mode          string `yaml:"mode"`                 // jwt, oidc, tokenreview, dev or none; the server requires one
+	Required      bool   `yaml:"required"`             // reject requests without credentials
+	JWKSFile      string `yaml:"jwks_file,omitempty"`  // keys for the jwt mode
+	IssuerURL     string `yaml:"issuer_url,omitempty"` // expected iss claim; discovery base for the oidc mode
+	Audience      string `yaml:"audience,omitempty"`   // required aud claim or TokenReview audience
+	UsernameClaim string `yaml:"username_claim,omitempty" default:"sub"`
+	GroupsClaim   string `yaml:"groups_claim,omitempty" default:"groups"`
+
+	TokenReviewURL       string        `yaml:"tokenreview_url,omitempty"`        // Kubernetes API server URL
+	TokenReviewTokenFile string        `yaml:"tokenreview_token_file,omitempty"` // credentials used to create TokenReviews
+	TokenReviewCAFile    string        `yaml:"tokenreview_ca_file,omitempty"`
+	CacheTTL             time.Duration `yaml:"cache_ttl,omitempty" default:"10s"`
+}
+
+// AuthzConfig defines how queries are scoped to the namespaces a caller may access
+type AuthzConfig struct {
+	Policy       string        `yaml:"policy" default:"none"`   // none, static or subjectaccessreview
+	PolicyFile   string        `yaml:"policy_file,omitempty"`   // rules for the static policy
+	Mode         string        `yaml:"mode" default:"narrow"`   // narrow or reject out-of-scope queries
+	APIServerURL string        `yaml:"apiserver_url,omitempty"` // Kubernetes API server for SubjectAccessReviews
+	TokenFile    string        `yaml:"token_file,omitempty"`    // credentials used to create SubjectAccessReviews
+	CAFile       string        `yaml:"ca_file,omitempty"`
+	CacheTTL     time.Duration `yaml:"cache_ttl,omitempty" default:"30s"`
+}
+
+// CacheConfig defines the response cache for repeated queries
+type CacheConfig struct {
+	Backend       string        `yaml:"backend" default:"none"` // none, memory or redis
+	TTL           time.Duration `yaml:"ttl" default:"5m"`
+	MaxEntries    int           `yaml:"max_entries" default:"1000"` // memory backend size limit
+	RedisAddr     string        `yaml:"redis_addr,omitempty"`
+	RedisPassword string        `yaml:"redis_password,omitempty"`
+	RedisDB       int           `yaml:"redis_db,omitempty"`
+	RedisPrefix   string        `yaml:"redis_prefix,omitempty"`
+
+	// Semantic reuses queries for near-duplicate questions
+	Semantic SemanticCacheConfig `yaml:"semantic"`
+}
+
+// SemanticCacheConfig defines the similarity cache consulted when the response
+// cache misses. It shares the response cache TTL.
+type SemanticCacheConfig struct {
+	Enabled           bool    `yaml:"enabled" default:"false"`
+	Threshold         float64 `yaml:"threshold" default:"0.92"`   // minimum cosine similarity, in (0, 1]
+	MaxEntries        int     `yaml:"max_entries" default:"1000"` // queries remembered
+	Embedder          string  `yaml:"embedder" default:"hashing"` // hashing or provider
+	Dimensions        int     `yaml:"dimensions,omitempty"`       // hashing embedder vector size
+	EmbeddingEndpoint string  `yaml:"embedding_endpoint,omitempty"`
+	EmbeddingModel    string  `yaml:"embedding_model,omitempty"`
+	EmbeddingAPIKey   string  `yaml:"embedding_api_key,omitempty"`
+}
+
+// AuditTrailConfig defines the persistent record of every processed query
+type AuditTrailConfig struct {
+	Backend        string   `yaml:"backend" default:"none"` // none, file or sql
+	Path           string   `yaml:"path,omitempty" default:"audit-trail.jsonl"`
+	MaxSizeMB      int      `yaml:"max_size_mb" default:"100"`               // file size before rotation
+	MaxBackups     int      `yaml:"max_backups" default:"10"`                // rotated files kept
+	SQLDriver      string   `yaml:"sql_driver,omitempty" default:"postgres"` // database/sql driver; the server links postgres
+	SQLDSN         string   `yaml:"sql_dsn,omitempty"`
+	SQLTable       string   `yaml:"sql_table,omitempty" default:"query_audit_trail"`
+	ReviewerGroups []string `yaml:"reviewer_groups,omitempty"` // groups that may read every user's records
+}
+
+// UsageConfig defines per-user budgets, in the pricing currency or in tokens,
+// per UTC day and calendar month. Zero disables a budget.
+type UsageConfig struct {
+	DailyBudget        float64 `yaml:"daily_budget,omitempty"`
+	MonthlyBudget      float64 `yaml:"monthly_budget,omitempty"`
+	DailyTokenBudget   int     `yaml:"daily_token_budget,omitempty"`
+	MonthlyTokenBudget int     `yaml:"monthly_token_budget,omitempty"`
+}
+
+// ReloadConfig defines how models.yaml, prompts.yaml and rules.yaml changes
+// are applied without a restart
+type ReloadConfig struct {
+	WatchInterval time.Duration `yaml:"watch_interval,omitempty"` // how often the files are polled for changes; zero disables watching
+	AdminGroups   []string      `yaml:"admin_groups,omitempty"`   // groups that may reload configuration through POST /admin/reload
+}
+
 // Validate validates the AppConfig and returns a ValidationResult
 func (c *AppConfig) Validate() ValidationResult {
 	result := ValidationResult{Valid: true}
@@ -113,6 +232,274 @@ func (c *AppConfig) Validate() ValidationResult {
 		result.Errors = append(result.Errors, promptsResult.Errors...)
 	}
 
+	// Validate sessions configuration
+	if sessionsResult := c.Sessions.Validate(); !sessionsResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, sessionsResult.Errors...)
+	}
+
+	// Validate auth configuration
+	authResult := c.Auth.Validate()
+	if !authResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, authResult.Errors...)
+	}
+	result.Warnings = append(result.Warnings, authResult.Warnings...)
+
+	// Validate authorization configuration
+	if authzResult := c.Authz.Validate(); !authzResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, authzResult.Errors...)
+	}
+
+	// Validate cache configuration
+	if cacheResult := c.Cache.Validate(); !cacheResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, cacheResult.Errors...)
+	}
+
+	// Validate audit trail configuration
+	if auditTrailResult := c.AuditTrail.Validate(); !auditTrailResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, auditTrailResult.Errors...)
+	}
+
+	// Validate usage budgets
+	if usageResult := c.Usage.Validate(); !usageResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, usageResult.Errors...)
+	}
+
+	// Validate configuration reloading
+	if reloadResult := c.Reload.Validate(); !reloadResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, reloadResult.Errors...)
+	}
+
+	return result
+}
+
+// Validate validates the SessionsConfig
+func (c *SessionsConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	switch c.Store {
+	case "", "memory":
+	case "file":
+		if c.Path == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "sessions path is required for the file store")
+		}
+	case "redis":
+		if c.RedisAddr == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "sessions redis_addr is required for the redis store")
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported sessions store '%s'", c.Store))
+	}
+
+	return result
+}
+
+// Validate validates the AuthConfig
+func (c *AuthConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	switch c.Mode {
+	case "":
+		result.Warnings = append(result.Warnings, "auth mode is not set; the server will not start until it is jwt, oidc, tokenreview, or explicitly dev or none")
+	case "dev":
+		result.Warnings = append(result.Warnings, "auth mode 'dev' trusts client-asserted identities; do not use it in production")
+	case "none":
+		if c.Required {
+			result.Valid = false
+			result.Errors = append(result.Errors, "auth cannot be required when mode is 'none'")
+		}
+	case "jwt":
+		if c.JWKSFile == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "auth jwks_file is required for the jwt mode")
+		}
+	case "oidc":
+		if c.IssuerURL == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "auth issuer_url is required for the oidc mode")
+		}
+	case "tokenreview":
+		if c.TokenReviewURL == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "auth tokenreview_url is required for the tokenreview mode")
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported auth mode '%s'", c.Mode))
+	}
+
+	if c.CacheTTL < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "auth cache_ttl cannot be negative")
+	}
+
+	return result
+}
+
+// Validate validates the AuthzConfig
+func (c *AuthzConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	switch c.Policy {
+	case "", "none":
+	case "static":
+		if c.PolicyFile == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "authorization policy_file is required for the static policy")
+		}
+	case "subjectaccessreview":
+		if c.APIServerURL == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "authorization apiserver_url is required for the subjectaccessreview policy")
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported authorization policy '%s'", c.Policy))
+	}
+
+	switch c.Mode {
+	case "", "narrow", "reject":
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported authorization mode '%s'", c.Mode))
+	}
+
+	if c.CacheTTL < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "authorization cache_ttl cannot be negative")
+	}
+
+	return result
+}
+
+// Validate validates the CacheConfig
+func (c *CacheConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	switch c.Backend {
+	case "", "none":
+	case "memory":
+		if c.MaxEntries < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, "cache max_entries cannot be negative")
+		}
+	case "redis":
+		if c.RedisAddr == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "cache redis_addr is required for the redis backend")
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported cache backend '%s'", c.Backend))
+	}
+
+	enabled := (c.Backend != "" && c.Backend != "none") || c.Semantic.Enabled
+	if enabled && c.TTL <= 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "cache ttl must be positive")
+	}
+
+	if semanticResult := c.Semantic.Validate(); !semanticResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, semanticResult.Errors...)
+	}
+
+	return result
+}
+
+// Validate validates the UsageConfig
+func (c *UsageConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+	if c.DailyBudget < 0 || c.MonthlyBudget < 0 || c.DailyTokenBudget < 0 || c.MonthlyTokenBudget < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "usage budgets cannot be negative")
+	}
+	return result
+}
+
+// Validate validates the ReloadConfig
+func (c *ReloadConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+	if c.WatchInterval < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "reload watch_interval cannot be negative")
+	}
+	return result
+}
+
+// Validate validates the AuditTrailConfig
+func (c *AuditTrailConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	switch c.Backend {
+	case "", "none":
+	case "file":
+		if c.Path == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "audit trail path is required for the file backend")
+		}
+		if c.MaxSizeMB < 0 || c.MaxBackups < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, "audit trail max_size_mb and max_backups cannot be negative")
+		}
+	case "sql":
+		if c.SQLDSN == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "audit trail sql_dsn is required for the sql backend")
+		}
+		if c.SQLDriver != "" && c.SQLDriver != "postgres" {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("audit trail sql_driver '%s' is not linked into the server, expected postgres", c.SQLDriver))
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported audit trail backend '%s'", c.Backend))
+	}
+
+	return result
+}
+
+// Validate validates the SemanticCacheConfig
+func (c *SemanticCacheConfig) Validate() ValidationResult {
+	result := ValidationResult{Valid: true}
+	if !c.Enabled {
+		return result
+	}
+
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("semantic cache threshold must be in (0, 1], got %v", c.Threshold))
+	}
+	if c.MaxEntries < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "semantic cache max_entries cannot be negative")
+	}
+
+	switch c.Embedder {
+	case "", "hashing":
+		if c.Dimensions < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, "semantic cache dimensions cannot be negative")
+		}
+	case "provider":
+		if c.EmbeddingEndpoint == "" || c.EmbeddingModel == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, "semantic cache embedding_endpoint and embedding_model are required for the provider embedder")
+		}
+	default:
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("unsupported semantic cache embedder '%s'", c.Embedder))
+	}
+
 	return result
 }
 
@@ -183,6 +570,30 @@ func (c *ModelsConfig) Validate() ValidationResult {
 		result.Errors = append(result.Errors, "at least one provider must be configured")
 	}
 
+	seen := make(map[string]bool)
+	for _, name := range c.FallbackProviders {
+		if _, exists := c.Providers[name]; !exists {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("fallback provider '%s' not found in providers", name))
+		} else if name == c.DefaultProvider || seen[name] {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("fallback provider '%s' is listed more than once or is the default provider", name))
+		}
+		seen[name] = true
+	}
+
+	if c.HealthCheckInterval < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, "health_check_interval must be non-negative")
+	}
+
+	for model, price := range c.Pricing.Models {
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("pricing for model '%s' cannot be negative", model))
+		}
+	}
+
 	for name, provider := range c.Providers {
 		if providerResult := provider.Validate(); !providerResult.Valid {
 			result.Valid = false
@@ -211,7 +622,7 @@ func (c *ModelConfig) Validate() ValidationResult {
 	}
 
 	// API key is only required for external providers, not for local models
-	if c.Provider != "ollama" && c.Provider != "local" && c.Provider != "generic" {
+	if c.Provider != "ollama" && c.Provider != "vllm" && c.Provider != "local" && c.Provider != "generic" {
 		if c.APIKey == "" {
 			result.Valid = false
 			result.Errors = append(result.Errors, "api_key is required")
@@ -417,7 +828,15 @@ func GetDefaultConfig() *AppConfig {
 			MaxRequestSize:  1048576, // 1MB
 		},
 		Models: ModelsConfig{
-			DefaultProvider: "claude",
+			DefaultProvider:     "claude",
+			HealthCheckInterval: 5 * time.Minute,
+			Pricing: PricingConfig{
+				Currency: "USD",
+				Models: map[string]ModelPrice{
+					"claude-3-5-sonnet-20241022": {InputPerMillion: 3, OutputPerMillion: 15},
+					"gpt-4":                      {InputPerMillion: 30, OutputPerMillion: 60},
+				},
+			},
 			Providers: map[string]ModelConfig{
 				"claude": {
 					Provider:        "anthropic",
@@ -458,7 +877,7 @@ func GetDefaultConfig() *AppConfig {
 					RetryAttempts:   3,
 					RetryDelay:      1 * time.Second,
 					InputAdapter:    "ollama_input_adapter",
-					OutputParser:    "generic_extractor",
+					OutputParser:    "ollama_extractor",
 					PromptFormatter: "generic_formatter",
 				},
 			},
@@ -543,5 +962,37 @@ JSON Response:`,
 				ForbiddenWords:  []string{"rm -rf", "delete --all", "system:admin"},
 			},
 		},
+		Sessions: SessionsConfig{
+			Store: "memory",
+		},
+		Auth: AuthConfig{
+			UsernameClaim: "sub",
+			GroupsClaim:   "groups",
+			CacheTTL:      10 * time.Second,
+		},
+		Authz: AuthzConfig{
+			Policy:   "none",
+			Mode:     "narrow",
+			CacheTTL: 30 * time.Second,
+		},
+		Cache: CacheConfig{
+			Backend:    "none",
+			TTL:        5 * time.Minute,
+			MaxEntries: 1000,
+			Semantic: SemanticCacheConfig{
+				Enabled:    false,
+				Threshold:  0.92,
+				MaxEntries: 1000,
+				Embedder:   "hashing",
+			},
+		},
+		AuditTrail: AuditTrailConfig{
+			Backend:    "none",
+			Path:       "audit-trail.jsonl",
+			MaxSizeMB:  100,
+			MaxBackups: 10,
+			SQLDriver:  "postgres",
+			SQLTable:   "query_audit_trail",
+		},
 	}
 }
@@ -137,6 +137,20 @@ func TestModelConfig_Validate(t *testing.T) {
 			},
 			wantValid: true,
 		},
+		{
+			name: "vllm without api key",
+			config: ModelConfig{
+				Provider:      "vllm",
+				Endpoint:      "http://localhost:8000",
+				ModelName:     "meta-llama/Llama-3.1-8B-Instruct",
+				MaxTokens:     4000,
+				Temperature:   0.1,
+				Timeout:       60 * time.Second,
+				RetryAttempts: 3,
+				RetryDelay:    1 * time.Second,
+			},
+			wantValid: true,
+		},
 		{
 			name: "missing provider",
 			config: ModelConfig{
@@ -252,6 +266,224 @@ func TestPromptsConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestSessionsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    SessionsConfig
+		wantValid bool
+	}{
+		{name: "default memory store", config: SessionsConfig{}, wantValid: true},
+		{name: "file store with path", config: SessionsConfig{Store: "file", Path: "/var/lib/genai/sessions"}, wantValid: true},
+		{name: "file store without path", config: SessionsConfig{Store: "file"}, wantValid: false},
+		{name: "redis store with address", config: SessionsConfig{Store: "redis", RedisAddr: "localhost:6379"}, wantValid: true},
+		{name: "redis store without address", config: SessionsConfig{Store: "redis"}, wantValid: false},
+		{name: "unknown store", config: SessionsConfig{Store: "etcd"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("SessionsConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestAuthConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AuthConfig
+		wantValid bool
+	}{
+		{name: "unset mode", config: AuthConfig{}, wantValid: true},
+		{name: "dev", config: AuthConfig{Mode: "dev"}, wantValid: true},
+		{name: "none", config: AuthConfig{Mode: "none"}, wantValid: true},
+		{name: "none cannot be required", config: AuthConfig{Mode: "none", Required: true}, wantValid: false},
+		{name: "jwt with jwks file", config: AuthConfig{Mode: "jwt", JWKSFile: "/etc/genai/jwks.json"}, wantValid: true},
+		{name: "jwt without jwks file", config: AuthConfig{Mode: "jwt"}, wantValid: false},
+		{name: "oidc with issuer", config: AuthConfig{Mode: "oidc", IssuerURL: "https://sso.example.com"}, wantValid: true},
+		{name: "oidc without issuer", config: AuthConfig{Mode: "oidc"}, wantValid: false},
+		{name: "tokenreview with url", config: AuthConfig{Mode: "tokenreview", TokenReviewURL: "https://kubernetes.default.svc"}, wantValid: true},
+		{name: "tokenreview without url", config: AuthConfig{Mode: "tokenreview"}, wantValid: false},
+		{name: "negative cache ttl", config: AuthConfig{Mode: "jwt", JWKSFile: "/etc/genai/jwks.json", CacheTTL: -time.Second}, wantValid: false},
+		{name: "unknown mode", config: AuthConfig{Mode: "ldap"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("AuthConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestAuthzConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AuthzConfig
+		wantValid bool
+	}{
+		{name: "default none", config: AuthzConfig{}, wantValid: true},
+		{name: "static with file", config: AuthzConfig{Policy: "static", PolicyFile: "/etc/genai/namespace-access.yaml"}, wantValid: true},
+		{name: "static without file", config: AuthzConfig{Policy: "static"}, wantValid: false},
+		{name: "sar with url", config: AuthzConfig{Policy: "subjectaccessreview", APIServerURL: "https://kubernetes.default.svc", Mode: "reject"}, wantValid: true},
+		{name: "sar without url", config: AuthzConfig{Policy: "subjectaccessreview"}, wantValid: false},
+		{name: "unknown policy", config: AuthzConfig{Policy: "opa"}, wantValid: false},
+		{name: "unknown mode", config: AuthzConfig{Mode: "audit"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("AuthzConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestCacheConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    CacheConfig
+		wantValid bool
+	}{
+		{name: "default none", config: CacheConfig{}, wantValid: true},
+		{name: "memory", config: CacheConfig{Backend: "memory", TTL: time.Minute, MaxEntries: 100}, wantValid: true},
+		{name: "memory without ttl", config: CacheConfig{Backend: "memory"}, wantValid: false},
+		{name: "memory with negative size", config: CacheConfig{Backend: "memory", TTL: time.Minute, MaxEntries: -1}, wantValid: false},
+		{name: "redis with address", config: CacheConfig{Backend: "redis", TTL: time.Minute, RedisAddr: "localhost:6379"}, wantValid: true},
+		{name: "redis without address", config: CacheConfig{Backend: "redis", TTL: time.Minute}, wantValid: false},
+		{name: "unknown backend", config: CacheConfig{Backend: "memcached", TTL: time.Minute}, wantValid: false},
+		{name: "semantic hashing", config: CacheConfig{TTL: time.Minute, Semantic: SemanticCacheConfig{Enabled: true, Threshold: 0.9}}, wantValid: true},
+		{name: "semantic without ttl", config: CacheConfig{Semantic: SemanticCacheConfig{Enabled: true, Threshold: 0.9}}, wantValid: false},
+		{name: "semantic threshold out of range", config: CacheConfig{TTL: time.Minute, Semantic: SemanticCacheConfig{Enabled: true, Threshold: 1.5}}, wantValid: false},
+		{name: "semantic provider", config: CacheConfig{TTL: time.Minute, Semantic: SemanticCacheConfig{Enabled: true, Threshold: 0.9, Embedder: "provider", EmbeddingEndpoint: "http://localhost:11434/api/embed", EmbeddingModel: "nomic-embed-text"}}, wantValid: true},
+		{name: "semantic provider without endpoint", config: CacheConfig{TTL: time.Minute, Semantic: SemanticCacheConfig{Enabled: true, Threshold: 0.9, Embedder: "provider"}}, wantValid: false},
+		{name: "semantic unknown embedder", config: CacheConfig{TTL: time.Minute, Semantic: SemanticCacheConfig{Enabled: true, Threshold: 0.9, Embedder: "word2vec"}}, wantValid: false},
+		{name: "semantic disabled ignores settings", config: CacheConfig{Semantic: SemanticCacheConfig{Embedder: "word2vec"}}, wantValid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("CacheConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestAuditTrailConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AuditTrailConfig
+		wantValid bool
+	}{
+		{name: "default none", config: AuditTrailConfig{}, wantValid: true},
+		{name: "file", config: AuditTrailConfig{Backend: "file", Path: "audit.jsonl", MaxSizeMB: 10}, wantValid: true},
+		{name: "file without path", config: AuditTrailConfig{Backend: "file"}, wantValid: false},
+		{name: "file with negative size", config: AuditTrailConfig{Backend: "file", Path: "audit.jsonl", MaxSizeMB: -1}, wantValid: false},
+		{name: "sql", config: AuditTrailConfig{Backend: "sql", SQLDriver: "postgres", SQLDSN: "postgres://localhost/audit"}, wantValid: true},
+		{name: "sql with the default driver", config: AuditTrailConfig{Backend: "sql", SQLDSN: "postgres://localhost/audit"}, wantValid: true},
+		{name: "sql without dsn", config: AuditTrailConfig{Backend: "sql", SQLDriver: "postgres"}, wantValid: false},
+		{name: "sql with a driver that is not linked", config: AuditTrailConfig{Backend: "sql", SQLDriver: "mysql", SQLDSN: "user@/audit"}, wantValid: false},
+		{name: "unknown backend", config: AuditTrailConfig{Backend: "syslog"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("AuditTrailConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestUsageConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    UsageConfig
+		wantValid bool
+	}{
+		{name: "unlimited", config: UsageConfig{}, wantValid: true},
+		{name: "cost budgets", config: UsageConfig{DailyBudget: 5, MonthlyBudget: 100}, wantValid: true},
+		{name: "token budgets", config: UsageConfig{DailyTokenBudget: 100000, MonthlyTokenBudget: 2000000}, wantValid: true},
+		{name: "negative daily budget", config: UsageConfig{DailyBudget: -1}, wantValid: false},
+		{name: "negative monthly token budget", config: UsageConfig{MonthlyTokenBudget: -1}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("UsageConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestReloadConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    ReloadConfig
+		wantValid bool
+	}{
+		{name: "watching disabled", config: ReloadConfig{}, wantValid: true},
+		{name: "watch interval", config: ReloadConfig{WatchInterval: 10 * time.Second, AdminGroups: []string{"platform-admins"}}, wantValid: true},
+		{name: "negative watch interval", config: ReloadConfig{WatchInterval: -time.Second}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("ReloadConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestModelsConfig_ValidatePricing(t *testing.T) {
+	models := GetDefaultConfig().Models
+	if result := models.Validate(); !result.Valid {
+		t.Fatalf("default pricing should be valid, got errors: %v", result.Errors)
+	}
+	models.Pricing.Models = map[string]ModelPrice{"gpt-4": {InputPerMillion: -30, OutputPerMillion: 60}}
+	if result := models.Validate(); result.Valid {
+		t.Error("expected a negative price to be rejected")
+	}
+}
+
+func TestModelsConfig_ValidateFallbackProviders(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallbacks []string
+		wantValid bool
+	}{
+		{name: "no failover", fallbacks: nil, wantValid: true},
+		{name: "configured fallback", fallbacks: []string{"openai", "local_llama"}, wantValid: true},
+		{name: "unknown fallback", fallbacks: []string{"gemini"}, wantValid: false},
+		{name: "default as fallback", fallbacks: []string{"claude"}, wantValid: false},
+		{name: "duplicate fallback", fallbacks: []string{"openai", "openai"}, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := GetDefaultConfig().Models
+			models.FallbackProviders = tt.fallbacks
+			result := models.Validate()
+			if result.Valid != tt.wantValid {
+				t.Errorf("ModelsConfig.Validate() = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
 func TestGetDefaultConfig(t *testing.T) {
 	config := GetDefaultConfig()
 
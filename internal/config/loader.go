@@ -74,6 +74,18 @@ func (l *Loader) loadModelsConfig(config *AppConfig) error {
 	if modelsConfig.Providers != nil {
 		config.Models.Providers = modelsConfig.Providers
 	}
+	if modelsConfig.FallbackProviders != nil {
+		config.Models.FallbackProviders = modelsConfig.FallbackProviders
+	}
+	if modelsConfig.HealthCheckInterval > 0 {
+		config.Models.HealthCheckInterval = modelsConfig.HealthCheckInterval
+	}
+	if modelsConfig.Pricing.Currency != "" {
+		config.Models.Pricing.Currency = modelsConfig.Pricing.Currency
+	}
+	if modelsConfig.Pricing.Models != nil {
+		config.Models.Pricing.Models = modelsConfig.Pricing.Models
+	}
 
 	return nil
 }
@@ -157,10 +169,233 @@ func (l *Loader) applyEnvironmentOverrides(config *AppConfig) {
 		}
 	}
 
+	// Sessions configuration overrides
+	if store := os.Getenv("SESSION_STORE"); store != "" {
+		config.Sessions.Store = store
+	}
+	if path := os.Getenv("SESSION_STORE_PATH"); path != "" {
+		config.Sessions.Path = path
+	}
+	if addr := os.Getenv("SESSION_REDIS_ADDR"); addr != "" {
+		config.Sessions.RedisAddr = addr
+	}
+	if password := os.Getenv("SESSION_REDIS_PASSWORD"); password != "" {
+		config.Sessions.RedisPassword = password
+	}
+	if db := os.Getenv("SESSION_REDIS_DB"); db != "" {
+		if n, err := parseInt(db); err == nil {
+			config.Sessions.RedisDB = n
+		}
+	}
+	if prefix := os.Getenv("SESSION_REDIS_PREFIX"); prefix != "" {
+		config.Sessions.RedisPrefix = prefix
+	}
+
+	// Auth configuration overrides
+	if mode := os.Getenv("AUTH_MODE"); mode != "" {
+		config.Auth.Mode = mode
+	}
+	if required := os.Getenv("AUTH_REQUIRED"); required != "" {
+		config.Auth.Required = required == "true"
+	}
+	if jwksFile := os.Getenv("AUTH_JWKS_FILE"); jwksFile != "" {
+		config.Auth.JWKSFile = jwksFile
+	}
+	if issuerURL := os.Getenv("AUTH_ISSUER_URL"); issuerURL != "" {
+		config.Auth.IssuerURL = issuerURL
+	}
+	if audience := os.Getenv("AUTH_AUDIENCE"); audience != "" {
+		config.Auth.Audience = audience
+	}
+	if claim := os.Getenv("AUTH_USERNAME_CLAIM"); claim != "" {
+		config.Auth.UsernameClaim = claim
+	}
+	if claim := os.Getenv("AUTH_GROUPS_CLAIM"); claim != "" {
+		config.Auth.GroupsClaim = claim
+	}
+	if url := os.Getenv("AUTH_TOKENREVIEW_URL"); url != "" {
+		config.Auth.TokenReviewURL = url
+	}
+	if tokenFile := os.Getenv("AUTH_TOKENREVIEW_TOKEN_FILE"); tokenFile != "" {
+		config.Auth.TokenReviewTokenFile = tokenFile
+	}
+	if caFile := os.Getenv("AUTH_TOKENREVIEW_CA_FILE"); caFile != "" {
+		config.Auth.TokenReviewCAFile = caFile
+	}
+	if cacheTTL := os.Getenv("AUTH_CACHE_TTL"); cacheTTL != "" {
+		if duration, err := parseDuration(cacheTTL); err == nil {
+			config.Auth.CacheTTL = duration
+		}
+	}
+
+	// Authorization configuration overrides
+	if policy := os.Getenv("AUTHZ_POLICY"); policy != "" {
+		config.Authz.Policy = policy
+	}
+	if policyFile := os.Getenv("AUTHZ_POLICY_FILE"); policyFile != "" {
+		config.Authz.PolicyFile = policyFile
+	}
+	if mode := os.Getenv("AUTHZ_MODE"); mode != "" {
+		config.Authz.Mode = mode
+	}
+	if url := os.Getenv("AUTHZ_APISERVER_URL"); url != "" {
+		config.Authz.APIServerURL = url
+	}
+	if tokenFile := os.Getenv("AUTHZ_TOKEN_FILE"); tokenFile != "" {
+		config.Authz.TokenFile = tokenFile
+	}
+	if caFile := os.Getenv("AUTHZ_CA_FILE"); caFile != "" {
+		config.Authz.CAFile = caFile
+	}
+	if cacheTTL := os.Getenv("AUTHZ_CACHE_TTL"); cacheTTL != "" {
+		if duration, err := parseDuration(cacheTTL); err == nil {
+			config.Authz.CacheTTL = duration
+		}
+	}
+
+	// Response cache configuration overrides
+	if backend := os.Getenv("CACHE_BACKEND"); backend != "" {
+		config.Cache.Backend = backend
+	}
+	if ttl := os.Getenv("CACHE_TTL"); ttl != "" {
+		if duration, err := parseDuration(ttl); err == nil {
+			config.Cache.TTL = duration
+		}
+	}
+	if maxEntries := os.Getenv("CACHE_MAX_ENTRIES"); maxEntries != "" {
+		if n, err := parseInt(maxEntries); err == nil {
+			config.Cache.MaxEntries = n
+		}
+	}
+	if addr := os.Getenv("CACHE_REDIS_ADDR"); addr != "" {
+		config.Cache.RedisAddr = addr
+	}
+	if password := os.Getenv("CACHE_REDIS_PASSWORD"); password != "" {
+		config.Cache.RedisPassword = password
+	}
+	if db := os.Getenv("CACHE_REDIS_DB"); db != "" {
+		if n, err := parseInt(db); err == nil {
+			config.Cache.RedisDB = n
+		}
+	}
+	if prefix := os.Getenv("CACHE_REDIS_PREFIX"); prefix != "" {
+		config.Cache.RedisPrefix = prefix
+	}
+	if enabled := os.Getenv("CACHE_SEMANTIC_ENABLED"); enabled != "" {
+		config.Cache.Semantic.Enabled = enabled == "true"
+	}
+	if threshold := os.Getenv("CACHE_SEMANTIC_THRESHOLD"); threshold != "" {
+		if f, err := parseFloat64(threshold); err == nil {
+			config.Cache.Semantic.Threshold = f
+		}
+	}
+	if maxEntries := os.Getenv("CACHE_SEMANTIC_MAX_ENTRIES"); maxEntries != "" {
+		if n, err := parseInt(maxEntries); err == nil {
+			config.Cache.Semantic.MaxEntries = n
+		}
+	}
+	if embedder := os.Getenv("CACHE_SEMANTIC_EMBEDDER"); embedder != "" {
+		config.Cache.Semantic.Embedder = embedder
+	}
+	if endpoint := os.Getenv("CACHE_EMBEDDING_ENDPOINT"); endpoint != "" {
+		config.Cache.Semantic.EmbeddingEndpoint = endpoint
+	}
+	if model := os.Getenv("CACHE_EMBEDDING_MODEL"); model != "" {
+		config.Cache.Semantic.EmbeddingModel = model
+	}
+	if apiKey := os.Getenv("CACHE_EMBEDDING_API_KEY"); apiKey != "" {
+		config.Cache.Semantic.EmbeddingAPIKey = apiKey
+	}
+
+	// Usage budget overrides
+	if budget := os.Getenv("USAGE_DAILY_BUDGET"); budget != "" {
+		if f, err := parseFloat64(budget); err == nil {
+			config.Usage.DailyBudget = f
+		}
+	}
+	if budget := os.Getenv("USAGE_MONTHLY_BUDGET"); budget != "" {
+		if f, err := parseFloat64(budget); err == nil {
+			config.Usage.MonthlyBudget = f
+		}
+	}
+	if budget := os.Getenv("USAGE_DAILY_TOKEN_BUDGET"); budget != "" {
+		if n, err := parseInt(budget); err == nil {
+			config.Usage.DailyTokenBudget = n
+		}
+	}
+	if budget := os.Getenv("USAGE_MONTHLY_TOKEN_BUDGET"); budget != "" {
+		if n, err := parseInt(budget); err == nil {
+			config.Usage.MonthlyTokenBudget = n
+		}
+	}
+
+	// Audit trail configuration overrides
+	if backend := os.Getenv("AUDIT_TRAIL_BACKEND"); backend != "" {
+		config.AuditTrail.Backend = backend
+	}
+	if path := os.Getenv("AUDIT_TRAIL_PATH"); path != "" {
+		config.AuditTrail.Path = path
+	}
+	if maxSize := os.Getenv("AUDIT_TRAIL_MAX_SIZE_MB"); maxSize != "" {
+		if n, err := parseInt(maxSize); err == nil {
+			config.AuditTrail.MaxSizeMB = n
+		}
+	}
+	if maxBackups := os.Getenv("AUDIT_TRAIL_MAX_BACKUPS"); maxBackups != "" {
+		if n, err := parseInt(maxBackups); err == nil {
+			config.AuditTrail.MaxBackups = n
+		}
+	}
+	if driver := os.Getenv("AUDIT_TRAIL_SQL_DRIVER"); driver != "" {
+		config.AuditTrail.SQLDriver = driver
+	}
+	if dsn := os.Getenv("AUDIT_TRAIL_SQL_DSN"); dsn != "" {
+		config.AuditTrail.SQLDSN = dsn
+	}
+	if table := os.Getenv("AUDIT_TRAIL_SQL_TABLE"); table != "" {
+		config.AuditTrail.SQLTable = table
+	}
+	if groups := os.Getenv("AUDIT_TRAIL_REVIEWER_GROUPS"); groups != "" {
+		config.AuditTrail.ReviewerGroups = nil
+		for _, group := range strings.Split(groups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				config.AuditTrail.ReviewerGroups = append(config.AuditTrail.ReviewerGroups, group)
+			}
+		}
+	}
+
+	// Configuration reload overrides
+	if interval := os.Getenv("CONFIG_WATCH_INTERVAL"); interval != "" {
+		if duration, err := parseDuration(interval); err == nil {
+			config.Reload.WatchInterval = duration
+		}
+	}
+	if groups := os.Getenv("CONFIG_ADMIN_GROUPS"); groups != "" {
+		config.Reload.AdminGroups = nil
+		for _, group := range strings.Split(groups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				config.Reload.AdminGroups = append(config.Reload.AdminGroups, group)
+			}
+		}
+	}
+
 	// Models configuration overrides
 	if defaultProvider := os.Getenv("DEFAULT_PROVIDER"); defaultProvider != "" {
 		config.Models.DefaultProvider = defaultProvider
 	}
+	if fallbacks := os.Getenv("FALLBACK_PROVIDERS"); fallbacks != "" {
+		config.Models.FallbackProviders = nil
+		for _, name := range strings.Split(fallbacks, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				config.Models.FallbackProviders = append(config.Models.FallbackProviders, name)
+			}
+		}
+	}
+	if interval := os.Getenv("HEALTH_CHECK_INTERVAL"); interval != "" {
+		if duration, err := parseDuration(interval); err == nil {
+			config.Models.HealthCheckInterval = duration
+		}
+	}
 
 	// Apply provider-specific overrides
 	for providerName, provider := range config.Models.Providers {
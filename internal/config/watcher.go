@@ -0,0 +1,126 @@
+package config
+
+import (
+	"crypto/sha256"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Watcher polls configuration files for changes and calls onChange when the
+// content of any of them changes, is created or is removed. A directory
+// changes when a file in it is added, removed or changed. Polling keeps the
+// watcher portable and catches files replaced by renames, as editors and
+// Kubernetes ConfigMap updates do.
+type Watcher struct {
+	paths    []string
+	interval time.Duration
+	onChange func()
+	logger   *log.Logger
+
+	// digests holds the last seen content digest of each path; missing
+	// files have an empty digest
+	digests map[string]string
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewWatcher creates a watcher of paths polled every interval. The current
+// content of the files is the baseline; only later changes call onChange.
+func NewWatcher(paths []string, interval time.Duration, onChange func()) *Watcher {
+	w := &Watcher{
+		paths:    paths,
+		interval: interval,
+		onChange: onChange,
+		logger:   log.New(log.Writer(), "[ConfigWatcher] ", log.LstdFlags),
+		digests:  make(map[string]string),
+		stopChan: make(chan struct{}),
+	}
+	for _, path := range paths {
+		w.digests[path] = fileDigest(path)
+	}
+	return w
+}
+
+// Start polls the files in the background until Stop is called. It must be
+// called at most once.
+func (w *Watcher) Start() {
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if w.Poll() {
+					w.onChange()
+				}
+			case <-w.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for a running onChange call to return. It is
+// safe to call more than once.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+		if w.done != nil {
+			<-w.done
+		}
+	})
+}
+
+// Poll checks the files once and reports whether any changed since the last
+// check. Start calls it on every tick.
+func (w *Watcher) Poll() bool {
+	changed := false
+	for _, path := range w.paths {
+		digest := fileDigest(path)
+		if digest != w.digests[path] {
+			w.logger.Printf("%s changed", path)
+			w.digests[path] = digest
+			changed = true
+		}
+	}
+	return changed
+}
+
+// fileDigest returns the SHA-256 digest of a file's content, or of the names
+// and content of the files in a directory, or an empty string when the path
+// cannot be read.
+func fileDigest(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+		sum := sha256.Sum256(data)
+		return string(sum[:])
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		h.Write([]byte(entry.Name()))
+		h.Write([]byte(fileDigest(filepath.Join(path, entry.Name()))))
+	}
+	return string(h.Sum(nil))
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	models := filepath.Join(dir, "models.yaml")
+	prompts := filepath.Join(dir, "prompts.yaml")
+	if err := os.WriteFile(models, []byte("default_provider: claude\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWatcher([]string{models, prompts}, time.Hour, func() {})
+	if w.Poll() {
+		t.Error("expected no change before the files are touched")
+	}
+
+	// Rewriting the same content is not a change
+	if err := os.WriteFile(models, []byte("default_provider: claude\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if w.Poll() {
+		t.Error("expected rewriting identical content not to be a change")
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+	}{
+		{"modified", func() error { return os.WriteFile(models, []byte("default_provider: openai\n"), 0o644) }},
+		{"created", func() error { return os.WriteFile(prompts, []byte("examples: []\n"), 0o644) }},
+		{"removed", func() error { return os.Remove(prompts) }},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatal(err)
+		}
+		if !w.Poll() {
+			t.Errorf("expected a %s file to be a change", step.name)
+		}
+		if w.Poll() {
+			t.Errorf("expected the %s file to be reported once", step.name)
+		}
+	}
+}
+
+func TestWatcher_PollDirectory(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "10-deny.yaml")
+	if err := os.WriteFile(first, []byte("policies: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWatcher([]string{dir}, time.Hour, func() {})
+	if w.Poll() {
+		t.Error("expected no change before the directory is touched")
+	}
+
+	second := filepath.Join(dir, "20-allow.yaml")
+	steps := []struct {
+		name   string
+		change func() error
+	}{
+		{"modified", func() error { return os.WriteFile(first, []byte("policies: [{name: x}]\n"), 0o644) }},
+		{"added", func() error { return os.WriteFile(second, []byte("policies: []\n"), 0o644) }},
+		{"renamed", func() error { return os.Rename(second, filepath.Join(dir, "30-allow.yaml")) }},
+		{"removed", func() error { return os.Remove(first) }},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatal(err)
+		}
+		if !w.Poll() {
+			t.Errorf("expected a %s policy file to be a change", step.name)
+		}
+		if w.Poll() {
+			t.Errorf("expected the %s policy file to be reported once", step.name)
+		}
+	}
+}
+
+func TestWatcher_StartCallsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	changes := make(chan struct{}, 1)
+	w := NewWatcher([]string{path}, 10*time.Millisecond, func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	})
+	w.Start()
+	defer w.Stop()
+
+	if err := os.WriteFile(path, []byte("safety_rules: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected onChange to be called after the file changed")
+	}
+
+	w.Stop()
+	w.Stop()
+}
@@ -0,0 +1,184 @@
+package context
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// sessionFileExt is the extension of session files written by FileStore.
+const sessionFileExt = ".json"
+
+// FileStore is an embedded store that keeps one JSON document per session in
+// a directory. Writes go to a temporary file that is renamed into place, so a
+// crash never leaves a partially written session behind.
+//
+// File names are the hex-encoded session ID, which keeps arbitrary client
+// supplied IDs from escaping the directory.
+type FileStore struct {
+	// dir is the directory holding session files
+	dir string
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("session store directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session store directory '%s': %w", dir, err)
+	}
+	return &FileStore{dir: dir, now: time.Now}, nil
+}
+
+// Get reads a session, removing it when it has expired.
+func (s *FileStore) Get(sessionID string) (*types.ConversationContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(sessionID)
+	context, err := readSessionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if s.now().After(context.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, ErrSessionNotFound
+	}
+	return context, nil
+}
+
+// Put atomically writes a session.
+func (s *FileStore) Put(context *types.ConversationContext) error {
+	if context == nil || context.SessionID == "" {
+		return fmt.Errorf("session ID cannot be empty")
+	}
+	data, err := json.Marshal(context)
+	if err != nil {
+		return fmt.Errorf("failed to encode session '%s': %w", context.SessionID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session '%s': %w", context.SessionID, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session '%s': %w", context.SessionID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session '%s': %w", context.SessionID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(context.SessionID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session '%s': %w", context.SessionID, err)
+	}
+	return nil
+}
+
+// Delete removes a session file.
+func (s *FileStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(sessionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session '%s': %w", sessionID, err)
+	}
+	return nil
+}
+
+// List reads all unexpired sessions. Unreadable files are skipped.
+func (s *FileStore) List() ([]*types.ConversationContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := s.sessionFiles()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	contexts := make([]*types.ConversationContext, 0, len(paths))
+	for _, path := range paths {
+		context, err := readSessionFile(path)
+		if err != nil || now.After(context.ExpiresAt) {
+			continue
+		}
+		contexts = append(contexts, context)
+	}
+	return contexts, nil
+}
+
+// DeleteExpired removes expired and unreadable session files.
+func (s *FileStore) DeleteExpired() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := s.sessionFiles()
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	removed := 0
+	for _, path := range paths {
+		context, err := readSessionFile(path)
+		if err == nil && !now.After(context.ExpiresAt) {
+			continue
+		}
+		if os.Remove(path) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Close is a no-op for the file store.
+func (s *FileStore) Close() error {
+	return nil
+}
+
+func (s *FileStore) path(sessionID string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(sessionID))+sessionFileExt)
+}
+
+func (s *FileStore) sessionFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session store directory '%s': %w", s.dir, err)
+	}
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), sessionFileExt) && !strings.HasPrefix(entry.Name(), ".") {
+			paths = append(paths, filepath.Join(s.dir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func readSessionFile(path string) (*types.ConversationContext, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to read session file '%s': %w", path, err)
+	}
+	var context types.ConversationContext
+	if err := json.Unmarshal(data, &context); err != nil {
+		return nil, fmt.Errorf("failed to decode session file '%s': %w", path, err)
+	}
+	return &context, nil
+}
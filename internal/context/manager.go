@@ -2,22 +2,26 @@ package context
 
 import (
 	"fmt"
+	"log"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
+
+	"github.com/google/uuid"
 )
 
 // ContextManager implements the ContextManager interface for managing conversation context and state.
-// This implementation provides in-memory storage for conversation sessions and comprehensive context management.
+// Conversation sessions are persisted in a pluggable Store; NewContextManager uses an in-memory store.
 type ContextManager struct {
-	// sessions stores conversation contexts indexed by session ID
-	sessions map[string]*types.ConversationContext
+	// store persists conversation contexts indexed by session ID
+	store Store
 
-	// mutex for thread-safe access to sessions map
+	// mutex serializing read-modify-write updates of sessions within this process
 	mu sync.RWMutex
 
 	// cleanupInterval is the interval for running session cleanup
@@ -28,15 +32,28 @@ type ContextManager struct {
 
 	// stopCleanup is a channel to stop the cleanup goroutine
 	stopCleanup chan bool
+
+	// logger reports store failures and cleanup activity
+	logger *log.Logger
 }
 
+// ContextManager also manages the session lifecycle.
+var _ interfaces.SessionManager = (*ContextManager)(nil)
+
 // NewContextManager creates a new instance of ContextManager with initialized in-memory storage.
 func NewContextManager() interfaces.ContextManager {
+	return NewContextManagerWithStore(NewMemoryStore())
+}
+
+// NewContextManagerWithStore creates a ContextManager persisting sessions in the given store.
+// Sessions written by other processes sharing the store are visible to this manager.
+func NewContextManagerWithStore(store Store) *ContextManager {
 	cm := &ContextManager{
-		sessions:        make(map[string]*types.ConversationContext),
+		store:           store,
 		cleanupInterval: 5 * time.Minute, // Run cleanup every 5 minutes
 		sessionTimeout:  24 * time.Hour,  // Default 24-hour session timeout
 		stopCleanup:     make(chan bool),
+		logger:          log.New(log.Writer(), "[ContextManager] ", log.LstdFlags),
 	}
 
 	// Start cleanup goroutine
@@ -61,11 +78,13 @@ func (cm *ContextManager) UpdateContext(sessionID string, query string, response
 	defer cm.mu.Unlock()
 
 	// Get or create session context
-	context, exists := cm.sessions[sessionID]
+	context, exists, err := cm.load(sessionID)
+	if err != nil {
+		return err
+	}
 	if !exists {
 		// Create new session context with unknown user
 		context = types.NewConversationContext(sessionID, "")
-		cm.sessions[sessionID] = context
 	} else {
 		// Update last activity and extend expiration for existing session
 		context.LastActivity = time.Now()
@@ -84,7 +103,7 @@ func (cm *ContextManager) UpdateContext(sessionID string, query string, response
 	// Enrich context with additional information
 	cm.enrichContext(context, query, response)
 
-	return nil
+	return cm.save(context)
 }
 
 // UpdateContextWithUser updates or creates a session context with the provided, validated user ID.
@@ -95,10 +114,12 @@ func (cm *ContextManager) UpdateContextWithUser(sessionID string, userID string,
 	sanitizedUserID := sanitizeUserID(userID)
 
 	// Get or create session context
-	context, exists := cm.sessions[sessionID]
+	context, exists, err := cm.load(sessionID)
+	if err != nil {
+		return err
+	}
 	if !exists {
 		context = types.NewConversationContext(sessionID, sanitizedUserID)
-		cm.sessions[sessionID] = context
 	} else {
 		// If user is not set, or different (and new is non-empty), update it
 		if sanitizedUserID != "" && context.UserID != sanitizedUserID {
@@ -120,7 +141,7 @@ func (cm *ContextManager) UpdateContextWithUser(sessionID string, userID string,
 	// Enrich context with additional information
 	cm.enrichContext(context, query, response)
 
-	return nil
+	return cm.save(context)
 }
 
 // sanitizeUserID performs basic validation and sanitization of a user ID.
@@ -161,9 +182,12 @@ func (cm *ContextManager) ResolvePronouns(query string, sessionID string) (strin
 	defer cm.mu.RUnlock()
 
 	// Get session context
-	context, exists := cm.sessions[sessionID]
+	context, exists, err := cm.load(sessionID)
+	if err != nil {
+		return query, err
+	}
 	if !exists {
-		return query, fmt.Errorf("session not found: %s", sessionID)
+		return query, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
 	}
 
 	// Resolve different types of pronouns and references
@@ -198,33 +222,183 @@ func (cm *ContextManager) GetContext(sessionID string) (*types.ConversationConte
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	context, exists := cm.sessions[sessionID]
+	context, exists, err := cm.load(sessionID)
+	if err != nil {
+		return nil, err
+	}
 	if !exists {
-		return nil, fmt.Errorf("session not found: %s", sessionID)
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
 	}
 
 	return context, nil
 }
 
+// CreateSession creates an empty session owned by userID and returns its ID.
+func (cm *ContextManager) CreateSession(userID string) (string, error) {
+	sanitizedUserID := sanitizeUserID(userID)
+	if strings.TrimSpace(userID) != "" && sanitizedUserID == "" {
+		return "", fmt.Errorf("invalid user ID")
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	context := types.NewConversationContext(uuid.NewString(), sanitizedUserID)
+	context.ExpiresAt = context.CreatedAt.Add(cm.sessionTimeout)
+	if err := cm.save(context); err != nil {
+		return "", err
+	}
+	return context.SessionID, nil
+}
+
+// GetSession returns the session, or an error wrapping ErrSessionNotFound.
+func (cm *ContextManager) GetSession(sessionID string) (*types.ConversationContext, error) {
+	return cm.GetContext(sessionID)
+}
+
+// UpdateSession records activity on a session and extends its lifetime.
+func (cm *ContextManager) UpdateSession(sessionID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	context, exists, err := cm.load(sessionID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	context.LastActivity = time.Now()
+	context.ExtendExpiration(cm.sessionTimeout)
+	return cm.save(context)
+}
+
+// DeleteSession removes a session and its conversation history.
+func (cm *ContextManager) DeleteSession(sessionID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, exists, err := cm.load(sessionID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	if err := cm.store.Delete(sessionID); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// ListActiveSessions returns the unexpired sessions owned by userID, most
+// recently active first.
+func (cm *ContextManager) ListActiveSessions(userID string) ([]string, error) {
+	contexts, err := cm.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(contexts))
+	for _, context := range contexts {
+		ids = append(ids, context.SessionID)
+	}
+	return ids, nil
+}
+
+// ListSessions returns the unexpired sessions owned by userID, most recently
+// active first.
+func (cm *ContextManager) ListSessions(userID string) ([]*types.ConversationContext, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	all, err := cm.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	contexts := make([]*types.ConversationContext, 0, len(all))
+	for _, context := range all {
+		if context.UserID == userID {
+			contexts = append(contexts, context)
+		}
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		if !contexts[i].LastActivity.Equal(contexts[j].LastActivity) {
+			return contexts[i].LastActivity.After(contexts[j].LastActivity)
+		}
+		return contexts[i].SessionID < contexts[j].SessionID
+	})
+	return contexts, nil
+}
+
+// CleanupExpiredSessions removes expired sessions and returns how many were removed.
+func (cm *ContextManager) CleanupExpiredSessions() (int, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.store.DeleteExpired()
+}
+
 // GetSessionCount returns the number of active sessions for monitoring purposes.
 // This is a helper method for testing and monitoring.
 func (cm *ContextManager) GetSessionCount() int {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
-	return len(cm.sessions)
+	contexts, err := cm.store.List()
+	if err != nil {
+		cm.logger.Printf("Failed to list sessions: %v", err)
+		return 0
+	}
+	return len(contexts)
 }
 
-// ClearAllSessions removes all sessions from memory.
+// ClearAllSessions removes all sessions from the store.
 // This is a helper method for testing and cleanup purposes.
 func (cm *ContextManager) ClearAllSessions() {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
-	cm.sessions = make(map[string]*types.ConversationContext)
+	contexts, err := cm.store.List()
+	if err != nil {
+		cm.logger.Printf("Failed to list sessions: %v", err)
+		return
+	}
+	for _, context := range contexts {
+		if err := cm.store.Delete(context.SessionID); err != nil {
+			cm.logger.Printf("Failed to delete session %s: %v", context.SessionID, err)
+		}
+	}
 }
 
-// Close stops the cleanup goroutine and performs final cleanup.
+// Close stops the cleanup goroutine and closes the store.
 func (cm *ContextManager) Close() error {
 	close(cm.stopCleanup)
+	return cm.store.Close()
+}
+
+// load returns the stored context for a session, reporting whether it exists.
+func (cm *ContextManager) load(sessionID string) (*types.ConversationContext, bool, error) {
+	context, err := cm.store.Get(sessionID)
+	if err == ErrSessionNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+	}
+	if context.ResolvedReferences == nil {
+		context.ResolvedReferences = make(map[string]types.ResolvedReference)
+	}
+	if context.ContextEnrichment == nil {
+		context.ContextEnrichment = make(map[string]interface{})
+	}
+	if context.SessionMetadata == nil {
+		context.SessionMetadata = make(map[string]interface{})
+	}
+	return context, true, nil
+}
+
+// save writes a context back to the store.
+func (cm *ContextManager) save(context *types.ConversationContext) error {
+	if err := cm.store.Put(context); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", context.SessionID, err)
+	}
 	return nil
 }
 
@@ -523,24 +697,14 @@ func (cm *ContextManager) runCleanup() {
 	}
 }
 
-// cleanupExpiredSessions removes expired sessions from memory.
+// cleanupExpiredSessions removes expired sessions from the store.
 func (cm *ContextManager) cleanupExpiredSessions() {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
-
-	expiredSessions := make([]string, 0)
-	for sessionID, context := range cm.sessions {
-		if context.IsExpired() {
-			expiredSessions = append(expiredSessions, sessionID)
-		}
-	}
-
-	for _, sessionID := range expiredSessions {
-		delete(cm.sessions, sessionID)
+	removed, err := cm.CleanupExpiredSessions()
+	if err != nil {
+		cm.logger.Printf("Failed to clean up expired sessions: %v", err)
+		return
 	}
-
-	if len(expiredSessions) > 0 {
-		// Log cleanup activity (in a real implementation, this would use a proper logger)
-		fmt.Printf("Cleaned up %d expired sessions\n", len(expiredSessions))
+	if removed > 0 {
+		cm.logger.Printf("Cleaned up %d expired sessions", removed)
 	}
 }
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -860,3 +861,71 @@ func TestResolvedReferences(t *testing.T) {
 		t.Errorf("Expected last_action 'delete', got %s", actionRef.Value)
 	}
 }
+
+func TestSessionManager_Lifecycle(t *testing.T) {
+	cm := NewContextManagerWithStore(NewMemoryStore())
+	defer cm.Close()
+
+	first, err := cm.CreateSession("alice")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	second, err := cm.CreateSession("alice")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if first == second {
+		t.Fatal("Expected unique session IDs")
+	}
+	if _, err := cm.CreateSession("bob"); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := cm.CreateSession("not a user"); err == nil {
+		t.Error("Expected error for invalid user ID")
+	}
+
+	session, err := cm.GetSession(first)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if session.UserID != "alice" || session.ExpiresAt.IsZero() {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	// Activity on the first session makes it the most recent
+	time.Sleep(2 * time.Millisecond)
+	if err := cm.UpdateContextWithUser(first, "alice", "Who deleted the CRD?", &types.StructuredQuery{LogSource: "kube-apiserver"}); err != nil {
+		t.Fatalf("UpdateContextWithUser failed: %v", err)
+	}
+	ids, err := cm.ListActiveSessions("alice")
+	if err != nil {
+		t.Fatalf("ListActiveSessions failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != first || ids[1] != second {
+		t.Errorf("Expected [%s %s], got %v", first, second, ids)
+	}
+
+	if err := cm.DeleteSession(first); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if _, err := cm.GetSession(first); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound after delete, got %v", err)
+	}
+	if err := cm.DeleteSession(first); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound deleting twice, got %v", err)
+	}
+	if err := cm.UpdateSession(second); err != nil {
+		t.Errorf("UpdateSession failed: %v", err)
+	}
+	if ids, _ := cm.ListActiveSessions("alice"); len(ids) != 1 {
+		t.Errorf("Expected 1 session for alice, got %v", ids)
+	}
+}
+
+func TestResolvePronouns_NewSessionIsNotFound(t *testing.T) {
+	cm := NewContextManager()
+	_, err := cm.ResolvePronouns("Who deleted it?", "brand-new")
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"genai-processing/internal/redisclient"
+	"genai-processing/pkg/types"
+)
+
+// DefaultRedisKeyPrefix namespaces session keys written by RedisStore.
+const DefaultRedisKeyPrefix = "genai:session:"
+
+// RedisStore keeps conversation contexts in any server speaking the Redis
+// protocol. Each session is stored as a JSON string whose TTL is derived from
+// ConversationContext.ExpiresAt, so the server expires sessions itself and
+// DeleteExpired has nothing to do.
+type RedisStore struct {
+	client    *redisclient.Client
+	keyPrefix string
+	now       func() time.Time
+}
+
+// NewRedisStore connects to a Redis-protocol server. An empty keyPrefix uses
+// DefaultRedisKeyPrefix.
+func NewRedisStore(config redisclient.Config, keyPrefix string) (*RedisStore, error) {
+	client, err := redisclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+	if keyPrefix == "" {
+		keyPrefix = DefaultRedisKeyPrefix
+	}
+	return &RedisStore{client: client, keyPrefix: keyPrefix, now: time.Now}, nil
+}
+
+// Get returns a session. Expired keys are removed by the server.
+func (s *RedisStore) Get(sessionID string) (*types.ConversationContext, error) {
+	reply, err := s.client.Do("GET", s.key(sessionID))
+	if err == redisclient.ErrNil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session '%s': %w", sessionID, err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected redis reply for session '%s'", sessionID)
+	}
+	var context types.ConversationContext
+	if err := json.Unmarshal([]byte(data), &context); err != nil {
+		return nil, fmt.Errorf("failed to decode session '%s': %w", sessionID, err)
+	}
+	if s.now().After(context.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return &context, nil
+}
+
+// Put writes a session with a TTL ending at its ExpiresAt. Already expired
+// sessions are deleted instead.
+func (s *RedisStore) Put(context *types.ConversationContext) error {
+	if context == nil || context.SessionID == "" {
+		return fmt.Errorf("session ID cannot be empty")
+	}
+	ttl := context.ExpiresAt.Sub(s.now()).Milliseconds()
+	if ttl <= 0 {
+		return s.Delete(context.SessionID)
+	}
+	data, err := json.Marshal(context)
+	if err != nil {
+		return fmt.Errorf("failed to encode session '%s': %w", context.SessionID, err)
+	}
+	if _, err := s.client.Do("SET", s.key(context.SessionID), string(data), "PX", strconv.FormatInt(ttl, 10)); err != nil {
+		return fmt.Errorf("failed to put session '%s': %w", context.SessionID, err)
+	}
+	return nil
+}
+
+// Delete removes a session.
+func (s *RedisStore) Delete(sessionID string) error {
+	if _, err := s.client.Do("DEL", s.key(sessionID)); err != nil {
+		return fmt.Errorf("failed to delete session '%s': %w", sessionID, err)
+	}
+	return nil
+}
+
+// List scans the key prefix and returns all unexpired sessions.
+func (s *RedisStore) List() ([]*types.ConversationContext, error) {
+	keys, err := s.client.Scan(s.keyPrefix + "*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	contexts := make([]*types.ConversationContext, 0, len(keys))
+	for _, key := range keys {
+		context, err := s.Get(strings.TrimPrefix(key, s.keyPrefix))
+		if err == ErrSessionNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		contexts = append(contexts, context)
+	}
+	return contexts, nil
+}
+
+// DeleteExpired is a no-op because the server expires keys by TTL.
+func (s *RedisStore) DeleteExpired() (int, error) {
+	return 0, nil
+}
+
+// Close closes the connection.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func (s *RedisStore) key(sessionID string) string {
+	return s.keyPrefix + sessionID
+}
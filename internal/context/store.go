@@ -0,0 +1,116 @@
+package context
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// ErrSessionNotFound is returned by a Store when a session does not exist or has expired.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Store persists conversation contexts for the ContextManager. Implementations
+// must treat a context whose ExpiresAt has passed as absent.
+//
+// Stores are not transactional: the ContextManager serializes updates within
+// a process, and concurrent writers in different processes follow
+// last-write-wins semantics for a session.
+type Store interface {
+	// Get returns the context for a session, or ErrSessionNotFound
+	Get(sessionID string) (*types.ConversationContext, error)
+
+	// Put creates or replaces the context for context.SessionID
+	Put(context *types.ConversationContext) error
+
+	// Delete removes a session; deleting a missing session is not an error
+	Delete(sessionID string) error
+
+	// List returns all unexpired contexts
+	List() ([]*types.ConversationContext, error)
+
+	// DeleteExpired removes expired contexts and returns how many were removed
+	DeleteExpired() (int, error)
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// MemoryStore keeps conversation contexts in process memory. It is the
+// default store and loses all sessions on restart.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*types.ConversationContext
+	now      func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*types.ConversationContext),
+		now:      time.Now,
+	}
+}
+
+// Get returns the stored context. The returned pointer is shared with the
+// store, matching the historical in-memory ContextManager behaviour.
+func (s *MemoryStore) Get(sessionID string) (*types.ConversationContext, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	context, ok := s.sessions[sessionID]
+	if !ok || s.now().After(context.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return context, nil
+}
+
+// Put stores the context.
+func (s *MemoryStore) Put(context *types.ConversationContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[context.SessionID] = context
+	return nil
+}
+
+// Delete removes the session.
+func (s *MemoryStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// List returns all unexpired contexts.
+func (s *MemoryStore) List() ([]*types.ConversationContext, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	contexts := make([]*types.ConversationContext, 0, len(s.sessions))
+	for _, context := range s.sessions {
+		if !now.After(context.ExpiresAt) {
+			contexts = append(contexts, context)
+		}
+	}
+	return contexts, nil
+}
+
+// DeleteExpired removes expired contexts.
+func (s *MemoryStore) DeleteExpired() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	removed := 0
+	for sessionID, context := range s.sessions {
+		if now.After(context.ExpiresAt) {
+			delete(s.sessions, sessionID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Close is a no-op for the memory store.
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,186 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"genai-processing/internal/redisclient"
+	"genai-processing/internal/redisclient/redistest"
+	"genai-processing/pkg/types"
+)
+
+// storeFactories builds each Store implementation with a shared clock.
+func storeFactories(t *testing.T, now func() time.Time) map[string]func() Store {
+	t.Helper()
+	srv, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start redis stand-in: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	srv.SetClock(now)
+	dir := t.TempDir()
+
+	return map[string]func() Store{
+		"memory": func() Store {
+			s := NewMemoryStore()
+			s.now = now
+			return s
+		},
+		"file": func() Store {
+			s, err := NewFileStore(dir)
+			if err != nil {
+				t.Fatalf("NewFileStore failed: %v", err)
+			}
+			s.now = now
+			return s
+		},
+		"redis": func() Store {
+			s, err := NewRedisStore(redisclient.Config{Addr: srv.Addr()}, "test:")
+			if err != nil {
+				t.Fatalf("NewRedisStore failed: %v", err)
+			}
+			s.now = now
+			return s
+		},
+	}
+}
+
+func TestStores_CRUDAndExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 29, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	for name, newStore := range storeFactories(t, clock) {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+			defer store.Close()
+
+			if _, err := store.Get("missing"); err != ErrSessionNotFound {
+				t.Fatalf("expected ErrSessionNotFound, got %v", err)
+			}
+
+			live := types.NewConversationContext("live/../session", "alice")
+			live.ExpiresAt = now.Add(time.Hour)
+			live.AddConversationEntry("who deleted the CRD", &types.StructuredQuery{LogSource: "kube-apiserver", User: *types.NewStringOrArray("john.doe")}, nil)
+			stale := types.NewConversationContext("stale", "bob")
+			stale.ExpiresAt = now.Add(time.Minute)
+
+			for _, c := range []*types.ConversationContext{live, stale} {
+				if err := store.Put(c); err != nil {
+					t.Fatalf("Put failed: %v", err)
+				}
+			}
+
+			got, err := store.Get(live.SessionID)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got.UserID != "alice" || len(got.ConversationHistory) != 1 || got.ConversationHistory[0].Response.User.GetString() != "john.doe" {
+				t.Errorf("session not round-tripped: %+v", got)
+			}
+
+			now = now.Add(30 * time.Minute)
+			defer func() { now = now.Add(-30 * time.Minute) }()
+
+			if _, err := store.Get("stale"); err != ErrSessionNotFound {
+				t.Errorf("expired session should not be returned, got %v", err)
+			}
+			if _, err := store.DeleteExpired(); err != nil {
+				t.Errorf("DeleteExpired failed: %v", err)
+			}
+			list, err := store.List()
+			if err != nil || len(list) != 1 || list[0].SessionID != live.SessionID {
+				t.Errorf("expected only the live session, got %v (%v)", list, err)
+			}
+
+			if err := store.Delete(live.SessionID); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := store.Get(live.SessionID); err != ErrSessionNotFound {
+				t.Errorf("deleted session still present: %v", err)
+			}
+			if err := store.Delete(live.SessionID); err != nil {
+				t.Errorf("deleting a missing session should succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileStore_KeepsFilesInsideDirectory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "sessions"))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	if err := store.Put(types.NewConversationContext("../../escape", "")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "sessions"))
+	if len(entries) != 1 {
+		t.Fatalf("expected one session file inside the store directory, got %d", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.json")); !os.IsNotExist(err) {
+		t.Errorf("session file escaped the store directory")
+	}
+}
+
+func TestContextManager_PersistsAcrossInstances(t *testing.T) {
+	srv, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start redis stand-in: %v", err)
+	}
+	defer srv.Close()
+	fileDir := t.TempDir()
+
+	backends := map[string]func() Store{
+		"file": func() Store {
+			s, err := NewFileStore(fileDir)
+			if err != nil {
+				t.Fatalf("NewFileStore failed: %v", err)
+			}
+			return s
+		},
+		"redis": func() Store {
+			s, err := NewRedisStore(redisclient.Config{Addr: srv.Addr()}, "")
+			if err != nil {
+				t.Fatalf("NewRedisStore failed: %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range backends {
+		t.Run(name, func(t *testing.T) {
+			first := NewContextManagerWithStore(newStore())
+			response := &types.StructuredQuery{
+				LogSource:           "kube-apiserver",
+				Verb:                *types.NewStringOrArray("delete"),
+				User:                *types.NewStringOrArray("john.doe"),
+				ResourceNamePattern: "customer",
+			}
+			if err := first.UpdateContextWithUser("sess-1", "alice", "who deleted the customer CRD", response); err != nil {
+				t.Fatalf("UpdateContextWithUser failed: %v", err)
+			}
+			first.Close()
+
+			// A restarted process or second replica sees the same conversation
+			second := NewContextManagerWithStore(newStore())
+			defer second.Close()
+			resolved, err := second.ResolvePronouns("when did he do it", "sess-1")
+			if err != nil {
+				t.Fatalf("ResolvePronouns failed: %v", err)
+			}
+			if resolved != "when did john.doe do it" {
+				t.Errorf("unexpected resolution: %q", resolved)
+			}
+			if err := second.UpdateContext("sess-1", resolved, response); err != nil {
+				t.Fatalf("UpdateContext failed: %v", err)
+			}
+			ctx, err := second.GetContext("sess-1")
+			if err != nil || ctx.UserID != "alice" || len(ctx.ConversationHistory) != 2 {
+				t.Errorf("expected 2 turns for alice, got %+v (%v)", ctx, err)
+			}
+		})
+	}
+}
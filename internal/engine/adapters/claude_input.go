@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"genai-processing/internal/schema"
 	"genai-processing/pkg/errors"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
@@ -34,6 +35,10 @@ type ClaudeInputAdapter struct {
 
 	// formatter formats prompts using configurable templates when provided
 	formatter interfaces.PromptFormatter
+
+	// jsonSchema is the StructuredQuery JSON Schema sent with each request;
+	// nil uses the schema of the StructuredQuery tags alone
+	jsonSchema map[string]interface{}
 }
 
 // ClaudeMessage represents a single message in Claude's message format
@@ -190,6 +195,8 @@ func (c *ClaudeInputAdapter) GetAPIParameters() map[string]interface{} {
 		"provider":    "anthropic",
 		"created_at":  time.Now().UTC(),
 		"system":      c.SystemPrompt,
+		// The provider constrains the response to this schema through tool calling
+		"json_schema": c.querySchema(),
 	}
 }
 
@@ -308,6 +315,20 @@ func (c *ClaudeInputAdapter) SetFormatter(formatter interfaces.PromptFormatter)
 	c.formatter = formatter
 }
 
+// SetJSONSchema sets the StructuredQuery JSON Schema sent with each request,
+// such as one generated from the loaded validation rules
+func (c *ClaudeInputAdapter) SetJSONSchema(jsonSchema map[string]interface{}) {
+	c.jsonSchema = jsonSchema
+}
+
+// querySchema returns the configured JSON Schema or the tag-derived default
+func (c *ClaudeInputAdapter) querySchema() map[string]interface{} {
+	if c.jsonSchema != nil {
+		return c.jsonSchema
+	}
+	return schema.StructuredQuery()
+}
+
 // getSystemPromptWithFallback returns configured system prompt or a minimal fallback
 func (c *ClaudeInputAdapter) getSystemPromptWithFallback() string {
 	if strings.TrimSpace(c.SystemPrompt) != "" {
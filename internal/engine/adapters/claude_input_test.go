@@ -165,6 +165,10 @@ func TestClaudeInputAdapter_GetAPIParameters(t *testing.T) {
 		t.Errorf("Expected provider anthropic, got %v", params["provider"])
 	}
 
+	if _, ok := params["json_schema"].(map[string]interface{}); !ok {
+		t.Error("Expected json_schema parameter for schema-constrained output")
+	}
+
 	// Check headers
 	headers, ok := params["headers"].(map[string]string)
 	if !ok {
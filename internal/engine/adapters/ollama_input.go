@@ -0,0 +1,278 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"genai-processing/internal/schema"
+	"genai-processing/pkg/errors"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// ollamaMaxTokens is the largest max_tokens accepted for self-hosted models,
+// whose context windows vary by model and server configuration.
+const ollamaMaxTokens = 32768
+
+// OllamaInputAdapter implements the InputAdapter interface for self-hosted
+// open-source models served by Ollama or vLLM. It sends the system prompt and
+// query as separate chat messages and attaches the StructuredQuery JSON Schema,
+// which the providers use to constrain decoding.
+type OllamaInputAdapter struct {
+	// ModelName is the model tag to use, e.g. llama3.1:8b
+	ModelName string
+
+	// MaxTokens is the maximum number of tokens to generate
+	MaxTokens int
+
+	// Temperature controls the randomness of responses (0.0 to 2.0)
+	Temperature float64
+
+	// SystemPrompt is the system prompt for OpenShift audit queries
+	SystemPrompt string
+
+	// examples are few-shot examples to include in prompt formatting
+	examples []types.Example
+
+	// formatter formats prompts using configurable templates when provided
+	formatter interfaces.PromptFormatter
+
+	// jsonSchema is the StructuredQuery JSON Schema sent with each request;
+	// nil uses the schema of the StructuredQuery tags alone
+	jsonSchema map[string]interface{}
+}
+
+// NewOllamaInputAdapter creates a new OllamaInputAdapter with default configuration
+func NewOllamaInputAdapter() *OllamaInputAdapter {
+	return &OllamaInputAdapter{
+		ModelName:   "llama3.1:8b",
+		MaxTokens:   4000,
+		Temperature: 0.1,
+	}
+}
+
+// AdaptRequest converts an internal request to system and user chat messages
+// with the JSON Schema the response must follow.
+func (o *OllamaInputAdapter) AdaptRequest(req *types.InternalRequest) (*types.ModelRequest, error) {
+	if req == nil {
+		return nil, errors.NewInputAdapterError(
+			"internal request cannot be nil",
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+
+	formattedPrompt, err := o.FormatPrompt(req.ProcessingRequest.Query, o.examples)
+	if err != nil {
+		return nil, errors.NewInputAdapterError(
+			fmt.Sprintf("failed to format prompt: %v", err),
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			true,
+		).WithDetails("query", req.ProcessingRequest.Query)
+	}
+
+	messages := []interface{}{
+		map[string]interface{}{
+			"role":    "system",
+			"content": o.getSystemPromptWithFallback(),
+		},
+		map[string]interface{}{
+			"role":    "user",
+			"content": formattedPrompt,
+		},
+	}
+
+	return &types.ModelRequest{
+		Model:      o.ModelName,
+		Messages:   messages,
+		Parameters: o.GetAPIParameters(),
+	}, nil
+}
+
+// FormatPrompt formats the user message with few-shot examples. The system
+// prompt travels in its own message, so it is left out of the user message.
+func (o *OllamaInputAdapter) FormatPrompt(prompt string, examples []types.Example) (string, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return "", errors.NewInputAdapterError(
+			"prompt cannot be empty",
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+
+	// Prefer formatter when available
+	if o.formatter != nil {
+		formatted, err := o.formatter.FormatComplete("", examples, prompt)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(formatted), nil
+	}
+
+	var builder strings.Builder
+	if len(examples) > 0 {
+		builder.WriteString("Examples:\n\n")
+		for i, ex := range examples {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(fmt.Sprintf("Input: %s\n", ex.Input))
+			builder.WriteString(fmt.Sprintf("Output: %s\n", ex.Output))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("Convert this query to JSON: ")
+	builder.WriteString(prompt)
+
+	return builder.String(), nil
+}
+
+// GetAPIParameters returns the request parameters, including the JSON Schema
+// under "json_schema" for providers that support constrained decoding.
+func (o *OllamaInputAdapter) GetAPIParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"model_name":  o.ModelName,
+		"max_tokens":  o.MaxTokens,
+		"temperature": o.Temperature,
+		"provider":    "ollama",
+		"created_at":  time.Now().UTC(),
+		"system":      o.SystemPrompt,
+		"json_schema": o.querySchema(),
+	}
+}
+
+// ValidateRequest validates the request parameters
+func (o *OllamaInputAdapter) ValidateRequest(req *types.ModelRequest) error {
+	if req == nil {
+		return errors.NewInputAdapterError(
+			"model request cannot be nil",
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+
+	if req.Model == "" {
+		return errors.NewInputAdapterError(
+			"model name is required",
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+
+	if len(req.Messages) == 0 {
+		return errors.NewInputAdapterError(
+			"at least one message is required",
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+
+	if maxTokens, ok := req.Parameters["max_tokens"].(int); ok {
+		if maxTokens <= 0 || maxTokens > ollamaMaxTokens {
+			return errors.NewInputAdapterError(
+				fmt.Sprintf("max_tokens must be between 1 and %d, got %d", ollamaMaxTokens, maxTokens),
+				errors.ComponentInputAdapter,
+				"ollama",
+				"ollama_input_adapter",
+				false,
+			)
+		}
+	}
+
+	if temp, ok := req.Parameters["temperature"].(float64); ok {
+		if temp < 0.0 || temp > 2.0 {
+			return errors.NewInputAdapterError(
+				fmt.Sprintf("temperature must be between 0.0 and 2.0, got %f", temp),
+				errors.ComponentInputAdapter,
+				"ollama",
+				"ollama_input_adapter",
+				false,
+			)
+		}
+	}
+
+	return nil
+}
+
+// SetModelName sets the model tag to use
+func (o *OllamaInputAdapter) SetModelName(modelName string) { o.ModelName = modelName }
+
+// SetMaxTokens sets the maximum number of tokens to generate
+func (o *OllamaInputAdapter) SetMaxTokens(maxTokens int) error {
+	if maxTokens <= 0 || maxTokens > ollamaMaxTokens {
+		return errors.NewInputAdapterError(
+			fmt.Sprintf("max_tokens must be between 1 and %d, got %d", ollamaMaxTokens, maxTokens),
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+	o.MaxTokens = maxTokens
+	return nil
+}
+
+// SetTemperature sets the temperature parameter
+func (o *OllamaInputAdapter) SetTemperature(temperature float64) error {
+	if temperature < 0.0 || temperature > 2.0 {
+		return errors.NewInputAdapterError(
+			fmt.Sprintf("temperature must be between 0.0 and 2.0, got %f", temperature),
+			errors.ComponentInputAdapter,
+			"ollama",
+			"ollama_input_adapter",
+			false,
+		)
+	}
+	o.Temperature = temperature
+	return nil
+}
+
+// SetSystemPrompt sets a custom system prompt
+func (o *OllamaInputAdapter) SetSystemPrompt(prompt string) { o.SystemPrompt = prompt }
+
+// SetExamples sets few-shot examples to include in formatting
+func (o *OllamaInputAdapter) SetExamples(examples []types.Example) { o.examples = examples }
+
+// SetFormatter sets a custom prompt formatter
+func (o *OllamaInputAdapter) SetFormatter(formatter interfaces.PromptFormatter) {
+	o.formatter = formatter
+}
+
+// SetJSONSchema sets the StructuredQuery JSON Schema sent with each request,
+// such as one generated from the loaded validation rules
+func (o *OllamaInputAdapter) SetJSONSchema(jsonSchema map[string]interface{}) {
+	o.jsonSchema = jsonSchema
+}
+
+// querySchema returns the configured JSON Schema or the tag-derived default
+func (o *OllamaInputAdapter) querySchema() map[string]interface{} {
+	if o.jsonSchema != nil {
+		return o.jsonSchema
+	}
+	return schema.StructuredQuery()
+}
+
+// getSystemPromptWithFallback returns configured system prompt or a minimal fallback
+func (o *OllamaInputAdapter) getSystemPromptWithFallback() string {
+	if strings.TrimSpace(o.SystemPrompt) != "" {
+		return o.SystemPrompt
+	}
+	return "You are an OpenShift audit query specialist. Convert natural language queries into structured JSON parameters for audit log analysis.\n\nRespond with valid JSON only. No markdown, no explanations."
+}
+
+// Ensure OllamaInputAdapter implements the InputAdapter interface
+var _ interfaces.InputAdapter = (*OllamaInputAdapter)(nil)
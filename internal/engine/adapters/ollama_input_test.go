@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestNewOllamaInputAdapter(t *testing.T) {
+	adapter := NewOllamaInputAdapter()
+
+	if adapter.ModelName != "llama3.1:8b" {
+		t.Errorf("Expected model name llama3.1:8b, got %s", adapter.ModelName)
+	}
+	if adapter.MaxTokens != 4000 {
+		t.Errorf("Expected max tokens 4000, got %d", adapter.MaxTokens)
+	}
+	if adapter.getSystemPromptWithFallback() == "" {
+		t.Error("Expected non-empty fallback system prompt")
+	}
+}
+
+func TestOllamaInputAdapter_AdaptRequest(t *testing.T) {
+	adapter := NewOllamaInputAdapter()
+	adapter.SetSystemPrompt("You convert audit queries to JSON.")
+	adapter.SetExamples([]types.Example{{Input: "who deleted pods", Output: `{"log_source":"kube-apiserver"}`}})
+
+	req := &types.InternalRequest{
+		RequestID: "test-request-id",
+		ProcessingRequest: types.ProcessingRequest{
+			Query:     "Who deleted the customer CRD yesterday?",
+			SessionID: "test-session",
+		},
+	}
+
+	modelReq, err := adapter.AdaptRequest(req)
+	if err != nil {
+		t.Fatalf("AdaptRequest returned error: %v", err)
+	}
+
+	if len(modelReq.Messages) != 2 {
+		t.Fatalf("Expected system and user messages, got %d", len(modelReq.Messages))
+	}
+	system := modelReq.Messages[0].(map[string]interface{})
+	if system["role"] != "system" || system["content"] != "You convert audit queries to JSON." {
+		t.Errorf("Unexpected system message: %v", system)
+	}
+	user := modelReq.Messages[1].(map[string]interface{})
+	content := user["content"].(string)
+	if !strings.Contains(content, "who deleted pods") || !strings.Contains(content, "customer CRD") {
+		t.Errorf("Expected examples and query in user message, got %q", content)
+	}
+
+	jsonSchema, ok := modelReq.Parameters["json_schema"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected json_schema parameter")
+	}
+	if _, ok := jsonSchema["properties"].(map[string]interface{})["log_source"]; !ok {
+		t.Error("Expected schema to describe log_source")
+	}
+
+	if err := adapter.ValidateRequest(modelReq); err != nil {
+		t.Errorf("ValidateRequest returned error: %v", err)
+	}
+}
+
+func TestOllamaInputAdapter_Errors(t *testing.T) {
+	adapter := NewOllamaInputAdapter()
+
+	if _, err := adapter.AdaptRequest(nil); err == nil {
+		t.Error("Expected error for nil request")
+	}
+	if _, err := adapter.FormatPrompt("  ", nil); err == nil {
+		t.Error("Expected error for empty prompt")
+	}
+	if err := adapter.SetMaxTokens(ollamaMaxTokens + 1); err == nil {
+		t.Error("Expected error for max_tokens above limit")
+	}
+	if err := adapter.SetTemperature(2.5); err == nil {
+		t.Error("Expected error for temperature above limit")
+	}
+	if err := adapter.ValidateRequest(&types.ModelRequest{Model: "llama3.1:8b"}); err == nil {
+		t.Error("Expected error for request without messages")
+	}
+}
+
+func TestOllamaInputAdapter_SetJSONSchema(t *testing.T) {
+	adapter := NewOllamaInputAdapter()
+	custom := map[string]interface{}{"type": "object", "title": "custom"}
+	adapter.SetJSONSchema(custom)
+
+	jsonSchema := adapter.GetAPIParameters()["json_schema"].(map[string]interface{})
+	if jsonSchema["title"] != "custom" {
+		t.Errorf("Expected the configured schema, got %v", jsonSchema)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"genai-processing/internal/schema"
 	"genai-processing/pkg/errors"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
@@ -34,6 +35,10 @@ type OpenAIInputAdapter struct {
 
 	// formatter formats prompts using configurable templates when provided
 	formatter interfaces.PromptFormatter
+
+	// jsonSchema is the StructuredQuery JSON Schema sent with each request;
+	// nil uses the schema of the StructuredQuery tags alone
+	jsonSchema map[string]interface{}
 }
 
 // OpenAIMessage represents a single message in OpenAI's chat format
@@ -181,6 +186,8 @@ func (o *OpenAIInputAdapter) GetAPIParameters() map[string]interface{} {
 		"provider":    "openai",
 		"created_at":  time.Now().UTC(),
 		"system":      o.SystemPrompt,
+		// The provider constrains the response to this schema through tool calling
+		"json_schema": o.querySchema(),
 	}
 }
 
@@ -299,6 +306,20 @@ func (o *OpenAIInputAdapter) SetFormatter(formatter interfaces.PromptFormatter)
 	o.formatter = formatter
 }
 
+// SetJSONSchema sets the StructuredQuery JSON Schema sent with each request,
+// such as one generated from the loaded validation rules
+func (o *OpenAIInputAdapter) SetJSONSchema(jsonSchema map[string]interface{}) {
+	o.jsonSchema = jsonSchema
+}
+
+// querySchema returns the configured JSON Schema or the tag-derived default
+func (o *OpenAIInputAdapter) querySchema() map[string]interface{} {
+	if o.jsonSchema != nil {
+		return o.jsonSchema
+	}
+	return schema.StructuredQuery()
+}
+
 // getSystemPromptWithFallback returns configured system prompt or a minimal fallback
 func (o *OpenAIInputAdapter) getSystemPromptWithFallback() string {
 	if strings.TrimSpace(o.SystemPrompt) != "" {
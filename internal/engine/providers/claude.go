@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"genai-processing/internal/schema"
 	"genai-processing/pkg/types"
 )
 
@@ -27,31 +29,54 @@ type ClaudeMessage struct {
 
 // ClaudeRequest represents the request payload for Claude API
 type ClaudeRequest struct {
-	Model       string          `json:"model"`
-	Messages    []ClaudeMessage `json:"messages"`
-	MaxTokens   int             `json:"max_tokens"`
-	Temperature float64         `json:"temperature,omitempty"`
-	System      string          `json:"system,omitempty"`
+	Model       string            `json:"model"`
+	Messages    []ClaudeMessage   `json:"messages"`
+	MaxTokens   int               `json:"max_tokens"`
+	Temperature float64           `json:"temperature,omitempty"`
+	System      string            `json:"system,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+	Tools       []ClaudeTool      `json:"tools,omitempty"`
+	ToolChoice  *ClaudeToolChoice `json:"tool_choice,omitempty"`
+}
+
+// ClaudeTool declares a tool the model may use
+type ClaudeTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// ClaudeToolChoice forces the model to use the named tool
+type ClaudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 // ClaudeResponse represents the response from Claude API
 type ClaudeResponse struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Role    string `json:"role"`
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Model        string `json:"model"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence"`
+	ID           string               `json:"id"`
+	Type         string               `json:"type"`
+	Role         string               `json:"role"`
+	Content      []ClaudeContentBlock `json:"content"`
+	Model        string               `json:"model"`
+	StopReason   string               `json:"stop_reason"`
+	StopSequence string               `json:"stop_sequence"`
 	Usage        struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
 }
 
+// ClaudeContentBlock is a block of a Claude response: text, or a tool_use
+// block carrying the tool's input
+type ClaudeContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
 // ClaudeError represents an error response from Claude API
 type ClaudeError struct {
 	Type    string `json:"type"`
@@ -75,6 +100,206 @@ func NewClaudeProvider(apiKey, endpoint string) *ClaudeProvider {
 
 // GenerateResponse implements the LLMProvider interface
 func (c *ClaudeProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	req, err := c.newHTTPRequest(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the request
+	startTime := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("anthropic", c.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	processingTime := time.Since(startTime)
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Handle error responses
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.apiError(resp.StatusCode, body)
+	}
+
+	// Parse successful response
+	var claudeResp ClaudeResponse
+	if err := json.Unmarshal(body, &claudeResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Claude response: %w", err)
+	}
+
+	// Extract text and tool use from the response content blocks
+	var content strings.Builder
+	var toolCalls []types.ToolCall
+	for _, block := range claudeResp.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, types.ToolCall{ID: block.ID, Name: block.Name, Arguments: string(block.Input)})
+		}
+	}
+
+	// TODO: Implement cost calculation based on Claude pricing
+	// estimatedCost := calculateClaudeCost(totalTokens, claudeResp.Model)
+
+	return claudeRawResponse(&chatCompletion{
+		ID:               claudeResp.ID,
+		Object:           claudeResp.Type,
+		Model:            claudeResp.Model,
+		Content:          content.String(),
+		ToolCalls:        toolCalls,
+		FinishReason:     claudeResp.StopReason,
+		PromptTokens:     claudeResp.Usage.InputTokens,
+		CompletionTokens: claudeResp.Usage.OutputTokens,
+	}, processingTime, false), nil
+}
+
+// GenerateResponseStream implements the StreamingProvider interface. It
+// requests a streamed message and calls onChunk with each text delta as it
+// arrives, returning the assembled response once the message is complete.
+func (c *ClaudeProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	req, err := c.newHTTPRequest(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("anthropic", c.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, c.apiError(resp.StatusCode, body)
+	}
+
+	completion, err := c.readStream(resp.Body, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return claudeRawResponse(completion, time.Since(startTime), true), nil
+}
+
+// claudeStreamEvent is one event of a streamed Claude message. Each event
+// type fills in a different subset of the fields.
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string `json:"id"`
+		Type  string `json:"type"`
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Index        int `json:"index"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error ClaudeError `json:"error"`
+}
+
+// readStream assembles a streamed Claude message, calling onChunk with each
+// text or tool input delta. An error event ends the stream with a ProviderError whose
+// status matches the equivalent non-streamed response.
+func (c *ClaudeProvider) readStream(body io.Reader, onChunk func(string)) (*chatCompletion, error) {
+	var completion chatCompletion
+	var content strings.Builder
+	var toolCalls toolCallBuilder
+	var streamErr error
+
+	err := readSSE(body, func(event, data string) error {
+		var ev claudeStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		switch ev.Type {
+		case "message_start":
+			completion.ID = ev.Message.ID
+			completion.Object = ev.Message.Type
+			completion.Model = ev.Message.Model
+			completion.PromptTokens = ev.Message.Usage.InputTokens
+		case "content_block_start":
+			if ev.ContentBlock.Type == "tool_use" {
+				toolCalls.add(ev.Index, ev.ContentBlock.ID, ev.ContentBlock.Name, "")
+			}
+		case "content_block_delta":
+			switch {
+			case ev.Delta.Type == "text_delta" && ev.Delta.Text != "":
+				content.WriteString(ev.Delta.Text)
+				if onChunk != nil {
+					onChunk(ev.Delta.Text)
+				}
+			case ev.Delta.Type == "input_json_delta" && ev.Delta.PartialJSON != "":
+				toolCalls.add(ev.Index, "", "", ev.Delta.PartialJSON)
+				if onChunk != nil {
+					onChunk(ev.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				completion.FinishReason = ev.Delta.StopReason
+			}
+			completion.CompletionTokens = ev.Usage.OutputTokens
+		case "message_stop":
+			return errStopStream
+		case "error":
+			streamErr = newAPIError("anthropic", c.Endpoint, claudeStreamErrorStatus(ev.Error.Type),
+				fmt.Sprintf("claude API error: %s - %s", ev.Error.Type, ev.Error.Message))
+			return errStopStream
+		}
+		return nil
+	})
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	if err != nil {
+		return nil, newTransportError("anthropic", c.Endpoint, err)
+	}
+
+	completion.Content = content.String()
+	completion.ToolCalls = toolCalls.toolCalls()
+	return &completion, nil
+}
+
+// claudeStreamErrorStatus maps the type of an error event received mid-stream
+// to the HTTP status the same error carries outside a stream.
+func claudeStreamErrorStatus(errorType string) int {
+	switch errorType {
+	case "overloaded_error":
+		return 529
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "api_error":
+		return http.StatusInternalServerError
+	default:
+		return 0
+	}
+}
+
+// newHTTPRequest converts a ModelRequest into a Claude API request.
+func (c *ClaudeProvider) newHTTPRequest(ctx context.Context, request *types.ModelRequest, stream bool) (*http.Request, error) {
 	// TODO: Enhance authentication with proper API key validation and rotation
 	if c.APIKey == "" {
 		return nil, fmt.Errorf("claude API key is required")
@@ -85,6 +310,7 @@ func (c *ClaudeProvider) GenerateResponse(ctx context.Context, request *types.Mo
 		Model:       request.Model,
 		MaxTokens:   4000, // Default max tokens
 		Temperature: 0.1,  // Default temperature
+		Stream:      stream,
 	}
 
 	// Extract parameters
@@ -100,6 +326,19 @@ func (c *ClaudeProvider) GenerateResponse(ctx context.Context, request *types.Mo
 		}
 	}
 
+	// Constrain the output to the query schema by forcing use of a tool
+	// whose input schema it is. Claude has no JSON mode, so every mode other
+	// than "none" uses the tool.
+	if jsonSchema, ok := jsonSchemaParameter(request.Parameters); ok &&
+		structuredOutputMode(request.Parameters, StructuredOutputTool) != StructuredOutputNone {
+		claudeReq.Tools = []ClaudeTool{{
+			Name:        schema.ToolName,
+			Description: schema.ToolDescription,
+			InputSchema: jsonSchema,
+		}}
+		claudeReq.ToolChoice = &ClaudeToolChoice{Type: "tool", Name: schema.ToolName}
+	}
+
 	// Convert messages to Claude format
 	for _, msg := range request.Messages {
 		if msgMap, ok := msg.(map[string]interface{}); ok {
@@ -127,76 +366,59 @@ func (c *ClaudeProvider) GenerateResponse(ctx context.Context, request *types.Mo
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", c.APIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
-
-	// Make the request
-	startTime := time.Now()
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
 	}
-	defer resp.Body.Close()
 
-	processingTime := time.Since(startTime)
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Handle error responses
-	if resp.StatusCode != http.StatusOK {
-		var claudeErr ClaudeError
-		if err := json.Unmarshal(body, &claudeErr); err != nil {
-			return nil, fmt.Errorf("HTTP %d: failed to parse error response: %s", resp.StatusCode, string(body))
-		}
-		return nil, fmt.Errorf("claude API error: %s - %s", claudeErr.Type, claudeErr.Message)
-	}
-
-	// Parse successful response
-	var claudeResp ClaudeResponse
-	if err := json.Unmarshal(body, &claudeResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Claude response: %w", err)
-	}
+	return req, nil
+}
 
-	// Extract content from response
-	var content string
-	if len(claudeResp.Content) > 0 {
-		content = claudeResp.Content[0].Text
+// apiError converts an unsuccessful Claude API response into an error.
+func (c *ClaudeProvider) apiError(statusCode int, body []byte) error {
+	var claudeErr ClaudeError
+	if err := json.Unmarshal(body, &claudeErr); err != nil {
+		return newAPIError("anthropic", c.Endpoint, statusCode, fmt.Sprintf("failed to parse error response: %s", string(body)))
 	}
+	return newAPIError("anthropic", c.Endpoint, statusCode, fmt.Sprintf("claude API error: %s - %s", claudeErr.Type, claudeErr.Message))
+}
 
+// claudeRawResponse builds the RawResponse for a completed Claude message.
+func claudeRawResponse(completion *chatCompletion, processingTime time.Duration, streamed bool) *types.RawResponse {
 	// Calculate token usage
-	totalTokens := claudeResp.Usage.InputTokens + claudeResp.Usage.OutputTokens
+	totalTokens := completion.PromptTokens + completion.CompletionTokens
 	tokensPerSecond := 0.0
 	if processingTime > 0 {
 		tokensPerSecond = float64(totalTokens) / processingTime.Seconds()
 	}
 
-	// TODO: Implement cost calculation based on Claude pricing
-	// estimatedCost := calculateClaudeCost(totalTokens, claudeResp.Model)
+	metadata := map[string]interface{}{
+		"provider":        "anthropic",
+		"api_version":     "2023-06-01",
+		"processing_time": processingTime.String(),
+		"token_usage": map[string]interface{}{
+			"prompt_tokens":     completion.PromptTokens,
+			"completion_tokens": completion.CompletionTokens,
+			"total_tokens":      totalTokens,
+			"tokens_per_second": tokensPerSecond,
+			"model_name":        completion.Model,
+			"timestamp":         time.Now(),
+		},
+	}
+	if streamed {
+		metadata["streamed"] = true
+	}
 
 	return &types.RawResponse{
-		Content: content,
+		Content:   completion.outputContent(),
+		ToolCalls: completion.ToolCalls,
 		ModelInfo: map[string]interface{}{
-			"model":       claudeResp.Model,
-			"id":          claudeResp.ID,
-			"stop_reason": claudeResp.StopReason,
-			"type":        claudeResp.Type,
+			"model":       completion.Model,
+			"id":          completion.ID,
+			"stop_reason": completion.FinishReason,
+			"type":        completion.Object,
 		},
-		Metadata: map[string]interface{}{
-			"provider":        "anthropic",
-			"api_version":     "2023-06-01",
-			"processing_time": processingTime.String(),
-			"token_usage": map[string]interface{}{
-				"prompt_tokens":     claudeResp.Usage.InputTokens,
-				"completion_tokens": claudeResp.Usage.OutputTokens,
-				"total_tokens":      totalTokens,
-				"tokens_per_second": tokensPerSecond,
-				"model_name":        claudeResp.Model,
-				"timestamp":         time.Now(),
-			},
-		},
-	}, nil
+		Metadata: metadata,
+	}
 }
 
 // GetModelInfo implements the LLMProvider interface
@@ -225,8 +447,7 @@ func (c *ClaudeProvider) GetModelInfo() types.ModelInfo {
 
 // SupportsStreaming implements the LLMProvider interface
 func (c *ClaudeProvider) SupportsStreaming() bool {
-	// TODO: Implement streaming support for Claude API
-	return false
+	return true
 }
 
 // ValidateConnection checks if the Claude API connection is working
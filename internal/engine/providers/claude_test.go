@@ -3,11 +3,13 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	pkgerrors "genai-processing/pkg/errors"
 	"genai-processing/pkg/types"
 )
 
@@ -89,10 +91,7 @@ func TestClaudeProvider_GenerateResponse(t *testing.T) {
 				ID:   "msg_123",
 				Type: "message",
 				Role: "assistant",
-				Content: []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				}{
+				Content: []ClaudeContentBlock{
 					{
 						Type: "text",
 						Text: "Hello! How can I help you today?",
@@ -167,13 +166,10 @@ func TestClaudeProvider_GenerateResponse(t *testing.T) {
 				},
 			},
 			mockResponse: ClaudeResponse{
-				ID:   "msg_123",
-				Type: "message",
-				Role: "assistant",
-				Content: []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				}{},
+				ID:         "msg_123",
+				Type:       "message",
+				Role:       "assistant",
+				Content:    []ClaudeContentBlock{},
 				Model:      "claude-3-5-sonnet-20241022",
 				StopReason: "end_turn",
 				Usage: struct {
@@ -294,8 +290,8 @@ func TestClaudeProvider_GetModelInfo(t *testing.T) {
 
 func TestClaudeProvider_SupportsStreaming(t *testing.T) {
 	provider := NewClaudeProvider("test-key", "")
-	if provider.SupportsStreaming() {
-		t.Error("SupportsStreaming() should return false for current implementation")
+	if !provider.SupportsStreaming() {
+		t.Error("SupportsStreaming() should return true")
 	}
 }
 
@@ -313,10 +309,7 @@ func TestClaudeProvider_ValidateConnection(t *testing.T) {
 				ID:   "msg_test",
 				Type: "message",
 				Role: "assistant",
-				Content: []struct {
-					Type string `json:"type"`
-					Text string `json:"text"`
-				}{
+				Content: []ClaudeContentBlock{
 					{
 						Type: "text",
 						Text: "Hello",
@@ -441,10 +434,7 @@ func TestClaudeProvider_MessageConversion(t *testing.T) {
 			ID:   "msg_test",
 			Type: "message",
 			Role: "assistant",
-			Content: []struct {
-				Type string `json:"type"`
-				Text string `json:"text"`
-			}{
+			Content: []ClaudeContentBlock{
 				{
 					Type: "text",
 					Text: "2+2 equals 4.",
@@ -473,3 +463,54 @@ func TestClaudeProvider_MessageConversion(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestClaudeProvider_GenerateResponse_ProviderErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		wantRetryable bool
+	}{
+		{name: "overloaded", statusCode: 529, wantRetryable: true},
+		{name: "rate limited", statusCode: http.StatusTooManyRequests, wantRetryable: true},
+		{name: "server error", statusCode: http.StatusInternalServerError, wantRetryable: true},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, wantRetryable: false},
+		{name: "bad request", statusCode: http.StatusBadRequest, wantRetryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				json.NewEncoder(w).Encode(ClaudeError{Type: "error", Message: http.StatusText(tt.statusCode)})
+			}))
+			defer server.Close()
+
+			provider := NewClaudeProvider("test-key", server.URL)
+			_, err := provider.GenerateResponse(context.Background(), &types.ModelRequest{Model: "claude-3-5-sonnet-20241022"})
+
+			var perr *pkgerrors.ProviderError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected ProviderError, got %T: %v", err, err)
+			}
+			if perr.StatusCode != tt.statusCode {
+				t.Errorf("expected status %d, got %d", tt.statusCode, perr.StatusCode)
+			}
+			if perr.Retryable != tt.wantRetryable {
+				t.Errorf("expected retryable=%t, got %t", tt.wantRetryable, perr.Retryable)
+			}
+		})
+	}
+
+	t.Run("connection refused", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		endpoint := server.URL
+		server.Close()
+
+		_, err := NewClaudeProvider("test-key", endpoint).GenerateResponse(context.Background(), &types.ModelRequest{})
+		var perr *pkgerrors.ProviderError
+		if !errors.As(err, &perr) || !perr.Retryable {
+			t.Fatalf("expected retryable ProviderError, got %v", err)
+		}
+	})
+}
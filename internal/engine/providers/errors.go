@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"genai-processing/internal/metrics"
+	pkgerrors "genai-processing/pkg/errors"
+)
+
+// isRetryableStatus reports whether an API status code indicates a failure
+// that may succeed on retry or on another provider: timeouts, rate limiting
+// and server-side errors (including Anthropic's 529 "overloaded").
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= http.StatusInternalServerError
+}
+
+// newAPIError reports an unsuccessful API response as a ProviderError and
+// counts it by status code.
+func newAPIError(providerName, endpoint string, statusCode int, message string) error {
+	metrics.ProviderErrors.With(providerName, strconv.Itoa(statusCode)).Inc()
+	return pkgerrors.NewProviderError(message, pkgerrors.ComponentProvider, providerName, statusCode, endpoint, isRetryableStatus(statusCode))
+}
+
+// newTransportError reports a request that received no response as a
+// ProviderError, counted with status code 0. It is retryable unless the
+// caller canceled the request.
+func newTransportError(providerName, endpoint string, err error) error {
+	metrics.ProviderErrors.With(providerName, "0").Inc()
+	retryable := !errors.Is(err, context.Canceled)
+	return pkgerrors.NewProviderError(fmt.Sprintf("failed to make HTTP request: %v", err), pkgerrors.ComponentProvider, providerName, 0, endpoint, retryable)
+}
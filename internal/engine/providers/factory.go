@@ -6,6 +6,20 @@ import (
 	"genai-processing/pkg/types"
 )
 
+// supportedProviders lists the provider types the factory can create
+var supportedProviders = []string{"claude", "openai", "generic", "ollama", "vllm"}
+
+// requiresAPIKey reports whether a provider type needs an API key. Generic,
+// Ollama and vLLM endpoints are often local and unauthenticated.
+func requiresAPIKey(providerType string) bool {
+	switch providerType {
+	case "generic", "ollama", "vllm":
+		return false
+	default:
+		return true
+	}
+}
+
 // ProviderFactory is responsible for creating and managing LLM providers
 type ProviderFactory struct {
 	configs map[string]*types.ProviderConfig
@@ -26,8 +40,8 @@ func (f *ProviderFactory) RegisterProvider(providerType string, config *types.Pr
 	if config == nil {
 		return fmt.Errorf("provider config cannot be nil")
 	}
-	// Allow empty API key for generic providers (e.g., local/unauthenticated endpoints)
-	if config.APIKey == "" && providerType != "generic" {
+	// Allow empty API key for local/unauthenticated endpoints
+	if config.APIKey == "" && requiresAPIKey(providerType) {
 		return fmt.Errorf("API key is required for provider %s", providerType)
 	}
 
@@ -61,6 +75,10 @@ func (f *ProviderFactory) CreateProvider(modelType string) (interfaces.LLMProvid
 			}
 		}
 		return NewGenericProvider(config.APIKey, config.Endpoint, config.ModelName, headers, config.Parameters), nil
+	case "ollama":
+		return NewOllamaProvider(config.Endpoint, config.ModelName, config.Parameters), nil
+	case "vllm":
+		return NewVLLMProvider(config.APIKey, config.Endpoint, config.ModelName, config.Parameters), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", modelType)
 	}
@@ -68,11 +86,9 @@ func (f *ProviderFactory) CreateProvider(modelType string) (interfaces.LLMProvid
 
 // GetSupportedProviders returns a list of all supported provider types
 func (f *ProviderFactory) GetSupportedProviders() []string {
-	supported := []string{"claude", "openai", "generic"}
-
 	// Return intersection of supported and registered providers
 	result := make([]string, 0)
-	for _, provider := range supported {
+	for _, provider := range supportedProviders {
 		if _, exists := f.configs[provider]; exists {
 			result = append(result, provider)
 		}
@@ -102,9 +118,8 @@ func (f *ProviderFactory) ValidateProvider(providerType string) error {
 	}
 
 	// Check if provider type is supported
-	supported := []string{"claude", "openai", "generic"}
 	isSupported := false
-	for _, supportedType := range supported {
+	for _, supportedType := range supportedProviders {
 		if providerType == supportedType {
 			isSupported = true
 			break
@@ -131,8 +146,8 @@ func (f *ProviderFactory) CreateProviderWithConfig(providerType string, config *
 	if config == nil {
 		return nil, fmt.Errorf("provider config cannot be nil")
 	}
-	// Allow empty API key for generic providers (e.g., local/unauthenticated endpoints)
-	if config.APIKey == "" && providerType != "generic" {
+	// Allow empty API key for local/unauthenticated endpoints
+	if config.APIKey == "" && requiresAPIKey(providerType) {
 		return nil, fmt.Errorf("API key is required for provider %s", providerType)
 	}
 
@@ -150,6 +165,10 @@ func (f *ProviderFactory) CreateProviderWithConfig(providerType string, config *
 			}
 		}
 		return NewGenericProvider(config.APIKey, config.Endpoint, config.ModelName, headers, config.Parameters), nil
+	case "ollama":
+		return NewOllamaProvider(config.Endpoint, config.ModelName, config.Parameters), nil
+	case "vllm":
+		return NewVLLMProvider(config.APIKey, config.Endpoint, config.ModelName, config.Parameters), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
 	}
@@ -189,6 +208,28 @@ func (f *ProviderFactory) GetDefaultConfig(providerType string) *types.ProviderC
 				"temperature": 0.1,
 			},
 		}
+	case "ollama":
+		return &types.ProviderConfig{
+			APIKey:    "", // Ollama does not authenticate requests
+			Endpoint:  "http://localhost:11434",
+			ModelName: "llama3.1:8b",
+			Parameters: map[string]interface{}{
+				"max_tokens":        4000,
+				"temperature":       0.1,
+				"structured_output": StructuredOutputJSONSchema,
+			},
+		}
+	case "vllm":
+		return &types.ProviderConfig{
+			APIKey:    "", // Optional, set when vLLM runs with --api-key
+			Endpoint:  "http://localhost:8000",
+			ModelName: "meta-llama/Llama-3.1-8B-Instruct",
+			Parameters: map[string]interface{}{
+				"max_tokens":        4000,
+				"temperature":       0.1,
+				"structured_output": StructuredOutputJSONSchema,
+			},
+		}
 	default:
 		return nil
 	}
@@ -548,6 +548,24 @@ func TestProviderFactory_CreateProviderWithConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:         "ollama provider without API key",
+			providerType: "ollama",
+			config: &types.ProviderConfig{
+				Endpoint:  "http://localhost:11434",
+				ModelName: "llama3.1:8b",
+			},
+			wantErr: false,
+		},
+		{
+			name:         "vllm provider without API key",
+			providerType: "vllm",
+			config: &types.ProviderConfig{
+				Endpoint:  "http://localhost:8000/v1",
+				ModelName: "meta-llama/Llama-3.1-8B-Instruct",
+			},
+			wantErr: false,
+		},
 		{
 			name:         "unsupported provider type",
 			providerType: "unsupported",
@@ -627,6 +645,18 @@ func TestProviderFactory_CreateProviderWithConfig(t *testing.T) {
 					if _, ok := provider.(*GenericProvider); !ok {
 						t.Error("CreateProviderWithConfig() returned wrong provider type for generic")
 					}
+				case "ollama":
+					if _, ok := provider.(*OllamaProvider); !ok {
+						t.Error("CreateProviderWithConfig() returned wrong provider type for ollama")
+					}
+				case "vllm":
+					vp, ok := provider.(*VLLMProvider)
+					if !ok {
+						t.Fatal("CreateProviderWithConfig() returned wrong provider type for vllm")
+					}
+					if vp.Endpoint != "http://localhost:8000" {
+						t.Errorf("vLLM provider Endpoint = %s, want http://localhost:8000", vp.Endpoint)
+					}
 				}
 			}
 		})
@@ -656,6 +686,16 @@ func TestProviderFactory_GetDefaultConfig(t *testing.T) {
 			providerType: "generic",
 			expectNil:    false,
 		},
+		{
+			name:         "ollama provider",
+			providerType: "ollama",
+			expectNil:    false,
+		},
+		{
+			name:         "vllm provider",
+			providerType: "vllm",
+			expectNil:    false,
+		},
 		{
 			name:         "unsupported provider",
 			providerType: "unsupported",
@@ -812,8 +852,8 @@ func TestProviderFactory_Integration(t *testing.T) {
 		t.Errorf("GetModelInfo() expected name 'claude-3-5-sonnet-20241022', got '%s'", modelInfo.Name)
 	}
 
-	if provider.SupportsStreaming() {
-		t.Error("SupportsStreaming() should return false for current implementation")
+	if !provider.SupportsStreaming() {
+		t.Error("SupportsStreaming() should return true")
 	}
 }
 
@@ -869,8 +909,8 @@ func TestProviderFactory_OpenAI_Integration(t *testing.T) {
 		t.Errorf("GetModelInfo() expected name 'gpt-4', got '%s'", modelInfo.Name)
 	}
 
-	if provider.SupportsStreaming() {
-		t.Error("SupportsStreaming() should return false for current implementation")
+	if !provider.SupportsStreaming() {
+		t.Error("SupportsStreaming() should return true")
 	}
 
 	// 9. Verify OpenAI provider has correct configuration
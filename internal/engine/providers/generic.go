@@ -102,6 +102,78 @@ func NewGenericProvider(apiKey, endpoint, modelName string, headers map[string]s
 
 // GenerateResponse sends a request to the configured endpoint and returns a RawResponse
 func (g *GenericProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	httpReq, err := g.newHTTPRequest(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, newTransportError("generic", g.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, g.apiError(resp.StatusCode, respBytes)
+	}
+
+	var chatResp GenericChatResponse
+	if err := json.Unmarshal(respBytes, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse generic chat response: %w", err)
+	}
+
+	completion := &chatCompletion{
+		Model:            chatResp.Model,
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+		TotalTokens:      chatResp.Usage.TotalTokens,
+	}
+	if len(chatResp.Choices) > 0 {
+		completion.Content = chatResp.Choices[0].Message.Content
+		completion.FinishReason = chatResp.Choices[0].FinishReason
+	}
+
+	return genericRawResponse(completion, time.Since(start), false), nil
+}
+
+// GenerateResponseStream sends a streaming request to the configured endpoint,
+// calling onChunk with each content delta, and returns the assembled response.
+func (g *GenericProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	httpReq, err := g.newHTTPRequest(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, newTransportError("generic", g.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, g.apiError(resp.StatusCode, respBytes)
+	}
+
+	completion, err := readChatCompletionStream(resp.Body, "generic", g.Endpoint, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return genericRawResponse(completion, time.Since(start), true), nil
+}
+
+// newHTTPRequest builds the chat request for the configured endpoint
+func (g *GenericProvider) newHTTPRequest(ctx context.Context, request *types.ModelRequest, stream bool) (*http.Request, error) {
 	if g.Endpoint == "" {
 		return nil, fmt.Errorf("generic provider endpoint is required")
 	}
@@ -118,7 +190,7 @@ func (g *GenericProvider) GenerateResponse(ctx context.Context, request *types.M
 		MaxTokens:   4000,
 		Temperature: 0.1,
 		TopP:        1.0,
-		Stream:      false,
+		Stream:      stream,
 	}
 
 	if g.Parameters != nil {
@@ -131,9 +203,6 @@ func (g *GenericProvider) GenerateResponse(ctx context.Context, request *types.M
 		if v, ok := g.Parameters["top_p"].(float64); ok {
 			chatReq.TopP = v
 		}
-		if v, ok := g.Parameters["stream"].(bool); ok {
-			chatReq.Stream = v
-		}
 	}
 
 	if request.Parameters != nil {
@@ -146,9 +215,6 @@ func (g *GenericProvider) GenerateResponse(ctx context.Context, request *types.M
 		if v, ok := request.Parameters["top_p"].(float64); ok {
 			chatReq.TopP = v
 		}
-		if v, ok := request.Parameters["stream"].(bool); ok {
-			chatReq.Stream = v
-		}
 	}
 
 	// Convert generic messages
@@ -174,67 +240,56 @@ func (g *GenericProvider) GenerateResponse(ctx context.Context, request *types.M
 	for k, v := range g.Headers {
 		httpReq.Header.Set(k, v)
 	}
-
-	start := time.Now()
-	resp, err := g.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
 	}
-	defer resp.Body.Close()
 
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+	return httpReq, nil
 
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse error envelope
-		var apiErr GenericAPIError
-		if err := json.Unmarshal(respBytes, &apiErr); err != nil {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBytes))
-		}
-		return nil, fmt.Errorf("generic API error: %s - %s", apiErr.Error.Type, apiErr.Error.Message)
-	}
-
-	var chatResp GenericChatResponse
-	if err := json.Unmarshal(respBytes, &chatResp); err != nil {
-		return nil, fmt.Errorf("failed to parse generic chat response: %w", err)
-	}
+}
 
-	content := ""
-	finishReason := ""
-	if len(chatResp.Choices) > 0 {
-		content = chatResp.Choices[0].Message.Content
-		finishReason = chatResp.Choices[0].FinishReason
+// apiError converts an unsuccessful response into an error
+func (g *GenericProvider) apiError(statusCode int, respBytes []byte) error {
+	// Try to parse error envelope
+	var apiErr GenericAPIError
+	if err := json.Unmarshal(respBytes, &apiErr); err != nil {
+		return newAPIError("generic", g.Endpoint, statusCode, string(respBytes))
 	}
+	return newAPIError("generic", g.Endpoint, statusCode, fmt.Sprintf("generic API error: %s - %s", apiErr.Error.Type, apiErr.Error.Message))
+}
 
-	processingTime := time.Since(start)
-
-	totalTokens := chatResp.Usage.TotalTokens
+// genericRawResponse builds the RawResponse for a completed chat completion
+func genericRawResponse(completion *chatCompletion, processingTime time.Duration, streamed bool) *types.RawResponse {
+	totalTokens := completion.TotalTokens
 	tokensPerSecond := 0.0
 	if processingTime > 0 {
 		tokensPerSecond = float64(totalTokens) / processingTime.Seconds()
 	}
 
+	metadata := map[string]interface{}{
+		"provider":        "generic",
+		"api_version":     "v1",
+		"processing_time": processingTime.String(),
+		"token_usage": map[string]interface{}{
+			"prompt_tokens":     completion.PromptTokens,
+			"completion_tokens": completion.CompletionTokens,
+			"total_tokens":      totalTokens,
+			"tokens_per_second": tokensPerSecond,
+			"timestamp":         time.Now(),
+		},
+	}
+	if streamed {
+		metadata["streamed"] = true
+	}
+
 	return &types.RawResponse{
-		Content: content,
+		Content: completion.Content,
 		ModelInfo: map[string]interface{}{
-			"model":         chatResp.Model,
-			"finish_reason": finishReason,
+			"model":         completion.Model,
+			"finish_reason": completion.FinishReason,
 		},
-		Metadata: map[string]interface{}{
-			"provider":        "generic",
-			"api_version":     "v1",
-			"processing_time": processingTime.String(),
-			"token_usage": map[string]interface{}{
-				"prompt_tokens":     chatResp.Usage.PromptTokens,
-				"completion_tokens": chatResp.Usage.CompletionTokens,
-				"total_tokens":      totalTokens,
-				"tokens_per_second": tokensPerSecond,
-				"timestamp":         time.Now(),
-			},
-		},
-	}, nil
+		Metadata: metadata,
+	}
 }
 
 // GetModelInfo returns basic model information
@@ -251,7 +306,7 @@ func (g *GenericProvider) GetModelInfo() types.ModelInfo {
 }
 
 // SupportsStreaming indicates whether streaming is supported
-func (g *GenericProvider) SupportsStreaming() bool { return false }
+func (g *GenericProvider) SupportsStreaming() bool { return true }
 
 // ValidateConnection makes a tiny test request to validate the endpoint
 func (g *GenericProvider) ValidateConnection() error {
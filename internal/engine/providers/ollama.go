@@ -0,0 +1,440 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// OllamaProvider implements the LLMProvider interface for Ollama's native chat
+// API, serving self-hosted models such as Llama 3.1 and Mistral.
+type OllamaProvider struct {
+	// Endpoint is the Ollama server's base URL, e.g. http://localhost:11434
+	Endpoint string
+
+	// ModelName is the default model tag, used when a request names none
+	ModelName string
+
+	// APIKey is sent as a bearer token when set, for servers behind an authenticating proxy
+	APIKey string
+
+	// Parameters are provider defaults from models.yaml
+	Parameters map[string]interface{}
+
+	client *http.Client
+}
+
+// OllamaMessage represents a message in the Ollama chat format
+type OllamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OllamaChatRequest represents the request payload for Ollama's /api/chat
+type OllamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	// Stream is always sent, since Ollama streams unless told otherwise
+	Stream    bool                   `json:"stream"`
+	Format    interface{}            `json:"format,omitempty"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive string                 `json:"keep_alive,omitempty"`
+}
+
+// OllamaChatResponse represents a response, or one streamed chunk, from /api/chat
+type OllamaChatResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       string        `json:"created_at"`
+	Message         OllamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	TotalDuration   int64         `json:"total_duration"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// ollamaTagsResponse represents the model list from /api/tags
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
+
+// NewOllamaProvider creates a new OllamaProvider. The endpoint may be the
+// server's base URL or its /api/chat URL.
+func NewOllamaProvider(endpoint, modelName string, parameters map[string]interface{}) *OllamaProvider {
+	if endpoint == "" {
+		endpoint = "http://localhost:11434"
+	}
+	if modelName == "" {
+		modelName = "llama3.1:8b"
+	}
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/api/chat")
+
+	return &OllamaProvider{
+		Endpoint:   endpoint,
+		ModelName:  modelName,
+		Parameters: parameters,
+		client: &http.Client{
+			// Local models can take a while to load on first use
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// GenerateResponse implements the LLMProvider interface
+func (o *OllamaProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	req, err := o.newHTTPRequest(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("ollama", o.chatURL(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.apiError(resp.StatusCode, body)
+	}
+
+	var chatResp OllamaChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	return o.rawResponse(&chatResp, chatResp.Message.Content, time.Since(startTime), false), nil
+}
+
+// GenerateResponseStream implements the StreamingProvider interface. Ollama
+// streams newline-delimited JSON objects, the last of which reports token counts.
+func (o *OllamaProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	req, err := o.newHTTPRequest(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("ollama", o.chatURL(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, o.apiError(resp.StatusCode, body)
+	}
+
+	var final OllamaChatResponse
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk OllamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, newTransportError("ollama", o.chatURL(), fmt.Errorf("failed to parse stream chunk: %w", err))
+		}
+		if chunk.Error != "" {
+			return nil, newAPIError("ollama", o.chatURL(), http.StatusInternalServerError, fmt.Sprintf("ollama API error: %s", chunk.Error))
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if onChunk != nil {
+				onChunk(chunk.Message.Content)
+			}
+		}
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, newTransportError("ollama", o.chatURL(), err)
+	}
+
+	return o.rawResponse(&final, content.String(), time.Since(startTime), true), nil
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (o *OllamaProvider) GetModelInfo() types.ModelInfo {
+	return types.ModelInfo{
+		Name:            o.ModelName,
+		Provider:        "ollama",
+		Version:         o.ModelName,
+		Description:     "Self-hosted open-source model served by Ollama",
+		ModelType:       "chat",
+		ContextWindow:   8192,
+		MaxOutputTokens: 4096,
+		PricingInfo: map[string]interface{}{
+			"input_cost_per_1k_tokens":  0.0,
+			"output_cost_per_1k_tokens": 0.0,
+			"currency":                  "USD",
+		},
+	}
+}
+
+// SupportsStreaming implements the LLMProvider interface
+func (o *OllamaProvider) SupportsStreaming() bool {
+	return true
+}
+
+// ValidateConnection checks that the server is reachable and has the
+// configured model pulled.
+func (o *OllamaProvider) ValidateConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	models, err := o.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+	for _, model := range models {
+		if model == o.ModelName || model == o.ModelName+":latest" {
+			return nil
+		}
+	}
+	return fmt.Errorf("model '%s' is not available on %s; pull it with 'ollama pull %s'", o.ModelName, o.Endpoint, o.ModelName)
+}
+
+// ListModels returns the tags of the models available on the server.
+func (o *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
+	endpoint := o.Endpoint + "/api/tags"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("ollama", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.apiError(resp.StatusCode, body)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		name := model.Name
+		if name == "" {
+			name = model.Model
+		}
+		models = append(models, name)
+	}
+	return models, nil
+}
+
+// chatURL returns the URL of the chat API
+func (o *OllamaProvider) chatURL() string {
+	return o.Endpoint + "/api/chat"
+}
+
+// newHTTPRequest converts a ModelRequest into an Ollama chat request.
+// Provider parameters from models.yaml are overridden by request parameters.
+func (o *OllamaProvider) newHTTPRequest(ctx context.Context, request *types.ModelRequest, stream bool) (*http.Request, error) {
+	params := mergeParameters(o.Parameters, request.Parameters)
+
+	chatReq := OllamaChatRequest{
+		Model:   request.Model,
+		Stream:  stream,
+		Options: map[string]interface{}{},
+	}
+	if chatReq.Model == "" {
+		chatReq.Model = o.ModelName
+	}
+
+	// Ollama names generation settings differently from OpenAI
+	if v, ok := intParameter(params, "max_tokens"); ok {
+		chatReq.Options["num_predict"] = v
+	}
+	if v, ok := floatParameter(params, "temperature"); ok {
+		chatReq.Options["temperature"] = v
+	}
+	if v, ok := floatParameter(params, "top_p"); ok {
+		chatReq.Options["top_p"] = v
+	}
+	for _, key := range []string{"top_k", "num_ctx", "seed"} {
+		if v, ok := intParameter(params, key); ok {
+			chatReq.Options[key] = v
+		}
+	}
+	if v, ok := params["keep_alive"].(string); ok {
+		chatReq.KeepAlive = v
+	}
+
+	// Constrain the output to the query schema or, failing that, to JSON
+	switch structuredOutputMode(params, StructuredOutputJSONSchema) {
+	case StructuredOutputJSONSchema, StructuredOutputTool:
+		if jsonSchema, ok := jsonSchemaParameter(params); ok {
+			chatReq.Format = jsonSchema
+		} else {
+			chatReq.Format = "json"
+		}
+	case StructuredOutputJSON:
+		chatReq.Format = "json"
+	}
+
+	chatReq.Messages = chatMessages(request.Messages, params)
+
+	reqBody, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.chatURL(), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+	if stream {
+		req.Header.Set("Accept", "application/x-ndjson")
+	}
+
+	return req, nil
+}
+
+// apiError converts an unsuccessful Ollama response into an error
+func (o *OllamaProvider) apiError(statusCode int, body []byte) error {
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		return newAPIError("ollama", o.chatURL(), statusCode, fmt.Sprintf("failed to parse error response: %s", string(body)))
+	}
+	return newAPIError("ollama", o.chatURL(), statusCode, fmt.Sprintf("ollama API error: %s", errResp.Error))
+}
+
+// rawResponse builds the RawResponse for a completed chat
+func (o *OllamaProvider) rawResponse(chatResp *OllamaChatResponse, content string, processingTime time.Duration, streamed bool) *types.RawResponse {
+	totalTokens := chatResp.PromptEvalCount + chatResp.EvalCount
+	tokensPerSecond := 0.0
+	if processingTime > 0 {
+		tokensPerSecond = float64(totalTokens) / processingTime.Seconds()
+	}
+
+	metadata := map[string]interface{}{
+		"provider":        "ollama",
+		"api_version":     "v1",
+		"processing_time": processingTime.String(),
+		"token_usage": map[string]interface{}{
+			"prompt_tokens":     chatResp.PromptEvalCount,
+			"completion_tokens": chatResp.EvalCount,
+			"total_tokens":      totalTokens,
+			"tokens_per_second": tokensPerSecond,
+			"model_name":        chatResp.Model,
+			"timestamp":         time.Now(),
+		},
+	}
+	if streamed {
+		metadata["streamed"] = true
+	}
+
+	return &types.RawResponse{
+		Content: content,
+		ModelInfo: map[string]interface{}{
+			"model":         chatResp.Model,
+			"created_at":    chatResp.CreatedAt,
+			"finish_reason": chatResp.DoneReason,
+		},
+		Metadata: metadata,
+	}
+}
+
+// mergeParameters returns provider defaults overridden by request parameters
+func mergeParameters(defaults, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// intParameter reads an integer parameter, accepting whole float values
+func intParameter(params map[string]interface{}, key string) (int, bool) {
+	switch v := params[key].(type) {
+	case int:
+		return v, true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// floatParameter reads a numeric parameter as a float
+func floatParameter(params map[string]interface{}, key string) (float64, bool) {
+	switch v := params[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// chatMessages converts role/content messages, adding the "system" parameter
+// as a system message when the messages carry none
+func chatMessages(messages []interface{}, params map[string]interface{}) []OllamaMessage {
+	var result []OllamaMessage
+	hasSystem := false
+	for _, msg := range messages {
+		if msgMap, ok := msg.(map[string]interface{}); ok {
+			role, _ := msgMap["role"].(string)
+			content, _ := msgMap["content"].(string)
+			if role == "system" {
+				hasSystem = true
+			}
+			result = append(result, OllamaMessage{Role: role, Content: content})
+		}
+	}
+	if system, ok := params["system"].(string); ok && strings.TrimSpace(system) != "" && !hasSystem {
+		result = append([]OllamaMessage{{Role: "system", Content: system}}, result...)
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestNewOllamaProvider_NormalizesEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"", "http://localhost:11434"},
+		{"http://gpu-host:11434/", "http://gpu-host:11434"},
+		{"http://gpu-host:11434/api/chat", "http://gpu-host:11434"},
+	}
+	for _, tt := range tests {
+		p := NewOllamaProvider(tt.endpoint, "llama3.1:8b", nil)
+		if p.Endpoint != tt.want {
+			t.Errorf("NewOllamaProvider(%q).Endpoint = %q, want %q", tt.endpoint, p.Endpoint, tt.want)
+		}
+	}
+}
+
+func TestOllamaProvider_GenerateResponse(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		fmt.Fprint(w, `{"model":"llama3.1:8b","message":{"role":"assistant","content":"{\"log_source\":\"kube-apiserver\"}"},"done":true,"done_reason":"stop","prompt_eval_count":40,"eval_count":12}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "llama3.1:8b", map[string]interface{}{"keep_alive": "10m"})
+	req := &types.ModelRequest{
+		Messages: []interface{}{map[string]interface{}{"role": "user", "content": "who deleted pods"}},
+		Parameters: map[string]interface{}{
+			"max_tokens":  256,
+			"temperature": 0.1,
+			"system":      "You convert queries to JSON",
+			"json_schema": map[string]interface{}{"type": "object"},
+		},
+	}
+
+	resp, err := p.GenerateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	if resp.Content != `{"log_source":"kube-apiserver"}` {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+	usage := resp.Metadata["token_usage"].(map[string]interface{})
+	if usage["total_tokens"] != 52 {
+		t.Errorf("total_tokens = %v, want 52", usage["total_tokens"])
+	}
+
+	if body["model"] != "llama3.1:8b" || body["stream"] != false || body["keep_alive"] != "10m" {
+		t.Errorf("unexpected request fields: %v", body)
+	}
+	if format, ok := body["format"].(map[string]interface{}); !ok || format["type"] != "object" {
+		t.Errorf("expected schema format, got %v", body["format"])
+	}
+	options := body["options"].(map[string]interface{})
+	if options["num_predict"] != float64(256) || options["temperature"] != 0.1 {
+		t.Errorf("unexpected options: %v", options)
+	}
+	messages := body["messages"].([]interface{})
+	if len(messages) != 2 || messages[0].(map[string]interface{})["role"] != "system" {
+		t.Errorf("expected system message to be prepended, got %v", messages)
+	}
+}
+
+func TestOllamaProvider_StructuredOutputModes(t *testing.T) {
+	tests := []struct {
+		mode       string
+		wantFormat interface{}
+	}{
+		{StructuredOutputJSON, "json"},
+		{StructuredOutputNone, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&body)
+				fmt.Fprint(w, `{"model":"m","message":{"role":"assistant","content":"{}"},"done":true}`)
+			}))
+			defer server.Close()
+
+			p := NewOllamaProvider(server.URL, "m", map[string]interface{}{"structured_output": tt.mode})
+			req := streamRequest("")
+			req.Parameters = map[string]interface{}{"json_schema": map[string]interface{}{"type": "object"}}
+			if _, err := p.GenerateResponse(context.Background(), req); err != nil {
+				t.Fatalf("GenerateResponse returned error: %v", err)
+			}
+			if body["format"] != tt.wantFormat {
+				t.Errorf("format = %v, want %v", body["format"], tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestOllamaProvider_GenerateResponseStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"model":"m","message":{"role":"assistant","content":"{\"log_"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"m","message":{"role":"assistant","content":"source\":\"oauth-server\"}"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"m","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":3}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "m", nil)
+	var chunks []string
+	resp, err := p.GenerateResponseStream(context.Background(), streamRequest(""), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+	if resp.Content != `{"log_source":"oauth-server"}` {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("expected 2 chunks, got %d", len(chunks))
+	}
+	if resp.Metadata["streamed"] != true {
+		t.Error("expected streamed metadata")
+	}
+}
+
+func TestOllamaProvider_GenerateResponseStream_ErrorChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"error":"model runner has unexpectedly stopped"}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "m", nil)
+	_, err := p.GenerateResponseStream(context.Background(), streamRequest(""), func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "unexpectedly stopped") {
+		t.Fatalf("expected error chunk to surface, got %v", err)
+	}
+}
+
+func TestOllamaProvider_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model 'missing' not found"}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "missing", nil)
+	_, err := p.GenerateResponse(context.Background(), streamRequest(""))
+	if err == nil || !strings.Contains(err.Error(), "model 'missing' not found") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+func TestOllamaProvider_ValidateConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"models":[{"name":"llama3.1:8b"},{"name":"mistral:latest"}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		model   string
+		wantErr bool
+	}{
+		{"llama3.1:8b", false},
+		{"mistral", false},
+		{"qwen2.5:7b", true},
+	}
+	for _, tt := range tests {
+		err := NewOllamaProvider(server.URL, tt.model, nil).ValidateConnection()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateConnection(%q) error = %v, wantErr %v", tt.model, err, tt.wantErr)
+		}
+	}
+
+	models, err := NewOllamaProvider(server.URL, "", nil).ListModels(context.Background())
+	if err != nil || len(models) != 2 {
+		t.Errorf("ListModels() = %v, %v", models, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"genai-processing/internal/schema"
 	"genai-processing/pkg/types"
 )
 
@@ -29,14 +30,67 @@ type OpenAIMessage struct {
 
 // OpenAIRequest represents the request payload for OpenAI API
 type OpenAIRequest struct {
-	Model            string          `json:"model"`
-	Messages         []OpenAIMessage `json:"messages"`
-	MaxTokens        int             `json:"max_tokens,omitempty"`
-	Temperature      float64         `json:"temperature,omitempty"`
-	TopP             float64         `json:"top_p,omitempty"`
-	FrequencyPenalty float64         `json:"frequency_penalty,omitempty"`
-	PresencePenalty  float64         `json:"presence_penalty,omitempty"`
-	Stream           bool            `json:"stream,omitempty"`
+	Model            string                `json:"model"`
+	Messages         []OpenAIMessage       `json:"messages"`
+	MaxTokens        int                   `json:"max_tokens,omitempty"`
+	Temperature      float64               `json:"temperature,omitempty"`
+	TopP             float64               `json:"top_p,omitempty"`
+	FrequencyPenalty float64               `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64               `json:"presence_penalty,omitempty"`
+	Stream           bool                  `json:"stream,omitempty"`
+	StreamOptions    *OpenAIStreamOptions  `json:"stream_options,omitempty"`
+	Tools            []OpenAITool          `json:"tools,omitempty"`
+	ToolChoice       *OpenAIToolChoice     `json:"tool_choice,omitempty"`
+	ResponseFormat   *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// OpenAITool declares a function the model may call
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction describes a function and the JSON Schema of its arguments
+type OpenAIFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// OpenAIToolChoice forces a call to the named function
+type OpenAIToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+// OpenAIResponseFormat selects JSON mode or, with JSONSchema, structured outputs
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+// OpenAIJSONSchema is the schema a structured output must follow
+type OpenAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict"`
+}
+
+// OpenAIToolCall is a function call made by the model
+type OpenAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// OpenAIStreamOptions configures a streamed response
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // OpenAIResponse represents the response from OpenAI API
@@ -60,6 +114,15 @@ type OpenAIResponse struct {
 	} `json:"usage"`
 }
 
+// openAIToolCallResponse holds the function calls of an OpenAI response
+type openAIToolCallResponse struct {
+	Choices []struct {
+		Message struct {
+			ToolCalls []OpenAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
 // OpenAIError represents an error response from OpenAI API
 type OpenAIError struct {
 	Error struct {
@@ -112,6 +175,103 @@ func NewOpenAIProviderWithConfig(apiKey, endpoint, modelName string, parameters
 
 // GenerateResponse implements the LLMProvider interface
 func (o *OpenAIProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	req, err := o.newHTTPRequest(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the request
+	startTime := time.Now()
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("openai", o.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	processingTime := time.Since(startTime)
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Handle error responses
+	if resp.StatusCode != http.StatusOK {
+		return nil, o.apiError(resp.StatusCode, body)
+	}
+
+	// Parse successful response
+	var openaiResp OpenAIResponse
+	if err := json.Unmarshal(body, &openaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+
+	// Extract content from response
+	completion := &chatCompletion{
+		ID:               openaiResp.ID,
+		Object:           openaiResp.Object,
+		Created:          openaiResp.Created,
+		Model:            openaiResp.Model,
+		PromptTokens:     openaiResp.Usage.PromptTokens,
+		CompletionTokens: openaiResp.Usage.CompletionTokens,
+		TotalTokens:      openaiResp.Usage.TotalTokens,
+	}
+	if len(openaiResp.Choices) > 0 {
+		completion.Content = openaiResp.Choices[0].Message.Content
+		completion.FinishReason = openaiResp.Choices[0].FinishReason
+	}
+
+	// Function calls are decoded separately so OpenAIResponse keeps its shape
+	var toolResp openAIToolCallResponse
+	if err := json.Unmarshal(body, &toolResp); err == nil && len(toolResp.Choices) > 0 {
+		for _, call := range toolResp.Choices[0].Message.ToolCalls {
+			completion.ToolCalls = append(completion.ToolCalls, types.ToolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+	}
+
+	return o.rawResponse(completion, processingTime, false), nil
+}
+
+// GenerateResponseStream implements the StreamingProvider interface. It
+// requests a streamed chat completion and calls onChunk with each content
+// delta as it arrives, returning the assembled response at the end.
+func (o *OpenAIProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	req, err := o.newHTTPRequest(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("openai", o.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, o.apiError(resp.StatusCode, body)
+	}
+
+	completion, err := readChatCompletionStream(resp.Body, "openai", o.Endpoint, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return o.rawResponse(completion, time.Since(startTime), true), nil
+}
+
+// newHTTPRequest converts a ModelRequest into an OpenAI API request. The
+// stream argument, not the "stream" parameter, decides whether the response
+// is streamed, since the caller must be ready to read it.
+func (o *OpenAIProvider) newHTTPRequest(ctx context.Context, request *types.ModelRequest, stream bool) (*http.Request, error) {
 	// Validate API key
 	if o.APIKey == "" {
 		return nil, fmt.Errorf("openai API key is required")
@@ -129,7 +289,7 @@ func (o *OpenAIProvider) GenerateResponse(ctx context.Context, request *types.Mo
 		MaxTokens:   4000, // Default max tokens
 		Temperature: 0.1,  // Default temperature
 		TopP:        1.0,  // Default top_p
-		Stream:      false,
+		Stream:      stream,
 	}
 
 	// Apply stored configuration as defaults
@@ -149,9 +309,6 @@ func (o *OpenAIProvider) GenerateResponse(ctx context.Context, request *types.Mo
 		if presPenalty, ok := o.Parameters["presence_penalty"].(float64); ok {
 			openaiReq.PresencePenalty = presPenalty
 		}
-		if stream, ok := o.Parameters["stream"].(bool); ok {
-			openaiReq.Stream = stream
-		}
 	}
 
 	// Override with request-specific parameters
@@ -171,9 +328,36 @@ func (o *OpenAIProvider) GenerateResponse(ctx context.Context, request *types.Mo
 		if presPenalty, ok := request.Parameters["presence_penalty"].(float64); ok {
 			openaiReq.PresencePenalty = presPenalty
 		}
-		if stream, ok := request.Parameters["stream"].(bool); ok {
-			openaiReq.Stream = stream
+	}
+
+	if stream {
+		openaiReq.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+	}
+
+	// Constrain the output to the query schema. Function calling is the
+	// default since every tool-capable model supports it; structured outputs
+	// are not strict because optional fields are left out of "required".
+	params := mergeParameters(o.Parameters, request.Parameters)
+	jsonSchema, hasSchema := jsonSchemaParameter(params)
+	switch mode := structuredOutputMode(params, StructuredOutputTool); {
+	case mode == StructuredOutputTool && hasSchema:
+		openaiReq.Tools = []OpenAITool{{
+			Type: "function",
+			Function: OpenAIFunction{
+				Name:        schema.ToolName,
+				Description: schema.ToolDescription,
+				Parameters:  jsonSchema,
+			},
+		}}
+		openaiReq.ToolChoice = &OpenAIToolChoice{Type: "function"}
+		openaiReq.ToolChoice.Function.Name = schema.ToolName
+	case mode == StructuredOutputJSONSchema && hasSchema:
+		openaiReq.ResponseFormat = &OpenAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &OpenAIJSONSchema{Name: schema.ToolName, Schema: jsonSchema},
 		}
+	case mode == StructuredOutputJSONSchema, mode == StructuredOutputJSON:
+		openaiReq.ResponseFormat = &OpenAIResponseFormat{Type: "json_object"}
 	}
 
 	// Convert messages to OpenAI format
@@ -202,81 +386,61 @@ func (o *OpenAIProvider) GenerateResponse(ctx context.Context, request *types.Mo
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+o.APIKey)
-
-	// Make the request
-	startTime := time.Now()
-	resp, err := o.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	processingTime := time.Since(startTime)
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
 	}
 
-	// Handle error responses
-	if resp.StatusCode != http.StatusOK {
-		var openaiErr OpenAIError
-		if err := json.Unmarshal(body, &openaiErr); err != nil {
-			return nil, fmt.Errorf("HTTP %d: failed to parse error response: %s", resp.StatusCode, string(body))
-		}
-		return nil, fmt.Errorf("openai API error: %s - %s", openaiErr.Error.Type, openaiErr.Error.Message)
-	}
+	return req, nil
 
-	// Parse successful response
-	var openaiResp OpenAIResponse
-	if err := json.Unmarshal(body, &openaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
+}
 
-	// Extract content from response
-	var content string
-	var finishReason string
-	if len(openaiResp.Choices) > 0 {
-		content = openaiResp.Choices[0].Message.Content
-		finishReason = openaiResp.Choices[0].FinishReason
+// apiError converts an unsuccessful OpenAI API response into an error.
+func (o *OpenAIProvider) apiError(statusCode int, body []byte) error {
+	var openaiErr OpenAIError
+	if err := json.Unmarshal(body, &openaiErr); err != nil {
+		return newAPIError("openai", o.Endpoint, statusCode, fmt.Sprintf("failed to parse error response: %s", string(body)))
 	}
+	return newAPIError("openai", o.Endpoint, statusCode, fmt.Sprintf("openai API error: %s - %s", openaiErr.Error.Type, openaiErr.Error.Message))
+}
 
+// rawResponse builds the RawResponse for a completed chat completion.
+func (o *OpenAIProvider) rawResponse(completion *chatCompletion, processingTime time.Duration, streamed bool) *types.RawResponse {
 	// Calculate token usage
-	totalTokens := openaiResp.Usage.TotalTokens
+	totalTokens := completion.TotalTokens
 	tokensPerSecond := 0.0
 	if processingTime > 0 {
 		tokensPerSecond = float64(totalTokens) / processingTime.Seconds()
 	}
 
-	// Calculate estimated cost (OpenAI pricing as of 2024)
-	estimatedCost := o.calculateCost(openaiResp.Usage.PromptTokens, openaiResp.Usage.CompletionTokens, openaiResp.Model)
+	metadata := map[string]interface{}{
+		"provider":        "openai",
+		"api_version":     "v1",
+		"processing_time": processingTime.String(),
+		"token_usage": map[string]interface{}{
+			"prompt_tokens":     completion.PromptTokens,
+			"completion_tokens": completion.CompletionTokens,
+			"total_tokens":      totalTokens,
+			"tokens_per_second": tokensPerSecond,
+			"model_name":        completion.Model,
+			"timestamp":         time.Now(),
+		},
+	}
+	if streamed {
+		metadata["streamed"] = true
+	}
 
 	return &types.RawResponse{
-		Content: content,
+		Content:   completion.outputContent(),
+		ToolCalls: completion.ToolCalls,
 		ModelInfo: map[string]interface{}{
-			"model":         openaiResp.Model,
-			"id":            openaiResp.ID,
-			"object":        openaiResp.Object,
-			"created":       openaiResp.Created,
-			"finish_reason": finishReason,
-		},
-		Metadata: map[string]interface{}{
-			"provider":        "openai",
-			"api_version":     "v1",
-			"processing_time": processingTime.String(),
-			"token_usage": map[string]interface{}{
-				"prompt_tokens":     openaiResp.Usage.PromptTokens,
-				"completion_tokens": openaiResp.Usage.CompletionTokens,
-				"total_tokens":      totalTokens,
-				"tokens_per_second": tokensPerSecond,
-				"model_name":        openaiResp.Model,
-				"estimated_cost":    estimatedCost,
-				"currency":          "USD",
-				"timestamp":         time.Now(),
-			},
+			"model":         completion.Model,
+			"id":            completion.ID,
+			"object":        completion.Object,
+			"created":       completion.Created,
+			"finish_reason": completion.FinishReason,
 		},
-	}, nil
+		Metadata: metadata,
+	}
 }
 
 // GetModelInfo implements the LLMProvider interface
@@ -305,8 +469,7 @@ func (o *OpenAIProvider) GetModelInfo() types.ModelInfo {
 
 // SupportsStreaming implements the LLMProvider interface
 func (o *OpenAIProvider) SupportsStreaming() bool {
-	// OpenAI supports streaming, but not implemented in this version
-	return false
+	return true
 }
 
 // ValidateConnection checks if the OpenAI API connection is working
@@ -350,30 +513,3 @@ func (o *OpenAIProvider) ValidateConnection() error {
 
 	return nil
 }
-
-// calculateCost estimates the cost of the API call based on OpenAI pricing
-func (o *OpenAIProvider) calculateCost(promptTokens, completionTokens int, model string) float64 {
-	var inputCostPer1k, outputCostPer1k float64
-
-	// OpenAI pricing as of 2024 (approximate)
-	switch model {
-	case "gpt-4":
-		inputCostPer1k = 0.03
-		outputCostPer1k = 0.06
-	case "gpt-4-turbo":
-		inputCostPer1k = 0.01
-		outputCostPer1k = 0.03
-	case "gpt-3.5-turbo":
-		inputCostPer1k = 0.0015
-		outputCostPer1k = 0.002
-	default:
-		// Default to GPT-4 pricing for unknown models
-		inputCostPer1k = 0.03
-		outputCostPer1k = 0.06
-	}
-
-	inputCost := float64(promptTokens) / 1000.0 * inputCostPer1k
-	outputCost := float64(completionTokens) / 1000.0 * outputCostPer1k
-
-	return inputCost + outputCost
-}
@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// Ensure the streaming providers implement the interface
+var (
+	_ interfaces.StreamingProvider = (*ClaudeProvider)(nil)
+	_ interfaces.StreamingProvider = (*OpenAIProvider)(nil)
+	_ interfaces.StreamingProvider = (*GenericProvider)(nil)
+	_ interfaces.StreamingProvider = (*OllamaProvider)(nil)
+	_ interfaces.StreamingProvider = (*VLLMProvider)(nil)
+)
+
+// maxStreamLineSize bounds a single line of a server-sent event stream.
+const maxStreamLineSize = 1 << 20
+
+// errStopStream ends readSSE early without reporting an error.
+var errStopStream = errors.New("stop stream")
+
+// readSSE reads a server-sent event stream, calling onEvent with each
+// event's type and data as the stream arrives. Multi-line data is joined
+// with newlines. Reading stops at the end of the stream, on a read error, or
+// when onEvent returns an error; errStopStream stops without an error.
+func readSSE(body io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := onEvent(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if errors.Is(err, errStopStream) {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, used as a keep-alive
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// A final event may end without its blank line
+	if err := dispatch(); err != nil && !errors.Is(err, errStopStream) {
+		return err
+	}
+	return nil
+}
+
+// chatCompletion is the content, identity and token usage of a chat
+// completion, whether read from a single response or assembled from a stream.
+type chatCompletion struct {
+	ID               string
+	Object           string
+	Created          int64
+	Model            string
+	Content          string
+	ToolCalls        []types.ToolCall
+	FinishReason     string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// chatCompletionChunk is one event of an OpenAI-style streamed chat completion.
+type chatCompletionChunk struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// readChatCompletionStream assembles an OpenAI-style streamed chat completion,
+// calling onChunk with each content or function argument delta. An error
+// event in the stream is reported as a retryable ProviderError, since the
+// request had been accepted.
+func readChatCompletionStream(body io.Reader, providerName, endpoint string, onChunk func(string)) (*chatCompletion, error) {
+	var result chatCompletion
+	var content strings.Builder
+	var toolCalls toolCallBuilder
+	var streamErr error
+
+	err := readSSE(body, func(event, data string) error {
+		if data == "[DONE]" {
+			return errStopStream
+		}
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			streamErr = newAPIError(providerName, endpoint, http.StatusInternalServerError,
+				fmt.Sprintf("%s API error: %s - %s", providerName, chunk.Error.Type, chunk.Error.Message))
+			return errStopStream
+		}
+		if chunk.ID != "" {
+			result.ID = chunk.ID
+		}
+		if chunk.Object != "" {
+			result.Object = chunk.Object
+		}
+		if chunk.Created != 0 {
+			result.Created = chunk.Created
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				if onChunk != nil {
+					onChunk(choice.Delta.Content)
+				}
+			}
+			for _, call := range choice.Delta.ToolCalls {
+				toolCalls.add(call.Index, call.ID, call.Function.Name, call.Function.Arguments)
+				if call.Function.Arguments != "" && onChunk != nil {
+					onChunk(call.Function.Arguments)
+				}
+			}
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				result.FinishReason = *choice.FinishReason
+			}
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+			result.TotalTokens = chunk.Usage.TotalTokens
+		}
+		return nil
+	})
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	if err != nil {
+		return nil, newTransportError(providerName, endpoint, err)
+	}
+
+	result.Content = content.String()
+	result.ToolCalls = toolCalls.toolCalls()
+	return &result, nil
+}
@@ -0,0 +1,246 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pkgerrors "genai-processing/pkg/errors"
+	"genai-processing/pkg/types"
+)
+
+// sseServer replies to every request with the given server-sent events and
+// records the decoded request body.
+func sseServer(t *testing.T, events []string, requestBody *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestBody != nil {
+			if err := json.NewDecoder(r.Body).Decode(requestBody); err != nil {
+				t.Errorf("failed to decode request body: %v", err)
+			}
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprint(w, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+func streamRequest(model string) *types.ModelRequest {
+	return &types.ModelRequest{
+		Model:    model,
+		Messages: []interface{}{map[string]interface{}{"role": "user", "content": "who deleted pods"}},
+	}
+}
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\n" +
+		"event: first\n" +
+		"data: line one\n" +
+		"data: line two\n" +
+		"\n" +
+		"data:no-space\n" +
+		"\n" +
+		"data: unterminated"
+
+	var got []string
+	err := readSSE(strings.NewReader(input), func(event, data string) error {
+		got = append(got, event+"|"+data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE returned error: %v", err)
+	}
+	want := []string{"first|line one\nline two", "|no-space", "|unterminated"}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("readSSE events = %q, want %q", got, want)
+	}
+}
+
+func TestClaudeProvider_GenerateResponseStream(t *testing.T) {
+	var requestBody map[string]interface{}
+	server := sseServer(t, []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"model\":\"claude-test\",\"usage\":{\"input_tokens\":12}}}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n",
+		"event: ping\ndata: {\"type\":\"ping\"}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"{\\\"log_source\\\": \"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"\\\"kube-apiserver\\\"}\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":7}}\n\n",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	}, &requestBody)
+	defer server.Close()
+
+	provider := NewClaudeProvider("test-key", server.URL)
+	var chunks []string
+	resp, err := provider.GenerateResponseStream(context.Background(), streamRequest("claude-test"), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+
+	if requestBody["stream"] != true {
+		t.Errorf("expected stream=true in request, got %v", requestBody["stream"])
+	}
+	if len(chunks) != 2 {
+		t.Errorf("expected 2 chunks, got %d: %q", len(chunks), chunks)
+	}
+	if resp.Content != `{"log_source": "kube-apiserver"}` {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+	if resp.ModelInfo["id"] != "msg_1" || resp.ModelInfo["stop_reason"] != "end_turn" {
+		t.Errorf("unexpected model info %v", resp.ModelInfo)
+	}
+	usage := resp.Metadata["token_usage"].(map[string]interface{})
+	if usage["prompt_tokens"] != 12 || usage["completion_tokens"] != 7 || usage["total_tokens"] != 19 {
+		t.Errorf("unexpected token usage %v", usage)
+	}
+	if resp.Metadata["streamed"] != true {
+		t.Error("expected streamed metadata")
+	}
+}
+
+func TestClaudeProvider_GenerateResponseStream_Errors(t *testing.T) {
+	t.Run("error event", func(t *testing.T) {
+		server := sseServer(t, []string{
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"model\":\"claude-test\"}}\n\n",
+			"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+		}, nil)
+		defer server.Close()
+
+		_, err := NewClaudeProvider("test-key", server.URL).GenerateResponseStream(context.Background(), streamRequest("claude-test"), nil)
+		var providerErr *pkgerrors.ProviderError
+		if !errors.As(err, &providerErr) {
+			t.Fatalf("expected ProviderError, got %v", err)
+		}
+		if providerErr.StatusCode != 529 || !providerErr.Retryable {
+			t.Errorf("expected retryable 529, got status=%d retryable=%v", providerErr.StatusCode, providerErr.Retryable)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"authentication_error","message":"invalid x-api-key"}`))
+		}))
+		defer server.Close()
+
+		_, err := NewClaudeProvider("test-key", server.URL).GenerateResponseStream(context.Background(), streamRequest("claude-test"), nil)
+		var providerErr *pkgerrors.ProviderError
+		if !errors.As(err, &providerErr) {
+			t.Fatalf("expected ProviderError, got %v", err)
+		}
+		if providerErr.StatusCode != http.StatusUnauthorized || providerErr.Retryable {
+			t.Errorf("expected non-retryable 401, got status=%d retryable=%v", providerErr.StatusCode, providerErr.Retryable)
+		}
+	})
+}
+
+func TestOpenAIProvider_GenerateResponseStream(t *testing.T) {
+	var requestBody map[string]interface{}
+	server := sseServer(t, []string{
+		"data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1700000000,\"model\":\"gpt-test\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"\"},\"finish_reason\":null}]}\n\n",
+		"data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-test\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"{\\\"limit\\\": \"},\"finish_reason\":null}]}\n\n",
+		"data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-test\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"20}\"},\"finish_reason\":\"stop\"}]}\n\n",
+		"data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-test\",\"choices\":[],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":4,\"total_tokens\":14}}\n\n",
+		"data: [DONE]\n\n",
+	}, &requestBody)
+	defer server.Close()
+
+	provider := NewOpenAIProvider("test-key", server.URL)
+	var chunks []string
+	resp, err := provider.GenerateResponseStream(context.Background(), streamRequest("gpt-test"), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+
+	if requestBody["stream"] != true {
+		t.Errorf("expected stream=true in request, got %v", requestBody["stream"])
+	}
+	if options, _ := requestBody["stream_options"].(map[string]interface{}); options["include_usage"] != true {
+		t.Errorf("expected stream_options.include_usage=true, got %v", requestBody["stream_options"])
+	}
+	if len(chunks) != 2 || resp.Content != `{"limit": 20}` {
+		t.Errorf("unexpected chunks %q and content %q", chunks, resp.Content)
+	}
+	if resp.ModelInfo["finish_reason"] != "stop" || resp.ModelInfo["created"] != int64(1700000000) {
+		t.Errorf("unexpected model info %v", resp.ModelInfo)
+	}
+	usage := resp.Metadata["token_usage"].(map[string]interface{})
+	if usage["total_tokens"] != 14 {
+		t.Errorf("unexpected token usage %v", usage)
+	}
+}
+
+func TestOpenAIProvider_GenerateResponse_IgnoresStreamParameter(t *testing.T) {
+	var requestBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-test","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProviderWithConfig("test-key", server.URL, "gpt-test", map[string]interface{}{"stream": true})
+	resp, err := provider.GenerateResponse(context.Background(), streamRequest("gpt-test"))
+	if err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	if _, ok := requestBody["stream"]; ok {
+		t.Errorf("expected no stream field in a non-streaming request, got %v", requestBody["stream"])
+	}
+	if resp.Content != "ok" {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+}
+
+func TestGenericProvider_GenerateResponseStream(t *testing.T) {
+	t.Run("content", func(t *testing.T) {
+		server := sseServer(t, []string{
+			"data: {\"model\":\"local\",\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\n",
+			"data: {\"model\":\"local\",\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n",
+			"data: [DONE]\n\n",
+		}, nil)
+		defer server.Close()
+
+		provider := NewGenericProvider("", server.URL, "local", nil, nil)
+		var chunks []string
+		resp, err := provider.GenerateResponseStream(context.Background(), streamRequest(""), func(chunk string) {
+			chunks = append(chunks, chunk)
+		})
+		if err != nil {
+			t.Fatalf("GenerateResponseStream returned error: %v", err)
+		}
+		if strings.Join(chunks, "") != "hello" || resp.Content != "hello" {
+			t.Errorf("unexpected chunks %q and content %q", chunks, resp.Content)
+		}
+		if resp.ModelInfo["finish_reason"] != "stop" {
+			t.Errorf("unexpected model info %v", resp.ModelInfo)
+		}
+	})
+
+	t.Run("error chunk", func(t *testing.T) {
+		server := sseServer(t, []string{
+			"data: {\"error\":{\"type\":\"server_error\",\"message\":\"model crashed\"}}\n\n",
+		}, nil)
+		defer server.Close()
+
+		provider := NewGenericProvider("", server.URL, "local", nil, nil)
+		_, err := provider.GenerateResponseStream(context.Background(), streamRequest(""), nil)
+		var providerErr *pkgerrors.ProviderError
+		if !errors.As(err, &providerErr) || !providerErr.Retryable {
+			t.Fatalf("expected retryable ProviderError, got %v", err)
+		}
+		if !strings.Contains(providerErr.Error(), "model crashed") {
+			t.Errorf("expected error message to be preserved, got %v", providerErr)
+		}
+	})
+}
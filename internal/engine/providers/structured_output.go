@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"strings"
+
+	"genai-processing/pkg/types"
+)
+
+// Structured output modes, set with the "structured_output" parameter. Each
+// provider uses the modes its API supports and treats the rest as the
+// nearest one it does.
+const (
+	// StructuredOutputTool forces a call to the schema.ToolName tool whose
+	// input schema is the request's "json_schema" parameter. Ollama and vLLM
+	// treat it as StructuredOutputJSONSchema.
+	StructuredOutputTool = "tool"
+
+	// StructuredOutputJSONSchema constrains decoding to the request's
+	// "json_schema" parameter, falling back to JSON mode without one
+	StructuredOutputJSONSchema = "json_schema"
+
+	// StructuredOutputJSON constrains decoding to any valid JSON
+	StructuredOutputJSON = "json"
+
+	// StructuredOutputNone leaves decoding unconstrained
+	StructuredOutputNone = "none"
+)
+
+// structuredOutputMode returns the "structured_output" parameter, or
+// defaultMode when it is unset or unknown
+func structuredOutputMode(params map[string]interface{}, defaultMode string) string {
+	mode, _ := params["structured_output"].(string)
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case StructuredOutputTool, StructuredOutputJSONSchema, StructuredOutputJSON, StructuredOutputNone:
+		return mode
+	default:
+		return defaultMode
+	}
+}
+
+// jsonSchemaParameter returns the request's "json_schema" parameter
+func jsonSchemaParameter(params map[string]interface{}) (map[string]interface{}, bool) {
+	jsonSchema, ok := params["json_schema"].(map[string]interface{})
+	return jsonSchema, ok && len(jsonSchema) > 0
+}
+
+// toolCallBuilder assembles tool calls whose arguments arrive in fragments,
+// keyed by the index the provider gives each call in a stream
+type toolCallBuilder struct {
+	order []int
+	calls map[int]*types.ToolCall
+	args  map[int]*strings.Builder
+}
+
+// add records a fragment of the call at index; id and name are set by the
+// first fragment that carries them
+func (b *toolCallBuilder) add(index int, id, name, arguments string) {
+	if b.calls == nil {
+		b.calls = map[int]*types.ToolCall{}
+		b.args = map[int]*strings.Builder{}
+	}
+	call, ok := b.calls[index]
+	if !ok {
+		call = &types.ToolCall{}
+		b.calls[index] = call
+		b.args[index] = &strings.Builder{}
+		b.order = append(b.order, index)
+	}
+	if id != "" {
+		call.ID = id
+	}
+	if name != "" {
+		call.Name = name
+	}
+	b.args[index].WriteString(arguments)
+}
+
+// toolCalls returns the assembled calls in the order they started
+func (b *toolCallBuilder) toolCalls() []types.ToolCall {
+	var calls []types.ToolCall
+	for _, index := range b.order {
+		call := *b.calls[index]
+		call.Arguments = b.args[index].String()
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// outputContent returns the completion's text or, when the model answered
+// only with a tool call, that call's arguments
+func (c *chatCompletion) outputContent() string {
+	if strings.TrimSpace(c.Content) == "" && len(c.ToolCalls) > 0 {
+		return c.ToolCalls[0].Arguments
+	}
+	return c.Content
+}
@@ -0,0 +1,208 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+// schemaRequest is a request carrying the query schema, as built by the
+// Claude and OpenAI input adapters.
+func schemaRequest(model string, params map[string]interface{}) *types.ModelRequest {
+	req := streamRequest(model)
+	req.Parameters = map[string]interface{}{
+		"json_schema": map[string]interface{}{"type": "object", "required": []string{"log_source"}},
+	}
+	for k, v := range params {
+		req.Parameters[k] = v
+	}
+	return req
+}
+
+// jsonServer replies to every request with body and records the decoded
+// request body.
+func jsonServer(t *testing.T, body string, requestBody *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(requestBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestClaudeProvider_ForcesStructuredQueryTool(t *testing.T) {
+	var body map[string]interface{}
+	server := jsonServer(t, `{"id":"msg_1","type":"message","model":"claude-test","stop_reason":"tool_use",
+		"content":[{"type":"tool_use","id":"toolu_1","name":"structured_query","input":{"log_source":"kube-apiserver","verb":"delete"}}],
+		"usage":{"input_tokens":10,"output_tokens":5}}`, &body)
+	defer server.Close()
+
+	resp, err := NewClaudeProvider("test-key", server.URL).GenerateResponse(context.Background(), schemaRequest("claude-test", nil))
+	if err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+
+	tools, _ := body["tools"].([]interface{})
+	if len(tools) != 1 {
+		t.Fatalf("expected one tool in request, got %v", body["tools"])
+	}
+	tool := tools[0].(map[string]interface{})
+	if tool["name"] != "structured_query" || tool["input_schema"] == nil {
+		t.Errorf("unexpected tool %v", tool)
+	}
+	choice := body["tool_choice"].(map[string]interface{})
+	if choice["type"] != "tool" || choice["name"] != "structured_query" {
+		t.Errorf("unexpected tool_choice %v", choice)
+	}
+
+	call := resp.FindToolCall("structured_query")
+	if call == nil || call.ID != "toolu_1" {
+		t.Fatalf("expected structured_query tool call, got %+v", resp.ToolCalls)
+	}
+	if call.Arguments != `{"log_source":"kube-apiserver","verb":"delete"}` {
+		t.Errorf("unexpected arguments %s", call.Arguments)
+	}
+	if resp.Content != call.Arguments {
+		t.Errorf("expected content to fall back to the tool arguments, got %q", resp.Content)
+	}
+}
+
+func TestClaudeProvider_StructuredOutputNone(t *testing.T) {
+	var body map[string]interface{}
+	server := jsonServer(t, `{"content":[{"type":"text","text":"{}"}]}`, &body)
+	defer server.Close()
+
+	req := schemaRequest("claude-test", map[string]interface{}{"structured_output": StructuredOutputNone})
+	if _, err := NewClaudeProvider("test-key", server.URL).GenerateResponse(context.Background(), req); err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	if _, ok := body["tools"]; ok {
+		t.Error("expected no tools when structured output is disabled")
+	}
+}
+
+func TestClaudeProvider_GenerateResponseStream_ToolUse(t *testing.T) {
+	server := sseServer(t, []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"model\":\"claude-test\",\"usage\":{\"input_tokens\":12}}}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"structured_query\",\"input\":{}}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"log_source\\\": \"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"oauth-server\\\"}\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":7}}\n\n",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	}, nil)
+	defer server.Close()
+
+	var chunks []string
+	resp, err := NewClaudeProvider("test-key", server.URL).GenerateResponseStream(context.Background(), schemaRequest("claude-test", nil), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("expected 2 chunks, got %q", chunks)
+	}
+	call := resp.FindToolCall("structured_query")
+	if call == nil || call.ID != "toolu_1" || call.Arguments != `{"log_source": "oauth-server"}` {
+		t.Fatalf("unexpected tool calls %+v", resp.ToolCalls)
+	}
+}
+
+func TestOpenAIProvider_StructuredOutputModes(t *testing.T) {
+	tests := []struct {
+		mode           string
+		wantTool       bool
+		wantFormatType string
+	}{
+		{"", true, ""},
+		{StructuredOutputTool, true, ""},
+		{StructuredOutputJSONSchema, false, "json_schema"},
+		{StructuredOutputJSON, false, "json_object"},
+		{StructuredOutputNone, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			var body map[string]interface{}
+			server := jsonServer(t, `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`, &body)
+			defer server.Close()
+
+			params := map[string]interface{}{}
+			if tt.mode != "" {
+				params["structured_output"] = tt.mode
+			}
+			provider := NewOpenAIProviderWithConfig("test-key", server.URL, "gpt-4o", params)
+			if _, err := provider.GenerateResponse(context.Background(), schemaRequest("", nil)); err != nil {
+				t.Fatalf("GenerateResponse returned error: %v", err)
+			}
+
+			_, hasTools := body["tools"]
+			if hasTools != tt.wantTool {
+				t.Errorf("tools present = %v, want %v", hasTools, tt.wantTool)
+			}
+			if tt.wantTool {
+				choice := body["tool_choice"].(map[string]interface{})
+				if choice["function"].(map[string]interface{})["name"] != "structured_query" {
+					t.Errorf("unexpected tool_choice %v", choice)
+				}
+			}
+			format, _ := body["response_format"].(map[string]interface{})
+			if got, _ := format["type"].(string); got != tt.wantFormatType {
+				t.Errorf("response_format type = %q, want %q", got, tt.wantFormatType)
+			}
+		})
+	}
+}
+
+func TestOpenAIProvider_GenerateResponse_ToolCall(t *testing.T) {
+	var body map[string]interface{}
+	server := jsonServer(t, `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":null,
+		"tool_calls":[{"id":"call_1","type":"function","function":{"name":"structured_query","arguments":"{\"log_source\":\"kube-apiserver\"}"}}]},
+		"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, &body)
+	defer server.Close()
+
+	resp, err := NewOpenAIProviderWithConfig("test-key", server.URL, "gpt-4o", nil).GenerateResponse(context.Background(), schemaRequest("", nil))
+	if err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	call := resp.FindToolCall("structured_query")
+	if call == nil || call.ID != "call_1" || call.Arguments != `{"log_source":"kube-apiserver"}` {
+		t.Fatalf("unexpected tool calls %+v", resp.ToolCalls)
+	}
+	if resp.Content != call.Arguments {
+		t.Errorf("expected content to fall back to the tool arguments, got %q", resp.Content)
+	}
+}
+
+func TestOpenAIProvider_GenerateResponseStream_ToolCall(t *testing.T) {
+	server := sseServer(t, []string{
+		"data: {\"id\":\"c\",\"model\":\"gpt-4o\",\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"structured_query\",\"arguments\":\"\"}}]}}]}\n\n",
+		"data: {\"id\":\"c\",\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"log_source\\\":\"}}]}}]}\n\n",
+		"data: {\"id\":\"c\",\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"\\\"oauth-server\\\"}\"}}]},\"finish_reason\":\"stop\"}]}\n\n",
+		"data: [DONE]\n\n",
+	}, nil)
+	defer server.Close()
+
+	var chunks []string
+	provider := NewOpenAIProviderWithConfig("test-key", server.URL, "gpt-4o", nil)
+	resp, err := provider.GenerateResponseStream(context.Background(), schemaRequest("", nil), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("expected 2 chunks, got %q", chunks)
+	}
+	call := resp.FindToolCall("structured_query")
+	if call == nil || call.ID != "call_1" || call.Arguments != `{"log_source":"oauth-server"}` {
+		t.Fatalf("unexpected tool calls %+v", resp.ToolCalls)
+	}
+}
@@ -0,0 +1,366 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// VLLMProvider implements the LLMProvider interface for vLLM's
+// OpenAI-compatible server, using its guided decoding to constrain output to
+// the query schema.
+type VLLMProvider struct {
+	// Endpoint is the server's base URL, e.g. http://localhost:8000
+	Endpoint string
+
+	// ModelName is the served model name, used when a request names none
+	ModelName string
+
+	// APIKey is sent as a bearer token when the server was started with --api-key
+	APIKey string
+
+	// Parameters are provider defaults from models.yaml
+	Parameters map[string]interface{}
+
+	client *http.Client
+}
+
+// VLLMChatRequest represents the request payload for vLLM's chat completions
+// API: the OpenAI fields plus vLLM's sampling and guided decoding extensions
+type VLLMChatRequest struct {
+	Model          string               `json:"model"`
+	Messages       []OpenAIMessage      `json:"messages"`
+	MaxTokens      int                  `json:"max_tokens,omitempty"`
+	Temperature    *float64             `json:"temperature,omitempty"`
+	TopP           float64              `json:"top_p,omitempty"`
+	TopK           int                  `json:"top_k,omitempty"`
+	Seed           *int                 `json:"seed,omitempty"`
+	Stream         bool                 `json:"stream,omitempty"`
+	StreamOptions  *OpenAIStreamOptions `json:"stream_options,omitempty"`
+	GuidedJSON     interface{}          `json:"guided_json,omitempty"`
+	ResponseFormat *VLLMResponseFormat  `json:"response_format,omitempty"`
+}
+
+// VLLMResponseFormat selects JSON mode
+type VLLMResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// vllmModelsResponse represents the model list from /v1/models
+type vllmModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// NewVLLMProvider creates a new VLLMProvider. The endpoint may be the
+// server's base URL, its /v1 URL or its chat completions URL.
+func NewVLLMProvider(apiKey, endpoint, modelName string, parameters map[string]interface{}) *VLLMProvider {
+	if endpoint == "" {
+		endpoint = "http://localhost:8000"
+	}
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	endpoint = strings.TrimSuffix(endpoint, "/chat/completions")
+	endpoint = strings.TrimSuffix(endpoint, "/v1")
+
+	return &VLLMProvider{
+		Endpoint:   endpoint,
+		ModelName:  modelName,
+		APIKey:     apiKey,
+		Parameters: parameters,
+		client: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// GenerateResponse implements the LLMProvider interface
+func (v *VLLMProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	req, err := v.newHTTPRequest(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("vllm", v.chatURL(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, v.apiError(resp.StatusCode, body)
+	}
+
+	var chatResp OpenAIResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse vLLM response: %w", err)
+	}
+
+	completion := &chatCompletion{
+		ID:               chatResp.ID,
+		Object:           chatResp.Object,
+		Created:          chatResp.Created,
+		Model:            chatResp.Model,
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+		TotalTokens:      chatResp.Usage.TotalTokens,
+	}
+	if len(chatResp.Choices) > 0 {
+		completion.Content = chatResp.Choices[0].Message.Content
+		completion.FinishReason = chatResp.Choices[0].FinishReason
+	}
+
+	return vllmRawResponse(completion, time.Since(startTime), false), nil
+}
+
+// GenerateResponseStream implements the StreamingProvider interface
+func (v *VLLMProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	req, err := v.newHTTPRequest(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("vllm", v.chatURL(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, v.apiError(resp.StatusCode, body)
+	}
+
+	completion, err := readChatCompletionStream(resp.Body, "vllm", v.chatURL(), onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return vllmRawResponse(completion, time.Since(startTime), true), nil
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (v *VLLMProvider) GetModelInfo() types.ModelInfo {
+	return types.ModelInfo{
+		Name:            v.ModelName,
+		Provider:        "vllm",
+		Version:         v.ModelName,
+		Description:     "Self-hosted open-source model served by vLLM",
+		ModelType:       "chat",
+		ContextWindow:   8192,
+		MaxOutputTokens: 4096,
+		PricingInfo: map[string]interface{}{
+			"input_cost_per_1k_tokens":  0.0,
+			"output_cost_per_1k_tokens": 0.0,
+			"currency":                  "USD",
+		},
+	}
+}
+
+// SupportsStreaming implements the LLMProvider interface
+func (v *VLLMProvider) SupportsStreaming() bool {
+	return true
+}
+
+// ValidateConnection checks that the server is reachable and serves the
+// configured model.
+func (v *VLLMProvider) ValidateConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	models, err := v.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+	if v.ModelName == "" && len(models) > 0 {
+		return nil
+	}
+	for _, model := range models {
+		if model == v.ModelName {
+			return nil
+		}
+	}
+	return fmt.Errorf("model '%s' is not served by %s; available models: %s", v.ModelName, v.Endpoint, strings.Join(models, ", "))
+}
+
+// ListModels returns the names of the models the server serves.
+func (v *VLLMProvider) ListModels(ctx context.Context) ([]string, error) {
+	endpoint := v.Endpoint + "/v1/models"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if v.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.APIKey)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, newTransportError("vllm", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, v.apiError(resp.StatusCode, body)
+	}
+
+	var list vllmModelsResponse
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+	models := make([]string, 0, len(list.Data))
+	for _, model := range list.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
+
+// chatURL returns the URL of the chat completions API
+func (v *VLLMProvider) chatURL() string {
+	return v.Endpoint + "/v1/chat/completions"
+}
+
+// newHTTPRequest converts a ModelRequest into a vLLM chat completions request.
+// Provider parameters from models.yaml are overridden by request parameters.
+func (v *VLLMProvider) newHTTPRequest(ctx context.Context, request *types.ModelRequest, stream bool) (*http.Request, error) {
+	params := mergeParameters(v.Parameters, request.Parameters)
+
+	chatReq := VLLMChatRequest{
+		Model:  request.Model,
+		Stream: stream,
+	}
+	if chatReq.Model == "" {
+		chatReq.Model = v.ModelName
+	}
+	if stream {
+		chatReq.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+	}
+
+	if n, ok := intParameter(params, "max_tokens"); ok {
+		chatReq.MaxTokens = n
+	}
+	if t, ok := floatParameter(params, "temperature"); ok {
+		chatReq.Temperature = &t
+	}
+	if p, ok := floatParameter(params, "top_p"); ok {
+		chatReq.TopP = p
+	}
+	if k, ok := intParameter(params, "top_k"); ok {
+		chatReq.TopK = k
+	}
+	if s, ok := intParameter(params, "seed"); ok {
+		chatReq.Seed = &s
+	}
+
+	// Constrain the output to the query schema or, failing that, to JSON
+	switch structuredOutputMode(params, StructuredOutputJSONSchema) {
+	case StructuredOutputJSONSchema, StructuredOutputTool:
+		if jsonSchema, ok := jsonSchemaParameter(params); ok {
+			chatReq.GuidedJSON = jsonSchema
+		} else {
+			chatReq.ResponseFormat = &VLLMResponseFormat{Type: "json_object"}
+		}
+	case StructuredOutputJSON:
+		chatReq.ResponseFormat = &VLLMResponseFormat{Type: "json_object"}
+	}
+
+	for _, msg := range chatMessages(request.Messages, params) {
+		chatReq.Messages = append(chatReq.Messages, OpenAIMessage(msg))
+	}
+
+	reqBody, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal vLLM request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.chatURL(), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.APIKey)
+	}
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	return req, nil
+}
+
+// apiError converts an unsuccessful vLLM response into an error. vLLM
+// reports errors either in OpenAI's envelope or as a flat object.
+func (v *VLLMProvider) apiError(statusCode int, body []byte) error {
+	var openaiErr OpenAIError
+	if err := json.Unmarshal(body, &openaiErr); err == nil && openaiErr.Error.Message != "" {
+		return newAPIError("vllm", v.chatURL(), statusCode, fmt.Sprintf("vllm API error: %s - %s", openaiErr.Error.Type, openaiErr.Error.Message))
+	}
+	var flat struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &flat); err == nil && flat.Message != "" {
+		return newAPIError("vllm", v.chatURL(), statusCode, fmt.Sprintf("vllm API error: %s - %s", flat.Type, flat.Message))
+	}
+	return newAPIError("vllm", v.chatURL(), statusCode, fmt.Sprintf("failed to parse error response: %s", string(body)))
+}
+
+// vllmRawResponse builds the RawResponse for a completed chat completion
+func vllmRawResponse(completion *chatCompletion, processingTime time.Duration, streamed bool) *types.RawResponse {
+	totalTokens := completion.TotalTokens
+	tokensPerSecond := 0.0
+	if processingTime > 0 {
+		tokensPerSecond = float64(totalTokens) / processingTime.Seconds()
+	}
+
+	metadata := map[string]interface{}{
+		"provider":        "vllm",
+		"api_version":     "v1",
+		"processing_time": processingTime.String(),
+		"token_usage": map[string]interface{}{
+			"prompt_tokens":     completion.PromptTokens,
+			"completion_tokens": completion.CompletionTokens,
+			"total_tokens":      totalTokens,
+			"tokens_per_second": tokensPerSecond,
+			"model_name":        completion.Model,
+			"timestamp":         time.Now(),
+		},
+	}
+	if streamed {
+		metadata["streamed"] = true
+	}
+
+	return &types.RawResponse{
+		Content: completion.Content,
+		ModelInfo: map[string]interface{}{
+			"model":         completion.Model,
+			"id":            completion.ID,
+			"object":        completion.Object,
+			"created":       completion.Created,
+			"finish_reason": completion.FinishReason,
+		},
+		Metadata: metadata,
+	}
+}
@@ -0,0 +1,159 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewVLLMProvider_NormalizesEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"", "http://localhost:8000"},
+		{"http://gpu-host:8000/v1", "http://gpu-host:8000"},
+		{"http://gpu-host:8000/v1/chat/completions", "http://gpu-host:8000"},
+	}
+	for _, tt := range tests {
+		p := NewVLLMProvider("", tt.endpoint, "m", nil)
+		if p.Endpoint != tt.want {
+			t.Errorf("NewVLLMProvider(%q).Endpoint = %q, want %q", tt.endpoint, p.Endpoint, tt.want)
+		}
+	}
+}
+
+func TestVLLMProvider_GenerateResponse(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		fmt.Fprint(w, `{"id":"cmpl-1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"{\"log_source\":\"kube-apiserver\"}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":10,"total_tokens":40}}`)
+	}))
+	defer server.Close()
+
+	p := NewVLLMProvider("secret", server.URL+"/v1", "m", map[string]interface{}{"top_k": 20})
+	req := streamRequest("")
+	req.Parameters = map[string]interface{}{
+		"max_tokens":  128,
+		"json_schema": map[string]interface{}{"type": "object"},
+	}
+
+	resp, err := p.GenerateResponse(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	if resp.Content != `{"log_source":"kube-apiserver"}` {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+
+	if body["model"] != "m" || body["max_tokens"] != float64(128) || body["top_k"] != float64(20) {
+		t.Errorf("unexpected request fields: %v", body)
+	}
+	if guided, ok := body["guided_json"].(map[string]interface{}); !ok || guided["type"] != "object" {
+		t.Errorf("expected guided_json schema, got %v", body["guided_json"])
+	}
+	if _, ok := body["response_format"]; ok {
+		t.Error("response_format should not be set with guided_json")
+	}
+}
+
+func TestVLLMProvider_JSONMode(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`)
+	}))
+	defer server.Close()
+
+	p := NewVLLMProvider("", server.URL, "m", map[string]interface{}{"structured_output": StructuredOutputJSON})
+	req := streamRequest("")
+	req.Parameters = map[string]interface{}{"json_schema": map[string]interface{}{"type": "object"}}
+	if _, err := p.GenerateResponse(context.Background(), req); err != nil {
+		t.Fatalf("GenerateResponse returned error: %v", err)
+	}
+	if _, ok := body["guided_json"]; ok {
+		t.Error("guided_json should not be set in json mode")
+	}
+	if format, ok := body["response_format"].(map[string]interface{}); !ok || format["type"] != "json_object" {
+		t.Errorf("expected json_object response format, got %v", body["response_format"])
+	}
+}
+
+func TestVLLMProvider_GenerateResponseStream(t *testing.T) {
+	var body map[string]interface{}
+	server := sseServer(t, []string{
+		"data: {\"id\":\"c\",\"model\":\"m\",\"choices\":[{\"delta\":{\"content\":\"{\\\"log_source\\\":\"}}]}\n\n",
+		"data: {\"id\":\"c\",\"model\":\"m\",\"choices\":[{\"delta\":{\"content\":\"\\\"oauth-server\\\"}\"},\"finish_reason\":\"stop\"}]}\n\n",
+		"data: [DONE]\n\n",
+	}, &body)
+	defer server.Close()
+
+	p := NewVLLMProvider("", server.URL, "m", nil)
+	var chunks []string
+	resp, err := p.GenerateResponseStream(context.Background(), streamRequest(""), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream returned error: %v", err)
+	}
+	if resp.Content != `{"log_source":"oauth-server"}` || len(chunks) != 2 {
+		t.Errorf("unexpected stream result %q from %d chunks", resp.Content, len(chunks))
+	}
+	if body["stream"] != true {
+		t.Errorf("expected stream to be requested, got %v", body["stream"])
+	}
+}
+
+func TestVLLMProvider_APIError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"openai envelope", `{"error":{"message":"context too long","type":"BadRequestError"}}`, "context too long"},
+		{"flat object", `{"object":"error","message":"model not found","type":"NotFoundError"}`, "model not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewVLLMProvider("", server.URL, "m", nil).GenerateResponse(context.Background(), streamRequest(""))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVLLMProvider_ValidateConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"meta-llama/Llama-3.1-8B-Instruct"}]}`)
+	}))
+	defer server.Close()
+
+	if err := NewVLLMProvider("", server.URL, "meta-llama/Llama-3.1-8B-Instruct", nil).ValidateConnection(); err != nil {
+		t.Errorf("ValidateConnection() unexpected error: %v", err)
+	}
+	if err := NewVLLMProvider("", server.URL, "mistralai/Mistral-7B-Instruct-v0.3", nil).ValidateConnection(); err == nil {
+		t.Error("ValidateConnection() expected error for a model the server does not serve")
+	}
+}
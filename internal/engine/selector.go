@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -62,6 +63,12 @@ type ProviderInfo struct {
 
 	// ResponseTime tracks the average response time for health checks
 	ResponseTime time.Duration
+
+	// RequestCount is the number of requests reported through RecordResult
+	RequestCount int
+
+	// FailureCount is the number of those requests that failed
+	FailureCount int
 }
 
 // HealthChecker manages health check operations for providers
@@ -161,48 +168,89 @@ func NewModelSelector(factory interfaces.ProviderFactory, config *SelectorConfig
 
 // SelectModel selects the appropriate LLM provider based on the request
 func (s *ModelSelector) SelectModel(ctx context.Context, req *SelectionRequest) (*SelectionResult, error) {
+	candidates, err := s.RankProviders(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Printf("Selected provider %s (%s)", candidates[0].ProviderName, candidates[0].Reason)
+	return candidates[0], nil
+}
+
+// RankProviders returns every healthy provider in the order SelectModel
+// considers them: the preferred model, the preference list, the default
+// provider, then any other healthy provider by name. Callers fail over down
+// the list when a provider returns an error.
+func (s *ModelSelector) RankProviders(ctx context.Context, req *SelectionRequest) ([]*SelectionResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if req == nil {
+		req = &SelectionRequest{}
+	}
 	s.logger.Printf("Selecting model for request: %+v", req)
 
-	// Step 1: Try to use preferred model if specified and healthy
+	var candidates []*SelectionResult
+	seen := make(map[string]bool)
+	add := func(name, reason string, confidence float64, fallbackUsed bool) {
+		provider, exists := s.providers[name]
+		if !exists || !provider.IsHealthy || seen[name] {
+			return
+		}
+		seen[name] = true
+		// Every candidate after the first is only used after a failover
+		candidates = append(candidates, s.createSelectionResult(provider, name, reason, confidence, fallbackUsed || len(candidates) > 0))
+	}
+
+	// Step 1: Use preferred model if specified and healthy
 	preferredModelSpecified := req.PreferredModel != ""
 	if preferredModelSpecified {
-		if provider, exists := s.providers[req.PreferredModel]; exists && provider.IsHealthy {
-			s.logger.Printf("Using preferred model: %s", req.PreferredModel)
-			return s.createSelectionResult(provider, req.PreferredModel, "preferred_model", 1.0, false), nil
-		}
-		s.logger.Printf("Preferred model %s not available or unhealthy, trying fallback", req.PreferredModel)
+		add(req.PreferredModel, "preferred_model", 1.0, false)
 	}
 
-	// Step 2: Try providers in preference order
+	// Step 2: Providers in preference order; a fallback if the preferred model was skipped
 	for _, providerName := range s.preferences {
-		if provider, exists := s.providers[providerName]; exists && provider.IsHealthy {
-			s.logger.Printf("Using preferred provider: %s", providerName)
-			// Mark as fallback if a preferred model was specified but couldn't be used
-			fallbackUsed := preferredModelSpecified
-			return s.createSelectionResult(provider, providerName, "preference_order", 0.9, fallbackUsed), nil
-		}
+		add(providerName, "preference_order", 0.9, preferredModelSpecified)
 	}
 
-	// Step 3: Try default provider as fallback
-	if provider, exists := s.providers[s.defaultProvider]; exists && provider.IsHealthy {
-		s.logger.Printf("Using default provider as fallback: %s", s.defaultProvider)
-		return s.createSelectionResult(provider, s.defaultProvider, "default_fallback", 0.7, true), nil
+	// Step 3: Default provider as fallback
+	add(s.defaultProvider, "default_fallback", 0.7, true)
+
+	// Step 4: Any other healthy provider, in a stable order
+	names := make([]string, 0, len(s.providers))
+	for providerName := range s.providers {
+		names = append(names, providerName)
+	}
+	sort.Strings(names)
+	for _, providerName := range names {
+		add(providerName, "any_healthy", 0.5, true)
 	}
 
-	// Step 4: Try any available healthy provider
-	for providerName, provider := range s.providers {
-		if provider.IsHealthy {
-			s.logger.Printf("Using any available healthy provider: %s", providerName)
-			return s.createSelectionResult(provider, providerName, "any_healthy", 0.5, true), nil
-		}
+	if len(candidates) == 0 {
+		s.logger.Printf("All providers are unhealthy")
+		return nil, fmt.Errorf("no healthy providers available")
 	}
+	return candidates, nil
+}
 
-	// Step 5: All providers are unhealthy, return error
-	s.logger.Printf("All providers are unhealthy")
-	return nil, fmt.Errorf("no healthy providers available")
+// RecordResult records the outcome of a request served by a provider. A
+// failed request marks the provider unhealthy until its next successful
+// health check, so that following requests fail over immediately instead of
+// waiting for the check interval.
+func (s *ModelSelector) RecordResult(providerName string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	provider, exists := s.providers[providerName]
+	if !exists {
+		return
+	}
+	provider.RequestCount++
+	if err != nil {
+		provider.FailureCount++
+		provider.IsHealthy = false
+		provider.LastError = err
+		s.logger.Printf("Provider %s marked unhealthy after request failure: %v", providerName, err)
+	}
 }
 
 // GetProviderHealth returns health status for all providers
@@ -257,17 +305,8 @@ func (s *ModelSelector) SetDefaultProvider(providerName string) error {
 
 // ForceHealthCheck performs an immediate health check on all providers
 func (s *ModelSelector) ForceHealthCheck(ctx context.Context) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	s.logger.Printf("Performing forced health check on all providers")
-
-	for name, provider := range s.providers {
-		if err := s.checkProviderHealth(ctx, name, provider); err != nil {
-			s.logger.Printf("Health check failed for %s: %v", name, err)
-		}
-	}
-
+	s.checkAllProviders(ctx)
 	return nil
 }
 
@@ -335,12 +374,22 @@ func (s *ModelSelector) startHealthChecker() {
 
 // performHealthCheck performs health checks on all providers
 func (s *ModelSelector) performHealthCheck(ctx context.Context) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	s.logger.Printf("Performing periodic health check")
+	s.checkAllProviders(ctx)
+}
 
+// checkAllProviders checks every provider's health. Connections are
+// validated without holding the lock so that a slow provider does not block
+// model selection for in-flight requests.
+func (s *ModelSelector) checkAllProviders(ctx context.Context) {
+	s.mu.RLock()
+	providers := make(map[string]*ProviderInfo, len(s.providers))
 	for name, provider := range s.providers {
+		providers[name] = provider
+	}
+	s.mu.RUnlock()
+
+	for name, provider := range providers {
 		if err := s.checkProviderHealth(ctx, name, provider); err != nil {
 			s.logger.Printf("Health check failed for %s: %v", name, err)
 		}
@@ -352,10 +401,14 @@ func (s *ModelSelector) checkProviderHealth(ctx context.Context, name string, pr
 	start := time.Now()
 
 	err := provider.Provider.ValidateConnection()
+	responseTime := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	provider.HealthCheckCount++
 	provider.LastHealthCheck = time.Now()
-	provider.ResponseTime = time.Since(start)
+	provider.ResponseTime = responseTime
 
 	if err != nil {
 		provider.IsHealthy = false
@@ -389,11 +442,15 @@ func (s *ModelSelector) createSelectionResult(provider *ProviderInfo, name, reas
 
 // calculateSuccessRate calculates the success rate for a provider
 func (s *ModelSelector) calculateSuccessRate(provider *ProviderInfo) float64 {
+	// Prefer the outcome of real requests once there are any
+	if provider.RequestCount > 0 {
+		return float64(provider.RequestCount-provider.FailureCount) / float64(provider.RequestCount)
+	}
+
 	if provider.HealthCheckCount == 0 {
 		return 0.0
 	}
 
-	// Simple calculation - can be enhanced with more sophisticated metrics
 	if provider.IsHealthy {
 		return 1.0
 	}
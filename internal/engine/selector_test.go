@@ -570,3 +570,87 @@ func TestModelSelector_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestModelSelector_RankProviders(t *testing.T) {
+	factory := NewMockProviderFactory()
+	factory.AddMockProvider("claude", false, 0) // Unhealthy
+	factory.AddMockProvider("openai", true, 0)
+	factory.AddMockProvider("generic", true, 0)
+
+	selector := NewModelSelector(factory, &SelectorConfig{
+		DefaultProvider:     "claude",
+		Preferences:         []string{"claude", "openai"},
+		HealthCheckInterval: time.Hour,
+		HealthCheckTimeout:  time.Second,
+	})
+	defer selector.Stop()
+	if err := selector.ForceHealthCheck(context.Background()); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+
+	candidates, err := selector.RankProviders(context.Background(), &SelectionRequest{})
+	if err != nil {
+		t.Fatalf("RankProviders failed: %v", err)
+	}
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.ProviderName)
+	}
+	if len(names) != 2 || names[0] != "openai" || names[1] != "generic" {
+		t.Fatalf("Expected [openai generic], got %v", names)
+	}
+	if candidates[0].FallbackUsed {
+		t.Error("Expected first candidate not to be a fallback")
+	}
+	if !candidates[1].FallbackUsed || candidates[1].Reason != "any_healthy" {
+		t.Errorf("Expected second candidate to be an any_healthy fallback, got %+v", candidates[1])
+	}
+}
+
+func TestModelSelector_RecordResult(t *testing.T) {
+	factory := NewMockProviderFactory()
+	factory.AddMockProvider("claude", true, 0)
+	factory.AddMockProvider("openai", true, 0)
+
+	selector := NewModelSelector(factory, &SelectorConfig{
+		DefaultProvider:     "claude",
+		Preferences:         []string{"claude", "openai"},
+		HealthCheckInterval: time.Hour,
+		HealthCheckTimeout:  time.Second,
+	})
+	defer selector.Stop()
+	if err := selector.ForceHealthCheck(context.Background()); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+
+	selector.RecordResult("claude", nil)
+	selector.RecordResult("claude", errors.NewProviderError("overloaded", "test", "anthropic", 529, "", true))
+
+	result, err := selector.SelectModel(context.Background(), &SelectionRequest{})
+	if err != nil {
+		t.Fatalf("SelectModel failed: %v", err)
+	}
+	if result.ProviderName != "openai" {
+		t.Errorf("Expected failed provider to be skipped, got %s", result.ProviderName)
+	}
+
+	health := selector.GetProviderHealth()["claude"]
+	if health.IsHealthy {
+		t.Error("Expected claude to be unhealthy after a failed request")
+	}
+	if health.SuccessRate != 0.5 {
+		t.Errorf("Expected success rate 0.5, got %f", health.SuccessRate)
+	}
+
+	// The next successful health check restores the provider
+	if err := selector.ForceHealthCheck(context.Background()); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+	result, err = selector.SelectModel(context.Background(), &SelectionRequest{})
+	if err != nil {
+		t.Fatalf("SelectModel failed: %v", err)
+	}
+	if result.ProviderName != "claude" {
+		t.Errorf("Expected claude after recovery, got %s", result.ProviderName)
+	}
+}
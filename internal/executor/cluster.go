@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// DefaultClusterTimeout bounds a single `oc adm node-logs | jq` execution.
+const DefaultClusterTimeout = 2 * time.Minute
+
+// DefaultMaxOutputSize bounds the jq output captured from a cluster execution.
+const DefaultMaxOutputSize = 10 * 1024 * 1024
+
+// ClusterRunner executes generated commands against a live cluster using the
+// oc and jq binaries. Only the argument vectors of a types.GeneratedCommand
+// are executed; the shell string in Command is never interpreted, so nothing
+// outside `oc adm node-logs` and jq can run.
+type ClusterRunner struct {
+	// ocPath and jqPath are the binaries to execute
+	ocPath string
+	jqPath string
+
+	// timeout kills the pipeline when exceeded
+	timeout time.Duration
+
+	// maxOutput bounds the captured output size
+	maxOutput int64
+}
+
+// NewClusterRunner creates a ClusterRunner using oc and jq from PATH.
+func NewClusterRunner() *ClusterRunner {
+	return &ClusterRunner{
+		ocPath:    "oc",
+		jqPath:    "jq",
+		timeout:   DefaultClusterTimeout,
+		maxOutput: DefaultMaxOutputSize,
+	}
+}
+
+// SetBinaries overrides the oc and jq binaries.
+func (r *ClusterRunner) SetBinaries(ocPath, jqPath string) {
+	if ocPath != "" {
+		r.ocPath = ocPath
+	}
+	if jqPath != "" {
+		r.jqPath = jqPath
+	}
+}
+
+// SetTimeout sets the maximum duration of a single execution.
+func (r *ClusterRunner) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+}
+
+// Run executes the command's oc invocation piped into its jq program and
+// returns jq's output. The output is truncated at the configured maximum.
+func (r *ClusterRunner) Run(ctx context.Context, cmd *types.GeneratedCommand) (string, bool, error) {
+	if err := checkReadOnly(cmd); err != nil {
+		return "", false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	oc := exec.CommandContext(ctx, r.ocPath, cmd.NodeLogsArgs...)
+	jq := exec.CommandContext(ctx, r.jqPath, append(append([]string{}, cmd.JQArgs...), cmd.JQFilter)...)
+
+	pipe, err := oc.StdoutPipe()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create pipe: %w", err)
+	}
+	jq.Stdin = pipe
+	var ocErr, jqErr bytes.Buffer
+	oc.Stderr = &ocErr
+	jq.Stderr = &jqErr
+	out := &limitedBuffer{limit: r.maxOutput}
+	jq.Stdout = out
+
+	if err := jq.Start(); err != nil {
+		return "", false, fmt.Errorf("failed to start jq: %w", err)
+	}
+	if err := oc.Start(); err != nil {
+		_ = jq.Process.Kill()
+		_ = jq.Wait()
+		return "", false, fmt.Errorf("failed to start oc: %w", err)
+	}
+	ocWaitErr := oc.Wait()
+	jqWaitErr := jq.Wait()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return out.String(), out.truncated, fmt.Errorf("audit query timed out after %s", r.timeout)
+	}
+	if ocWaitErr != nil {
+		return "", false, fmt.Errorf("oc adm node-logs failed: %v: %s", ocWaitErr, strings.TrimSpace(ocErr.String()))
+	}
+	if jqWaitErr != nil {
+		return "", false, fmt.Errorf("jq failed: %v: %s", jqWaitErr, strings.TrimSpace(jqErr.String()))
+	}
+	return out.String(), out.truncated, nil
+}
+
+// checkReadOnly enforces that only `oc adm node-logs` is invoked.
+func checkReadOnly(cmd *types.GeneratedCommand) error {
+	if cmd == nil {
+		return fmt.Errorf("command cannot be nil")
+	}
+	if len(cmd.NodeLogsArgs) < 2 || cmd.NodeLogsArgs[0] != "adm" || cmd.NodeLogsArgs[1] != "node-logs" {
+		return fmt.Errorf("only 'oc adm node-logs' commands may be executed")
+	}
+	for _, arg := range cmd.NodeLogsArgs[2:] {
+		if !strings.HasPrefix(arg, "--role=") && !strings.HasPrefix(arg, "--path=") {
+			return fmt.Errorf("unexpected oc argument '%s'", arg)
+		}
+	}
+	if strings.TrimSpace(cmd.JQFilter) == "" {
+		return fmt.Errorf("jq filter cannot be empty")
+	}
+	return nil
+}
+
+// limitedBuffer keeps at most limit bytes and discards the rest, recording
+// that truncation happened. It never returns an error so the writer keeps
+// draining the pipe.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - int64(b.buf.Len())
+	if remaining <= 0 {
+		b.truncated = true
+		return len(p), nil
+	}
+	if int64(len(p)) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
+
+var _ io.Writer = (*limitedBuffer)(nil)
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestClusterRunner_RejectsNonNodeLogsCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *types.GeneratedCommand
+		want string
+	}{
+		{"nil command", nil, "cannot be nil"},
+		{"other oc verb", &types.GeneratedCommand{NodeLogsArgs: []string{"delete", "pods", "--all"}, JQFilter: "."}, "only 'oc adm node-logs'"},
+		{"extra argument", &types.GeneratedCommand{NodeLogsArgs: []string{"adm", "node-logs", "--role=master", "--kubeconfig=/tmp/x"}, JQFilter: "."}, "unexpected oc argument"},
+		{"empty filter", &types.GeneratedCommand{NodeLogsArgs: []string{"adm", "node-logs", "--role=master", "--path=kube-apiserver/audit.log"}}, "jq filter"},
+	}
+	r := NewClusterRunner()
+	r.SetBinaries("/nonexistent/oc", "/nonexistent/jq")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := r.Run(context.Background(), tt.cmd)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer_Truncates(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	if n, err := b.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatalf("unexpected write result %d %v", n, err)
+	}
+	if n, err := b.Write([]byte("defgh")); n != 5 || err != nil {
+		t.Fatalf("writes must report full length to keep draining, got %d %v", n, err)
+	}
+	if b.String() != "abcde" || !b.truncated {
+		t.Errorf("expected truncated 'abcde', got %q (truncated=%v)", b.String(), b.truncated)
+	}
+}
@@ -0,0 +1,346 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"genai-processing/internal/analysis"
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/types"
+)
+
+// StdinSource is the source name reported when events are read from stdin.
+const StdinSource = "stdin"
+
+// DefaultMaxLineSize bounds a single audit log line. Audit events that carry
+// request and response objects can be large, so this is well above bufio's default.
+const DefaultMaxLineSize = 4 * 1024 * 1024
+
+// Executor evaluates StructuredQuery values against Kubernetes audit.log JSONL
+// files on local disk (or stdin), without requiring access to a live cluster.
+//
+// Files are resolved from the configured log directory. For a query with
+// log_source "kube-apiserver" the executor reads every audit*.log and
+// audit*.log.gz file in <logDir>/kube-apiserver, oldest name first. When that
+// subdirectory does not exist, audit files directly under logDir are read.
+type Executor struct {
+	// logDir is the directory containing audit logs; "-" reads from stdin
+	logDir string
+
+	// stdin is the reader used when logDir is "-"
+	stdin io.Reader
+
+	// defaultLimit is used when the query does not set a limit
+	defaultLimit int
+
+	// maxLineSize bounds the size of a single audit log line
+	maxLineSize int
+
+	// now returns the reference time for resolving relative timeframes
+	now func() time.Time
+}
+
+// NewExecutor creates an Executor reading audit logs from logDir. Passing "-"
+// reads a single JSONL stream from os.Stdin instead.
+func NewExecutor(logDir string) *Executor {
+	return &Executor{
+		logDir:       logDir,
+		stdin:        os.Stdin,
+		defaultLimit: generator.DefaultLimit,
+		maxLineSize:  DefaultMaxLineSize,
+		now:          time.Now,
+	}
+}
+
+// SetClock overrides the reference time used to resolve relative timeframes.
+func (e *Executor) SetClock(now func() time.Time) {
+	if now != nil {
+		e.now = now
+	}
+}
+
+// SetDefaultLimit sets the limit applied when a query omits one.
+func (e *Executor) SetDefaultLimit(limit int) {
+	if limit > 0 {
+		e.defaultLimit = limit
+	}
+}
+
+// SetStdin overrides the reader used when the executor is configured for stdin.
+func (e *Executor) SetStdin(r io.Reader) {
+	if r != nil {
+		e.stdin = r
+	}
+}
+
+// LogDir returns the configured log directory.
+func (e *Executor) LogDir() string {
+	return e.logDir
+}
+
+// Execute evaluates the query against the audit logs for its log source.
+func (e *Executor) Execute(ctx context.Context, query *types.StructuredQuery) (*types.ExecutionResult, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+	if e.logDir == "-" {
+		return e.ExecuteReader(ctx, query, e.stdin, StdinSource)
+	}
+
+	files, err := e.ResolveFiles(query.LogSource)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	m, err := NewMatcher(query, e.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCollector(query, e.limit(query))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if err := e.scanFile(ctx, path, m, c); err != nil {
+			return nil, err
+		}
+	}
+	return e.finish(query, c, files, start), nil
+}
+
+// ExecuteReader evaluates the query against a single JSONL stream. The name
+// is reported in ExecutionResult.Sources.
+func (e *Executor) ExecuteReader(ctx context.Context, query *types.StructuredQuery, r io.Reader, name string) (*types.ExecutionResult, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+	if r == nil {
+		return nil, fmt.Errorf("reader cannot be nil")
+	}
+	start := time.Now()
+	m, err := NewMatcher(query, e.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCollector(query, e.limit(query))
+	if err != nil {
+		return nil, err
+	}
+	if err := e.scan(ctx, r, name, m, c); err != nil {
+		return nil, err
+	}
+	return e.finish(query, c, []string{name}, start), nil
+}
+
+// ResolveFiles returns the audit log files for a log source, sorted by name.
+func (e *Executor) ResolveFiles(logSource string) ([]string, error) {
+	logPath, ok := generator.LogSourcePath(logSource)
+	if !ok {
+		return nil, fmt.Errorf("unsupported log source '%s'", logSource)
+	}
+	if strings.TrimSpace(e.logDir) == "" {
+		return nil, fmt.Errorf("audit log directory is not configured")
+	}
+
+	dir := filepath.Join(e.logDir, filepath.Dir(logPath))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = e.logDir
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isAuditLogFile(entry.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no audit log files found in %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isAuditLogFile matches audit.log and rotated files such as
+// audit-2025-01-28T10-00-00.000.log, optionally gzip-compressed.
+func isAuditLogFile(name string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.HasPrefix(name, "audit") && strings.HasSuffix(name, ".log")
+}
+
+func (e *Executor) scanFile(ctx context.Context, path string, m *Matcher, c *collector) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to open compressed audit log %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return e.scan(ctx, r, path, m, c)
+}
+
+// scan decodes one audit event per line. As with the generated jq program,
+// any prefix before the first '{' (such as the node name emitted by
+// `oc adm node-logs --role`) is stripped, and undecodable lines are counted
+// as malformed rather than failing the execution.
+func (e *Executor) scan(ctx context.Context, r io.Reader, name string, m *Matcher, c *collector) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), e.maxLineSize)
+
+	for scanner.Scan() {
+		if c.scanned%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		line := scanner.Bytes()
+		idx := bytes.IndexByte(line, '{')
+		if idx < 0 {
+			if len(bytes.TrimSpace(line)) > 0 {
+				c.malformed++
+			}
+			continue
+		}
+
+		var ev types.AuditEvent
+		if err := json.Unmarshal(line[idx:], &ev); err != nil {
+			c.malformed++
+			continue
+		}
+		c.scanned++
+		if m.Match(&ev) {
+			c.add(ev)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// limit returns the query limit, or the default when the query omits one.
+func (e *Executor) limit(query *types.StructuredQuery) int {
+	if query.Limit > 0 {
+		return query.Limit
+	}
+	return e.defaultLimit
+}
+
+func (e *Executor) finish(query *types.StructuredQuery, c *collector, sources []string, start time.Time) *types.ExecutionResult {
+	limit := e.limit(query)
+
+	events := c.events
+	sortEvents(events, query.SortBy, query.SortOrder)
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	if !query.IncludeChanges {
+		for i := range events {
+			events[i].RequestObject = nil
+			events[i].ResponseObject = nil
+		}
+	}
+
+	result := &types.ExecutionResult{
+		Events:         events,
+		TotalScanned:   c.scanned,
+		TotalMatched:   c.matched,
+		Truncated:      c.matched > len(events),
+		MalformedLines: c.malformed,
+		Sources:        sources,
+		ExecutionTime:  time.Since(start),
+	}
+	if c.aggregator != nil {
+		result.Analysis = c.aggregator.Result()
+	}
+	return result
+}
+
+// collector accumulates matching events and scan statistics. When the
+// query is unsorted only the first keep events are retained, so memory stays
+// bounded by the limit rather than by the size of the logs.
+type collector struct {
+	events     []types.AuditEvent
+	aggregator *analysis.Aggregator
+	keep       int
+	matched    int
+	scanned    int
+	malformed  int
+}
+
+// newCollector creates a collector for the query. keep is zero (unbounded)
+// when events must be sorted before the limit is applied. Queries with
+// group_by or an analysis block also feed every match into an aggregator.
+func newCollector(query *types.StructuredQuery, limit int) (*collector, error) {
+	c := &collector{}
+	if strings.TrimSpace(query.SortBy) == "" {
+		c.keep = limit
+	}
+	agg, err := analysis.NewAggregator(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if agg.Active() {
+		c.aggregator = agg
+	}
+	return c, nil
+}
+
+func (c *collector) add(ev types.AuditEvent) {
+	c.matched++
+	if c.aggregator != nil {
+		c.aggregator.Add(&ev)
+	}
+	if c.keep > 0 && len(c.events) >= c.keep {
+		return
+	}
+	c.events = append(c.events, ev)
+}
+
+// sortEvents orders events by the query's sort field. Without a sort field the
+// read order is preserved. sort_by=count needs grouping and falls back to
+// timestamp order, matching the generated jq program.
+func sortEvents(events []types.AuditEvent, sortBy, sortOrder string) {
+	if strings.TrimSpace(sortBy) == "" {
+		return
+	}
+	field, ok := generator.CanonicalField(sortBy)
+	if !ok {
+		field = "timestamp"
+	}
+	desc := generator.SortDirection(sortBy, sortOrder) == "desc"
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := &events[i], &events[j]
+		if desc {
+			a, b = b, a
+		}
+		if field == "timestamp" {
+			return a.RequestReceivedTimestamp.Before(b.RequestReceivedTimestamp)
+		}
+		return a.Field(field) < b.Field(field)
+	})
+}
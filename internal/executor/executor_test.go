@@ -0,0 +1,294 @@
+package executor
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+var fixedNow = time.Date(2025, 1, 29, 14, 30, 0, 0, time.UTC)
+
+// auditLines mirror the generator fixtures: node-prefixed lines, plain JSON lines and one malformed line.
+var auditLines = []string{
+	`master-0 {"verb":"delete","user":{"username":"john.doe"},"sourceIPs":["10.0.0.5"],"objectRef":{"resource":"customresourcedefinitions","name":"customers.example.com"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-28T10:15:00.123456Z","annotations":{"authorization.k8s.io/decision":"allow"}}`,
+	`master-1 {"verb":"delete","user":{"username":"system:serviceaccount:kube-system:gc"},"sourceIPs":["10.0.0.9"],"objectRef":{"resource":"customresourcedefinitions","name":"customers.example.com"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-28T11:00:00.000000Z"}`,
+	`{"verb":"get","user":{"username":"jane.smith"},"sourceIPs":["10.0.0.7"],"objectRef":{"resource":"secrets","namespace":"prod"},"responseStatus":{"code":403,"message":"secrets is forbidden"},"requestReceivedTimestamp":"2025-01-29T02:00:00.000000Z","annotations":{"authorization.k8s.io/decision":"forbid","authorization.k8s.io/reason":"RBAC: access denied"}}`,
+	`{"verb":"get","user":{"username":"jane.smith"},"sourceIPs":["10.0.0.7"],"objectRef":{"resource":"secrets","namespace":"dev"},"responseStatus":{"code":403},"requestReceivedTimestamp":"2025-01-29T03:00:00.000000Z","requestObject":{"kind":"Secret"}}`,
+	`not a json line`,
+}
+
+func newTestExecutor(dir string) *Executor {
+	e := NewExecutor(dir)
+	e.SetClock(func() time.Time { return fixedNow })
+	return e
+}
+
+func executeLines(t *testing.T, q *types.StructuredQuery) *types.ExecutionResult {
+	t.Helper()
+	e := newTestExecutor("-")
+	e.SetStdin(strings.NewReader(strings.Join(auditLines, "\n") + "\n"))
+	res, err := e.Execute(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return res
+}
+
+func usernames(res *types.ExecutionResult) []string {
+	var out []string
+	for _, ev := range res.Events {
+		out = append(out, ev.User.Username)
+	}
+	return out
+}
+
+func TestExecute_Filters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *types.StructuredQuery
+		want  []string
+	}{
+		{
+			name: "verb resource exclude_users timeframe",
+			query: &types.StructuredQuery{
+				LogSource:           "kube-apiserver",
+				Verb:                *types.NewStringOrArray("delete"),
+				Resource:            *types.NewStringOrArray("customresourcedefinitions"),
+				ResourceNamePattern: "customer",
+				Timeframe:           "yesterday",
+				ExcludeUsers:        []string{"system:"},
+			},
+			want: []string{"john.doe"},
+		},
+		{
+			name: "namespace array",
+			query: &types.StructuredQuery{
+				LogSource: "kube-apiserver",
+				Namespace: *types.NewStringOrArray([]string{"prod", "staging"}),
+			},
+			want: []string{"jane.smith"},
+		},
+		{
+			name: "user pattern and response status class",
+			query: &types.StructuredQuery{
+				LogSource:      "kube-apiserver",
+				UserPattern:    "^jane",
+				ResponseStatus: *types.NewStringOrArray("4xx"),
+			},
+			want: []string{"jane.smith", "jane.smith"},
+		},
+		{
+			name: "auth decision",
+			query: &types.StructuredQuery{
+				LogSource:    "kube-apiserver",
+				AuthDecision: "forbid",
+			},
+			want: []string{"jane.smith"},
+		},
+		{
+			name: "source ip",
+			query: &types.StructuredQuery{
+				LogSource: "kube-apiserver",
+				SourceIP:  *types.NewStringOrArray([]string{"10.0.0.9", "10.0.0.5"}),
+			},
+			want: []string{"john.doe", "system:serviceaccount:kube-system:gc"},
+		},
+		{
+			name: "time range",
+			query: &types.StructuredQuery{
+				LogSource: "kube-apiserver",
+				TimeRange: &types.TimeRange{
+					Start: time.Date(2025, 1, 29, 2, 30, 0, 0, time.UTC),
+					End:   time.Date(2025, 1, 29, 4, 0, 0, 0, time.UTC),
+				},
+			},
+			want: []string{"jane.smith"},
+		},
+		{
+			name: "authorization reason and response message patterns",
+			query: &types.StructuredQuery{
+				LogSource:                  "kube-apiserver",
+				AuthorizationReasonPattern: "RBAC",
+				ResponseMessagePattern:     "forbidden",
+			},
+			want: []string{"jane.smith"},
+		},
+		{
+			name: "missing annotation",
+			query: &types.StructuredQuery{
+				LogSource:         "kube-apiserver",
+				Verb:              *types.NewStringOrArray("get"),
+				MissingAnnotation: "authorization.k8s.io/decision",
+			},
+			want: []string{"jane.smith"},
+		},
+		{
+			name: "business hours outside only",
+			query: &types.StructuredQuery{
+				LogSource:     "kube-apiserver",
+				BusinessHours: &types.BusinessHours{OutsideOnly: true, StartHour: 9, EndHour: 17},
+			},
+			want: []string{"jane.smith", "jane.smith"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := executeLines(t, tt.query)
+			got := usernames(res)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got users %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_Statistics(t *testing.T) {
+	res := executeLines(t, &types.StructuredQuery{LogSource: "kube-apiserver", Limit: 2})
+
+	if res.TotalScanned != 4 {
+		t.Errorf("TotalScanned = %d, want 4", res.TotalScanned)
+	}
+	if res.MalformedLines != 1 {
+		t.Errorf("MalformedLines = %d, want 1", res.MalformedLines)
+	}
+	if res.TotalMatched != 4 || len(res.Events) != 2 || !res.Truncated {
+		t.Errorf("expected 4 matched, 2 returned and truncated; got %d/%d/%v", res.TotalMatched, len(res.Events), res.Truncated)
+	}
+	if len(res.Sources) != 1 || res.Sources[0] != StdinSource {
+		t.Errorf("unexpected sources: %v", res.Sources)
+	}
+}
+
+func TestExecute_SortAndIncludeChanges(t *testing.T) {
+	res := executeLines(t, &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		SortBy:    "timestamp",
+		Limit:     1,
+	})
+	if len(res.Events) != 1 || res.Events[0].Field("namespace") != "dev" {
+		t.Fatalf("expected the most recent event first, got %+v", res.Events)
+	}
+	if res.Events[0].RequestObject != nil {
+		t.Error("request object should be dropped unless include_changes is set")
+	}
+
+	res = executeLines(t, &types.StructuredQuery{
+		LogSource:      "kube-apiserver",
+		SortBy:         "user",
+		SortOrder:      "asc",
+		IncludeChanges: true,
+	})
+	got := usernames(res)
+	want := []string{"jane.smith", "jane.smith", "john.doe", "system:serviceaccount:kube-system:gc"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if res.Events[1].RequestObject == nil {
+		t.Error("request object should be kept when include_changes is set")
+	}
+}
+
+func TestExecute_Directory(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "kube-apiserver")
+	if err := os.MkdirAll(sourceDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "audit.log"), []byte(strings.Join(auditLines[:2], "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(sourceDir, "audit-2025-01-28T00-00-00.000.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(strings.Join(auditLines[2:], "\n") + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	f.Close()
+	if err := os.WriteFile(filepath.Join(sourceDir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := newTestExecutor(dir)
+	res, err := e.Execute(context.Background(), &types.StructuredQuery{LogSource: "kube-apiserver", Verb: *types.NewStringOrArray("get")})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(res.Sources) != 2 {
+		t.Errorf("expected 2 sources, got %v", res.Sources)
+	}
+	if res.TotalMatched != 2 || res.TotalScanned != 4 {
+		t.Errorf("expected 2 matched of 4 scanned, got %d of %d", res.TotalMatched, res.TotalScanned)
+	}
+
+	if _, err := e.Execute(context.Background(), &types.StructuredQuery{LogSource: "oauth-server"}); err == nil {
+		t.Error("expected error when no audit logs exist for the log source")
+	}
+}
+
+func TestExecute_Errors(t *testing.T) {
+	e := newTestExecutor(t.TempDir())
+	tests := []struct {
+		name  string
+		query *types.StructuredQuery
+	}{
+		{"nil query", nil},
+		{"unsupported log source", &types.StructuredQuery{LogSource: "node-journal"}},
+		{"no audit files", &types.StructuredQuery{LogSource: "kube-apiserver"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := e.Execute(context.Background(), tt.query); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := e.ExecuteReader(context.Background(), &types.StructuredQuery{LogSource: "kube-apiserver", UserPattern: "("}, strings.NewReader(""), "test"); err == nil {
+		t.Error("expected error for invalid user_pattern")
+	}
+}
+
+func TestExecute_ContextCanceled(t *testing.T) {
+	e := newTestExecutor("-")
+	e.SetStdin(strings.NewReader(strings.Join(auditLines, "\n")))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Execute(ctx, &types.StructuredQuery{LogSource: "kube-apiserver"}); err == nil {
+		t.Error("expected context cancellation error")
+	}
+}
+
+func TestExecute_GroupByAnalysis(t *testing.T) {
+	res := executeLines(t, &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		GroupBy:   *types.NewStringOrArray("user"),
+		Limit:     1,
+	})
+	if res.Analysis == nil {
+		t.Fatal("expected analysis result for group_by query")
+	}
+	if res.Analysis.TotalGroups != 3 || len(res.Analysis.Groups) != 1 {
+		t.Fatalf("expected top 1 of 3 groups, got %+v", res.Analysis)
+	}
+	if g := res.Analysis.Groups[0]; g.Key["user"] != "jane.smith" || g.Count != 2 {
+		t.Errorf("unexpected top group: %+v", g)
+	}
+	if res.Analysis.EventsAnalyzed != res.TotalMatched {
+		t.Errorf("all matched events should be analyzed, got %d of %d", res.Analysis.EventsAnalyzed, res.TotalMatched)
+	}
+
+	res = executeLines(t, &types.StructuredQuery{LogSource: "kube-apiserver"})
+	if res.Analysis != nil {
+		t.Error("no analysis expected without group_by or analysis")
+	}
+}
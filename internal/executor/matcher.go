@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/types"
+)
+
+// Matcher evaluates a StructuredQuery's filters against individual audit events.
+// Its semantics mirror the jq program produced by generator.CommandGenerator so
+// that local execution and `oc adm node-logs` pipelines return the same events.
+type Matcher struct {
+	verbs            map[string]bool
+	resources        map[string]bool
+	namespaces       map[string]bool
+	users            map[string]bool
+	sourceIPs        map[string]bool
+	excludeUsers     []string
+	excludeResources []string
+
+	userPattern         *regexp.Regexp
+	namespacePattern    *regexp.Regexp
+	resourceNamePattern *regexp.Regexp
+	requestURIPattern   *regexp.Regexp
+	authReasonPattern   *regexp.Regexp
+	responseMsgPattern  *regexp.Regexp
+
+	statusMatchers      []generator.StatusMatcher
+	authDecision        string
+	subresource         string
+	requestObjectFilter []byte
+	missingAnnotation   string
+
+	window        *generator.TimeWindow
+	businessHours *types.BusinessHours
+}
+
+// NewMatcher compiles the filters of a query. Relative timeframes are
+// resolved against now.
+func NewMatcher(query *types.StructuredQuery, now time.Time) (*Matcher, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+
+	m := &Matcher{
+		verbs:             toSet(query.Verb.Values(), true),
+		resources:         toSet(query.Resource.Values(), true),
+		namespaces:        toSet(query.Namespace.Values(), false),
+		users:             toSet(query.User.Values(), false),
+		sourceIPs:         toSet(query.SourceIP.Values(), false),
+		excludeUsers:      nonEmpty(query.ExcludeUsers),
+		excludeResources:  nonEmpty(query.ExcludeResources),
+		authDecision:      strings.ToLower(strings.TrimSpace(query.AuthDecision)),
+		subresource:       strings.TrimSpace(query.Subresource),
+		missingAnnotation: strings.TrimSpace(query.MissingAnnotation),
+		businessHours:     query.BusinessHours,
+	}
+	if f := strings.TrimSpace(query.RequestObjectFilter); f != "" {
+		m.requestObjectFilter = []byte(f)
+	}
+
+	patterns := []struct {
+		name    string
+		pattern string
+		target  **regexp.Regexp
+	}{
+		{"user_pattern", query.UserPattern, &m.userPattern},
+		{"namespace_pattern", query.NamespacePattern, &m.namespacePattern},
+		{"resource_name_pattern", query.ResourceNamePattern, &m.resourceNamePattern},
+		{"request_uri_pattern", query.RequestURIPattern, &m.requestURIPattern},
+		{"authorization_reason_pattern", query.AuthorizationReasonPattern, &m.authReasonPattern},
+		{"response_message_pattern", query.ResponseMessagePattern, &m.responseMsgPattern},
+	}
+	for _, p := range patterns {
+		if strings.TrimSpace(p.pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", p.name, p.pattern, err)
+		}
+		*p.target = re
+	}
+
+	for _, v := range query.ResponseStatus.Values() {
+		sm, err := generator.ParseStatusMatcher(v)
+		if err != nil {
+			return nil, err
+		}
+		m.statusMatchers = append(m.statusMatchers, sm)
+	}
+
+	window, err := generator.ResolveTimeWindow(query, now)
+	if err != nil {
+		return nil, err
+	}
+	m.window = window
+
+	if m.businessHours != nil {
+		// Surface timezone errors at compile time rather than per event
+		if _, err := generator.InBusinessHours(m.businessHours, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Match reports whether the event satisfies every filter in the query.
+func (m *Matcher) Match(ev *types.AuditEvent) bool {
+	if len(m.verbs) > 0 && !m.verbs[strings.ToLower(ev.Verb)] {
+		return false
+	}
+	if len(m.resources) > 0 && !m.resources[strings.ToLower(ev.Field("resource"))] {
+		return false
+	}
+	if len(m.namespaces) > 0 && !m.namespaces[ev.Field("namespace")] {
+		return false
+	}
+	if len(m.users) > 0 && !m.users[ev.User.Username] {
+		return false
+	}
+	for _, prefix := range m.excludeUsers {
+		if strings.HasPrefix(ev.User.Username, prefix) {
+			return false
+		}
+	}
+	for _, prefix := range m.excludeResources {
+		if strings.HasPrefix(ev.Field("resource"), prefix) {
+			return false
+		}
+	}
+
+	if !matchPattern(m.userPattern, ev.User.Username) ||
+		!matchPattern(m.namespacePattern, ev.Field("namespace")) ||
+		!matchPattern(m.resourceNamePattern, ev.Field("resource_name")) ||
+		!matchPattern(m.requestURIPattern, ev.RequestURI) ||
+		!matchPattern(m.authReasonPattern, ev.Annotations[types.AnnotationAuthorizationReason]) {
+		return false
+	}
+	if m.responseMsgPattern != nil {
+		msg := ""
+		if ev.ResponseStatus != nil {
+			msg = ev.ResponseStatus.Message
+		}
+		if !m.responseMsgPattern.MatchString(msg) {
+			return false
+		}
+	}
+
+	if len(m.statusMatchers) > 0 {
+		code := 0
+		if ev.ResponseStatus != nil {
+			code = ev.ResponseStatus.Code
+		}
+		matched := false
+		for _, sm := range m.statusMatchers {
+			if sm.Matches(code) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if m.authDecision != "" && ev.Annotations[types.AnnotationAuthorizationDecision] != m.authDecision {
+		return false
+	}
+	if len(m.sourceIPs) > 0 {
+		matched := false
+		for _, ip := range ev.SourceIPs {
+			if m.sourceIPs[ip] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if m.subresource != "" && ev.Field("subresource") != m.subresource {
+		return false
+	}
+	if m.requestObjectFilter != nil && !bytes.Contains(ev.RequestObject, m.requestObjectFilter) {
+		return false
+	}
+	if m.missingAnnotation != "" {
+		if _, ok := ev.Annotations[m.missingAnnotation]; ok {
+			return false
+		}
+	}
+
+	if m.window != nil {
+		if ev.RequestReceivedTimestamp.IsZero() || !m.window.Contains(ev.RequestReceivedTimestamp) {
+			return false
+		}
+	}
+	if m.businessHours != nil {
+		if ev.RequestReceivedTimestamp.IsZero() {
+			return false
+		}
+		ok, err := generator.InBusinessHours(m.businessHours, ev.RequestReceivedTimestamp)
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchPattern returns true when no pattern is set or the value matches it.
+func matchPattern(re *regexp.Regexp, value string) bool {
+	return re == nil || re.MatchString(value)
+}
+
+func toSet(values []string, lower bool) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if lower {
+			v = strings.ToLower(v)
+		}
+		set[v] = true
+	}
+	return set
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,385 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// DefaultLimit is applied when a query does not specify a limit, matching
+// timeframe_limits.default_limit in rules.yaml.
+const DefaultLimit = 20
+
+// logSourcePaths maps supported log sources to their node-relative audit log path.
+var logSourcePaths = map[string]string{
+	"kube-apiserver":      "kube-apiserver/audit.log",
+	"openshift-apiserver": "openshift-apiserver/audit.log",
+	"oauth-server":        "oauth-server/audit.log",
+	"oauth-apiserver":     "oauth-apiserver/audit.log",
+}
+
+// LogSourcePath returns the node-relative audit log path for a supported log
+// source, and false if the log source is not supported.
+func LogSourcePath(logSource string) (string, bool) {
+	path, ok := logSourcePaths[strings.ToLower(strings.TrimSpace(logSource))]
+	return path, ok
+}
+
+// CommandGenerator compiles StructuredQuery values into `oc adm node-logs`
+// invocations piped into a jq program. Compilation is deterministic: the
+// same query and clock always produce the same command.
+type CommandGenerator struct {
+	// nodeRole is the node role passed to `oc adm node-logs --role`
+	nodeRole string
+
+	// defaultLimit is used when the query does not set a limit
+	defaultLimit int
+
+	// now returns the reference time for resolving relative timeframes
+	now func() time.Time
+}
+
+// NewCommandGenerator creates a CommandGenerator targeting master nodes.
+func NewCommandGenerator() *CommandGenerator {
+	return &CommandGenerator{
+		nodeRole:     "master",
+		defaultLimit: DefaultLimit,
+		now:          time.Now,
+	}
+}
+
+// SetClock overrides the reference time used to resolve relative timeframes.
+func (g *CommandGenerator) SetClock(now func() time.Time) {
+	if now != nil {
+		g.now = now
+	}
+}
+
+// SetDefaultLimit sets the limit applied when a query omits one.
+func (g *CommandGenerator) SetDefaultLimit(limit int) {
+	if limit > 0 {
+		g.defaultLimit = limit
+	}
+}
+
+// Generate compiles the structured query into an executable command.
+func (g *CommandGenerator) Generate(query *types.StructuredQuery) (*types.GeneratedCommand, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+
+	logPath, ok := LogSourcePath(query.LogSource)
+	if !ok {
+		return nil, fmt.Errorf("unsupported log source '%s'", query.LogSource)
+	}
+
+	now := g.now().UTC()
+	window, err := ResolveTimeWindow(query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := g.compileFilter(query, window, now)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeLogsArgs := []string{"adm", "node-logs", "--role=" + g.nodeRole, "--path=" + logPath}
+	jqArgs := []string{"-c", "-R", "-n"}
+
+	cmd := &types.GeneratedCommand{
+		Command:      fmt.Sprintf("oc %s | jq %s %s", strings.Join(nodeLogsArgs, " "), strings.Join(jqArgs, " "), shellQuote(filter)),
+		LogPath:      logPath,
+		NodeLogsArgs: nodeLogsArgs,
+		JQArgs:       jqArgs,
+		JQFilter:     filter,
+	}
+	if window != nil {
+		tr := &types.TimeRange{Start: window.Start, End: window.End}
+		if tr.End.IsZero() {
+			tr.End = now
+		}
+		cmd.TimeWindow = tr
+	}
+	return cmd, nil
+}
+
+// compileFilter builds the jq program. Input is read raw (-R) so that node
+// name prefixes emitted by `oc adm node-logs --role` are stripped before each
+// line is decoded; lines that are not JSON are skipped.
+func (g *CommandGenerator) compileFilter(query *types.StructuredQuery, window *TimeWindow, now time.Time) (string, error) {
+	conditions, err := g.compileConditions(query, window, now)
+	if err != nil {
+		return "", err
+	}
+
+	events := `inputs | sub("^[^{]*"; "") | fromjson?`
+	if len(conditions) > 0 {
+		events += " | select(" + strings.Join(conditions, " and ") + ")"
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = g.defaultLimit
+	}
+
+	groupFields, err := canonicalFields(query.GroupBy.Values())
+	if err != nil {
+		return "", err
+	}
+
+	if len(groupFields) > 0 {
+		return g.compileGrouped(events, groupFields, query, limit), nil
+	}
+
+	projection := ""
+	if !query.IncludeChanges {
+		projection = " | del(.requestObject, .responseObject)"
+	}
+
+	if query.SortBy == "" {
+		return fmt.Sprintf("limit(%d; %s)%s", limit, events, projection), nil
+	}
+
+	// Counting requires grouping, so sort_by=count falls back to timestamp order
+	field, ok := CanonicalField(query.SortBy)
+	if !ok {
+		field = "timestamp"
+	}
+	order := SortDirection(query.SortBy, query.SortOrder)
+	program := fmt.Sprintf("[%s] | sort_by(%s)", events, jqFieldPaths[field])
+	if order == "desc" {
+		program += " | reverse"
+	}
+	return fmt.Sprintf("%s | .[:%d] | .[]%s", program, limit, projection), nil
+}
+
+// compileGrouped builds an aggregation program producing one object per group
+// with the group keys, event count and first/last seen timestamps.
+func (g *CommandGenerator) compileGrouped(events string, fields []string, query *types.StructuredQuery, limit int) string {
+	keys := make([]string, 0, len(fields))
+	projections := make([]string, 0, len(fields)+3)
+	for _, f := range fields {
+		keys = append(keys, jqFieldPaths[f])
+		projections = append(projections, fmt.Sprintf("%s: .[0]%s", f, jqFieldPaths[f]))
+	}
+	projections = append(projections,
+		"count: length",
+		"first_seen: (map(.requestReceivedTimestamp) | min)",
+		"last_seen: (map(.requestReceivedTimestamp) | max)",
+	)
+
+	sortKey := ".count"
+	if field, ok := CanonicalField(query.SortBy); ok {
+		switch {
+		case field == "timestamp":
+			sortKey = ".last_seen"
+		case contains(fields, field):
+			sortKey = "." + field
+		}
+	}
+	sortField := query.SortBy
+	if sortField == "" {
+		sortField = "count"
+	}
+
+	program := fmt.Sprintf("[%s] | group_by([%s]) | map({%s}) | sort_by(%s)",
+		events, strings.Join(keys, ", "), strings.Join(projections, ", "), sortKey)
+	if SortDirection(sortField, query.SortOrder) == "desc" {
+		program += " | reverse"
+	}
+	return fmt.Sprintf("%s | .[:%d] | .[]", program, limit)
+}
+
+// compileConditions translates each populated filter field into a jq boolean expression.
+func (g *CommandGenerator) compileConditions(query *types.StructuredQuery, window *TimeWindow, now time.Time) ([]string, error) {
+	var conds []string
+
+	if vals := lowerAll(query.Verb.Values()); len(vals) > 0 {
+		conds = append(conds, anyEquals(".verb", vals))
+	}
+	if vals := lowerAll(query.Resource.Values()); len(vals) > 0 {
+		conds = append(conds, anyEquals(".objectRef.resource", vals))
+	}
+	if vals := query.Namespace.Values(); len(vals) > 0 {
+		conds = append(conds, anyEquals(".objectRef.namespace", vals))
+	}
+	if vals := query.User.Values(); len(vals) > 0 {
+		conds = append(conds, anyEquals(".user.username", vals))
+	}
+	for _, prefix := range query.ExcludeUsers {
+		if p := strings.TrimSpace(prefix); p != "" {
+			conds = append(conds, fmt.Sprintf(`((.user.username // "") | startswith(%s) | not)`, jqString(p)))
+		}
+	}
+	for _, prefix := range query.ExcludeResources {
+		if p := strings.TrimSpace(prefix); p != "" {
+			conds = append(conds, fmt.Sprintf(`((.objectRef.resource // "") | startswith(%s) | not)`, jqString(p)))
+		}
+	}
+
+	patterns := []struct {
+		path    string
+		pattern string
+	}{
+		{".user.username", query.UserPattern},
+		{".objectRef.namespace", query.NamespacePattern},
+		{".objectRef.name", query.ResourceNamePattern},
+		{".requestURI", query.RequestURIPattern},
+		{`.annotations["authorization.k8s.io/reason"]`, query.AuthorizationReasonPattern},
+		{".responseStatus.message", query.ResponseMessagePattern},
+	}
+	for _, p := range patterns {
+		if strings.TrimSpace(p.pattern) != "" {
+			conds = append(conds, fmt.Sprintf(`((%s // "") | test(%s))`, p.path, jqString(p.pattern)))
+		}
+	}
+
+	if vals := query.ResponseStatus.Values(); len(vals) > 0 {
+		var parts []string
+		for _, v := range vals {
+			m, err := ParseStatusMatcher(v)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, m.jqCondition())
+		}
+		conds = append(conds, "("+strings.Join(parts, " or ")+")")
+	}
+
+	if d := strings.TrimSpace(query.AuthDecision); d != "" {
+		conds = append(conds, fmt.Sprintf(`(.annotations["authorization.k8s.io/decision"] == %s)`, jqString(strings.ToLower(d))))
+	}
+	if vals := query.SourceIP.Values(); len(vals) > 0 {
+		conds = append(conds, fmt.Sprintf("((.sourceIPs // []) | any(%s))", anyEquals(".", vals)))
+	}
+	if s := strings.TrimSpace(query.Subresource); s != "" {
+		conds = append(conds, fmt.Sprintf("(.objectRef.subresource == %s)", jqString(s)))
+	}
+	if f := strings.TrimSpace(query.RequestObjectFilter); f != "" {
+		conds = append(conds, fmt.Sprintf("((.requestObject // {}) | tojson | contains(%s))", jqString(f)))
+	}
+	if a := strings.TrimSpace(query.MissingAnnotation); a != "" {
+		conds = append(conds, fmt.Sprintf("((.annotations // {}) | has(%s) | not)", jqString(a)))
+	}
+
+	if window != nil {
+		ts := `(.requestReceivedTimestamp // "")[0:19]`
+		if !window.Start.IsZero() {
+			conds = append(conds, fmt.Sprintf("(%s >= %s)", ts, jqString(window.Start.UTC().Format("2006-01-02T15:04:05"))))
+		}
+		if !window.End.IsZero() {
+			conds = append(conds, fmt.Sprintf("(%s < %s)", ts, jqString(window.End.UTC().Format("2006-01-02T15:04:05"))))
+		}
+	}
+
+	if query.BusinessHours != nil {
+		cond, err := businessHoursCondition(query.BusinessHours, now)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+
+	return conds, nil
+}
+
+// businessHoursCondition renders the business hours filter. jq has no
+// timezone database, so the zone's UTC offset at generation time is used.
+func businessHoursCondition(bh *types.BusinessHours, now time.Time) (string, error) {
+	start, end, loc, err := businessHoursBounds(bh)
+	if err != nil {
+		return "", err
+	}
+	_, offsetSeconds := now.In(loc).Zone()
+
+	hour := fmt.Sprintf(`((((.requestReceivedTimestamp[11:13] | tonumber) * 60 + (.requestReceivedTimestamp[14:16] | tonumber) + %d + 1440) %% 1440) / 60 | floor)`, offsetSeconds/60)
+	inside := fmt.Sprintf("($h >= %d and $h < %d)", start, end)
+	if start > end {
+		inside = fmt.Sprintf("($h >= %d or $h < %d)", start, end)
+	}
+	if bh.OutsideOnly {
+		inside += " | not"
+	}
+	return fmt.Sprintf("(try (%s as $h | %s) catch false)", hour, inside), nil
+}
+
+// SortDirection returns the explicit order or the default for the field:
+// descending for count and timestamp, ascending otherwise.
+func SortDirection(field, order string) string {
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "asc":
+		return "asc"
+	case "desc":
+		return "desc"
+	}
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "count", "timestamp":
+		return "desc"
+	}
+	return "asc"
+}
+
+// canonicalFields maps group_by values to canonical field names, rejecting
+// unknown fields and removing duplicates while preserving order.
+func canonicalFields(names []string) ([]string, error) {
+	var out []string
+	for _, n := range names {
+		f, ok := CanonicalField(n)
+		if !ok || f == "timestamp" {
+			return nil, fmt.Errorf("unsupported group_by field '%s'", n)
+		}
+		if !contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// anyEquals renders a disjunction comparing path against each value.
+func anyEquals(path string, values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	parts := make([]string, 0, len(sorted))
+	for _, v := range sorted {
+		parts = append(parts, fmt.Sprintf("%s == %s", path, jqString(v)))
+	}
+	return "(" + strings.Join(parts, " or ") + ")"
+}
+
+// jqString encodes s as a jq string literal. jq string literals share JSON's
+// escaping rules.
+func jqString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, strings.ToLower(v))
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+var fixedNow = time.Date(2025, 1, 29, 14, 30, 0, 0, time.UTC)
+
+// auditLines are node-logs style lines: some prefixed with the node name, one not JSON.
+var auditLines = []string{
+	`master-0 {"verb":"delete","user":{"username":"john.doe"},"sourceIPs":["10.0.0.5"],"objectRef":{"resource":"customresourcedefinitions","name":"customers.example.com"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-28T10:15:00.123456Z","annotations":{"authorization.k8s.io/decision":"allow"}}`,
+	`master-1 {"verb":"delete","user":{"username":"system:serviceaccount:kube-system:gc"},"sourceIPs":["10.0.0.9"],"objectRef":{"resource":"customresourcedefinitions","name":"customers.example.com"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-28T11:00:00.000000Z"}`,
+	`{"verb":"get","user":{"username":"jane.smith"},"sourceIPs":["10.0.0.7"],"objectRef":{"resource":"secrets","namespace":"prod"},"responseStatus":{"code":403},"requestReceivedTimestamp":"2025-01-29T02:00:00.000000Z","annotations":{"authorization.k8s.io/decision":"forbid"}}`,
+	`{"verb":"get","user":{"username":"jane.smith"},"sourceIPs":["10.0.0.7"],"objectRef":{"resource":"secrets","namespace":"dev"},"responseStatus":{"code":403},"requestReceivedTimestamp":"2025-01-29T03:00:00.000000Z"}`,
+	`not a json line`,
+}
+
+func newTestGenerator() *CommandGenerator {
+	g := NewCommandGenerator()
+	g.SetClock(func() time.Time { return fixedNow })
+	return g
+}
+
+// runJQ executes the generated filter against the fixture lines, skipping when jq is unavailable.
+func runJQ(t *testing.T, cmd *types.GeneratedCommand) []map[string]interface{} {
+	t.Helper()
+	if _, err := exec.LookPath("jq"); err != nil {
+		t.Skip("jq not available")
+	}
+	c := exec.Command("jq", append(cmd.JQArgs, cmd.JQFilter)...)
+	c.Stdin = strings.NewReader(strings.Join(auditLines, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		t.Fatalf("jq failed: %v: %s\nfilter: %s", err, stderr.String(), cmd.JQFilter)
+	}
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid jq output line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestGenerate_BasicCommand(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource:           "kube-apiserver",
+		Verb:                *types.NewStringOrArray("delete"),
+		Resource:            *types.NewStringOrArray("customresourcedefinitions"),
+		ResourceNamePattern: "customer",
+		Timeframe:           "yesterday",
+		ExcludeUsers:        []string{"system:"},
+		Limit:               20,
+	}
+
+	cmd, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.HasPrefix(cmd.Command, "oc adm node-logs --role=master --path=kube-apiserver/audit.log | jq -c -R -n '") {
+		t.Errorf("unexpected command prefix: %s", cmd.Command)
+	}
+	for _, want := range []string{`.verb == "delete"`, `startswith("system:")`, `test("customer")`, `"2025-01-28T00:00:00"`, `"2025-01-29T00:00:00"`, "limit(20;"} {
+		if !strings.Contains(cmd.JQFilter, want) {
+			t.Errorf("filter missing %q: %s", want, cmd.JQFilter)
+		}
+	}
+	if cmd.TimeWindow == nil || !cmd.TimeWindow.Start.Equal(time.Date(2025, 1, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time window: %+v", cmd.TimeWindow)
+	}
+
+	results := runJQ(t, cmd)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d: %v", len(results), results)
+	}
+	if user := results[0]["user"].(map[string]interface{})["username"]; user != "john.doe" {
+		t.Errorf("expected john.doe, got %v", user)
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		Verb:      *types.NewStringOrArray([]string{"update", "create", "delete"}),
+	}
+	first, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	second, _ := g.Generate(q)
+	if first.Command != second.Command {
+		t.Errorf("commands differ:\n%s\n%s", first.Command, second.Command)
+	}
+	if !strings.Contains(first.JQFilter, `(.verb == "create" or .verb == "delete" or .verb == "update")`) {
+		t.Errorf("expected sorted verb disjunction, got %s", first.JQFilter)
+	}
+}
+
+func TestGenerate_ResponseStatusAndAuthDecision(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource:      "kube-apiserver",
+		ResponseStatus: *types.NewStringOrArray(">=400"),
+		AuthDecision:   "forbid",
+	}
+	cmd, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	results := runJQ(t, cmd)
+	if len(results) != 1 || results[0]["objectRef"].(map[string]interface{})["namespace"] != "prod" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestGenerate_GroupBySortLimit(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource: "kube-apiserver",
+		GroupBy:   *types.NewStringOrArray([]string{"username", "source_ip"}),
+		SortBy:    "count",
+		SortOrder: "desc",
+		Limit:     2,
+	}
+	cmd, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(cmd.JQFilter, "group_by([.user.username, .sourceIPs[0]])") {
+		t.Errorf("unexpected group filter: %s", cmd.JQFilter)
+	}
+	results := runJQ(t, cmd)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 groups, got %d: %v", len(results), results)
+	}
+	if results[0]["user"] != "jane.smith" || results[0]["count"].(float64) != 2 {
+		t.Errorf("expected jane.smith with count 2 first, got %v", results[0])
+	}
+}
+
+func TestGenerate_BusinessHoursOutsideOnly(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource:     "kube-apiserver",
+		BusinessHours: &types.BusinessHours{OutsideOnly: true, StartHour: 9, EndHour: 17},
+		SortBy:        "timestamp",
+		SortOrder:     "asc",
+	}
+	cmd, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	results := runJQ(t, cmd)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 off-hours events, got %d", len(results))
+	}
+	if results[0]["requestReceivedTimestamp"] != "2025-01-29T02:00:00.000000Z" {
+		t.Errorf("expected ascending order, got %v", results[0]["requestReceivedTimestamp"])
+	}
+}
+
+func TestGenerate_QuotesShellSafely(t *testing.T) {
+	g := newTestGenerator()
+	q := &types.StructuredQuery{
+		LogSource:   "kube-apiserver",
+		UserPattern: `o'brien`,
+	}
+	cmd, err := g.Generate(q)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(cmd.Command, `test("o'\''brien")`) {
+		t.Errorf("single quote not escaped for shell: %s", cmd.Command)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	g := newTestGenerator()
+	tests := []struct {
+		name  string
+		query *types.StructuredQuery
+	}{
+		{name: "nil query", query: nil},
+		{name: "unknown log source", query: &types.StructuredQuery{LogSource: "etcd; rm -rf /"}},
+		{name: "unknown timeframe", query: &types.StructuredQuery{LogSource: "kube-apiserver", Timeframe: "eventually"}},
+		{name: "invalid status", query: &types.StructuredQuery{LogSource: "kube-apiserver", ResponseStatus: *types.NewStringOrArray("teapot")}},
+		{name: "unknown group field", query: &types.StructuredQuery{LogSource: "kube-apiserver", GroupBy: *types.NewStringOrArray("color")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.Generate(tt.query); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestResolveTimeframe(t *testing.T) {
+	tests := []struct {
+		timeframe string
+		start     time.Time
+		end       time.Time
+	}{
+		{"today", time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC), time.Time{}},
+		{"yesterday", time.Date(2025, 1, 28, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC)},
+		{"1_hour_ago", fixedNow.Add(-time.Hour), time.Time{}},
+		{"7_days_ago", fixedNow.AddDate(0, 0, -7), time.Time{}},
+		{"last_24_hours", fixedNow.Add(-24 * time.Hour), time.Time{}},
+		{"this_week", time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC), time.Time{}},
+		{"last_week", time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC)},
+		{"last_month", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timeframe, func(t *testing.T) {
+			w, err := ResolveTimeframe(tt.timeframe, fixedNow)
+			if err != nil {
+				t.Fatalf("ResolveTimeframe failed: %v", err)
+			}
+			if !w.Start.Equal(tt.start) || !w.End.Equal(tt.end) {
+				t.Errorf("got [%v, %v), want [%v, %v)", w.Start, w.End, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestStatusMatcher(t *testing.T) {
+	tests := []struct {
+		expr  string
+		code  int
+		match bool
+	}{
+		{"403", 403, true},
+		{"403", 404, false},
+		{">=400", 500, true},
+		{">=400", 200, false},
+		{"!=200", 201, true},
+		{"5xx", 503, true},
+		{"5xx", 404, false},
+	}
+	for _, tt := range tests {
+		m, err := ParseStatusMatcher(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseStatusMatcher(%q) failed: %v", tt.expr, err)
+		}
+		if got := m.Matches(tt.code); got != tt.match {
+			t.Errorf("%q.Matches(%d) = %v, want %v", tt.expr, tt.code, got, tt.match)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// Explain renders a deterministic, plain-English description of what a
+// structured query searches for, e.g. "Searches kube-apiserver audit logs for
+// delete on customresourcedefinitions excluding system: users, yesterday."
+// The explanation is built only from the query fields so reviewers can confirm
+// intent before the compiled command is run.
+func Explain(query *types.StructuredQuery) string {
+	if query == nil {
+		return ""
+	}
+
+	source := strings.TrimSpace(query.LogSource)
+	if source == "" {
+		source = "unspecified"
+	}
+	head := fmt.Sprintf("Searches %s audit logs %s", source, describeAction(query))
+
+	var qualifiers []string
+	if p := strings.TrimSpace(query.ResourceNamePattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("with names matching %q", p))
+	}
+	if s := strings.TrimSpace(query.Subresource); s != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("on the %s subresource", s))
+	}
+	if vals := query.Namespace.Values(); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("in %s %s", plural(len(vals), "namespace", "namespaces"), joinList(vals, "or")))
+	}
+	if p := strings.TrimSpace(query.NamespacePattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("in namespaces matching %q", p))
+	}
+	if vals := query.User.Values(); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("by %s %s", plural(len(vals), "user", "users"), joinList(vals, "or")))
+	}
+	if p := strings.TrimSpace(query.UserPattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("by users matching %q", p))
+	}
+	if vals := nonEmptyValues(query.ExcludeUsers); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("excluding %s users", joinList(vals, "and")))
+	}
+	if vals := nonEmptyValues(query.ExcludeResources); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("excluding %s resources", joinList(vals, "and")))
+	}
+	if vals := query.SourceIP.Values(); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("from %s %s", plural(len(vals), "source IP", "source IPs"), joinList(vals, "or")))
+	}
+	if vals := query.ResponseStatus.Values(); len(vals) > 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("with response status %s", joinList(vals, "or")))
+	}
+	if d := strings.TrimSpace(query.AuthDecision); d != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("with authorization decision %s", strings.ToLower(d)))
+	}
+	if p := strings.TrimSpace(query.AuthorizationReasonPattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("with authorization reasons matching %q", p))
+	}
+	if p := strings.TrimSpace(query.ResponseMessagePattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("with response messages matching %q", p))
+	}
+	if p := strings.TrimSpace(query.RequestURIPattern); p != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("with request URIs matching %q", p))
+	}
+	if f := strings.TrimSpace(query.RequestObjectFilter); f != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("whose request object contains %q", f))
+	}
+	if a := strings.TrimSpace(query.MissingAnnotation); a != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("without the %s annotation", a))
+	}
+
+	var clauses []string
+	if t := describeTime(query); t != "" {
+		clauses = append(clauses, t)
+	}
+	if bh := describeBusinessHours(query.BusinessHours); bh != "" {
+		clauses = append(clauses, bh)
+	}
+	if vals := query.GroupBy.Values(); len(vals) > 0 {
+		clauses = append(clauses, "grouped by "+joinList(vals, "and"))
+	}
+	if s := strings.TrimSpace(query.SortBy); s != "" {
+		clauses = append(clauses, fmt.Sprintf("sorted by %s %s", s, sortOrderWord(SortDirection(s, query.SortOrder))))
+	}
+	if a := describeAnalysis(query.Analysis); a != "" {
+		clauses = append(clauses, a)
+	}
+	if query.IncludeChanges {
+		clauses = append(clauses, "including request and response objects")
+	}
+	if query.Limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("returning at most %d %s", query.Limit, plural(query.Limit, "result", "results")))
+	}
+
+	sentence := head
+	if len(qualifiers) > 0 {
+		sentence += " " + strings.Join(qualifiers, " ")
+	}
+	if len(clauses) > 0 {
+		sentence += ", " + strings.Join(clauses, ", ")
+	}
+	return sentence + "."
+}
+
+// describeAction renders the verb/resource part of the explanation.
+func describeAction(query *types.StructuredQuery) string {
+	verbs := query.Verb.Values()
+	resources := query.Resource.Values()
+	switch {
+	case len(verbs) > 0 && len(resources) > 0:
+		return fmt.Sprintf("for %s on %s", joinList(verbs, "or"), joinList(resources, "or"))
+	case len(verbs) > 0:
+		return fmt.Sprintf("for %s requests", joinList(verbs, "or"))
+	case len(resources) > 0:
+		return fmt.Sprintf("for requests on %s", joinList(resources, "or"))
+	}
+	return "for all requests"
+}
+
+// describeTime renders the time constraint. An explicit time_range takes
+// precedence over timeframe, as in ResolveTimeWindow.
+func describeTime(query *types.StructuredQuery) string {
+	if tr := query.TimeRange; tr != nil && (!tr.Start.IsZero() || !tr.End.IsZero()) {
+		switch {
+		case tr.Start.IsZero():
+			return "before " + tr.End.UTC().Format(time.RFC3339)
+		case tr.End.IsZero():
+			return "since " + tr.Start.UTC().Format(time.RFC3339)
+		}
+		return fmt.Sprintf("between %s and %s", tr.Start.UTC().Format(time.RFC3339), tr.End.UTC().Format(time.RFC3339))
+	}
+
+	tf := strings.ToLower(strings.TrimSpace(query.Timeframe))
+	switch tf {
+	case "":
+		return ""
+	case "today", "yesterday":
+		return tf
+	case "last_hour":
+		return "in the last hour"
+	case "this_week", "last_week", "this_month", "last_month":
+		return strings.ReplaceAll(tf, "_", " ")
+	}
+	if m := relativeTimeframe.FindStringSubmatch(tf); m != nil {
+		unit := m[2]
+		if m[1] != "1" {
+			unit += "s"
+		}
+		return fmt.Sprintf("in the last %s %s", m[1], unit)
+	}
+	return "during " + query.Timeframe
+}
+
+// describeBusinessHours renders the business hours filter with defaults applied.
+func describeBusinessHours(bh *types.BusinessHours) string {
+	if bh == nil {
+		return ""
+	}
+	start, end := bh.StartHour, bh.EndHour
+	if start == 0 && end == 0 {
+		start, end = DefaultBusinessStartHour, DefaultBusinessEndHour
+	}
+	tz := strings.TrimSpace(bh.Timezone)
+	if tz == "" {
+		tz = "UTC"
+	}
+	where := "during"
+	if bh.OutsideOnly {
+		where = "outside"
+	}
+	return fmt.Sprintf("%s business hours (%02d:00-%02d:00 %s)", where, start, end, tz)
+}
+
+// describeAnalysis renders the advanced analysis configuration.
+func describeAnalysis(a *types.AnalysisConfig) string {
+	if a == nil || strings.TrimSpace(a.Type) == "" {
+		return ""
+	}
+	desc := fmt.Sprintf("running %s analysis", strings.ReplaceAll(a.Type, "_", " "))
+	var details []string
+	if a.Threshold > 0 {
+		details = append(details, fmt.Sprintf("threshold %d", a.Threshold))
+	}
+	if w := strings.TrimSpace(a.TimeWindow); w != "" {
+		details = append(details, w+" time window")
+	}
+	if vals := a.GroupBy.Values(); len(vals) > 0 {
+		details = append(details, "per "+joinList(vals, "and"))
+	}
+	if len(details) > 0 {
+		desc += " (" + strings.Join(details, ", ") + ")"
+	}
+	return desc
+}
+
+func sortOrderWord(direction string) string {
+	if direction == "desc" {
+		return "descending"
+	}
+	return "ascending"
+}
+
+// joinList joins values as natural language: "a", "a or b", "a, b or c".
+func joinList(values []string, conjunction string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " " + conjunction + " " + values[len(values)-1]
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
+
+func nonEmptyValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
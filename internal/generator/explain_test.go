@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *types.StructuredQuery
+		want  string
+	}{
+		{
+			name: "crd deletion",
+			query: &types.StructuredQuery{
+				LogSource:    "kube-apiserver",
+				Verb:         *types.NewStringOrArray("delete"),
+				Resource:     *types.NewStringOrArray("customresourcedefinitions"),
+				Timeframe:    "yesterday",
+				ExcludeUsers: []string{"system:"},
+			},
+			want: "Searches kube-apiserver audit logs for delete on customresourcedefinitions excluding system: users, yesterday.",
+		},
+		{
+			name: "failed authentication",
+			query: &types.StructuredQuery{
+				LogSource:    "oauth-server",
+				Timeframe:    "1_hour_ago",
+				AuthDecision: "error",
+				Limit:        20,
+			},
+			want: "Searches oauth-server audit logs for all requests with authorization decision error, in the last 1 hour, returning at most 20 results.",
+		},
+		{
+			name: "grouped with business hours",
+			query: &types.StructuredQuery{
+				LogSource:     "kube-apiserver",
+				Verb:          *types.NewStringOrArray([]string{"get", "list", "watch"}),
+				Namespace:     *types.NewStringOrArray([]string{"prod", "staging"}),
+				GroupBy:       *types.NewStringOrArray("user"),
+				SortBy:        "count",
+				BusinessHours: &types.BusinessHours{OutsideOnly: true},
+				TimeRange: &types.TimeRange{
+					Start: time.Date(2025, 1, 28, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: "Searches kube-apiserver audit logs for get, list or watch requests in namespaces prod or staging, between 2025-01-28T00:00:00Z and 2025-01-29T00:00:00Z, outside business hours (09:00-17:00 UTC), grouped by user, sorted by count descending.",
+		},
+		{
+			name: "analysis",
+			query: &types.StructuredQuery{
+				LogSource: "kube-apiserver",
+				Resource:  *types.NewStringOrArray("secrets"),
+				Analysis:  &types.AnalysisConfig{Type: "excessive_reads", Threshold: 50, TimeWindow: "short"},
+			},
+			want: "Searches kube-apiserver audit logs for requests on secrets, running excessive reads analysis (threshold 50, short time window).",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Explain(tt.query)
+			if got != tt.want {
+				t.Errorf("Explain() =\n  %s\nwant\n  %s", got, tt.want)
+			}
+			if again := Explain(tt.query); again != got {
+				t.Errorf("Explain is not deterministic: %q vs %q", got, again)
+			}
+		})
+	}
+
+	if Explain(nil) != "" {
+		t.Error("Explain(nil) should be empty")
+	}
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldAliases maps the field names accepted in group_by/sort_by to their
+// canonical names. LLM output uses several spellings for the same field.
+var fieldAliases = map[string]string{
+	"user":            "user",
+	"username":        "user",
+	"user_name":       "user",
+	"namespace":       "namespace",
+	"resource":        "resource",
+	"resource_name":   "resource_name",
+	"name":            "resource_name",
+	"verb":            "verb",
+	"source_ip":       "source_ip",
+	"sourceip":        "source_ip",
+	"ip":              "source_ip",
+	"response_status": "response_status",
+	"status_code":     "response_status",
+	"status":          "response_status",
+	"user_agent":      "user_agent",
+	"subresource":     "subresource",
+	"auth_decision":   "auth_decision",
+	"timestamp":       "timestamp",
+}
+
+// jqFieldPaths maps canonical field names to jq paths within a Kubernetes audit event.
+var jqFieldPaths = map[string]string{
+	"user":            ".user.username",
+	"namespace":       ".objectRef.namespace",
+	"resource":        ".objectRef.resource",
+	"resource_name":   ".objectRef.name",
+	"verb":            ".verb",
+	"source_ip":       ".sourceIPs[0]",
+	"response_status": ".responseStatus.code",
+	"user_agent":      ".userAgent",
+	"subresource":     ".objectRef.subresource",
+	"auth_decision":   `.annotations["authorization.k8s.io/decision"]`,
+	"timestamp":       ".requestReceivedTimestamp",
+}
+
+// CanonicalField returns the canonical audit field name for a group_by or
+// sort_by value, and false if the field is not known.
+func CanonicalField(name string) (string, bool) {
+	canonical, ok := fieldAliases[strings.ToLower(strings.TrimSpace(name))]
+	return canonical, ok
+}
+
+// StatusMatcher matches audit event response codes against a response_status
+// expression such as "403", ">=400", "!=200" or "5xx".
+type StatusMatcher struct {
+	// Op is the comparison operator (==, !=, >=, <=, >, <)
+	Op string
+
+	// Code is the status code compared against
+	Code int
+
+	// Class is the hundreds digit for class expressions like "4xx" (0 when unused)
+	Class int
+}
+
+// ParseStatusMatcher parses a response_status expression.
+func ParseStatusMatcher(expr string) (StatusMatcher, error) {
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return StatusMatcher{}, fmt.Errorf("empty response status")
+	}
+
+	lower := strings.ToLower(s)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") && lower[0] >= '1' && lower[0] <= '5' {
+		return StatusMatcher{Op: "==", Class: int(lower[0] - '0')}, nil
+	}
+
+	op := "=="
+	for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "="} {
+		if strings.HasPrefix(s, candidate) {
+			op = candidate
+			s = strings.TrimSpace(strings.TrimPrefix(s, candidate))
+			break
+		}
+	}
+	if op == "=" {
+		op = "=="
+	}
+
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return StatusMatcher{}, fmt.Errorf("invalid response status '%s'", expr)
+	}
+	return StatusMatcher{Op: op, Code: code}, nil
+}
+
+// Matches reports whether the given response code satisfies the matcher.
+func (m StatusMatcher) Matches(code int) bool {
+	if m.Class > 0 {
+		return code/100 == m.Class
+	}
+	switch m.Op {
+	case "!=":
+		return code != m.Code
+	case ">=":
+		return code >= m.Code
+	case "<=":
+		return code <= m.Code
+	case ">":
+		return code > m.Code
+	case "<":
+		return code < m.Code
+	default:
+		return code == m.Code
+	}
+}
+
+// jqCondition renders the matcher as a jq boolean expression.
+func (m StatusMatcher) jqCondition() string {
+	code := "(.responseStatus.code // 0)"
+	if m.Class > 0 {
+		return fmt.Sprintf("((%s / 100 | floor) == %d)", code, m.Class)
+	}
+	return fmt.Sprintf("(%s %s %d)", code, m.Op, m.Code)
+}
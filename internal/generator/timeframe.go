@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// Default business hours applied when a BusinessHours block omits them,
+// matching the business_hours defaults in rules.yaml.
+const (
+	DefaultBusinessStartHour = 9
+	DefaultBusinessEndHour   = 17
+)
+
+// relativeTimeframe matches timeframes such as "1_hour_ago", "7_days_ago" and "last_24_hours".
+var relativeTimeframe = regexp.MustCompile(`^(?:last_)?(\d+)_(minute|hour|day|week)s?(?:_ago)?$`)
+
+// TimeWindow is a half-open [Start, End) interval. A zero End means the
+// window is open-ended and extends up to the present.
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Start.IsZero() && t.Before(w.Start) {
+		return false
+	}
+	if !w.End.IsZero() && !t.Before(w.End) {
+		return false
+	}
+	return true
+}
+
+// ResolveTimeWindow resolves the time constraint of a query relative to now.
+// An explicit TimeRange takes precedence over Timeframe. Returns nil when the
+// query has no time constraint.
+func ResolveTimeWindow(query *types.StructuredQuery, now time.Time) (*TimeWindow, error) {
+	if query == nil {
+		return nil, nil
+	}
+	if query.TimeRange != nil && (!query.TimeRange.Start.IsZero() || !query.TimeRange.End.IsZero()) {
+		if !query.TimeRange.Start.IsZero() && !query.TimeRange.End.IsZero() && !query.TimeRange.End.After(query.TimeRange.Start) {
+			return nil, fmt.Errorf("time_range end must be after start")
+		}
+		return &TimeWindow{Start: query.TimeRange.Start.UTC(), End: query.TimeRange.End.UTC()}, nil
+	}
+	if strings.TrimSpace(query.Timeframe) == "" {
+		return nil, nil
+	}
+	window, err := ResolveTimeframe(query.Timeframe, now)
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// ResolveTimeframe converts a symbolic timeframe into an absolute UTC window.
+// Calendar timeframes (today, yesterday, this_week, last_week, this_month,
+// last_month) align to UTC day boundaries with weeks starting on Monday.
+func ResolveTimeframe(timeframe string, now time.Time) (TimeWindow, error) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := midnight.AddDate(0, 0, -((int(midnight.Weekday()) + 6) % 7))
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tf := strings.ToLower(strings.TrimSpace(timeframe))
+	switch tf {
+	case "today":
+		return TimeWindow{Start: midnight}, nil
+	case "yesterday":
+		return TimeWindow{Start: midnight.AddDate(0, 0, -1), End: midnight}, nil
+	case "last_hour":
+		return TimeWindow{Start: now.Add(-time.Hour)}, nil
+	case "this_week":
+		return TimeWindow{Start: weekStart}, nil
+	case "last_week":
+		return TimeWindow{Start: weekStart.AddDate(0, 0, -7), End: weekStart}, nil
+	case "this_month":
+		return TimeWindow{Start: monthStart}, nil
+	case "last_month":
+		return TimeWindow{Start: monthStart.AddDate(0, -1, 0), End: monthStart}, nil
+	}
+
+	m := relativeTimeframe.FindStringSubmatch(tf)
+	if m == nil {
+		return TimeWindow{}, fmt.Errorf("unsupported timeframe '%s'", timeframe)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return TimeWindow{}, fmt.Errorf("unsupported timeframe '%s'", timeframe)
+	}
+	var unit time.Duration
+	switch m[2] {
+	case "minute":
+		unit = time.Minute
+	case "hour":
+		unit = time.Hour
+	case "day":
+		unit = 24 * time.Hour
+	case "week":
+		unit = 7 * 24 * time.Hour
+	}
+	return TimeWindow{Start: now.Add(-time.Duration(n) * unit)}, nil
+}
+
+// businessHoursBounds returns the configured start/end hour and location,
+// applying defaults for unset values.
+func businessHoursBounds(bh *types.BusinessHours) (int, int, *time.Location, error) {
+	start, end := bh.StartHour, bh.EndHour
+	if start == 0 && end == 0 {
+		start, end = DefaultBusinessStartHour, DefaultBusinessEndHour
+	}
+	loc := time.UTC
+	if tz := strings.TrimSpace(bh.Timezone); tz != "" && !strings.EqualFold(tz, "UTC") {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid business hours timezone '%s': %w", tz, err)
+		}
+		loc = l
+	}
+	return start, end, loc, nil
+}
+
+// InBusinessHours reports whether t satisfies the business hours filter.
+// With OutsideOnly set it matches events outside the configured hours.
+func InBusinessHours(bh *types.BusinessHours, t time.Time) (bool, error) {
+	if bh == nil {
+		return true, nil
+	}
+	start, end, loc, err := businessHoursBounds(bh)
+	if err != nil {
+		return false, err
+	}
+	hour := t.In(loc).Hour()
+	inside := hour >= start && hour < end
+	if start > end {
+		// Overnight business hours, e.g. 22-6
+		inside = hour >= start || hour < end
+	}
+	if bh.OutsideOnly {
+		return !inside, nil
+	}
+	return inside, nil
+}
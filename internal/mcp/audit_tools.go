@@ -0,0 +1,595 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"genai-processing/internal/analysis"
+	"genai-processing/internal/executor"
+	"genai-processing/internal/generator"
+	"genai-processing/internal/processor"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// Tool names exposed by AuditTools.
+const (
+	ToolGenerateAuditQuery = "generate_audit_query"
+	ToolExecuteAuditQuery  = "execute_audit_query"
+	ToolParseAuditResults  = "parse_audit_results"
+)
+
+// DefaultMaxIssuedCommands bounds the registry of commands issued by
+// generate_audit_query that execute_audit_query will accept by string.
+const DefaultMaxIssuedCommands = 256
+
+// AuditTools implements the audit query MCP tools on top of the processor,
+// safety validator, command generator and executors.
+//
+// execute_audit_query never runs arbitrary shell: structured parameters are
+// validated and compiled by the command generator, and a raw command string is
+// only accepted when it was previously issued by generate_audit_query on this
+// server. Execution uses the local audit-log executor when configured, and
+// otherwise the cluster runner when cluster execution is enabled.
+type AuditTools struct {
+	// processor translates natural language queries; optional
+	processor *processor.GenAIProcessor
+
+	// validator checks structured queries before they are compiled or executed
+	validator interfaces.SafetyValidator
+
+	// generator compiles structured queries into oc/jq commands
+	generator *generator.CommandGenerator
+
+	// executor evaluates queries against local audit logs; optional
+	executor *executor.Executor
+
+	// cluster runs generated commands against a live cluster; optional
+	cluster *executor.ClusterRunner
+
+	// defaultLimit is used when aggregating parsed results without a query limit
+	defaultLimit int
+
+	// issued maps command strings returned by generate_audit_query to their queries
+	mu        sync.Mutex
+	issued    map[string]issuedCommand
+	order     []string
+	maxIssued int
+
+	logger *log.Logger
+}
+
+// issuedCommand records a command returned to a client with its source query.
+type issuedCommand struct {
+	query   *types.StructuredQuery
+	command *types.GeneratedCommand
+}
+
+// GenerateResult is the output of generate_audit_query.
+type GenerateResult struct {
+	// StructuredQuery is the query that was validated and compiled
+	StructuredQuery *types.StructuredQuery `json:"structured_query"`
+
+	// Command is the compiled oc/jq pipeline; absent when validation failed
+	Command *types.GeneratedCommand `json:"command,omitempty"`
+
+	// Explanation is a plain-English description of the query
+	Explanation string `json:"explanation"`
+
+	// Validation contains the safety validation outcome
+	Validation interface{} `json:"validation,omitempty"`
+
+	// Confidence is the parser confidence for natural language input
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// ParseResult is the output of parse_audit_results and of cluster executions.
+type ParseResult struct {
+	// Events contains decoded audit events
+	Events []types.AuditEvent `json:"events,omitempty"`
+
+	// Rows contains grouped rows (objects with a count field) produced by group_by queries
+	Rows []map[string]interface{} `json:"rows,omitempty"`
+
+	// TotalEvents is the number of audit events decoded
+	TotalEvents int `json:"total_events"`
+
+	// TotalRows is the number of grouped rows decoded
+	TotalRows int `json:"total_rows"`
+
+	// MalformedLines counts non-empty lines that could not be decoded
+	MalformedLines int `json:"malformed_lines"`
+
+	// Truncated is true when the raw output was cut at the output size limit
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Summary is a short human-readable description of the results
+	Summary string `json:"summary"`
+
+	// Analysis contains group-by aggregates and findings when requested by the query context
+	Analysis *types.AnalysisResult `json:"analysis,omitempty"`
+
+	// CSV is the CSV rendering of the events or rows when format is "csv"
+	CSV string `json:"csv,omitempty"`
+}
+
+// NewAuditTools creates the audit tools with the given validator and generator.
+func NewAuditTools(validator interfaces.SafetyValidator, gen *generator.CommandGenerator) *AuditTools {
+	return &AuditTools{
+		validator:    validator,
+		generator:    gen,
+		defaultLimit: generator.DefaultLimit,
+		issued:       make(map[string]issuedCommand),
+		maxIssued:    DefaultMaxIssuedCommands,
+		logger:       log.New(log.Writer(), "[AuditTools] ", log.LstdFlags),
+	}
+}
+
+// SetProcessor enables natural language input to generate_audit_query.
+func (t *AuditTools) SetProcessor(p *processor.GenAIProcessor) {
+	t.processor = p
+}
+
+// SetExecutor enables execution against local audit logs.
+func (t *AuditTools) SetExecutor(e *executor.Executor) {
+	t.executor = e
+}
+
+// SetClusterRunner enables execution against a live cluster.
+func (t *AuditTools) SetClusterRunner(r *executor.ClusterRunner) {
+	t.cluster = r
+}
+
+// Register registers the audit tools with the server.
+func (t *AuditTools) Register(s *Server) error {
+	structuredParams := map[string]interface{}{
+		"type":        "object",
+		"description": "StructuredQuery parameters, e.g. {\"log_source\": \"kube-apiserver\", \"verb\": \"delete\", \"resource\": \"pods\", \"timeframe\": \"yesterday\"}",
+		"properties": map[string]interface{}{
+			"log_source": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"kube-apiserver", "openshift-apiserver", "oauth-server", "oauth-apiserver", "node-auditd"},
+			},
+		},
+		"required": []string{"log_source"},
+	}
+
+	tools := []struct {
+		tool    Tool
+		handler ToolHandler
+	}{
+		{
+			tool: Tool{
+				Name:        ToolGenerateAuditQuery,
+				Description: "Translate a natural language question or structured parameters into a validated, read-only `oc adm node-logs | jq` audit log query with a plain-English explanation.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query":             map[string]interface{}{"type": "string", "description": "Natural language audit question"},
+						"session_id":        map[string]interface{}{"type": "string", "description": "Conversation session for resolving follow-up questions"},
+						"structured_params": structuredParams,
+					},
+				},
+			},
+			handler: t.handleGenerate,
+		},
+		{
+			tool: Tool{
+				Name:        ToolExecuteAuditQuery,
+				Description: "Execute an audit query from structured parameters, or a command previously returned by generate_audit_query, and return the matching audit events.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"structured_params": structuredParams,
+						"command":           map[string]interface{}{"type": "string", "description": "Command returned by generate_audit_query"},
+					},
+				},
+			},
+			handler: t.handleExecute,
+		},
+		{
+			tool: Tool{
+				Name:        ToolParseAuditResults,
+				Description: "Parse raw audit query output (JSON lines) into events or grouped rows with totals, an optional analysis and optional CSV.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"raw_output":    map[string]interface{}{"type": "string", "description": "Raw output of an audit query"},
+						"query_context": map[string]interface{}{"type": "object", "description": "StructuredQuery that produced the output; enables group_by and analysis"},
+						"format":        map[string]interface{}{"type": "string", "enum": []string{"json", "csv"}},
+					},
+					"required": []string{"raw_output"},
+				},
+			},
+			handler: t.handleParse,
+		},
+	}
+
+	for _, entry := range tools {
+		if err := s.RegisterTool(entry.tool, entry.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type generateArgs struct {
+	Query            string                 `json:"query"`
+	SessionID        string                 `json:"session_id"`
+	StructuredParams *types.StructuredQuery `json:"structured_params"`
+}
+
+func (t *AuditTools) handleGenerate(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+	var args generateArgs
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	var result *GenerateResult
+	switch {
+	case args.StructuredParams != nil:
+		query := args.StructuredParams
+		validation, err := t.validate(query)
+		if err != nil {
+			return nil, err
+		}
+		result = &GenerateResult{StructuredQuery: query, Explanation: generator.Explain(query), Validation: validation}
+		if validation.IsValid {
+			cmd, err := t.generator.Generate(query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate command: %w", err)
+			}
+			result.Command = cmd
+		}
+	case strings.TrimSpace(args.Query) != "":
+		if t.processor == nil {
+			return nil, fmt.Errorf("natural language queries are not enabled on this server; pass structured_params instead")
+		}
+		resp, err := t.processor.PreviewQuery(ctx, &types.ProcessingRequest{Query: args.Query, SessionID: args.SessionID})
+		if err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, fmt.Errorf("%s", resp.Error)
+		}
+		query, ok := resp.StructuredQuery.(*types.StructuredQuery)
+		if !ok {
+			return nil, fmt.Errorf("processor returned no structured query")
+		}
+		result = &GenerateResult{
+			StructuredQuery: query,
+			Command:         resp.Command,
+			Explanation:     resp.Explanation,
+			Validation:      resp.ValidationInfo,
+			Confidence:      resp.Confidence,
+		}
+	default:
+		return nil, fmt.Errorf("either query or structured_params is required")
+	}
+
+	if result.Command != nil {
+		t.remember(result.StructuredQuery, result.Command)
+	}
+	out, err := JSONResult(result)
+	if err != nil {
+		return nil, err
+	}
+	out.IsError = result.Command == nil
+	return out, nil
+}
+
+type executeArgs struct {
+	StructuredParams *types.StructuredQuery `json:"structured_params"`
+	Command          string                 `json:"command"`
+}
+
+func (t *AuditTools) handleExecute(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+	var args executeArgs
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	var query *types.StructuredQuery
+	var cmd *types.GeneratedCommand
+	switch {
+	case args.StructuredParams != nil:
+		query = args.StructuredParams
+		validation, err := t.validate(query)
+		if err != nil {
+			return nil, err
+		}
+		if !validation.IsValid {
+			return nil, fmt.Errorf("query failed safety validation: %s", validation.Message)
+		}
+		if cmd, err = t.generator.Generate(query); err != nil {
+			return nil, fmt.Errorf("failed to generate command: %w", err)
+		}
+	case strings.TrimSpace(args.Command) != "":
+		entry, ok := t.lookup(strings.TrimSpace(args.Command))
+		if !ok {
+			return nil, fmt.Errorf("command was not issued by this server; call %s first or pass structured_params", ToolGenerateAuditQuery)
+		}
+		query, cmd = entry.query, entry.command
+	default:
+		return nil, fmt.Errorf("either structured_params or command is required")
+	}
+
+	switch {
+	case t.executor != nil:
+		res, err := t.executor.Execute(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return JSONResult(res)
+	case t.cluster != nil:
+		start := time.Now()
+		output, truncated, err := t.cluster.Run(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		t.logger.Printf("cluster execution completed in %s", time.Since(start))
+		res, err := t.parse(output, query, "json")
+		if err != nil {
+			return nil, err
+		}
+		res.Truncated = truncated
+		return JSONResult(res)
+	}
+	return nil, fmt.Errorf("no execution backend is configured; start the server with an audit log directory or with cluster execution enabled")
+}
+
+type parseArgs struct {
+	RawOutput    string                 `json:"raw_output"`
+	QueryContext *types.StructuredQuery `json:"query_context"`
+	Format       string                 `json:"format"`
+}
+
+func (t *AuditTools) handleParse(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+	var args parseArgs
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	format := strings.ToLower(strings.TrimSpace(args.Format))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		return nil, fmt.Errorf("unsupported format '%s'", args.Format)
+	}
+	res, err := t.parse(args.RawOutput, args.QueryContext, format)
+	if err != nil {
+		return nil, err
+	}
+	return JSONResult(res)
+}
+
+// parse decodes raw query output. Each line may carry a node name prefix
+// before the JSON object, as emitted by `oc adm node-logs --role`. Objects with
+// a count field and no audit ID are grouped rows; everything else is decoded
+// as an audit event. A single JSON array is also accepted.
+func (t *AuditTools) parse(raw string, query *types.StructuredQuery, format string) (*ParseResult, error) {
+	res := &ParseResult{}
+
+	objects, malformed, err := splitObjects(raw)
+	if err != nil {
+		return nil, err
+	}
+	res.MalformedLines = malformed
+
+	for _, obj := range objects {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(obj, &fields); err != nil {
+			res.MalformedLines++
+			continue
+		}
+		if _, isRow := fields["count"]; isRow && fields["auditID"] == nil {
+			res.Rows = append(res.Rows, fields)
+			continue
+		}
+		var ev types.AuditEvent
+		if err := json.Unmarshal(obj, &ev); err != nil {
+			res.MalformedLines++
+			continue
+		}
+		res.Events = append(res.Events, ev)
+	}
+	res.TotalEvents = len(res.Events)
+	res.TotalRows = len(res.Rows)
+
+	if query != nil && len(res.Events) > 0 && (len(query.GroupBy.Values()) > 0 || query.Analysis != nil) {
+		agg, err := analysis.Aggregate(query, res.Events, t.defaultLimit)
+		if err != nil {
+			return nil, err
+		}
+		res.Analysis = agg
+	}
+
+	res.Summary = summarize(res)
+	if format == "csv" {
+		out, err := toCSV(res)
+		if err != nil {
+			return nil, err
+		}
+		res.CSV = out
+	}
+	return res, nil
+}
+
+// splitObjects splits raw output into JSON objects, accepting either a single
+// JSON array or one object per line with any prefix before '{' stripped.
+func splitObjects(raw string) ([]json.RawMessage, int, error) {
+	var objects []json.RawMessage
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "[") && json.Unmarshal([]byte(trimmed), &objects) == nil {
+		return objects, 0, nil
+	}
+
+	objects = nil
+	malformed := 0
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), executor.DefaultMaxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		idx := bytes.IndexByte(line, '{')
+		if idx < 0 {
+			if len(bytes.TrimSpace(line)) > 0 {
+				malformed++
+			}
+			continue
+		}
+		objects = append(objects, append(json.RawMessage(nil), line[idx:]...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read raw output: %w", err)
+	}
+	return objects, malformed, nil
+}
+
+// summarize describes the parsed results in one sentence.
+func summarize(res *ParseResult) string {
+	if res.TotalRows > 0 {
+		return fmt.Sprintf("%d grouped rows", res.TotalRows)
+	}
+	if res.TotalEvents == 0 {
+		return "No audit events matched"
+	}
+
+	users := make(map[string]bool)
+	verbs := make(map[string]int)
+	var first, last time.Time
+	for i := range res.Events {
+		ev := &res.Events[i]
+		users[ev.User.Username] = true
+		verbs[ev.Verb]++
+		ts := ev.RequestReceivedTimestamp
+		if first.IsZero() || ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	verbNames := make([]string, 0, len(verbs))
+	for v := range verbs {
+		verbNames = append(verbNames, v)
+	}
+	sort.Slice(verbNames, func(i, j int) bool {
+		if verbs[verbNames[i]] != verbs[verbNames[j]] {
+			return verbs[verbNames[i]] > verbs[verbNames[j]]
+		}
+		return verbNames[i] < verbNames[j]
+	})
+	parts := make([]string, 0, len(verbNames))
+	for _, v := range verbNames {
+		parts = append(parts, fmt.Sprintf("%s=%d", v, verbs[v]))
+	}
+	return fmt.Sprintf("%d audit events from %d users between %s and %s (%s)",
+		res.TotalEvents, len(users), first.UTC().Format(time.RFC3339), last.UTC().Format(time.RFC3339), strings.Join(parts, ", "))
+}
+
+// csvEventFields are the canonical fields written for each event in CSV output.
+var csvEventFields = []string{"timestamp", "user", "verb", "resource", "namespace", "resource_name", "response_status", "source_ip", "user_agent"}
+
+// toCSV renders grouped rows, or events when there are no rows, as CSV.
+func toCSV(res *ParseResult) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if len(res.Rows) > 0 {
+		columns := make(map[string]bool)
+		for _, row := range res.Rows {
+			for k := range row {
+				columns[k] = true
+			}
+		}
+		header := make([]string, 0, len(columns))
+		for k := range columns {
+			header = append(header, k)
+		}
+		sort.Strings(header)
+		if err := w.Write(header); err != nil {
+			return "", err
+		}
+		for _, row := range res.Rows {
+			record := make([]string, len(header))
+			for i, k := range header {
+				if v, ok := row[k]; ok && v != nil {
+					record[i] = fmt.Sprint(v)
+				}
+			}
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		if err := w.Write(csvEventFields); err != nil {
+			return "", err
+		}
+		for i := range res.Events {
+			record := make([]string, len(csvEventFields))
+			for j, f := range csvEventFields {
+				record[j] = res.Events[i].Field(f)
+			}
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// validate runs the safety validator over a structured query.
+func (t *AuditTools) validate(query *types.StructuredQuery) (*interfaces.ValidationResult, error) {
+	if t.validator == nil {
+		return nil, fmt.Errorf("safety validator is not configured")
+	}
+	result, err := t.validator.ValidateQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return result, nil
+}
+
+// remember records an issued command, evicting the oldest beyond the registry size.
+func (t *AuditTools) remember(query *types.StructuredQuery, cmd *types.GeneratedCommand) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.issued[cmd.Command]; !ok {
+		t.order = append(t.order, cmd.Command)
+	}
+	t.issued[cmd.Command] = issuedCommand{query: query, command: cmd}
+	for len(t.order) > t.maxIssued {
+		delete(t.issued, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// lookup returns a previously issued command.
+func (t *AuditTools) lookup(command string) (issuedCommand, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.issued[command]
+	return entry, ok
+}
+
+// decodeArguments decodes tool arguments, treating absent arguments as empty.
+func decodeArguments(arguments json.RawMessage, v interface{}) error {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(arguments, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/internal/executor"
+	"genai-processing/internal/generator"
+	"genai-processing/internal/validator"
+	"genai-processing/pkg/types"
+)
+
+var toolNow = time.Date(2025, 1, 29, 14, 30, 0, 0, time.UTC)
+
+var toolAuditLines = []string{
+	`master-0 {"auditID":"a1","verb":"delete","user":{"username":"john.doe"},"objectRef":{"resource":"pods","namespace":"prod","name":"web-1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-29T10:15:00Z"}`,
+	`master-0 {"auditID":"a2","verb":"delete","user":{"username":"jane.smith"},"objectRef":{"resource":"pods","namespace":"dev","name":"web-2"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-29T11:15:00Z"}`,
+	`master-1 {"auditID":"a3","verb":"get","user":{"username":"john.doe"},"objectRef":{"resource":"secrets","namespace":"prod"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2025-01-29T12:15:00Z"}`,
+	`garbage`,
+}
+
+func newTestTools(t *testing.T, logDir string) (*Server, *AuditTools) {
+	t.Helper()
+	gen := generator.NewCommandGenerator()
+	gen.SetClock(func() time.Time { return toolNow })
+	tools := NewAuditTools(validator.NewSafetyValidator(), gen)
+	tools.logger = log.New(io.Discard, "", 0)
+	if logDir != "" {
+		e := executor.NewExecutor(logDir)
+		e.SetClock(func() time.Time { return toolNow })
+		tools.SetExecutor(e)
+	}
+	s := NewServer("test", "0.0.1")
+	s.SetLogger(log.New(io.Discard, "", 0))
+	if err := tools.Register(s); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return s, tools
+}
+
+func callTool(t *testing.T, s *Server, name string, args interface{}) *CallToolResult {
+	t.Helper()
+	params, _ := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+	msg, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": json.RawMessage(params)})
+	out := s.HandleMessage(context.Background(), msg)
+	var resp struct {
+		Result *CallToolResult `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", out, err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("tools/call %s failed: %v", name, resp.Error)
+	}
+	return resp.Result
+}
+
+func decodeText(t *testing.T, res *CallToolResult, v interface{}) {
+	t.Helper()
+	if len(res.Content) == 0 {
+		t.Fatalf("empty tool result")
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].Text), v); err != nil {
+		t.Fatalf("tool result is not JSON: %v: %s", err, res.Content[0].Text)
+	}
+}
+
+func TestAuditTools_GenerateAndExecute(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "audit.log"), []byte(strings.Join(toolAuditLines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestTools(t, dir)
+
+	params := map[string]interface{}{"log_source": "kube-apiserver", "verb": "delete", "resource": "pods", "timeframe": "today"}
+	res := callTool(t, s, ToolGenerateAuditQuery, map[string]interface{}{"structured_params": params})
+	if res.IsError {
+		t.Fatalf("generate failed: %s", res.Content[0].Text)
+	}
+	var gen GenerateResult
+	decodeText(t, res, &gen)
+	if gen.Command == nil || !strings.HasPrefix(gen.Command.Command, "oc adm node-logs") {
+		t.Fatalf("expected a generated command, got %+v", gen)
+	}
+	if !strings.Contains(gen.Explanation, "delete") {
+		t.Errorf("unexpected explanation: %s", gen.Explanation)
+	}
+
+	res = callTool(t, s, ToolExecuteAuditQuery, map[string]interface{}{"command": gen.Command.Command})
+	if res.IsError {
+		t.Fatalf("execute failed: %s", res.Content[0].Text)
+	}
+	var exec types.ExecutionResult
+	decodeText(t, res, &exec)
+	if exec.TotalMatched != 2 || exec.MalformedLines != 1 {
+		t.Errorf("expected 2 matches and 1 malformed line, got %d and %d", exec.TotalMatched, exec.MalformedLines)
+	}
+
+	res = callTool(t, s, ToolExecuteAuditQuery, map[string]interface{}{"structured_params": params})
+	if res.IsError {
+		t.Fatalf("execute with structured_params failed: %s", res.Content[0].Text)
+	}
+}
+
+func TestAuditTools_ExecuteRejectsUnknownCommands(t *testing.T) {
+	s, _ := newTestTools(t, t.TempDir())
+	res := callTool(t, s, ToolExecuteAuditQuery, map[string]interface{}{"command": "rm -rf / ; oc adm node-logs"})
+	if !res.IsError || !strings.Contains(res.Content[0].Text, "not issued") {
+		t.Errorf("expected unknown command to be rejected, got %+v", res)
+	}
+
+	res = callTool(t, s, ToolExecuteAuditQuery, map[string]interface{}{"structured_params": map[string]interface{}{"log_source": "/etc/passwd"}})
+	if !res.IsError {
+		t.Errorf("expected invalid log source to be rejected")
+	}
+
+	s, _ = newTestTools(t, "")
+	res = callTool(t, s, ToolExecuteAuditQuery, map[string]interface{}{"structured_params": map[string]interface{}{"log_source": "kube-apiserver"}})
+	if !res.IsError || !strings.Contains(res.Content[0].Text, "no execution backend") {
+		t.Errorf("expected missing backend error, got %+v", res)
+	}
+}
+
+func TestAuditTools_ParseResults(t *testing.T) {
+	s, _ := newTestTools(t, "")
+
+	res := callTool(t, s, ToolParseAuditResults, map[string]interface{}{
+		"raw_output":    strings.Join(toolAuditLines, "\n"),
+		"query_context": map[string]interface{}{"log_source": "kube-apiserver", "group_by": "username"},
+		"format":        "csv",
+	})
+	if res.IsError {
+		t.Fatalf("parse failed: %s", res.Content[0].Text)
+	}
+	var parsed ParseResult
+	decodeText(t, res, &parsed)
+	if parsed.TotalEvents != 3 || parsed.MalformedLines != 1 {
+		t.Errorf("expected 3 events and 1 malformed line, got %d and %d", parsed.TotalEvents, parsed.MalformedLines)
+	}
+	if parsed.Analysis == nil || len(parsed.Analysis.Groups) != 2 || parsed.Analysis.Groups[0].Key["user"] != "john.doe" {
+		t.Errorf("expected user groups with john.doe first, got %+v", parsed.Analysis)
+	}
+	if lines := strings.Split(strings.TrimSpace(parsed.CSV), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "timestamp,user,verb") {
+		t.Errorf("unexpected CSV: %q", parsed.CSV)
+	}
+
+	res = callTool(t, s, ToolParseAuditResults, map[string]interface{}{
+		"raw_output": `{"user":"john.doe","count":2,"first_seen":"2025-01-29T10:15:00Z","last_seen":"2025-01-29T12:15:00Z"}` + "\n" + `{"user":"jane.smith","count":1}`,
+	})
+	parsed = ParseResult{}
+	decodeText(t, res, &parsed)
+	if parsed.TotalRows != 2 || parsed.TotalEvents != 0 {
+		t.Errorf("expected 2 grouped rows, got %+v", parsed)
+	}
+
+	res = callTool(t, s, ToolParseAuditResults, map[string]interface{}{"raw_output": "", "format": "xml"})
+	if !res.IsError {
+		t.Errorf("expected unsupported format to fail")
+	}
+}
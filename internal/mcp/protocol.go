@@ -0,0 +1,130 @@
+// Package mcp implements the subset of the Model Context Protocol needed to
+// expose audit query tools to MCP clients: JSON-RPC 2.0 framing, the
+// initialize handshake, ping, tools/list and tools/call, over stdio or
+// streamable HTTP.
+package mcp
+
+import (
+	"encoding/json"
+)
+
+// ProtocolVersion is the MCP protocol revision implemented by this server.
+const ProtocolVersion = "2025-03-26"
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Request is a JSON-RPC 2.0 request or notification. Notifications have no ID.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification reports whether the request expects no response.
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// Response is a JSON-RPC 2.0 response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Implementation identifies an MCP client or server.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeParams are sent by the client to start a session.
+type InitializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities,omitempty"`
+	ClientInfo      Implementation         `json:"clientInfo"`
+}
+
+// InitializeResult is returned in response to initialize.
+type InitializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      Implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// Tool describes a tool exposed by the server.
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// ListToolsResult is returned in response to tools/list.
+type ListToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// CallToolParams are sent by the client to invoke a tool.
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Content is a single content block in a tool result.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult is returned in response to tools/call. Tool failures are
+// reported with IsError rather than as JSON-RPC errors so the model can see them.
+type CallToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// TextResult creates a successful tool result with a single text block.
+func TextResult(text string) *CallToolResult {
+	return &CallToolResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// JSONResult creates a successful tool result carrying v as structured content
+// and as its JSON text rendering, for clients that only read text blocks.
+func JSONResult(v interface{}) (*CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &CallToolResult{
+		Content:           []Content{{Type: "text", Text: string(data)}},
+		StructuredContent: v,
+	}, nil
+}
+
+// ErrorResult creates a tool result reporting a tool-level failure.
+func ErrorResult(err error) *CallToolResult {
+	return &CallToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}
+}
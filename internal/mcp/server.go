@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ToolHandler executes a tool call with the raw JSON arguments.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error)
+
+// registeredTool pairs a tool definition with its handler.
+type registeredTool struct {
+	tool    Tool
+	handler ToolHandler
+}
+
+// Server dispatches MCP requests to registered tools.
+type Server struct {
+	info         Implementation
+	instructions string
+	logger       *log.Logger
+
+	mu    sync.RWMutex
+	tools map[string]registeredTool
+}
+
+// NewServer creates an MCP server with the given implementation info. Logs go
+// to stderr because stdout carries the protocol on the stdio transport.
+func NewServer(name, version string) *Server {
+	return &Server{
+		info:   Implementation{Name: name, Version: version},
+		logger: log.New(log.Writer(), "[MCPServer] ", log.LstdFlags),
+		tools:  make(map[string]registeredTool),
+	}
+}
+
+// SetInstructions sets the usage instructions returned from initialize.
+func (s *Server) SetInstructions(instructions string) {
+	s.instructions = instructions
+}
+
+// SetLogger overrides the server logger.
+func (s *Server) SetLogger(logger *log.Logger) {
+	if logger != nil {
+		s.logger = logger
+	}
+}
+
+// RegisterTool registers a tool. Registering a name twice replaces the earlier tool.
+func (s *Server) RegisterTool(tool Tool, handler ToolHandler) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool '%s' handler cannot be nil", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]interface{}{"type": "object"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[tool.Name] = registeredTool{tool: tool, handler: handler}
+	return nil
+}
+
+// Tools returns the registered tools sorted by name.
+func (s *Server) Tools() []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tools := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, t.tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// HandleMessage processes a single JSON-RPC message and returns the encoded
+// response, or nil for notifications. Batches are not supported.
+func (s *Server) HandleMessage(ctx context.Context, data []byte) []byte {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return s.encode(&Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: "parse error: " + err.Error()}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.IsNotification() {
+			return nil
+		}
+		return s.encode(&Response{JSONRPC: "2.0", ID: req.ID, Error: &Error{Code: CodeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}})
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	if req.IsNotification() {
+		return nil
+	}
+	resp := &Response{JSONRPC: "2.0", ID: req.ID}
+	if rpcErr != nil {
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+	return s.encode(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, req *Request) (interface{}, *Error) {
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+			}
+		}
+		s.logger.Printf("initialize from %s %s (protocol %s)", params.ClientInfo.Name, params.ClientInfo.Version, params.ProtocolVersion)
+		return &InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: map[string]interface{}{
+				"tools": map[string]interface{}{"listChanged": false},
+			},
+			ServerInfo:   s.info,
+			Instructions: s.instructions,
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return &ListToolsResult{Tools: s.Tools()}, nil
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+		}
+		s.mu.RLock()
+		t, ok := s.tools[params.Name]
+		s.mu.RUnlock()
+		if !ok {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool '%s'", params.Name)}
+		}
+		s.logger.Printf("tools/call %s", params.Name)
+		result, err := t.handler(ctx, params.Arguments)
+		if err != nil {
+			return ErrorResult(err), nil
+		}
+		return result, nil
+	}
+	return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+func (s *Server) encode(resp *Response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		s.logger.Printf("failed to encode response: %v", err)
+		data, _ = json.Marshal(&Response{JSONRPC: "2.0", ID: resp.ID, Error: &Error{Code: CodeInternalError, Message: "failed to encode response"}})
+	}
+	return data
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted or ctx is canceled.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp := s.HandleMessage(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if _, err := w.Write(append(resp, '\n')); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// HTTPHandler returns a streamable HTTP transport handler. Each POST carries
+// one JSON-RPC message and receives a JSON response; notifications are
+// acknowledged with 202 Accepted. A session ID is issued on initialize.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 16*1024*1024))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		var probe Request
+		isInitialize := json.Unmarshal(body, &probe) == nil && probe.Method == "initialize"
+
+		resp := s.HandleMessage(r.Context(), body)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if isInitialize {
+			w.Header().Set("Mcp-Session-Id", uuid.NewString())
+		} else if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+			w.Header().Set("Mcp-Session-Id", id)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(resp); err != nil {
+			s.logger.Printf("failed to write HTTP response: %v", err)
+		}
+	})
+}
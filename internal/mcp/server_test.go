@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer("test", "0.0.1")
+	s.SetLogger(log.New(io.Discard, "", 0))
+	err := s.RegisterTool(Tool{Name: "echo", Description: "Echo the message"}, func(ctx context.Context, args json.RawMessage) (*CallToolResult, error) {
+		var in struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return nil, err
+		}
+		if in.Message == "" {
+			return nil, fmt.Errorf("message is required")
+		}
+		return TextResult(in.Message), nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	return s
+}
+
+func call(t *testing.T, s *Server, msg string) *Response {
+	t.Helper()
+	out := s.HandleMessage(context.Background(), []byte(msg))
+	if out == nil {
+		t.Fatalf("expected a response for %s", msg)
+	}
+	var resp Response
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", out, err)
+	}
+	return &resp
+}
+
+func TestServer_HandleMessage(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"c","version":"1"}}}`)
+	if resp.Error != nil {
+		t.Fatalf("initialize failed: %v", resp.Error)
+	}
+	result := resp.Result.(map[string]interface{})
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("unexpected protocol version: %v", result["protocolVersion"])
+	}
+
+	if out := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); out != nil {
+		t.Errorf("notifications must not be answered, got %s", out)
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	tools := resp.Result.(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 1 || tools[0].(map[string]interface{})["name"] != "echo" {
+		t.Errorf("unexpected tools: %v", tools)
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}}}`)
+	content := resp.Result.(map[string]interface{})["content"].([]interface{})
+	if content[0].(map[string]interface{})["text"] != "hi" {
+		t.Errorf("unexpected tool result: %v", resp.Result)
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`)
+	if resp.Error != nil || resp.Result.(map[string]interface{})["isError"] != true {
+		t.Errorf("tool failures should be reported with isError, got %+v", resp)
+	}
+
+	tests := []struct {
+		msg  string
+		code int
+	}{
+		{`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`, CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"missing"}}`, CodeInvalidParams},
+		{`{"id":7,"method":"ping"}`, CodeInvalidRequest},
+		{`{not json`, CodeParseError},
+	}
+	for _, tt := range tests {
+		resp := call(t, s, tt.msg)
+		if resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: expected error code %d, got %+v", tt.msg, tt.code, resp.Error)
+		}
+	}
+}
+
+func TestServer_ServeStdio(t *testing.T) {
+	s := newTestServer(t)
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		``,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+}
+
+func TestServer_HTTPHandler(t *testing.T) {
+	srv := httptest.NewServer(newTestServer(t).HTTPHandler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Mcp-Session-Id") == "" {
+		t.Errorf("expected 200 with a session ID, got %d %q", resp.StatusCode, resp.Header.Get("Mcp-Session-Id"))
+	}
+
+	resp, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("expected 202 for notification, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
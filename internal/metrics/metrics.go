@@ -0,0 +1,310 @@
+// Package metrics collects counters and histograms and writes them in the
+// Prometheus text exposition format. It implements the small subset of the
+// Prometheus client used by the processing pipeline without pulling in an
+// external dependency.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are latency buckets in seconds, suited to LLM round trips.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// collector is a metric family the registry writes.
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families and writes them for scraping. It is safe
+// for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]collector
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]collector)}
+}
+
+// Default is the registry the pipeline metrics are registered with.
+var Default = NewRegistry()
+
+// register adds a family, panicking on a duplicate name like the Prometheus
+// client's MustRegister.
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.families[name]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %s", name))
+	}
+	r.families[name] = c
+}
+
+// NewCounterVec registers a counter family partitioned by the label names.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{family: newFamily(name, help, labelNames), counters: make(map[string]*Counter)}
+	r.register(name, c)
+	return c
+}
+
+// NewHistogramVec registers a histogram family partitioned by the label
+// names. Nil buckets use DefaultBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{family: newFamily(name, help, labelNames), buckets: buckets, histograms: make(map[string]*Histogram)}
+	r.register(name, h)
+	return h
+}
+
+// Write writes every family in the Prometheus text format, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	families := make([]collector, len(names))
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registry's metrics to Prometheus scrapers.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Only GET method is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.Write(w); err != nil {
+			http.Error(w, "failed to write metrics", http.StatusInternalServerError)
+		}
+	})
+}
+
+// family is the name, help and label names shared by a metric's series.
+type family struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+func newFamily(name, help string, labelNames []string) family {
+	return family{name: name, help: help, labelNames: labelNames}
+}
+
+// key identifies a series by its label values.
+func (f family) key(labelValues []string) string {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// labels formats label pairs, with an optional extra pair such as le.
+func (f family) labels(labelValues []string, extraName, extraValue string) string {
+	var pairs []string
+	for i, name := range f.labelNames {
+		pairs = append(pairs, name+"=\""+escapeLabel(labelValues[i])+"\"")
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+"=\""+extraValue+"\"")
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (f family) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, kind)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns series keys in a stable order for output.
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct {
+	family
+	mu       sync.Mutex
+	counters map[string]*Counter
+}
+
+// With returns the counter for the label values, in label name order,
+// creating it at zero.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	key := v.key(labelValues)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, ok := v.counters[key]
+	if !ok {
+		c = &Counter{labelValues: append([]string(nil), labelValues...)}
+		v.counters[key] = c
+	}
+	return c
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.header(w, "counter")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, key := range sortedKeys(v.counters) {
+		c := v.counters[key]
+		fmt.Fprintf(w, "%s%s %s\n", v.name, v.labels(c.labelValues, "", ""), formatFloat(c.Value()))
+	}
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	labelValues []string
+	mu          sync.Mutex
+	value       float64
+}
+
+// Inc adds one.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds a non-negative amount; negative amounts are ignored.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// HistogramVec is a family of histograms partitioned by labels.
+type HistogramVec struct {
+	family
+	buckets    []float64
+	mu         sync.Mutex
+	histograms map[string]*Histogram
+}
+
+// With returns the histogram for the label values, in label name order,
+// creating it empty.
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	key := v.key(labelValues)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	h, ok := v.histograms[key]
+	if !ok {
+		h = &Histogram{labelValues: append([]string(nil), labelValues...), buckets: v.buckets, counts: make([]uint64, len(v.buckets))}
+		v.histograms[key] = h
+	}
+	return h
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.header(w, "histogram")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, key := range sortedKeys(v.histograms) {
+		h := v.histograms[key]
+		counts, count, sum := h.snapshot()
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labels(h.labelValues, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labels(h.labelValues, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, v.labels(h.labelValues, "", ""), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, v.labels(h.labelValues, "", ""), count)
+	}
+}
+
+// Histogram counts observations in buckets and tracks their sum.
+type Histogram struct {
+	labelValues []string
+	buckets     []float64
+	mu          sync.Mutex
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// Observe records a value.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) snapshot() ([]uint64, uint64, float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]uint64(nil), h.counts...), h.count, h.sum
+}
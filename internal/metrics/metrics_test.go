@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	errorsTotal := r.NewCounterVec("test_errors_total", "Errors by code.", "provider", "status_code")
+	latency := r.NewHistogramVec("test_latency_seconds", "Latency.", []float64{1, 0.1}, "stage")
+
+	errorsTotal.With("openai", "429").Inc()
+	errorsTotal.With("openai", "429").Add(2)
+	errorsTotal.With(`we"ird`, "500").Inc()
+	latency.With("parsing").Observe(0.05)
+	latency.With("parsing").Observe(0.5)
+	latency.With("parsing").Observe(3)
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	want := `# HELP test_errors_total Errors by code.
+# TYPE test_errors_total counter
+test_errors_total{provider="openai",status_code="429"} 3
+test_errors_total{provider="we\"ird",status_code="500"} 1
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{stage="parsing",le="0.1"} 1
+test_latency_seconds_bucket{stage="parsing",le="1"} 2
+test_latency_seconds_bucket{stage="parsing",le="+Inf"} 3
+test_latency_seconds_sum{stage="parsing"} 3.55
+test_latency_seconds_count{stage="parsing"} 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("test_total", "Test.", "result")
+
+	assertPanics := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		f()
+	}
+	assertPanics("duplicate name", func() { r.NewCounterVec("test_total", "Test.") })
+	assertPanics("wrong label count", func() { counter.With("success", "extra") })
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test.", "result").With(Result(errors.New("boom"))).Inc()
+
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rr.Body.String(), `test_total{result="failure"} 1`) {
+		t.Errorf("expected the counter in the output, got:\n%s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+// Processing stages timed by StageDuration
+const (
+	StageContextResolution = "context_resolution"
+	StageCacheLookup       = "cache_lookup"
+	StageProviderCall      = "provider_call"
+	StageParsing           = "parsing"
+	StageNormalization     = "normalization"
+	StageValidation        = "validation"
+	StageNamespaceScoping  = "namespace_scoping"
+	StageCommandGeneration = "command_generation"
+)
+
+// ConfidenceBuckets partition the parser confidence of structured queries.
+var ConfidenceBuckets = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1}
+
+// Pipeline metrics, registered with Default.
+var (
+	// RequestDuration times whole requests by outcome: success, rejected or error
+	RequestDuration = Default.NewHistogramVec("genai_request_duration_seconds",
+		"Time to process a query, by outcome.", nil, "outcome")
+
+	// StageDuration times each processing stage
+	StageDuration = Default.NewHistogramVec("genai_stage_duration_seconds",
+		"Time spent in each processing stage.", nil, "stage")
+
+	// ProviderRequests counts provider calls by provider and result: success or failure
+	ProviderRequests = Default.NewCounterVec("genai_provider_requests_total",
+		"LLM provider calls, by provider and result.", "provider", "result")
+
+	// ProviderErrors counts failed provider calls by provider and HTTP status
+	// code; calls that received no response have status code 0
+	ProviderErrors = Default.NewCounterVec("genai_provider_errors_total",
+		"LLM provider errors, by provider and HTTP status code.", "provider", "status_code")
+
+	// RetryAttempts counts parse attempts by strategy and result: success or failure
+	RetryAttempts = Default.NewCounterVec("genai_parser_retry_attempts_total",
+		"Response parse attempts, by retry strategy and result.", "strategy", "result")
+
+	// FallbackQueries counts uses of the fallback handler by result: success or failure
+	FallbackQueries = Default.NewCounterVec("genai_parser_fallback_total",
+		"Minimal queries built by the fallback handler after all strategies failed, by result.", "result")
+
+	// NormalizerRuns counts normalizer runs by normalizer and result: success or failure
+	NormalizerRuns = Default.NewCounterVec("genai_normalizer_runs_total",
+		"Normalization pipeline runs, by normalizer and result.", "normalizer", "result")
+
+	// ValidationFailures counts failed safety validation rules by rule name
+	ValidationFailures = Default.NewCounterVec("genai_validation_failures_total",
+		"Safety validation failures, by rule name.", "rule")
+
+	// Confidence is the parser confidence of the queries the retry parser returns
+	Confidence = Default.NewHistogramVec("genai_parser_confidence",
+		"Parser confidence of the structured queries returned by the retry parser.", ConfidenceBuckets)
+)
+
+// Result returns "success" for a nil error and "failure" otherwise, the
+// result label of the pipeline counters.
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
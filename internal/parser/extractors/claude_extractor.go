@@ -11,7 +11,8 @@ import (
 )
 
 // ClaudeExtractor implements the Parser interface for Claude model responses.
-// It handles Claude-specific output formats including markdown-wrapped JSON
+// It reads the structured_query tool call when the model made one, otherwise
+// handles Claude-specific output formats including markdown-wrapped JSON,
 // and provides confidence scoring for parsing quality assessment.
 type ClaudeExtractor struct {
 	confidence float64
@@ -34,6 +35,15 @@ func (c *ClaudeExtractor) ParseResponse(raw *types.RawResponse, modelType string
 	// Reset confidence for new parsing attempt
 	c.confidence = 0.0
 
+	// Prefer the structured_query tool call requested by the input adapter
+	if query, ok, err := parseToolCall(raw, c.validateQuery); ok {
+		if err != nil {
+			return nil, err
+		}
+		c.confidence = toolCallConfidence
+		return query, nil
+	}
+
 	// Extract content from raw response
 	content, err := c.extractContent(raw.Content)
 	if err != nil {
@@ -0,0 +1,183 @@
+package extractors
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// thinkBlockPattern matches the reasoning blocks that models such as
+// DeepSeek-R1 and Qwen3 emit before their answer.
+var thinkBlockPattern = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// OllamaExtractor implements the Parser interface for self-hosted open-source
+// models served by Ollama or vLLM. Constrained decoding usually yields bare
+// JSON, but models run without it may wrap the answer in reasoning blocks,
+// code fences or prose, which are stripped before parsing.
+type OllamaExtractor struct {
+	confidence float64
+}
+
+// NewOllamaExtractor creates a new instance of OllamaExtractor.
+func NewOllamaExtractor() *OllamaExtractor {
+	return &OllamaExtractor{
+		confidence: 0.0,
+	}
+}
+
+// ParseResponse parses a raw response from a self-hosted model into structured format.
+func (o *OllamaExtractor) ParseResponse(raw *types.RawResponse, modelType string) (*types.StructuredQuery, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("raw response is nil")
+	}
+
+	// Reset confidence for new parsing attempt
+	o.confidence = 0.0
+
+	content, err := o.extractContent(raw.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract content: %w", err)
+	}
+
+	var query types.StructuredQuery
+	if err := json.Unmarshal([]byte(content), &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	if err := o.validateQuery(&query); err != nil {
+		return nil, fmt.Errorf("query validation failed: %w", err)
+	}
+
+	o.confidence = o.calculateConfidence(raw.Content, content)
+
+	return &query, nil
+}
+
+// CanHandle determines whether this parser can handle responses from
+// self-hosted models.
+func (o *OllamaExtractor) CanHandle(modelType string) bool {
+	patterns := []string{
+		"ollama",
+		"vllm",
+		"llama",
+		"mistral",
+		"mixtral",
+		"qwen",
+		"gemma",
+		"phi",
+		"deepseek",
+	}
+
+	modelTypeLower := strings.ToLower(modelType)
+	for _, pattern := range patterns {
+		if strings.Contains(modelTypeLower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConfidence returns the confidence score of the last parsing operation.
+func (o *OllamaExtractor) GetConfidence() float64 {
+	return o.confidence
+}
+
+// extractContent returns the JSON object in the response, without reasoning
+// blocks, code fences or surrounding prose.
+func (o *OllamaExtractor) extractContent(rawContent string) (string, error) {
+	content := strings.TrimSpace(thinkBlockPattern.ReplaceAllString(rawContent, ""))
+	if content == "" {
+		return "", fmt.Errorf("raw content is empty")
+	}
+
+	// Handle markdown-wrapped JSON (```json...```)
+	codeBlockPattern := regexp.MustCompile("(?s)```(?:json)?\\s*(.*?)\\s*```")
+	if matches := codeBlockPattern.FindStringSubmatch(content); len(matches) > 1 {
+		content = strings.TrimSpace(matches[1])
+	}
+
+	start := strings.Index(content, "{")
+	if start == -1 {
+		return "", fmt.Errorf("no valid JSON content found in response")
+	}
+	end := findMatchingBrace(content, start)
+	if end == -1 {
+		return "", fmt.Errorf("unterminated JSON object in response")
+	}
+
+	// Remove any trailing commas before closing braces/brackets
+	return regexp.MustCompile(`,(\s*[}\]])`).ReplaceAllString(content[start:end+1], "$1"), nil
+}
+
+// validateQuery validates the parsed query for required fields and constraints.
+func (o *OllamaExtractor) validateQuery(query *types.StructuredQuery) error {
+	if query.LogSource == "" {
+		return fmt.Errorf("log_source is required")
+	}
+
+	type enumCheck struct {
+		field string
+		value string
+		valid []string
+	}
+	checks := []enumCheck{
+		{"log_source", query.LogSource, []string{"kube-apiserver", "openshift-apiserver", "oauth-server", "oauth-apiserver"}},
+		{"auth_decision", query.AuthDecision, []string{"allow", "error", "forbid"}},
+		{"sort_order", query.SortOrder, []string{"asc", "desc"}},
+		{"sort_by", query.SortBy, []string{"timestamp", "user", "resource", "count"}},
+	}
+	if query.Analysis != nil {
+		checks = append(checks, enumCheck{"analysis.type", query.Analysis.Type, []string{"multi_namespace_access", "excessive_reads", "privilege_escalation", "anomaly_detection", "correlation"}})
+	}
+
+	for _, check := range checks {
+		if check.value == "" {
+			continue
+		}
+		found := false
+		for _, valid := range check.valid {
+			if check.value == valid {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid %s: %s, must be one of %v", check.field, check.value, check.valid)
+		}
+	}
+
+	if query.Limit < 0 || query.Limit > 1000 {
+		return fmt.Errorf("limit must be between 1 and 1000, got %d", query.Limit)
+	}
+
+	return nil
+}
+
+// calculateConfidence scores how much of the response had to be discarded
+// to reach the JSON object. Bare JSON, as produced by constrained decoding,
+// scores highest.
+func (o *OllamaExtractor) calculateConfidence(rawContent, jsonContent string) float64 {
+	confidence := 0.95
+
+	if thinkBlockPattern.MatchString(rawContent) {
+		confidence -= 0.05
+	}
+	if strings.Contains(rawContent, "```") {
+		confidence -= 0.1
+	}
+	if strings.TrimSpace(thinkBlockPattern.ReplaceAllString(rawContent, "")) != jsonContent && !strings.Contains(rawContent, "```") {
+		confidence -= 0.15 // JSON was surrounded by prose
+	}
+
+	if confidence < 0.0 {
+		confidence = 0.0
+	}
+	return confidence
+}
+
+// Ensure OllamaExtractor implements the Parser interface
+var _ interfaces.Parser = (*OllamaExtractor)(nil)
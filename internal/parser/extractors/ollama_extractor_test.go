@@ -0,0 +1,114 @@
+package extractors
+
+import (
+	"testing"
+
+	"genai-processing/pkg/types"
+)
+
+func TestOllamaExtractor_CanHandle(t *testing.T) {
+	extractor := NewOllamaExtractor()
+
+	tests := []struct {
+		modelType string
+		expected  bool
+	}{
+		{"ollama", true},
+		{"vllm", true},
+		{"llama3.1:8b", true},
+		{"mistral:7b", true},
+		{"Qwen/Qwen2.5-7B-Instruct", true},
+		{"claude", false},
+		{"gpt-4", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := extractor.CanHandle(tt.modelType); got != tt.expected {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.modelType, got, tt.expected)
+		}
+	}
+}
+
+func TestOllamaExtractor_ParseResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantErr       bool
+		wantSource    string
+		minConfidence float64
+		maxConfidence float64
+	}{
+		{
+			name:          "bare JSON from constrained decoding",
+			content:       `{"log_source":"kube-apiserver","verb":"delete","resource":"pods"}`,
+			wantSource:    "kube-apiserver",
+			minConfidence: 0.9,
+			maxConfidence: 1.0,
+		},
+		{
+			name:          "reasoning block before answer",
+			content:       "<think>\nThe user wants deletions.\n{not json}\n</think>\n{\"log_source\":\"oauth-server\"}",
+			wantSource:    "oauth-server",
+			minConfidence: 0.85,
+			maxConfidence: 0.9,
+		},
+		{
+			name:          "markdown code fence",
+			content:       "```json\n{\"log_source\":\"openshift-apiserver\",}\n```",
+			wantSource:    "openshift-apiserver",
+			minConfidence: 0.5,
+			maxConfidence: 0.85,
+		},
+		{
+			name:          "JSON surrounded by prose",
+			content:       "Here is the query: {\"log_source\":\"kube-apiserver\"} Hope this helps.",
+			wantSource:    "kube-apiserver",
+			minConfidence: 0.5,
+			maxConfidence: 0.8,
+		},
+		{
+			name:    "invalid log source",
+			content: `{"log_source":"node-logs"}`,
+			wantErr: true,
+		},
+		{
+			name:    "no JSON",
+			content: "I cannot help with that.",
+			wantErr: true,
+		},
+		{
+			name:    "only reasoning",
+			content: "<think>hmm</think>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor := NewOllamaExtractor()
+			query, err := extractor.ParseResponse(&types.RawResponse{Content: tt.content}, "ollama")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got query %+v", query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query.LogSource != tt.wantSource {
+				t.Errorf("LogSource = %q, want %q", query.LogSource, tt.wantSource)
+			}
+			if c := extractor.GetConfidence(); c < tt.minConfidence || c > tt.maxConfidence {
+				t.Errorf("confidence = %f, want between %f and %f", c, tt.minConfidence, tt.maxConfidence)
+			}
+		})
+	}
+}
+
+func TestOllamaExtractor_ParseResponse_NilResponse(t *testing.T) {
+	if _, err := NewOllamaExtractor().ParseResponse(nil, "ollama"); err == nil {
+		t.Error("expected error for nil response")
+	}
+}
@@ -11,8 +11,9 @@ import (
 )
 
 // OpenAIExtractor implements the Parser interface for OpenAI model responses.
-// It handles OpenAI-specific output formats including clean JSON responses
-// and function call responses, providing confidence scoring for parsing quality assessment.
+// It reads the structured_query function call when the model made one, and
+// otherwise handles OpenAI-specific output formats including clean JSON
+// responses, providing confidence scoring for parsing quality assessment.
 type OpenAIExtractor struct {
 	confidence float64
 }
@@ -34,6 +35,15 @@ func (o *OpenAIExtractor) ParseResponse(raw *types.RawResponse, modelType string
 	// Reset confidence for new parsing attempt
 	o.confidence = 0.0
 
+	// Prefer the structured_query tool call requested by the input adapter
+	if query, ok, err := parseToolCall(raw, o.validateQuery); ok {
+		if err != nil {
+			return nil, err
+		}
+		o.confidence = toolCallConfidence
+		return query, nil
+	}
+
 	// Extract content from raw response
 	content, err := o.extractContent(raw.Content)
 	if err != nil {
@@ -0,0 +1,33 @@
+package extractors
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"genai-processing/internal/schema"
+	"genai-processing/pkg/types"
+)
+
+// toolCallConfidence is the confidence of a query read from the
+// structured_query tool call. The provider constrained the arguments to the
+// query schema, so nothing was scraped or repaired.
+const toolCallConfidence = 1.0
+
+// parseToolCall reads the query from the response's structured_query tool
+// call. It reports false when the model made no such call, in which case the
+// caller falls back to extracting JSON from the response text.
+func parseToolCall(raw *types.RawResponse, validate func(*types.StructuredQuery) error) (*types.StructuredQuery, bool, error) {
+	call := raw.FindToolCall(schema.ToolName)
+	if call == nil {
+		return nil, false, nil
+	}
+
+	var query types.StructuredQuery
+	if err := json.Unmarshal([]byte(call.Arguments), &query); err != nil {
+		return nil, true, fmt.Errorf("failed to unmarshal %s tool call arguments: %w", schema.ToolName, err)
+	}
+	if err := validate(&query); err != nil {
+		return nil, true, fmt.Errorf("query validation failed: %w", err)
+	}
+	return &query, true, nil
+}
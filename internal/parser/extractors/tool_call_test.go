@@ -0,0 +1,45 @@
+package extractors
+
+import (
+	"testing"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+func TestExtractors_ParseToolCall(t *testing.T) {
+	parsers := map[string]interfaces.Parser{
+		"claude": NewClaudeExtractor(),
+		"openai": NewOpenAIExtractor(),
+	}
+
+	for name, parser := range parsers {
+		t.Run(name, func(t *testing.T) {
+			raw := &types.RawResponse{
+				Content: "I'll look that up.",
+				ToolCalls: []types.ToolCall{
+					{Name: "other_tool", Arguments: `{}`},
+					{ID: "call_1", Name: "structured_query", Arguments: `{"log_source":"kube-apiserver","verb":"delete","resource":"pods"}`},
+				},
+			}
+			query, err := parser.ParseResponse(raw, name)
+			if err != nil {
+				t.Fatalf("ParseResponse returned error: %v", err)
+			}
+			if query.LogSource != "kube-apiserver" || query.Verb.GetString() != "delete" {
+				t.Errorf("unexpected query %+v", query)
+			}
+			if parser.GetConfidence() != toolCallConfidence {
+				t.Errorf("confidence = %f, want %f", parser.GetConfidence(), toolCallConfidence)
+			}
+
+			raw.ToolCalls[1].Arguments = `{"log_source":"node-logs"}`
+			if _, err := parser.ParseResponse(raw, name); err == nil {
+				t.Error("expected a tool call with an invalid log_source to be rejected")
+			}
+			if parser.GetConfidence() != 0 {
+				t.Errorf("expected confidence to be reset, got %f", parser.GetConfidence())
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"genai-processing/internal/metrics"
+	"genai-processing/internal/schema"
 	"genai-processing/pkg/errors"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
@@ -123,9 +125,18 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 	// Define the retry strategy chain
 	strategies := []RetryStrategy{StrategySpecific, StrategyGeneric, StrategyError}
 
-	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
+	// A structured_query tool call was constrained to the query schema by the
+	// provider, so parsing it again cannot succeed where the first pass
+	// failed; only re-prompting can produce a different answer.
+	maxRetries := r.config.MaxRetries
+	if raw.FindToolCall(schema.ToolName) != nil {
+		maxRetries = 0
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
 		for _, strategy := range strategies {
 			result := r.tryParseWithStrategy(ctx, raw, modelType, strategy, attempt, originalQuery, sessionID)
+			result.record()
 
 			// Update best result if this attempt is better
 			if result.Success && (bestResult == nil || result.Confidence > bestResult.Confidence) {
@@ -134,6 +145,7 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 
 			// If we have a successful result above threshold, return it
 			if result.Success && result.Confidence >= r.config.ConfidenceThreshold {
+				metrics.Confidence.With().Observe(result.Confidence)
 				return result.Query, nil
 			}
 
@@ -145,7 +157,9 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 			// If this is a successful result but below threshold, try re-prompting
 			if result.Success && result.Confidence < r.config.ConfidenceThreshold && r.config.EnableReprompting {
 				if repromptResult := r.tryReprompting(ctx, raw, modelType, originalQuery, sessionID, result); repromptResult != nil {
+					repromptResult.record()
 					if repromptResult.Success && repromptResult.Confidence >= r.config.ConfidenceThreshold {
+						metrics.Confidence.With().Observe(repromptResult.Confidence)
 						return repromptResult.Query, nil
 					}
 					if repromptResult.Confidence > bestResult.Confidence {
@@ -156,7 +170,7 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 		}
 
 		// If this is not the last attempt, wait before retrying
-		if attempt < r.config.MaxRetries {
+		if attempt < maxRetries {
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -168,12 +182,18 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 
 	// If we have a best result, return it even if below threshold
 	if bestResult != nil && bestResult.Success {
+		metrics.Confidence.With().Observe(bestResult.Confidence)
 		return bestResult.Query, nil
 	}
 
 	// Use fallback handler if configured
 	if r.fallbackHandler != nil {
-		if fallback, ferr := r.fallbackHandler.CreateMinimalQuery(raw, modelType, originalQuery); ferr == nil && fallback != nil {
+		fallback, ferr := r.fallbackHandler.CreateMinimalQuery(raw, modelType, originalQuery)
+		if ferr == nil && fallback == nil {
+			ferr = fmt.Errorf("fallback handler returned no query")
+		}
+		metrics.FallbackQueries.With(metrics.Result(ferr)).Inc()
+		if ferr == nil {
 			return fallback, nil
 		}
 	}
@@ -199,6 +219,15 @@ func (r *RetryParser) ParseWithRetry(ctx context.Context, raw *types.RawResponse
 		)
 }
 
+// record counts the attempt in the retry attempt metrics.
+func (result *RetryResult) record() {
+	outcome := "failure"
+	if result.Success {
+		outcome = "success"
+	}
+	metrics.RetryAttempts.With(string(result.Strategy), outcome).Inc()
+}
+
 // tryParseWithStrategy attempts to parse using a specific strategy.
 func (r *RetryParser) tryParseWithStrategy(_ context.Context, raw *types.RawResponse, modelType string, strategy RetryStrategy, attempt int, _ string, _ string) *RetryResult {
 	startTime := time.Now()
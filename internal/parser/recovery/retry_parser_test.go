@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"genai-processing/internal/metrics"
 	"genai-processing/pkg/errors"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
@@ -411,6 +412,44 @@ func TestRetryParser_ParseWithRetry_AllStrategiesFail(t *testing.T) {
 	}
 }
 
+func TestRetryParser_ParseWithRetry_Metrics(t *testing.T) {
+	config := &RetryConfig{MaxRetries: 0, ConfidenceThreshold: 0.8}
+	retryParser := NewRetryParser(config, nil, nil)
+	retryParser.RegisterParser(StrategySpecific, NewMockParser(true, true, 0.0))
+	genericParser := NewMockParser(true, false, 0.9)
+	genericParser.parseResults = []*types.StructuredQuery{createTestStructuredQuery()}
+	retryParser.RegisterParser(StrategyGeneric, genericParser)
+
+	specificFailures := metrics.RetryAttempts.With(string(StrategySpecific), "failure").Value()
+	genericSuccesses := metrics.RetryAttempts.With(string(StrategyGeneric), "success").Value()
+	confidences := metrics.Confidence.With().Count()
+
+	if _, err := retryParser.ParseWithRetry(context.Background(), createTestRawResponse(`{"log_source": "kube-apiserver"}`), "claude", "test query", "test-session"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := metrics.RetryAttempts.With(string(StrategySpecific), "failure").Value() - specificFailures; got != 1 {
+		t.Errorf("expected 1 failed specific attempt, got %v", got)
+	}
+	if got := metrics.RetryAttempts.With(string(StrategyGeneric), "success").Value() - genericSuccesses; got != 1 {
+		t.Errorf("expected 1 successful generic attempt, got %v", got)
+	}
+	if got := metrics.Confidence.With().Count() - confidences; got != 1 {
+		t.Errorf("expected the returned query's confidence to be observed, got %d observations", got)
+	}
+
+	// Once every strategy fails the fallback handler's use is counted
+	failing := NewRetryParser(config, nil, nil)
+	failing.RegisterParser(StrategySpecific, NewMockParser(true, true, 0.0))
+	failing.SetFallbackHandler(NewFallbackHandler())
+	fallbacks := metrics.FallbackQueries.With("success").Value()
+	if _, err := failing.ParseWithRetry(context.Background(), createTestRawResponse(`not json`), "claude", "who deleted pods", "test-session"); err != nil {
+		t.Fatalf("expected the fallback handler to build a query, got %v", err)
+	}
+	if got := metrics.FallbackQueries.With("success").Value() - fallbacks; got != 1 {
+		t.Errorf("expected 1 fallback query, got %v", got)
+	}
+}
+
 func TestRetryParser_ParseWithRetry_ContextCancellation(t *testing.T) {
 	config := &RetryConfig{
 		MaxRetries:          5,
@@ -733,3 +772,33 @@ func TestRetryParser_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestRetryParser_ParseWithRetry_ToolCallNotRetried(t *testing.T) {
+	config := &RetryConfig{
+		MaxRetries:          3,
+		RetryDelay:          time.Second,
+		ConfidenceThreshold: 0.8,
+		EnableReprompting:   false,
+	}
+
+	retryParser := NewRetryParser(config, nil, nil)
+	failingParser := NewMockParser(true, true, 0.0)
+	retryParser.RegisterParser(StrategySpecific, failingParser)
+
+	raw := &types.RawResponse{
+		Content:   `{"log_source": "node-logs"}`,
+		ToolCalls: []types.ToolCall{{Name: "structured_query", Arguments: `{"log_source": "node-logs"}`}},
+	}
+
+	start := time.Now()
+	_, err := retryParser.ParseWithRetry(context.Background(), raw, "claude", "test query", "test-session")
+	if err == nil {
+		t.Fatal("expected error for a rejected tool call")
+	}
+	if failingParser.callCount != 1 {
+		t.Errorf("expected the tool call to be parsed once, got %d calls", failingParser.callCount)
+	}
+	if elapsed := time.Since(start); elapsed >= config.RetryDelay {
+		t.Errorf("expected no retry delay, took %v", elapsed)
+	}
+}
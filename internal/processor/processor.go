@@ -8,18 +8,28 @@ import (
 	"strings"
 	"time"
 
+	"genai-processing/internal/audittrail"
+	"genai-processing/internal/auth"
+	"genai-processing/internal/authz"
+	"genai-processing/internal/cache"
 	"genai-processing/internal/config"
 	contextpkg "genai-processing/internal/context"
 	"genai-processing/internal/engine"
-	"genai-processing/internal/engine/adapters"
 	"genai-processing/internal/engine/providers"
+	"genai-processing/internal/generator"
+	"genai-processing/internal/metrics"
 	"genai-processing/internal/parser/extractors"
 	norm "genai-processing/internal/parser/normalizers"
 	"genai-processing/internal/parser/recovery"
-	promptformatters "genai-processing/internal/prompts/formatters"
+	"genai-processing/internal/redisclient"
+	"genai-processing/internal/schema"
+	"genai-processing/internal/usage"
 	"genai-processing/internal/validator"
+	pkgerrors "genai-processing/pkg/errors"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
+
+	"github.com/google/uuid"
 )
 
 // GenAIProcessor implements the complete processing pipeline for natural language
@@ -32,6 +42,9 @@ type GenAIProcessor struct {
 	RetryParser     *recovery.RetryParser
 	safetyValidator interfaces.SafetyValidator
 
+	// commandGenerator compiles validated queries into oc/jq commands
+	commandGenerator *generator.CommandGenerator
+
 	// Configuration
 	defaultModel string
 	logger       *log.Logger
@@ -43,6 +56,42 @@ type GenAIProcessor struct {
 
 	// Prompt validation settings from prompts.yaml
 	promptValidation config.PromptValidation
+
+	// namespaceScoper limits queries to the namespaces the caller may access; nil disables scoping
+	namespaceScoper *authz.Scoper
+
+	// defaultProvider is the models.yaml name of the provider behind llmEngine
+	defaultProvider string
+
+	// modelSelector ranks providers by health for failover; nil sends every
+	// request to the default provider
+	modelSelector *engine.ModelSelector
+
+	// routes holds each provider the selector may choose, keyed by models.yaml name
+	routes map[string]*providerRoute
+
+	// routeOrder is the default provider followed by the fallback providers
+	routeOrder []string
+
+	// querySchema is the StructuredQuery JSON Schema embedded in system prompts
+	querySchema map[string]interface{}
+
+	// responseCache serves repeated queries without an LLM call; nil disables caching
+	responseCache cache.Cache
+
+	// semanticCache reuses queries for near-duplicate questions when the
+	// response cache misses; nil disables it
+	semanticCache *cache.SemanticCache
+
+	// auditTrail records every processed query (FR-016); nil disables it
+	auditTrail audittrail.Store
+
+	// accountant prices provider token usage and enforces per-user budgets;
+	// nil disables accounting
+	accountant *usage.Accountant
+
+	// reload guards the fields Reload replaces; nil disables reloading
+	reload *reloadLocks
 }
 
 // NewGenAIProcessorWithDeps creates a new instance of GenAIProcessor with injected dependencies.
@@ -54,12 +103,14 @@ func NewGenAIProcessorWithDeps(
 	safetyValidator interfaces.SafetyValidator,
 ) *GenAIProcessor {
 	return &GenAIProcessor{
-		contextManager:  contextManager,
-		llmEngine:       llmEngine,
-		RetryParser:     retryParser,
-		safetyValidator: safetyValidator,
-		defaultModel:    "claude-3-5-sonnet-20241022",
-		logger:          log.New(log.Writer(), "[GenAIProcessor] ", log.LstdFlags),
+		contextManager:   contextManager,
+		llmEngine:        llmEngine,
+		RetryParser:      retryParser,
+		safetyValidator:  safetyValidator,
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		logger:           log.New(log.Writer(), "[GenAIProcessor] ", log.LstdFlags),
+		reload:           &reloadLocks{},
 	}
 }
 
@@ -125,245 +176,226 @@ func NewGenAIProcessorFromConfig(appConfig *config.AppConfig) (*GenAIProcessor,
 
 	logger := log.New(log.Writer(), "[GenAIProcessor] ", log.LstdFlags)
 
-	// Initialize context manager
-	contextManager := contextpkg.NewContextManager()
-
-	// Build provider factory and map configs
-	factory := providers.NewProviderFactory()
-
-	// Helper to convert ModelConfig.Parameters (map[string]string) to map[string]interface{}
-	toIfaceParams := func(mc config.ModelConfig) map[string]interface{} {
-		result := map[string]interface{}{}
-		if mc.Parameters != nil {
-			for k, v := range mc.Parameters {
-				// Try to parse int
-				var intVal int
-				var floatVal float64
-				if _, err := fmt.Sscanf(v, "%d", &intVal); err == nil {
-					result[k] = intVal
-					continue
-				}
-				if _, err := fmt.Sscanf(v, "%f", &floatVal); err == nil {
-					result[k] = floatVal
-					continue
-				}
-				// fallback to string
-				result[k] = v
-			}
-		}
-		// Ensure core numeric params present from top-level too
-		if mc.MaxTokens > 0 {
-			result["max_tokens"] = mc.MaxTokens
-		}
-		if mc.Temperature >= 0 {
-			result["temperature"] = mc.Temperature
-		}
-		return result
+	// Initialize context manager backed by the configured session store
+	contextManager, err := newContextManager(appConfig.Sessions)
+	if err != nil {
+		return nil, err
 	}
+	logger.Printf("Session store: %s", sessionStoreName(appConfig.Sessions))
 
-	// Register all providers (best-effort; duplicates for 'generic' may overwrite)
-	for name, mc := range appConfig.Models.Providers {
-		cfg := &types.ProviderConfig{
-			APIKey:     mc.APIKey,
-			Endpoint:   mc.Endpoint,
-			ModelName:  mc.ModelName,
-			Parameters: toIfaceParams(mc),
-		}
+	// Initialize namespace scoping from the authorization policy
+	namespaceScoper, err := newNamespaceScoper(appConfig.Authz)
+	if err != nil {
+		return nil, err
+	}
+	if namespaceScoper != nil {
+		logger.Printf("Namespace scoping: %s policy, %s mode", appConfig.Authz.Policy, namespaceScoper.Mode())
+	}
 
-		providerType := mapProviderType(name, mc.Provider)
-		if err := factory.RegisterProvider(providerType, cfg); err != nil {
-			// Log and continue to allow others to register
-			logger.Printf("warning: failed to register provider '%s' as '%s': %v", name, providerType, err)
-		}
+	// Initialize the response cache for repeated queries
+	responseCache, err := newResponseCache(appConfig.Cache)
+	if err != nil {
+		return nil, err
+	}
+	if responseCache != nil {
+		logger.Printf("Response cache: %s, ttl %v", appConfig.Cache.Backend, appConfig.Cache.TTL)
+	}
+	semanticCache, err := newSemanticCache(appConfig.Cache)
+	if err != nil {
+		return nil, err
+	}
+	if semanticCache != nil {
+		logger.Printf("Semantic cache: %s embedder, threshold %v", appConfig.Cache.Semantic.Embedder, appConfig.Cache.Semantic.Threshold)
 	}
 
-	// Select active provider
-	defaultKey := appConfig.Models.DefaultProvider
-	mc, ok := appConfig.Models.Providers[defaultKey]
-	if !ok {
-		return nil, fmt.Errorf("default_provider '%s' not found in providers", defaultKey)
+	// Open the audit trail of processed queries
+	auditTrail, err := newAuditTrail(appConfig.AuditTrail)
+	if err != nil {
+		return nil, err
 	}
-	activeCfg := &types.ProviderConfig{
-		APIKey:     mc.APIKey,
-		Endpoint:   mc.Endpoint,
-		ModelName:  mc.ModelName,
-		Parameters: toIfaceParams(mc),
+	if auditTrail != nil {
+		logger.Printf("Audit trail: %s", appConfig.AuditTrail.Backend)
 	}
-	providerType := mapProviderType(defaultKey, mc.Provider)
 
-	// Create concrete provider from the selected config
-	provider, err := factory.CreateProviderWithConfig(providerType, activeCfg)
+	// Build the safety validator and provider routes from rules.yaml,
+	// models.yaml and prompts.yaml; this comes last because fallback
+	// providers start background health checks
+	safetyValidator, err := newSafetyValidator()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create provider '%s': %w", providerType, err)
-	}
-
-	// Helper: choose system prompt from prompts.yaml with fallbacks
-	chooseSystemPrompt := func(providerType string) (string, string) {
-		sys := ""
-		key := ""
-		if appConfig != nil && appConfig.Prompts.SystemPrompts != nil {
-			switch providerType {
-			case "claude":
-				key = "claude_specific"
-				sys = appConfig.Prompts.SystemPrompts[key]
-			case "openai":
-				key = "openai_specific"
-				sys = appConfig.Prompts.SystemPrompts[key]
-			case "generic":
-				key = "generic_specific"
-				sys = appConfig.Prompts.SystemPrompts[key]
-			}
-			if sys == "" {
-				key = "base"
-				sys = appConfig.Prompts.SystemPrompts[key]
+		return nil, err
+	}
+	pipeline, err := newPipeline(appConfig, safetyValidator, contextManager, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	proc := &GenAIProcessor{
+		contextManager:   contextManager,
+		commandGenerator: generator.NewCommandGenerator(),
+		responseCache:    responseCache,
+		semanticCache:    semanticCache,
+		auditTrail:       auditTrail,
+		accountant:       newAccountant(appConfig),
+		logger:           logger,
+		namespaceScoper:  namespaceScoper,
+		reload:           &reloadLocks{},
+	}
+	proc.install(pipeline)
+
+	return proc, nil
+}
+
+// newNamespaceScoper creates the namespace scoper for the configured
+// authorization policy, or nil when scoping is disabled.
+func newNamespaceScoper(cfg config.AuthzConfig) (*authz.Scoper, error) {
+	var access authz.NamespaceAccess
+	switch cfg.Policy {
+	case "", "none":
+		return nil, nil
+	case "static":
+		policy, err := authz.LoadStaticPolicy(cfg.PolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		access = policy
+	case "subjectaccessreview":
+		client, err := auth.NewHTTPClient(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		reviewConfig := authz.SubjectAccessReviewConfig{URL: cfg.APIServerURL, CacheTTL: cfg.CacheTTL, HTTPClient: client}
+		if cfg.TokenFile != "" {
+			if reviewConfig.Token, err = auth.ReadTokenFile(cfg.TokenFile); err != nil {
+				return nil, err
 			}
 		}
-		return sys, key
-	}
-
-	// Examples are already []types.Example in config
-
-	// Helper: select formatter implementation based on mc.PromptFormatter or provider type
-	// Falls back gracefully when templates are missing
-	makeFormatter := func(providerType string, formatterName string) interfaces.PromptFormatter {
-		// Prefer explicit formatterName if provided
-		normalized := strings.ToLower(formatterName)
-		kind := providerType
-		if strings.Contains(normalized, "openai") {
-			kind = "openai"
-		} else if strings.Contains(normalized, "claude") {
-			kind = "claude"
-		} else if strings.Contains(normalized, "generic") {
-			kind = "generic"
-		}
-		switch kind {
-		case "claude":
-			return promptformatters.NewClaudeFormatter(appConfig.Prompts.Formats.Claude.Template)
-		case "openai":
-			of := appConfig.Prompts.Formats.OpenAI
-			return promptformatters.NewOpenAIFormatter(of.Template, of.SystemMessage, of.UserMessage)
-		default:
-			return promptformatters.NewGenericFormatter(appConfig.Prompts.Formats.Generic.Template)
-		}
-	}
-
-	// Build input adapter based on config
-	var adapter interfaces.InputAdapter
-	switch mc.InputAdapter {
-	case "claude_input_adapter":
-		claude := adapters.NewClaudeInputAdapter(mc.APIKey)
-		claude.SetModelName(mc.ModelName)
-		_ = claude.SetMaxTokens(mc.MaxTokens)
-		_ = claude.SetTemperature(mc.Temperature)
-		// System prompt precedence: models.yaml parameters.system > prompts.yaml
-		if sys, ok := activeCfg.Parameters["system"].(string); ok && sys != "" {
-			claude.SetSystemPrompt(sys)
-			logger.Printf("system prompt: override from models.yaml parameters.system for provider claude")
-		} else if sp, key := chooseSystemPrompt("claude"); sp != "" {
-			claude.SetSystemPrompt(sp)
-			logger.Printf("system prompt: selected '%s' for provider claude", key)
-		}
-		// Wire examples from prompts.yaml
-		claude.SetExamples(appConfig.Prompts.Examples)
-		// Formatter (mc.PromptFormatter overrides provider default)
-		claude.SetFormatter(makeFormatter("claude", mc.PromptFormatter))
-		adapter = claude
-	case "openai_input_adapter":
-		openai := adapters.NewOpenAIInputAdapter(mc.APIKey)
-		openai.SetModelName(mc.ModelName)
-		_ = openai.SetMaxTokens(mc.MaxTokens)
-		_ = openai.SetTemperature(mc.Temperature)
-		if sys, ok := activeCfg.Parameters["system"].(string); ok && sys != "" {
-			openai.SetSystemPrompt(sys)
-			logger.Printf("system prompt: override from models.yaml parameters.system for provider openai")
-		} else if sp, key := chooseSystemPrompt("openai"); sp != "" {
-			openai.SetSystemPrompt(sp)
-			logger.Printf("system prompt: selected '%s' for provider openai", key)
-		}
-		openai.SetExamples(appConfig.Prompts.Examples)
-		openai.SetFormatter(makeFormatter("openai", mc.PromptFormatter))
-		adapter = openai
+		access = authz.NewSubjectAccessReviewer(reviewConfig)
 	default:
-		generic := adapters.NewGenericInputAdapter(mc.APIKey)
-		generic.SetModelName(mc.ModelName)
-		_ = generic.SetMaxTokens(mc.MaxTokens)
-		_ = generic.SetTemperature(mc.Temperature)
-		generic.SetExamples(appConfig.Prompts.Examples)
-		if sys, ok := activeCfg.Parameters["system"].(string); ok && sys != "" {
-			generic.SetSystemPrompt(sys)
-			logger.Printf("system prompt: override from models.yaml parameters.system for provider generic")
-		} else if sp, key := chooseSystemPrompt("generic"); sp != "" {
-			generic.SetSystemPrompt(sp)
-			logger.Printf("system prompt: selected '%s' for provider generic", key)
-		}
-		generic.SetFormatter(makeFormatter("generic", mc.PromptFormatter))
-		// Generic adapter has no SetSystemPrompt
-		adapter = generic
-	}
-
-	// Create LLM engine
-	llmEngine := engine.NewLLMEngine(provider, adapter)
-
-	// Retry parser configuration derives from model retry settings
-	retryConfig := &recovery.RetryConfig{
-		MaxRetries:          mc.RetryAttempts,
-		RetryDelay:          mc.RetryDelay,
-		ConfidenceThreshold: 0.7,
-		EnableReprompting:   true,
-		RepromptTemplate:    "The previous response was not in the expected JSON format. Please provide a valid JSON response for the following query: %s",
-	}
-	retryParser := recovery.NewRetryParser(retryConfig, llmEngine, contextManager)
-	// Enforce LLM output length from prompts validation if specified
-	if appConfig.Prompts.Validation.MaxOutputLength > 0 {
-		retryParser.SetMaxOutputLength(appConfig.Prompts.Validation.MaxOutputLength)
+		return nil, fmt.Errorf("unsupported authorization policy '%s'", cfg.Policy)
 	}
+	return authz.NewScoper(access, authz.Mode(cfg.Mode))
+}
 
-	// Parser preferences based on OutputParser using factory
-	claudeExtractor := extractors.NewClaudeExtractor()
-	openaiExtractor := extractors.NewOpenAIExtractor()
-	genericExtractor := extractors.NewGenericExtractor()
+// newContextManager creates a context manager persisting sessions in the configured store.
+func newContextManager(cfg config.SessionsConfig) (interfaces.ContextManager, error) {
+	switch cfg.Store {
+	case "", "memory":
+		return contextpkg.NewContextManager(), nil
+	case "file":
+		store, err := contextpkg.NewFileStore(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return contextpkg.NewContextManagerWithStore(store), nil
+	case "redis":
+		store, err := contextpkg.NewRedisStore(redisclient.Config{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}, cfg.RedisPrefix)
+		if err != nil {
+			return nil, err
+		}
+		return contextpkg.NewContextManagerWithStore(store), nil
+	}
+	return nil, fmt.Errorf("unsupported sessions store '%s'", cfg.Store)
+}
 
-	extractorFactory := extractors.NewExtractorFactory()
-	extractorFactory.Register("claude", claudeExtractor, "anthropic")
-	extractorFactory.Register("openai", openaiExtractor)
-	extractorFactory.SetGeneric(genericExtractor)
+// newResponseCache creates the configured response cache, or nil when
+// caching is disabled.
+func newResponseCache(cfg config.CacheConfig) (cache.Cache, error) {
+	switch cfg.Backend {
+	case "", "none":
+		return nil, nil
+	case "memory":
+		return cache.NewMemoryCache(cfg.TTL, cfg.MaxEntries), nil
+	case "redis":
+		c, err := cache.NewRedisCache(redisclient.Config{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}, cfg.RedisPrefix, cfg.TTL)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("unsupported cache backend '%s'", cfg.Backend)
+}
 
-	switch mc.OutputParser {
-	case "claude_extractor":
-		retryParser.RegisterParser(recovery.StrategySpecific, claudeExtractor)
-	case "openai_extractor":
-		retryParser.RegisterParser(recovery.StrategySpecific, openaiExtractor)
+// newSemanticCache creates the configured semantic cache, or nil when it is
+// disabled.
+func newSemanticCache(cfg config.CacheConfig) (*cache.SemanticCache, error) {
+	if !cfg.Semantic.Enabled {
+		return nil, nil
+	}
+	var embedder cache.Embedder
+	switch cfg.Semantic.Embedder {
+	case "", "hashing":
+		embedder = cache.NewHashingEmbedder(cfg.Semantic.Dimensions)
+	case "provider":
+		e, err := cache.NewProviderEmbedder(cfg.Semantic.EmbeddingEndpoint, cfg.Semantic.EmbeddingModel, cfg.Semantic.EmbeddingAPIKey)
+		if err != nil {
+			return nil, err
+		}
+		embedder = e
 	default:
-		// Delegate by model type via extractor factory
-		retryParser.RegisterParser(recovery.StrategySpecific, extractorFactory.CreateDelegatingParser())
+		return nil, fmt.Errorf("unsupported semantic cache embedder '%s'", cfg.Semantic.Embedder)
 	}
-	retryParser.RegisterParser(recovery.StrategyGeneric, genericExtractor)
+	return cache.NewSemanticCache(embedder, cfg.Semantic.Threshold, cfg.TTL, cfg.Semantic.MaxEntries)
+}
 
-	// Safety validator
-	safetyValidator := validator.NewSafetyValidator()
+// newAuditTrail opens the configured audit trail store, or returns nil when
+// the audit trail is disabled.
+func newAuditTrail(cfg config.AuditTrailConfig) (audittrail.Store, error) {
+	switch cfg.Backend {
+	case "", "none":
+		return nil, nil
+	case "file":
+		s, err := audittrail.NewFileStore(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "sql":
+		s, err := audittrail.OpenSQLStore(cfg.SQLDriver, cfg.SQLDSN, cfg.SQLTable)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported audit trail backend '%s'", cfg.Backend)
+}
 
-	// Configure fallback handler for retry parser
-	retryParser.SetFallbackHandler(recovery.NewFallbackHandler())
+// newAccountant creates the usage accountant from the models.yaml price table
+// and the configured budgets.
+func newAccountant(appConfig *config.AppConfig) *usage.Accountant {
+	return usage.NewAccountant(modelPrices(appConfig), appConfig.Models.Pricing.Currency, usage.Budget{
+		DailyCost:     appConfig.Usage.DailyBudget,
+		MonthlyCost:   appConfig.Usage.MonthlyBudget,
+		DailyTokens:   appConfig.Usage.DailyTokenBudget,
+		MonthlyTokens: appConfig.Usage.MonthlyTokenBudget,
+	})
+}
 
-	// Log prompt formatter selection (now active via formatters package)
-	if mc.PromptFormatter != "" {
-		logger.Printf("prompt_formatter active: %s", mc.PromptFormatter)
+// modelPrices returns the price table of models.yaml keyed by model name.
+func modelPrices(appConfig *config.AppConfig) map[string]usage.Price {
+	prices := make(map[string]usage.Price, len(appConfig.Models.Pricing.Models))
+	for model, price := range appConfig.Models.Pricing.Models {
+		prices[model] = usage.Price{InputPerMillion: price.InputPerMillion, OutputPerMillion: price.OutputPerMillion}
 	}
+	return prices
+}
 
-	proc := &GenAIProcessor{
-		contextManager:   contextManager,
-		llmEngine:        llmEngine,
-		RetryParser:      retryParser,
-		safetyValidator:  safetyValidator,
-		defaultModel:     mc.ModelName,
-		logger:           logger,
-		providerTimeout:  mc.Timeout,
-		retryAttempts:    mc.RetryAttempts,
-		retryDelay:       mc.RetryDelay,
-		promptValidation: appConfig.Prompts.Validation,
+// sessionStoreName describes the configured session store for logging.
+func sessionStoreName(cfg config.SessionsConfig) string {
+	switch cfg.Store {
+	case "file":
+		return "file (" + cfg.Path + ")"
+	case "redis":
+		return "redis (" + cfg.RedisAddr + ")"
 	}
-
-	return proc, nil
+	return "memory"
 }
 
 // mapProviderType maps config provider name/key to factory provider type
@@ -375,12 +407,20 @@ func mapProviderType(key string, providerName string) string {
 		return "openai"
 	case "generic":
 		return "generic"
+	case "ollama":
+		return "ollama"
+	case "vllm":
+		return "vllm"
 	}
 	switch providerName {
 	case "anthropic":
 		return "claude"
 	case "openai":
 		return "openai"
+	case "ollama":
+		return "ollama"
+	case "vllm":
+		return "vllm"
 	default:
 		return "generic"
 	}
@@ -388,12 +428,94 @@ func mapProviderType(key string, providerName string) string {
 
 // ProcessQuery orchestrates the complete processing pipeline:
 // 1. Context resolution using ContextManager
-// 2. Input adaptation and LLM processing
+// 2. Response cache lookup, or input adaptation and LLM processing
 // 3. Response parsing
 // 4. Safety validation
 // 5. Context update
+// 6. Audit trail record
 func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.ProcessingRequest) (*types.ProcessingResponse, error) {
+	return p.processAndRecord(ctx, req, nil)
+}
+
+// ProcessQueryStream processes a query like ProcessQuery, calling onEvent as
+// the query passes each pipeline stage and with the provider's output as it is
+// generated. onEvent is called on the calling goroutine and must not block for
+// long, since it holds up processing. The final response is returned rather
+// than reported through onEvent.
+func (p *GenAIProcessor) ProcessQueryStream(ctx context.Context, req *types.ProcessingRequest, onEvent func(types.StreamEvent)) (*types.ProcessingResponse, error) {
+	return p.processAndRecord(ctx, req, onEvent)
+}
+
+// processAndRecord runs the processing pipeline under a new request ID,
+// returned in the response, and appends the request's audit trail record.
+// The request runs on a snapshot of the processor, unaffected by reloads.
+func (p *GenAIProcessor) processAndRecord(ctx context.Context, req *types.ProcessingRequest, onEvent func(types.StreamEvent)) (*types.ProcessingResponse, error) {
+	p = p.snapshot()
+	record := &audittrail.Record{
+		RequestID: uuid.New().String(),
+		Timestamp: time.Now().UTC(),
+		SessionID: req.SessionID,
+		Query:     req.Query,
+	}
+	if identity := auth.IdentityFromContext(ctx); identity != nil {
+		record.UserID = identity.UserID
+	}
+
+	response, err := p.process(ctx, req, onEvent, record)
+	if response != nil {
+		response.RequestID = record.RequestID
+		response.Usage = record.Usage
+	}
+	record.LatencyMS = time.Since(record.Timestamp).Milliseconds()
+	record.Outcome, record.Error = requestOutcome(response, err)
+	metrics.RequestDuration.With(record.Outcome).ObserveSince(record.Timestamp)
+	p.recordAuditTrail(record, response)
+	return response, err
+}
+
+// requestOutcome classifies a processed request as an error, a query rejected
+// by validation or a success, with the error message of a failed request.
+func requestOutcome(response *types.ProcessingResponse, err error) (string, string) {
+	switch {
+	case err != nil:
+		return audittrail.OutcomeError, err.Error()
+	case response == nil:
+		return audittrail.OutcomeError, "no response"
+	case response.Error != "":
+		return audittrail.OutcomeError, response.Error
+	}
+	if validation, ok := response.ValidationInfo.(*interfaces.ValidationResult); ok && validation != nil && !validation.IsValid {
+		return audittrail.OutcomeRejected, ""
+	}
+	return audittrail.OutcomeSuccess, ""
+}
+
+// recordAuditTrail completes a request's audit trail record from its response
+// and appends it. Failures are logged rather than failing the request.
+func (p *GenAIProcessor) recordAuditTrail(record *audittrail.Record, response *types.ProcessingResponse) {
+	if p.auditTrail == nil {
+		return
+	}
+	if response != nil {
+		record.Cache = response.Cache
+		record.StructuredQuery, _ = response.StructuredQuery.(*types.StructuredQuery)
+		record.Validation, _ = response.ValidationInfo.(*interfaces.ValidationResult)
+	}
+
+	if err := p.auditTrail.Append(record); err != nil {
+		p.logger.Printf("Audit trail write failed for request %s: %v", record.RequestID, err)
+	}
+}
+
+// process runs the processing pipeline, reporting progress to onEvent when it
+// is not nil and filling in the request's audit trail record.
+func (p *GenAIProcessor) process(ctx context.Context, req *types.ProcessingRequest, onEvent func(types.StreamEvent), record *audittrail.Record) (*types.ProcessingResponse, error) {
 	startTime := time.Now()
+	emit := func(event types.StreamEvent) {
+		if onEvent != nil {
+			onEvent(event)
+		}
+	}
 	p.logger.Printf("Starting query processing for session: %s", req.SessionID)
 
 	// Step 0: Enforce configured input length from prompts validation (if available)
@@ -414,27 +536,28 @@ func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.Processing
 		req.Query = q
 	}
 
-	// Step 1: Context resolution
+	// Step 1: Session ownership check, budget check and context resolution
+	if err := p.authorizeSession(ctx, req.SessionID); err != nil {
+		p.logger.Printf("Session access denied: %v", err)
+		return p.createErrorResponse("session_access_denied", err), nil
+	}
+	if p.accountant != nil {
+		if err := p.accountant.Check(record.UserID); err != nil {
+			p.logger.Printf("Request rejected: %v", err)
+			return p.createErrorResponse("budget_exceeded", err), nil
+		}
+	}
+	stageStart := time.Now()
 	resolvedQuery, err := p.resolveContext(req.Query, req.SessionID)
+	metrics.StageDuration.With(metrics.StageContextResolution).ObserveSince(stageStart)
 	if err != nil {
 		p.logger.Printf("Context resolution failed: %v", err)
 		return p.createErrorResponse("context_resolution_failed", err), nil
 	}
+	record.ResolvedQuery = resolvedQuery
+	emit(types.StreamEvent{Stage: types.StreamStageContextResolved, Message: "query context resolved", Query: resolvedQuery})
 
-	// Step 2: Prepare internal request for LLM processing via input adapters
-	internalReq := &types.InternalRequest{
-		RequestID: fmt.Sprintf("%s-%d", req.SessionID, time.Now().UnixNano()),
-		ProcessingRequest: types.ProcessingRequest{
-			Query:     resolvedQuery,
-			SessionID: req.SessionID,
-			ModelType: req.ModelType,
-		},
-		ProcessingOptions: map[string]interface{}{
-			"original_query": req.Query,
-		},
-	}
-
-	// Step 3: Get conversation context for LLM
+	// Step 2: Get conversation context for LLM
 	convContext, err := p.contextManager.GetContext(req.SessionID)
 	if err != nil {
 		// Create new context if session doesn't exist
@@ -445,85 +568,312 @@ func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.Processing
 		}
 	}
 
-	// Step 4: Adapt input using engine's adapter and send to provider
-	p.logger.Printf("Adapting input via LLM engine adapter")
-	modelReq, err := p.llmEngine.AdaptInput(internalReq)
+	// Step 3: Serve repeated queries from the response cache (FR-010), then
+	// near-duplicates from the semantic cache, or generate the structured
+	// query with the LLM providers
+	candidates := p.candidateRoutes(ctx, req.ModelType)
+	var structuredQuery *types.StructuredQuery
+	var route *providerRoute
+	var providerName string
+	var semanticMatch *cache.SemanticMatch
+	var queryVector []float64
+	stageStart = time.Now()
+	cached := p.cachedQuery(resolvedQuery, candidates)
+	if cached == nil {
+		semanticMatch, queryVector = p.similarQuery(ctx, resolvedQuery, candidates)
+		if semanticMatch != nil {
+			cached = semanticMatch.Entry
+		}
+	}
+	if p.responseCache != nil || p.semanticCache != nil {
+		metrics.StageDuration.With(metrics.StageCacheLookup).ObserveSince(stageStart)
+	}
+	if semanticMatch != nil {
+		structuredQuery, providerName = cached.Query, cached.Provider
+		p.logger.Printf("Semantic cache hit for provider '%s' (similarity %.3f)", providerName, semanticMatch.Similarity)
+		emit(types.StreamEvent{Stage: types.StreamStageParsed, Message: "structured query reused from a similar query in the semantic cache", Provider: providerName})
+	} else if cached != nil {
+		structuredQuery, providerName = cached.Query, cached.Provider
+		p.logger.Printf("Response cache hit for provider '%s'", providerName)
+		emit(types.StreamEvent{Stage: types.StreamStageParsed, Message: "structured query served from the response cache", Provider: providerName})
+	} else {
+		var errResponse *types.ProcessingResponse
+		structuredQuery, route, errResponse = p.generateQuery(ctx, req, resolvedQuery, convContext, candidates, onEvent, record)
+		if errResponse != nil {
+			return errResponse, nil
+		}
+		providerName = route.name
+	}
+	record.Provider = providerName
+	if route != nil {
+		record.Model = route.modelName
+	} else if len(candidates) > 0 {
+		record.Model = candidates[0].modelName
+	}
+
+	// Step 7b: Safety validation
+	p.logger.Printf("Validating query safety")
+	stageStart = time.Now()
+	validationResult, err := p.validateQuery(ctx, structuredQuery, convContext)
+	metrics.StageDuration.With(metrics.StageValidation).ObserveSince(stageStart)
 	if err != nil {
-		p.logger.Printf("Input adaptation failed: %v", err)
-		return p.createErrorResponse("input_adaptation_failed", err), nil
+		p.logger.Printf("Safety validation failed: %v", err)
+		return p.createErrorResponse("validation_failed", err), nil
 	}
 
-	p.logger.Printf("Sending adapted request to LLM provider")
-	// Prefer direct provider call if engine exposes provider; otherwise, fall back to existing ProcessQuery path
-	var rawResponse *types.RawResponse
-	type engineWithProvider interface {
-		GetProvider() interfaces.LLMProvider
+	// Cache queries that passed validation, before they are scoped to the caller
+	if cached == nil && validationResult != nil && validationResult.IsValid {
+		p.cacheQuery(resolvedQuery, queryVector, route, structuredQuery)
+	}
+
+	// Repair rejected queries with safe deterministic fixes when remediation
+	// is enabled. Repaired queries are not cached: the diff is reported
+	// every time they are served
+	if validationResult != nil && !validationResult.IsValid {
+		structuredQuery, validationResult = p.remediateQuery(ctx, structuredQuery, validationResult, convContext)
+	}
+
+	// Record reuse of a similar query's result so it can be audited
+	if semanticMatch != nil && validationResult != nil {
+		validationResult.Warnings = append(validationResult.Warnings, fmt.Sprintf(
+			"structured query reused from similar query %q (similarity %.3f)", semanticMatch.Query, semanticMatch.Similarity))
+		if validationResult.Details == nil {
+			validationResult.Details = make(map[string]interface{})
+		}
+		validationResult.Details["semantic_cache"] = semanticMatch
 	}
-	if ep, ok := p.llmEngine.(engineWithProvider); ok {
-		provider := ep.GetProvider()
-
-		// Apply timeout and retry logic using configuration values
-		attempts := p.retryAttempts
-		if attempts < 0 {
-			attempts = 0
-		}
-		var lastErr error
-		for attempt := 0; attempt <= attempts; attempt++ {
-			callCtx := ctx
-			if p.providerTimeout > 0 {
-				var cancel context.CancelFunc
-				callCtx, cancel = context.WithTimeout(ctx, p.providerTimeout)
-				defer cancel()
-			}
 
-			rawResponse, err = provider.GenerateResponse(callCtx, modelReq)
-			if err == nil {
-				break
+	// Step 7c: Namespace scoping for the caller (FR-019)
+	if p.namespaceScoper != nil && structuredQuery != nil {
+		stageStart = time.Now()
+		scope, err := p.namespaceScoper.Scope(ctx, auth.IdentityFromContext(ctx), structuredQuery)
+		metrics.StageDuration.With(metrics.StageNamespaceScoping).ObserveSince(stageStart)
+		if err != nil {
+			p.logger.Printf("Namespace scoping failed: %v", err)
+			if errors.Is(err, authz.ErrAccessDenied) {
+				return p.createErrorResponse("namespace_access_denied", err), nil
 			}
-			lastErr = err
-
-			// Decide retry
-			if attempt < attempts && isTransientError(err) {
-				p.logger.Printf("Transient provider error (attempt %d/%d): %v", attempt+1, attempts+1, err)
-				td := p.retryDelay
-				if td < 0 {
-					td = 0
+			return p.createErrorResponse("authorization_failed", err), nil
+		}
+		if scope.Narrowed && validationResult != nil {
+			// Validate the scoped query again: the caller's namespaces can
+			// exceed limits the generated query met, such as
+			// max_namespace_array_size
+			if validationResult.IsValid {
+				scoped, err := p.validateQuery(ctx, structuredQuery, convContext)
+				if err != nil {
+					p.logger.Printf("Safety validation of the scoped query failed: %v", err)
+					return p.createErrorResponse("validation_failed", err), nil
 				}
-				timer := time.NewTimer(td)
-				select {
-				case <-ctx.Done():
-					p.logger.Printf("Context canceled during retry wait: %v", ctx.Err())
-					return p.createErrorResponse("llm_processing_failed", ctx.Err()), nil
-				case <-timer.C:
+				if scoped != nil && !scoped.IsValid {
+					p.logger.Printf("Scoped query failed validation: %v", scoped.Errors)
+					validationResult = scoped
 				}
-				continue
 			}
+			validationResult.Warnings = append(validationResult.Warnings, scope.Message())
+			if validationResult.Details == nil {
+				validationResult.Details = make(map[string]interface{})
+			}
+			validationResult.Details["namespace_scope"] = scope
+		}
+	}
+
+	emit(types.StreamEvent{Stage: types.StreamStageValidated, Message: "structured query validated"})
 
-			// Non-retryable or out of attempts
-			p.logger.Printf("Provider call failed: %v", err)
-			return p.createErrorResponse("llm_processing_failed", err), nil
+	// Step 8: Update context with new query/response, including user identity if available
+	if userID, ok := ctx.Value(types.ContextKeyUserID).(string); ok && userID != "" {
+		_ = p.contextManager.UpdateContextWithUser(req.SessionID, userID, req.Query, structuredQuery)
+	} else {
+		if convContext != nil && convContext.UserID != "" {
+			_ = p.contextManager.UpdateContextWithUser(req.SessionID, convContext.UserID, req.Query, structuredQuery)
+		} else {
+			if err := p.contextManager.UpdateContext(req.SessionID, req.Query, structuredQuery); err != nil {
+				p.logger.Printf("Context update failed: %v", err)
+			}
 		}
-		if lastErr != nil && rawResponse == nil {
-			p.logger.Printf("Provider call failed after retries: %v", lastErr)
-			return p.createErrorResponse("llm_processing_failed", lastErr), nil
+	}
+	// Don't fail the entire request for context update issues
+
+	// Step 9: Create response
+	processingTime := time.Since(startTime)
+	p.logger.Printf("Query processing completed in %v", processingTime)
+
+	// Get confidence from retry parser statistics or use default
+	confidence := 0.8 // Default confidence for successful parsing
+	if stats := p.RetryParser.GetRetryStatistics(); stats != nil {
+		if threshold, ok := stats["confidence_threshold"].(float64); ok {
+			confidence = threshold
 		}
-	} else {
-		// Backward compatibility: if engine cannot send ModelRequest directly, use existing ProcessQuery
-		p.logger.Printf("Engine does not expose provider send; using fallback ProcessQuery path")
-		rawResponse, err = p.llmEngine.ProcessQuery(ctx, resolvedQuery, *convContext)
+	}
+
+	stageStart = time.Now()
+	command := p.generateCommand(structuredQuery, validationResult)
+	metrics.StageDuration.With(metrics.StageCommandGeneration).ObserveSince(stageStart)
+
+	response := &types.ProcessingResponse{
+		StructuredQuery: structuredQuery,
+		Confidence:      confidence,
+		ValidationInfo:  validationResult,
+		Command:         command,
+		Provider:        providerName,
+	}
+	if p.responseCache != nil || p.semanticCache != nil {
+		response.Cache = types.CacheMiss
+		if cached != nil {
+			response.Cache = types.CacheHit
+		}
+	}
+
+	return response, nil
+}
+
+// cachedQuery returns the cached result of a query sent to the first
+// candidate provider, or nil on a miss or when caching is disabled.
+func (p *GenAIProcessor) cachedQuery(resolvedQuery string, candidates []*providerRoute) *cache.Entry {
+	if p.responseCache == nil || len(candidates) == 0 {
+		return nil
+	}
+	entry, err := p.responseCache.Get(cache.Key(resolvedQuery, candidates[0].name, candidates[0].modelName))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			p.logger.Printf("Response cache lookup failed: %v", err)
+		}
+		return nil
+	}
+	return entry
+}
+
+// similarQuery returns the semantic cache's closest match for a query sent to
+// the first candidate provider, or nil on a miss or when the semantic cache is
+// disabled. The query's embedding is returned for storing its result.
+func (p *GenAIProcessor) similarQuery(ctx context.Context, resolvedQuery string, candidates []*providerRoute) (*cache.SemanticMatch, []float64) {
+	if p.semanticCache == nil || len(candidates) == 0 {
+		return nil, nil
+	}
+	vector, err := p.semanticCache.Embed(ctx, resolvedQuery)
+	if err != nil {
+		p.logger.Printf("Semantic cache lookup failed: %v", err)
+		return nil, nil
+	}
+	match, err := p.semanticCache.Lookup(vector, resolvedQuery, candidates[0].name, candidates[0].modelName)
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			p.logger.Printf("Semantic cache lookup failed: %v", err)
+		}
+		return nil, vector
+	}
+	return match, vector
+}
+
+// cacheQuery stores a validated query under the provider that produced it,
+// and in the semantic cache when the query was embedded. Failures are logged
+// rather than failing the request.
+func (p *GenAIProcessor) cacheQuery(resolvedQuery string, queryVector []float64, route *providerRoute, query *types.StructuredQuery) {
+	if route == nil || query == nil {
+		return
+	}
+	entry := &cache.Entry{Query: query, Provider: route.name}
+	if p.responseCache != nil {
+		if err := p.responseCache.Set(cache.Key(resolvedQuery, route.name, route.modelName), entry); err != nil {
+			p.logger.Printf("Response cache store failed: %v", err)
+		}
+	}
+	if p.semanticCache != nil && queryVector != nil {
+		if err := p.semanticCache.Add(queryVector, resolvedQuery, route.name, route.modelName, entry); err != nil {
+			p.logger.Printf("Semantic cache store failed: %v", err)
+		}
+	}
+}
+
+// generateQuery sends a query to the candidate providers in turn and parses,
+// normalizes and checks the answer of the first that responds. It returns the
+// query and the provider that answered, or an error response. The provider,
+// model and a hash of the provider's output are noted in record.
+func (p *GenAIProcessor) generateQuery(ctx context.Context, req *types.ProcessingRequest, resolvedQuery string, convContext *types.ConversationContext, candidates []*providerRoute, onEvent func(types.StreamEvent), record *audittrail.Record) (*types.StructuredQuery, *providerRoute, *types.ProcessingResponse) {
+	emit := func(event types.StreamEvent) {
+		if onEvent != nil {
+			onEvent(event)
+		}
+	}
+
+	// Step 2: Prepare internal request for LLM processing via input adapters
+	internalReq := &types.InternalRequest{
+		RequestID: fmt.Sprintf("%s-%d", req.SessionID, time.Now().UnixNano()),
+		ProcessingRequest: types.ProcessingRequest{
+			Query:     resolvedQuery,
+			SessionID: req.SessionID,
+			ModelType: req.ModelType,
+		},
+		ProcessingOptions: map[string]interface{}{
+			"original_query": req.Query,
+		},
+	}
+
+	// Step 4: Adapt input for each candidate provider and send it, failing over
+	// to the next provider when one is unavailable
+	var rawResponse *types.RawResponse
+	var route *providerRoute
+	for i, candidate := range candidates {
+		p.logger.Printf("Adapting input via LLM engine adapter for provider '%s'", candidate.name)
+		modelReq, err := candidate.engine.AdaptInput(internalReq)
 		if err != nil {
-			p.logger.Printf("LLM processing failed: %v", err)
-			return p.createErrorResponse("llm_processing_failed", err), nil
+			p.logger.Printf("Input adaptation failed: %v", err)
+			return nil, nil, p.createErrorResponse("input_adaptation_failed", err)
+		}
+
+		emit(types.StreamEvent{
+			Stage:    types.StreamStageModelCalled,
+			Message:  fmt.Sprintf("calling provider '%s'", candidate.name),
+			Provider: candidate.name,
+			Model:    candidate.modelName,
+		})
+		var onChunk func(string)
+		if onEvent != nil {
+			providerName := candidate.name
+			onChunk = func(chunk string) {
+				onEvent(types.StreamEvent{Stage: types.StreamStageTokens, Provider: providerName, Text: chunk})
+			}
+		}
+		stageStart := time.Now()
+		rawResponse, err = p.sendToProvider(ctx, candidate, modelReq, resolvedQuery, convContext, onChunk)
+		metrics.StageDuration.With(metrics.StageProviderCall).ObserveSince(stageStart)
+		metrics.ProviderRequests.With(candidate.name, metrics.Result(err)).Inc()
+		if p.modelSelector != nil {
+			p.modelSelector.RecordResult(candidate.name, err)
+		}
+		if err == nil {
+			route = candidate
+			record.Provider, record.Model = candidate.name, candidate.modelName
+			if rawResponse != nil {
+				record.RawOutputSHA256 = audittrail.HashOutput(rawResponse.Content)
+				if p.accountant != nil {
+					record.Usage = p.accountant.Record(record.UserID, req.SessionID, candidate.name, candidate.modelName, rawResponse)
+				}
+			}
+			break
+		}
+		if ctx.Err() == nil && i < len(candidates)-1 && isRetryableProviderError(err) {
+			p.logger.Printf("Provider '%s' failed, failing over to '%s': %v", candidate.name, candidates[i+1].name, err)
+			continue
 		}
+		p.logger.Printf("Provider call failed: %v", err)
+		return nil, nil, p.createErrorResponse("llm_processing_failed", err)
+	}
+	if route == nil {
+		return nil, nil, p.createErrorResponse("llm_processing_failed", fmt.Errorf("no LLM provider configured"))
 	}
 
-	// Step 5: Response parsing with retry mechanism
+	// Step 5: Response parsing with retry mechanism, using the parsers
+	// matching the provider that answered
 	p.logger.Printf("Parsing LLM response with retry mechanism")
-	structuredQuery, err := p.RetryParser.ParseWithRetry(ctx, rawResponse, p.defaultModel, req.Query, req.SessionID)
+	stageStart := time.Now()
+	structuredQuery, err := route.retryParser.ParseWithRetry(ctx, rawResponse, route.modelName, req.Query, req.SessionID)
+	metrics.StageDuration.With(metrics.StageParsing).ObserveSince(stageStart)
 	if err != nil {
 		p.logger.Printf("Response parsing failed after retries: %v", err)
-		return p.createErrorResponse("parsing_failed", err), nil
+		return nil, nil, p.createErrorResponse("parsing_failed", err)
 	}
+	emit(types.StreamEvent{Stage: types.StreamStageParsed, Message: "response parsed into a structured query", Provider: route.name})
 
 	// Step 6: Normalization pipeline (JSONNormalizer → FieldMapper → SchemaValidator)
 	p.logger.Printf("Normalizing structured query")
@@ -531,17 +881,25 @@ func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.Processing
 	fieldMapper := norm.NewFieldMapper()
 	schemaValidator := norm.NewSchemaValidator()
 
-	if structuredQuery, err = jsonNormalizer.Normalize(structuredQuery); err != nil {
+	stageStart = time.Now()
+	structuredQuery, err = jsonNormalizer.Normalize(structuredQuery)
+	metrics.NormalizerRuns.With("json_normalizer", metrics.Result(err)).Inc()
+	if err != nil {
 		p.logger.Printf("Normalization (JSON) failed: %v", err)
-		return p.createErrorResponse("normalization_failed", err), nil
+		return nil, nil, p.createErrorResponse("normalization_failed", err)
 	}
-	if structuredQuery, err = fieldMapper.MapFields(structuredQuery); err != nil {
+	structuredQuery, err = fieldMapper.MapFields(structuredQuery)
+	metrics.NormalizerRuns.With("field_mapper", metrics.Result(err)).Inc()
+	if err != nil {
 		p.logger.Printf("Normalization (FieldMapper) failed: %v", err)
-		return p.createErrorResponse("normalization_failed", err), nil
+		return nil, nil, p.createErrorResponse("normalization_failed", err)
 	}
-	if err = schemaValidator.ValidateSchema(structuredQuery); err != nil {
+	err = schemaValidator.ValidateSchema(structuredQuery)
+	metrics.NormalizerRuns.With("schema_validator", metrics.Result(err)).Inc()
+	metrics.StageDuration.With(metrics.StageNormalization).ObserveSince(stageStart)
+	if err != nil {
 		p.logger.Printf("Normalization (SchemaValidator) failed: %v", err)
-		return p.createErrorResponse("normalization_failed", err), nil
+		return nil, nil, p.createErrorResponse("normalization_failed", err)
 	}
 
 	// Step 7a: Enhanced prompt validation required fields
@@ -550,31 +908,31 @@ func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.Processing
 			switch strings.ToLower(rf) {
 			case "log_source", "logsource":
 				if strings.TrimSpace(sq.LogSource) == "" {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: log_source")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: log_source"))
 				}
 			case "verb":
 				if sq.Verb.IsEmpty() {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: verb")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: verb"))
 				}
 			case "resource":
 				if sq.Resource.IsEmpty() {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: resource")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: resource"))
 				}
 			case "timeframe":
 				if strings.TrimSpace(sq.Timeframe) == "" {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: timeframe")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: timeframe"))
 				}
 			case "user":
 				if sq.User.IsEmpty() {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: user")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: user"))
 				}
 			case "namespace":
 				if sq.Namespace.IsEmpty() {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing: namespace")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing: namespace"))
 				}
 			case "limit":
 				if sq.Limit <= 0 {
-					return p.createErrorResponse("validation_failed", fmt.Errorf("required field missing or invalid: limit")), nil
+					return nil, nil, p.requiredFieldMissing(fmt.Errorf("required field missing or invalid: limit"))
 				}
 			default:
 				p.logger.Printf("warning: unknown required field '%s' in validation config", rf)
@@ -582,54 +940,282 @@ func (p *GenAIProcessor) ProcessQuery(ctx context.Context, req *types.Processing
 		}
 	}
 
-	// Step 7b: Safety validation
-	p.logger.Printf("Validating query safety")
-	validationResult, err := p.safetyValidator.ValidateQuery(structuredQuery)
-	if err != nil {
-		p.logger.Printf("Safety validation failed: %v", err)
-		return p.createErrorResponse("validation_failed", err), nil
+	return structuredQuery, route, nil
+}
+
+// requiredFieldMissing counts a query missing a field the prompt validation
+// requires and returns its error response.
+func (p *GenAIProcessor) requiredFieldMissing(err error) *types.ProcessingResponse {
+	metrics.ValidationFailures.With("prompt_required_fields").Inc()
+	return p.createErrorResponse("validation_failed", err)
+}
+
+// sendToProvider sends an adapted request to a route's provider, applying the
+// route's timeout and retrying transient errors. When onChunk is set and the
+// provider can stream, generated text is passed to onChunk as it arrives.
+func (p *GenAIProcessor) sendToProvider(ctx context.Context, route *providerRoute, modelReq *types.ModelRequest, resolvedQuery string, convContext *types.ConversationContext, onChunk func(string)) (*types.RawResponse, error) {
+	p.logger.Printf("Sending adapted request to LLM provider")
+	// Prefer direct provider call if engine exposes provider; otherwise, fall back to existing ProcessQuery path
+	type engineWithProvider interface {
+		GetProvider() interfaces.LLMProvider
+	}
+	ep, ok := route.engine.(engineWithProvider)
+	if !ok {
+		// Backward compatibility: if engine cannot send ModelRequest directly, use existing ProcessQuery
+		p.logger.Printf("Engine does not expose provider send; using fallback ProcessQuery path")
+		return route.engine.ProcessQuery(ctx, resolvedQuery, *convContext)
+	}
+	provider := ep.GetProvider()
+	streamer, _ := provider.(interfaces.StreamingProvider)
+	if onChunk == nil || !provider.SupportsStreaming() {
+		streamer = nil
 	}
 
-	// Step 8: Update context with new query/response, including user identity if available
-	if userID, ok := ctx.Value(types.ContextKeyUserID).(string); ok && userID != "" {
-		_ = p.contextManager.UpdateContextWithUser(req.SessionID, userID, req.Query, structuredQuery)
-	} else {
-		if convContext != nil && convContext.UserID != "" {
-			_ = p.contextManager.UpdateContextWithUser(req.SessionID, convContext.UserID, req.Query, structuredQuery)
-		} else {
-			if err := p.contextManager.UpdateContext(req.SessionID, req.Query, structuredQuery); err != nil {
-				p.logger.Printf("Context update failed: %v", err)
-			}
+	// Apply timeout and retry logic using configuration values
+	attempts := route.retryAttempts
+	if attempts < 0 {
+		attempts = 0
+	}
+	// send makes one attempt, releasing its timeout as soon as it returns
+	send := func() (*types.RawResponse, error) {
+		callCtx := ctx
+		if route.timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(ctx, route.timeout)
+			defer cancel()
+		}
+		if streamer != nil {
+			return streamer.GenerateResponseStream(callCtx, modelReq, onChunk)
 		}
+		return provider.GenerateResponse(callCtx, modelReq)
 	}
-	// Don't fail the entire request for context update issues
+	for attempt := 0; ; attempt++ {
+		rawResponse, err := send()
+		if err == nil {
+			return rawResponse, nil
+		}
 
-	// Step 9: Create response
-	processingTime := time.Since(startTime)
-	p.logger.Printf("Query processing completed in %v", processingTime)
+		// Non-retryable or out of attempts
+		if attempt >= attempts || !isRetryableProviderError(err) {
+			return nil, err
+		}
 
-	// Get confidence from retry parser statistics or use default
-	confidence := 0.8 // Default confidence for successful parsing
-	if stats := p.RetryParser.GetRetryStatistics(); stats != nil {
-		if threshold, ok := stats["confidence_threshold"].(float64); ok {
-			confidence = threshold
+		p.logger.Printf("Transient provider error (attempt %d/%d): %v", attempt+1, attempts+1, err)
+		td := route.retryDelay
+		if td < 0 {
+			td = 0
+		}
+		timer := time.NewTimer(td)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			p.logger.Printf("Context canceled during retry wait: %v", ctx.Err())
+			return nil, ctx.Err()
+		case <-timer.C:
 		}
 	}
+}
 
-	response := &types.ProcessingResponse{
-		StructuredQuery: structuredQuery,
-		Confidence:      confidence,
-		ValidationInfo:  validationResult,
+// PreviewQuery processes a natural language query like ProcessQuery and adds a
+// deterministic plain-English explanation of the resulting structured query, so
+// reviewers can confirm intent before the compiled command is run.
+func (p *GenAIProcessor) PreviewQuery(ctx context.Context, req *types.ProcessingRequest) (*types.ProcessingResponse, error) {
+	response, err := p.ProcessQuery(ctx, req)
+	if err != nil || response == nil || response.Error != "" {
+		return response, err
+	}
+	if sq, ok := response.StructuredQuery.(*types.StructuredQuery); ok {
+		response.Explanation = generator.Explain(sq)
 	}
-
 	return response, nil
 }
 
+// generateCommand compiles a validated query into an executable command.
+// Commands are only generated for queries that passed safety validation;
+// generation failures are reported as validation warnings rather than
+// failing the request.
+func (p *GenAIProcessor) generateCommand(query *types.StructuredQuery, validationResult *interfaces.ValidationResult) *types.GeneratedCommand {
+	if p.commandGenerator == nil || query == nil {
+		return nil
+	}
+	if validationResult != nil && !validationResult.IsValid {
+		return nil
+	}
+	cmd, err := p.commandGenerator.Generate(query)
+	if err != nil {
+		p.logger.Printf("Command generation failed: %v", err)
+		if validationResult != nil {
+			validationResult.Warnings = append(validationResult.Warnings, fmt.Sprintf("command generation skipped: %v", err))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// Close stops background provider health checks and closes the response
+// cache and audit trail. It must be called at most once.
+func (p *GenAIProcessor) Close() {
+	if p.reload != nil {
+		p.reload.reloading.Lock()
+		defer p.reload.reloading.Unlock()
+	}
+	if p.modelSelector != nil {
+		p.modelSelector.Stop()
+	}
+	if p.responseCache != nil {
+		_ = p.responseCache.Close()
+	}
+	if p.auditTrail != nil {
+		_ = p.auditTrail.Close()
+	}
+}
+
+// SetResponseCache enables caching of validated queries; nil disables it.
+func (p *GenAIProcessor) SetResponseCache(c cache.Cache) {
+	p.responseCache = c
+}
+
+// SetSemanticCache enables reuse of queries for near-duplicate questions; nil disables it.
+func (p *GenAIProcessor) SetSemanticCache(c *cache.SemanticCache) {
+	p.semanticCache = c
+}
+
+// SetAuditTrail enables recording of every processed query; nil disables it.
+func (p *GenAIProcessor) SetAuditTrail(s audittrail.Store) {
+	p.auditTrail = s
+}
+
+// AuditTrail returns the audit trail store, or nil when the audit trail is disabled.
+func (p *GenAIProcessor) AuditTrail() audittrail.Store {
+	return p.auditTrail
+}
+
+// SetUsageAccountant enables token usage accounting and budgets; nil disables them.
+func (p *GenAIProcessor) SetUsageAccountant(a *usage.Accountant) {
+	p.accountant = a
+}
+
+// UsageAccountant returns the usage accountant, or nil when accounting is disabled.
+func (p *GenAIProcessor) UsageAccountant() *usage.Accountant {
+	return p.accountant
+}
+
+// SetNamespaceScoper enables namespace scoping of queries for the caller; nil disables it.
+func (p *GenAIProcessor) SetNamespaceScoper(s *authz.Scoper) {
+	p.namespaceScoper = s
+}
+
+// SetCommandGenerator replaces the generator used to compile validated queries into commands.
+func (p *GenAIProcessor) SetCommandGenerator(g *generator.CommandGenerator) {
+	p.commandGenerator = g
+}
+
+// QuerySchema returns the StructuredQuery JSON Schema the processor asks
+// providers for. Processors built without configuration derive it from their
+// safety validator's rules, or from the StructuredQuery tags alone.
+func (p *GenAIProcessor) QuerySchema() map[string]interface{} {
+	p = p.snapshot()
+	if p.querySchema != nil {
+		return p.querySchema
+	}
+	if sv, ok := p.safetyValidator.(*validator.SafetyValidator); ok {
+		return schema.Generate(sv.GetConfig())
+	}
+	return schema.StructuredQuery()
+}
+
+// authorizeSession rejects requests that would read or continue a session
+// owned by a different user. Unknown sessions are allowed; they are created,
+// owned by the requesting user, on first use.
+func (p *GenAIProcessor) authorizeSession(ctx context.Context, sessionID string) error {
+	session, err := p.contextManager.GetContext(sessionID)
+	if err != nil || session == nil {
+		return nil
+	}
+	userID, _ := ctx.Value(types.ContextKeyUserID).(string)
+	if !session.AccessibleBy(userID) {
+		return fmt.Errorf("session %s belongs to another user", sessionID)
+	}
+	return nil
+}
+
+// validateQuery validates a structured query for the caller in ctx and
+// their session, so policy rules can depend on who is asking.
+func (p *GenAIProcessor) validateQuery(ctx context.Context, query *types.StructuredQuery, session *types.ConversationContext) (*interfaces.ValidationResult, error) {
+	sv, ok := p.safetyValidator.(interfaces.ContextualSafetyValidator)
+	if !ok {
+		return p.safetyValidator.ValidateQuery(query)
+	}
+	vctx := &interfaces.ValidationContext{Session: session}
+	if identity := auth.IdentityFromContext(ctx); identity != nil {
+		vctx.UserID, vctx.Groups = identity.UserID, identity.Groups
+	}
+	return sv.ValidateQueryWithContext(query, vctx)
+}
+
+// remediateQuery repairs a query that failed validation and re-validates
+// it. The repaired query and its validation result, carrying the changes
+// under the remediation details, replace the originals only when the
+// repaired query passes; otherwise the originals are returned.
+func (p *GenAIProcessor) remediateQuery(ctx context.Context, query *types.StructuredQuery, result *interfaces.ValidationResult, session *types.ConversationContext) (*types.StructuredQuery, *interfaces.ValidationResult) {
+	remediator, ok := p.safetyValidator.(interfaces.RemediatingSafetyValidator)
+	if !ok {
+		return query, result
+	}
+	repaired, changes := remediator.RemediateQuery(query)
+	if len(changes) == 0 {
+		return query, result
+	}
+	revalidated, err := p.validateQuery(ctx, repaired, session)
+	if err != nil || revalidated == nil || !revalidated.IsValid {
+		p.logger.Printf("Query remediation made %d change(s) but the query is still invalid", len(changes))
+		return query, result
+	}
+
+	p.logger.Printf("Query remediated with %d change(s)", len(changes))
+	for _, change := range changes {
+		revalidated.Warnings = append(revalidated.Warnings, fmt.Sprintf(
+			"query repaired: %s changed from %v to %v (%s)", change.Field, formatChangeValue(change.From), formatChangeValue(change.To), change.Reason))
+	}
+	if revalidated.Details == nil {
+		revalidated.Details = make(map[string]interface{})
+	}
+	revalidated.Details["remediation"] = map[string]interface{}{
+		"changes":         changes,
+		"original_errors": result.Errors,
+	}
+	return repaired, revalidated
+}
+
+// formatChangeValue formats a remediated value for a warning message.
+func formatChangeValue(v interface{}) string {
+	switch value := v.(type) {
+	case *types.TimeRange:
+		return value.Start.Format(time.RFC3339) + ".." + value.End.Format(time.RFC3339)
+	case []string:
+		return "[" + strings.Join(value, ", ") + "]"
+	case string:
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprint(v)
+}
+
+// SessionManager returns the session lifecycle manager, or nil when the
+// configured context manager does not manage sessions.
+func (p *GenAIProcessor) SessionManager() interfaces.SessionManager {
+	sm, _ := p.contextManager.(interfaces.SessionManager)
+	return sm
+}
+
 // resolveContext resolves pronouns and references in the query using conversation context
 func (p *GenAIProcessor) resolveContext(query, sessionID string) (string, error) {
 	p.logger.Printf("Resolving context for query: %s", query)
 
 	resolvedQuery, err := p.contextManager.ResolvePronouns(query, sessionID)
+	if errors.Is(err, contextpkg.ErrSessionNotFound) {
+		// First query of a new session: nothing to resolve yet
+		return query, nil
+	}
 	if err != nil {
 		return query, fmt.Errorf("failed to resolve context: %w", err)
 	}
@@ -761,6 +1347,17 @@ func (e *simpleLLMEngine) ValidateConnection() error {
 // multiModelParser was replaced by a factory-backed delegating parser. The prior
 // implementation has been removed as unused to satisfy staticcheck.
 
+// isRetryableProviderError reports whether a provider call may succeed on
+// retry or on another provider. Providers that classify their errors with a
+// ProviderError are trusted; other errors are classified by isTransientError.
+func isRetryableProviderError(err error) bool {
+	var perr *pkgerrors.ProviderError
+	if errors.As(err, &perr) {
+		return perr.Retryable
+	}
+	return isTransientError(err)
+}
+
 // isTransientError determines whether a provider error is likely transient
 // based on common network/timeouts and HTTP semantics embedded in error text.
 func isTransientError(err error) bool {
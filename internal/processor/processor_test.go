@@ -2,14 +2,24 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"genai-processing/internal/audittrail"
+	"genai-processing/internal/auth"
+	"genai-processing/internal/authz"
+	"genai-processing/internal/cache"
 	"genai-processing/internal/config"
+	"genai-processing/internal/generator"
+	"genai-processing/internal/metrics"
 	"genai-processing/internal/parser/recovery"
+	"genai-processing/internal/usage"
+	"genai-processing/internal/validator"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
 )
@@ -216,6 +226,18 @@ func (m *mockSafetyValidator) GetApplicableRules() []interfaces.ValidationRule {
 	return []interfaces.ValidationRule{}
 }
 
+// contextualSafetyValidator implements interfaces.ContextualSafetyValidator
+// and records the validation context it was called with
+type contextualSafetyValidator struct {
+	*mockSafetyValidator
+	vctx *interfaces.ValidationContext
+}
+
+func (c *contextualSafetyValidator) ValidateQueryWithContext(query *types.StructuredQuery, vctx *interfaces.ValidationContext) (*interfaces.ValidationResult, error) {
+	c.vctx = vctx
+	return c.ValidateQuery(query)
+}
+
 // spyProvider implements interfaces.LLMProvider and records whether GenerateResponse was called
 type spyProvider struct {
 	called bool
@@ -346,6 +368,174 @@ func TestProcessQuery_Success(t *testing.T) {
 	}
 }
 
+func TestProcessQuery_RejectsOtherUsersSession(t *testing.T) {
+	mockContext := newMockContextManager()
+	mockContext.sessions["owned-session"] = &types.ConversationContext{SessionID: "owned-session", UserID: "alice"}
+
+	processor := &GenAIProcessor{
+		contextManager:  mockContext,
+		llmEngine:       newMockLLMEngine(),
+		RetryParser:     newMockRetryParser(),
+		safetyValidator: newMockSafetyValidator(),
+		logger:          log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	tests := []struct {
+		name      string
+		userID    string
+		wantError bool
+	}{
+		{name: "owner", userID: "alice", wantError: false},
+		{name: "other_user", userID: "bob", wantError: true},
+		{name: "anonymous", userID: "", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.userID != "" {
+				ctx = context.WithValue(ctx, types.ContextKeyUserID, tt.userID)
+			}
+			response, err := processor.ProcessQuery(ctx, &types.ProcessingRequest{
+				Query:     "Who deleted the customer CRD yesterday?",
+				SessionID: "owned-session",
+			})
+			if err != nil {
+				t.Fatalf("ProcessQuery failed: %v", err)
+			}
+			denied := strings.Contains(response.Error, "session_access_denied")
+			if denied != tt.wantError {
+				t.Errorf("Expected denied=%v, got error %q", tt.wantError, response.Error)
+			}
+		})
+	}
+}
+
+func TestProcessQuery_NamespaceScoping(t *testing.T) {
+	policy, err := authz.ParseStaticPolicy([]byte("rules:\n  - groups: [team-payments]\n    namespaces: [payments, payments-staging]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoper, err := authz.NewScoper(policy, authz.ModeNarrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor := &GenAIProcessor{
+		contextManager:  newMockContextManager(),
+		llmEngine:       newMockLLMEngine(),
+		RetryParser:     newMockRetryParser(),
+		safetyValidator: newMockSafetyValidator(),
+		logger:          log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	processor.SetNamespaceScoper(scoper)
+	req := &types.ProcessingRequest{Query: "Who deleted pods?", SessionID: "scoping-session"}
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "alice", Groups: []string{"team-payments"}})
+	response, err := processor.ProcessQuery(ctx, req)
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+	sq := response.StructuredQuery.(*types.StructuredQuery)
+	if got := sq.Namespace.Values(); len(got) != 2 || got[0] != "payments" || got[1] != "payments-staging" {
+		t.Errorf("Expected query narrowed to payments namespaces, got %v", got)
+	}
+	info := response.ValidationInfo.(*interfaces.ValidationResult)
+	if len(info.Warnings) == 0 || !strings.Contains(info.Warnings[0], "narrowed") {
+		t.Errorf("Expected a narrowing warning, got %v", info.Warnings)
+	}
+	if _, ok := info.Details["namespace_scope"]; !ok {
+		t.Error("Expected namespace_scope details")
+	}
+
+	response, err = processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "Who deleted pods?", SessionID: "anonymous-session"})
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	if !strings.Contains(response.Error, "namespace_access_denied") {
+		t.Errorf("Expected namespace_access_denied for anonymous caller, got %q", response.Error)
+	}
+}
+
+// namespaceLimitValidator rejects queries over more than one namespace
+type namespaceLimitValidator struct {
+	*mockSafetyValidator
+}
+
+func (n *namespaceLimitValidator) ValidateQuery(query *types.StructuredQuery) (*interfaces.ValidationResult, error) {
+	if len(query.Namespace.Values()) > 1 {
+		return &interfaces.ValidationResult{
+			IsValid:  false,
+			RuleName: "query_limits",
+			Severity: "medium",
+			Errors:   []string{"namespace array size exceeds maximum of 1"},
+		}, nil
+	}
+	return n.mockSafetyValidator.ValidateQuery(query)
+}
+
+func TestProcessQuery_RevalidatesScopedQuery(t *testing.T) {
+	policy, err := authz.ParseStaticPolicy([]byte("rules:\n  - groups: [team-payments]\n    namespaces: [payments, payments-staging]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoper, err := authz.NewScoper(policy, authz.ModeNarrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  &namespaceLimitValidator{mockSafetyValidator: newMockSafetyValidator()},
+		commandGenerator: generator.NewCommandGenerator(),
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	processor.SetNamespaceScoper(scoper)
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "alice", Groups: []string{"team-payments"}})
+	response, err := processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "Who deleted pods?", SessionID: "scoped-limit-session"})
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+	info := response.ValidationInfo.(*interfaces.ValidationResult)
+	if info.IsValid {
+		t.Fatal("Expected the scoped query to fail the namespace limit")
+	}
+	if len(info.Warnings) == 0 || !strings.Contains(info.Warnings[0], "narrowed") {
+		t.Errorf("Expected a narrowing warning, got %v", info.Warnings)
+	}
+	if response.Command != nil {
+		t.Errorf("Expected no command for the rejected query, got %+v", response.Command)
+	}
+}
+
+func TestProcessQuery_ValidatesWithCallerAndSession(t *testing.T) {
+	validator := &contextualSafetyValidator{mockSafetyValidator: newMockSafetyValidator()}
+	processor := &GenAIProcessor{
+		contextManager:  newMockContextManager(),
+		llmEngine:       newMockLLMEngine(),
+		RetryParser:     newMockRetryParser(),
+		safetyValidator: validator,
+		logger:          log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "alice", Groups: []string{"security-team"}})
+	response, err := processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "Who deleted pods?", SessionID: "policy-session"})
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+	vctx := validator.vctx
+	if vctx == nil {
+		t.Fatal("Expected the query to be validated with a validation context")
+	}
+	if vctx.UserID != "alice" || len(vctx.Groups) != 1 || vctx.Groups[0] != "security-team" {
+		t.Errorf("Expected the caller's identity, got %q %v", vctx.UserID, vctx.Groups)
+	}
+	if vctx.Session == nil || vctx.Session.SessionID != "policy-session" {
+		t.Errorf("Expected the caller's session, got %+v", vctx.Session)
+	}
+}
+
 func TestProcessQuery_ContextResolutionFailure(t *testing.T) {
 	mockContext := newMockContextManager()
 	mockContext.errors = map[string]error{
@@ -518,6 +708,410 @@ func TestProcessQuery_ValidationFailure(t *testing.T) {
 	}
 }
 
+func TestProcessQuery_GeneratesCommand(t *testing.T) {
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	req := &types.ProcessingRequest{
+		Query:     "List pods",
+		SessionID: "test-session-command",
+	}
+
+	response, err := processor.ProcessQuery(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	if response.Command == nil {
+		t.Fatal("Command should be generated for a valid query")
+	}
+	if !strings.HasPrefix(response.Command.Command, "oc adm node-logs --role=master --path=kube-apiserver/audit.log") {
+		t.Errorf("unexpected command: %s", response.Command.Command)
+	}
+	if !strings.Contains(response.Command.JQFilter, `.objectRef.resource == "pods"`) {
+		t.Errorf("filter should include resource condition: %s", response.Command.JQFilter)
+	}
+}
+
+// countingLLMEngine counts the queries sent to the mock engine
+type countingLLMEngine struct {
+	*mockLLMEngine
+	calls int
+}
+
+func (e *countingLLMEngine) ProcessQuery(ctx context.Context, query string, context types.ConversationContext) (*types.RawResponse, error) {
+	e.calls++
+	return e.mockLLMEngine.ProcessQuery(ctx, query, context)
+}
+
+func TestProcessQuery_ResponseCache(t *testing.T) {
+	engine := &countingLLMEngine{mockLLMEngine: newMockLLMEngine()}
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        engine,
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultProvider:  "claude",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	processor.SetResponseCache(cache.NewMemoryCache(time.Minute, 10))
+
+	first, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "Show daily authentication failures?", SessionID: "s1"})
+	if err != nil || first.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, first.Error)
+	}
+	if first.Cache != types.CacheMiss {
+		t.Errorf("expected first query to miss the cache, got %q", first.Cache)
+	}
+
+	second, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "show  daily authentication failures", SessionID: "s2"})
+	if err != nil || second.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, second.Error)
+	}
+	if second.Cache != types.CacheHit {
+		t.Errorf("expected repeated query to hit the cache, got %q", second.Cache)
+	}
+	if engine.calls != 1 {
+		t.Errorf("expected one LLM call, got %d", engine.calls)
+	}
+	if second.Provider != "claude" || second.Command == nil || second.ValidationInfo == nil {
+		t.Errorf("expected a complete response from the cache, got %+v", second)
+	}
+	if sq := second.StructuredQuery.(*types.StructuredQuery); sq.LogSource != "kube-apiserver" || sq.Resource.GetString() != "pods" {
+		t.Errorf("unexpected cached query %+v", sq)
+	}
+}
+
+func TestProcessQuery_ResponseCacheSkipsInvalidQueries(t *testing.T) {
+	engine := &countingLLMEngine{mockLLMEngine: newMockLLMEngine()}
+	validator := newMockSafetyValidator()
+	validator.results["kube-apiserver_get"] = &interfaces.ValidationResult{IsValid: false, RuleName: "mock_validator"}
+	processor := &GenAIProcessor{
+		contextManager:  newMockContextManager(),
+		llmEngine:       engine,
+		RetryParser:     newMockRetryParser(),
+		safetyValidator: validator,
+		defaultModel:    "claude-3-5-sonnet-20241022",
+		logger:          log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	processor.SetResponseCache(cache.NewMemoryCache(time.Minute, 10))
+
+	for i := 0; i < 2; i++ {
+		response, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+		if err != nil {
+			t.Fatalf("ProcessQuery failed: %v", err)
+		}
+		if response.Cache != types.CacheMiss {
+			t.Errorf("expected invalid query not to be cached, got %q", response.Cache)
+		}
+	}
+	if engine.calls != 2 {
+		t.Errorf("expected two LLM calls, got %d", engine.calls)
+	}
+}
+
+func TestProcessQuery_SemanticCache(t *testing.T) {
+	engine := &countingLLMEngine{mockLLMEngine: newMockLLMEngine()}
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        engine,
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultProvider:  "claude",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	semanticCache, err := cache.NewSemanticCache(cache.NewHashingEmbedder(0), 0.9, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("NewSemanticCache returned error: %v", err)
+	}
+	processor.SetSemanticCache(semanticCache)
+
+	first, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted the customer CRD yesterday", SessionID: "s1"})
+	if err != nil || first.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, first.Error)
+	}
+	if first.Cache != types.CacheMiss {
+		t.Errorf("expected first query to miss the cache, got %q", first.Cache)
+	}
+
+	second, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "which user removed the customer CRD yesterday", SessionID: "s2"})
+	if err != nil || second.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, second.Error)
+	}
+	if second.Cache != types.CacheHit || engine.calls != 1 {
+		t.Errorf("expected the similar query to reuse the first, got cache %q after %d LLM calls", second.Cache, engine.calls)
+	}
+	info := second.ValidationInfo.(*interfaces.ValidationResult)
+	match, ok := info.Details["semantic_cache"].(*cache.SemanticMatch)
+	if !ok {
+		t.Fatalf("expected semantic cache reuse in validation details, got %v", info.Details)
+	}
+	if match.Query != "who deleted the customer CRD yesterday" || match.Similarity < 0.9 {
+		t.Errorf("unexpected semantic match %+v", match)
+	}
+	if len(info.Warnings) == 0 {
+		t.Error("expected a warning recording the reuse")
+	}
+
+	third, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who created secrets in namespace payments", SessionID: "s3"})
+	if err != nil || third.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, third.Error)
+	}
+	if third.Cache != types.CacheMiss || engine.calls != 2 {
+		t.Errorf("expected a different query to miss, got cache %q after %d LLM calls", third.Cache, engine.calls)
+	}
+}
+
+func TestProcessQuery_AuditTrail(t *testing.T) {
+	store, err := audittrail.NewFileStore(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore returned error: %v", err)
+	}
+	defer store.Close()
+	validator := newMockSafetyValidator()
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  validator,
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultProvider:  "claude",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	processor.SetAuditTrail(store)
+
+	ctx := context.WithValue(context.Background(), types.ContextKeyUserID, "alice")
+	response, err := processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+	if response.RequestID == "" {
+		t.Fatal("expected the response to carry its request ID")
+	}
+
+	records, err := store.Query(audittrail.Filter{RequestID: response.RequestID})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one audit record, got %v, %v", records, err)
+	}
+	record := records[0]
+	if record.UserID != "alice" || record.SessionID != "s1" || record.Query != "List pods" || record.ResolvedQuery == "" {
+		t.Errorf("unexpected request details %+v", record)
+	}
+	if record.Provider != "claude" || record.Model != "claude-3-5-sonnet-20241022" || record.RawOutputSHA256 == "" {
+		t.Errorf("unexpected provider details %+v", record)
+	}
+	if record.Outcome != audittrail.OutcomeSuccess || record.StructuredQuery == nil || record.Validation == nil || !record.Validation.IsValid {
+		t.Errorf("unexpected outcome %+v", record)
+	}
+
+	validator.errors["kube-apiserver_get"] = fmt.Errorf("validator unavailable")
+	response, _ = processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+	records, _ = store.Query(audittrail.Filter{RequestID: response.RequestID})
+	if len(records) != 1 || records[0].Outcome != audittrail.OutcomeError || records[0].Error == "" {
+		t.Errorf("expected an error record, got %+v", records)
+	}
+}
+
+func TestProcessQuery_UsageBudget(t *testing.T) {
+	engine := newMockLLMEngine()
+	engine.responses["List pods"] = &types.RawResponse{
+		Content: `{"log_source": "kube-apiserver", "verb": "get", "resource": "pods", "limit": 20}`,
+		Metadata: map[string]interface{}{
+			"token_usage": map[string]interface{}{"prompt_tokens": 100, "completion_tokens": 50},
+		},
+	}
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        engine,
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultProvider:  "claude",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+	accountant := usage.NewAccountant(map[string]usage.Price{"claude-3-5-sonnet-20241022": {InputPerMillion: 3, OutputPerMillion: 15}}, "USD", usage.Budget{DailyTokens: 100})
+	processor.SetUsageAccountant(accountant)
+
+	ctx := context.WithValue(context.Background(), types.ContextKeyUserID, "alice")
+	response, err := processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+	if response.Usage == nil || response.Usage.TotalTokens != 150 || response.Usage.EstimatedCost <= 0 {
+		t.Errorf("expected priced usage in the response, got %+v", response.Usage)
+	}
+	if totals := accountant.UserSummary("alice"); totals.Today.Requests != 1 {
+		t.Errorf("expected usage to be accounted to the user, got %+v", totals)
+	}
+
+	response, err = processor.ProcessQuery(ctx, &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	if !strings.HasPrefix(response.Error, "budget_exceeded") {
+		t.Errorf("expected the spent budget to reject the request, got %q", response.Error)
+	}
+
+	other := context.WithValue(context.Background(), types.ContextKeyUserID, "bob")
+	if response, _ := processor.ProcessQuery(other, &types.ProcessingRequest{Query: "List pods", SessionID: "s2"}); response.Error != "" {
+		t.Errorf("expected another user's budget to be unaffected, got %q", response.Error)
+	}
+}
+
+func TestProcessQuery_Metrics(t *testing.T) {
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		defaultProvider:  "claude",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	successes := metrics.RequestDuration.With(audittrail.OutcomeSuccess).Count()
+	providerCalls := metrics.StageDuration.With(metrics.StageProviderCall).Count()
+	parses := metrics.StageDuration.With(metrics.StageParsing).Count()
+	providerSuccesses := metrics.ProviderRequests.With("claude", "success").Value()
+	normalizations := metrics.NormalizerRuns.With("schema_validator", "success").Value()
+
+	response, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "List pods", SessionID: "s1"})
+	if err != nil || response.Error != "" {
+		t.Fatalf("ProcessQuery failed: %v %s", err, response.Error)
+	}
+
+	if got := metrics.RequestDuration.With(audittrail.OutcomeSuccess).Count() - successes; got != 1 {
+		t.Errorf("expected 1 successful request to be timed, got %d", got)
+	}
+	if got := metrics.StageDuration.With(metrics.StageProviderCall).Count() - providerCalls; got != 1 {
+		t.Errorf("expected 1 provider call to be timed, got %d", got)
+	}
+	if got := metrics.StageDuration.With(metrics.StageParsing).Count() - parses; got != 1 {
+		t.Errorf("expected parsing to be timed once, got %d", got)
+	}
+	if got := metrics.ProviderRequests.With("claude", "success").Value() - providerSuccesses; got != 1 {
+		t.Errorf("expected 1 successful provider request, got %v", got)
+	}
+	if got := metrics.NormalizerRuns.With("schema_validator", "success").Value() - normalizations; got != 1 {
+		t.Errorf("expected 1 schema validator run, got %v", got)
+	}
+}
+
+func TestPreviewQuery_IncludesExplanation(t *testing.T) {
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  newMockSafetyValidator(),
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	response, err := processor.PreviewQuery(context.Background(), &types.ProcessingRequest{Query: "List pods", SessionID: "test-session-preview"})
+	if err != nil {
+		t.Fatalf("PreviewQuery failed: %v", err)
+	}
+	if response.Command == nil {
+		t.Fatal("Command should be included in the preview")
+	}
+	if !strings.HasPrefix(response.Explanation, "Searches kube-apiserver audit logs for get on pods") {
+		t.Errorf("unexpected explanation: %q", response.Explanation)
+	}
+}
+
+func TestProcessQuery_SkipsCommandForInvalidQuery(t *testing.T) {
+	mockValidator := newMockSafetyValidator()
+	mockValidator.results = map[string]*interfaces.ValidationResult{
+		"kube-apiserver_get": {IsValid: false, RuleName: "mock_validator", Errors: []string{"blocked"}},
+	}
+
+	processor := &GenAIProcessor{
+		contextManager:   newMockContextManager(),
+		llmEngine:        newMockLLMEngine(),
+		RetryParser:      newMockRetryParser(),
+		safetyValidator:  mockValidator,
+		commandGenerator: generator.NewCommandGenerator(),
+		defaultModel:     "claude-3-5-sonnet-20241022",
+		logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+	}
+
+	response, err := processor.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "List pods", SessionID: "test-session-invalid"})
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	if response.Command != nil {
+		t.Errorf("Command should not be generated for an invalid query, got %s", response.Command.Command)
+	}
+}
+
+func TestProcessQuery_RemediatesInvalidQuery(t *testing.T) {
+	newProcessor := func(remediation bool) *GenAIProcessor {
+		config := &validator.ValidationConfig{}
+		config.SafetyRules.AllowedLogSources = []string{"kube-apiserver"}
+		config.SafetyRules.AllowedVerbs = []string{"get", "list"}
+		config.SafetyRules.AllowedResources = []string{"pods"}
+		config.SafetyRules.TimeframeLimits = map[string]interface{}{"max_limit": 10}
+		config.SafetyRules.Remediation.Enabled = remediation
+		return &GenAIProcessor{
+			contextManager:   newMockContextManager(),
+			llmEngine:        newMockLLMEngine(),
+			RetryParser:      newMockRetryParser(),
+			safetyValidator:  validator.NewSafetyValidatorWithConfig(config),
+			commandGenerator: generator.NewCommandGenerator(),
+			logger:           log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+		}
+	}
+	req := &types.ProcessingRequest{Query: "List pods", SessionID: "test-session-remediation"}
+
+	response, err := newProcessor(false).ProcessQuery(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	if info := response.ValidationInfo.(*interfaces.ValidationResult); info.IsValid {
+		t.Fatal("Expected the limit of 20 to be rejected without remediation")
+	}
+
+	response, err = newProcessor(true).ProcessQuery(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessQuery failed: %v", err)
+	}
+	info := response.ValidationInfo.(*interfaces.ValidationResult)
+	if !info.IsValid {
+		t.Fatalf("Expected the repaired query to pass, got %v", info.Errors)
+	}
+	if sq := response.StructuredQuery.(*types.StructuredQuery); sq.Limit != 10 {
+		t.Errorf("Expected the limit to be clamped to 10, got %d", sq.Limit)
+	}
+	remediation, ok := info.Details["remediation"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected remediation details")
+	}
+	changes := remediation["changes"].([]interfaces.QueryChange)
+	if len(changes) != 1 || changes[0].Field != "limit" || changes[0].From != 20 || changes[0].To != 10 {
+		t.Errorf("Expected the limit change, got %+v", changes)
+	}
+	if len(info.Warnings) == 0 || !strings.Contains(info.Warnings[0], "limit changed from 20 to 10") {
+		t.Errorf("Expected a repair warning, got %v", info.Warnings)
+	}
+	if response.Command == nil {
+		t.Error("Expected a command for the repaired query")
+	}
+}
+
 func TestProcessQuery_WithPronounResolution(t *testing.T) {
 	mockContext := newMockContextManager()
 	mockContext.pronouns = map[string]string{
@@ -835,6 +1429,51 @@ func TestSimpleLLMEngine(t *testing.T) {
 	}
 }
 
+func TestNewAuditTrail_Backends(t *testing.T) {
+	// Nothing listens on port 1, so the sql backend reaches the linked
+	// postgres driver and fails to connect rather than to find the driver
+	unreachable := "postgres://audit@127.0.0.1:1/audit?sslmode=disable&connect_timeout=1"
+
+	tests := []struct {
+		name      string
+		cfg       config.AuditTrailConfig
+		wantStore bool
+		wantErr   string
+	}{
+		{name: "none", cfg: config.AuditTrailConfig{Backend: "none"}},
+		{name: "file", cfg: config.AuditTrailConfig{Backend: "file", Path: filepath.Join(t.TempDir(), "audit.jsonl")}, wantStore: true},
+		{name: "sql", cfg: config.AuditTrailConfig{Backend: "sql", SQLDriver: "postgres", SQLDSN: unreachable}, wantErr: "failed to create audit trail table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := newAuditTrail(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error mentioning %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newAuditTrail returned error: %v", err)
+			}
+			if (store != nil) != tt.wantStore {
+				t.Fatalf("expected a store %v, got %v", tt.wantStore, store)
+			}
+			if store != nil {
+				store.Close()
+			}
+		})
+	}
+
+	app := config.GetDefaultConfig()
+	app.AuditTrail.Backend = "sql"
+	app.AuditTrail.SQLDSN = unreachable
+	if _, err := NewGenAIProcessorFromConfig(app); err == nil || strings.Contains(err.Error(), "unknown driver") {
+		t.Errorf("expected the unreachable database to fail with the linked driver, got %v", err)
+	}
+}
+
 func TestNewGenAIProcessorFromConfig_ValidatesAndBuilds(t *testing.T) {
 	app := config.GetDefaultConfig()
 	// Ensure API keys present so providers pass registration
@@ -879,14 +1518,37 @@ func TestProcessQuery_TimeoutAndRetry(t *testing.T) {
 	if resp == nil || resp.Error != "" {
 		t.Fatalf("expected success after retry, got resp=%v err=%v", resp, err)
 	}
+	if len(prov.contexts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(prov.contexts))
+	}
+	if prov.live != 0 {
+		t.Errorf("expected the failed attempt's context to be canceled before the retry, found %d live contexts", prov.live)
+	}
+	for i, c := range prov.contexts {
+		if !errors.Is(c.Err(), context.Canceled) {
+			t.Errorf("expected attempt %d's context to be canceled, got %v", i+1, c.Err())
+		}
+	}
 }
 
-// flakyProvider fails a fixed number of initial attempts, then succeeds
-type flakyProvider struct{ fails *int }
+// flakyProvider fails a fixed number of initial attempts, then succeeds. It
+// records each attempt's context and counts the earlier ones still live when
+// another attempt starts.
+type flakyProvider struct {
+	fails    *int
+	contexts []context.Context
+	live     int
+}
 
 func intPtr(i int) *int { return &i }
 
 func (f *flakyProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	for _, c := range f.contexts {
+		if c.Err() == nil {
+			f.live++
+		}
+	}
+	f.contexts = append(f.contexts, ctx)
 	if f.fails != nil && *f.fails > 0 {
 		*f.fails = *f.fails - 1
 		return nil, fmt.Errorf("temporary error: timeout")
@@ -0,0 +1,193 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"genai-processing/internal/config"
+	"genai-processing/internal/engine"
+	"genai-processing/internal/schema"
+	"genai-processing/internal/validator"
+	"genai-processing/pkg/interfaces"
+)
+
+// pipeline is the part of the processor built from models.yaml, prompts.yaml
+// and rules.yaml: the provider routes with their adapters, formatters and
+// examples, the prompt validation settings and the safety validator. Reload
+// replaces it as a whole; sessions, caches, the audit trail and usage
+// accounting are kept.
+type pipeline struct {
+	safetyValidator  interfaces.SafetyValidator
+	querySchema      map[string]interface{}
+	promptValidation config.PromptValidation
+
+	// defaultRoute serves requests when there is no provider to fail over to
+	defaultRoute *providerRoute
+
+	// routes, routeOrder and modelSelector are set when fallback providers
+	// are configured
+	routes        map[string]*providerRoute
+	routeOrder    []string
+	modelSelector *engine.ModelSelector
+}
+
+// reloadLocks guards a processor's pipeline against concurrent reloads.
+type reloadLocks struct {
+	// reloading serializes reloads
+	reloading sync.Mutex
+
+	// pipeline is held for reading while a request takes its snapshot of
+	// the processor and for writing while a reload installs a pipeline
+	pipeline sync.RWMutex
+}
+
+// newPipeline builds the safety validator's query schema and the default and
+// fallback provider routes for appConfig. Fallback providers that cannot be
+// built are logged and skipped; the default provider is required.
+func newPipeline(appConfig *config.AppConfig, safetyValidator *validator.SafetyValidator, contextManager interfaces.ContextManager, logger *log.Logger) (*pipeline, error) {
+	// The query schema follows the validation rules, so prompts and
+	// constrained output ask for what the safety validator accepts
+	querySchema := schema.Generate(safetyValidator.GetConfig())
+
+	// Build the default provider route, then any fallback providers
+	defaultKey := appConfig.Models.DefaultProvider
+	defaultRoute, err := newProviderRoute(appConfig, defaultKey, querySchema, contextManager, logger)
+	if err != nil {
+		return nil, err
+	}
+	routes := map[string]*providerRoute{defaultKey: defaultRoute}
+	routeOrder := []string{defaultKey}
+	for _, name := range appConfig.Models.FallbackProviders {
+		route, err := newProviderRoute(appConfig, name, querySchema, contextManager, logger)
+		if err != nil {
+			// Log and continue to allow the other providers to serve requests
+			logger.Printf("warning: fallback provider '%s' unavailable: %v", name, err)
+			continue
+		}
+		routes[name] = route
+		routeOrder = append(routeOrder, name)
+	}
+
+	pl := &pipeline{
+		safetyValidator:  safetyValidator,
+		querySchema:      querySchema,
+		promptValidation: appConfig.Prompts.Validation,
+		defaultRoute:     defaultRoute,
+	}
+
+	// Route requests through the model selector when there is a provider to fail over to
+	if len(routeOrder) > 1 {
+		interval := appConfig.Models.HealthCheckInterval
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+		pl.routes = routes
+		pl.routeOrder = routeOrder
+		pl.modelSelector = engine.NewModelSelector(&routeFactory{routes: routes}, &engine.SelectorConfig{
+			DefaultProvider:     defaultKey,
+			Preferences:         routeOrder,
+			HealthCheckInterval: interval,
+			HealthCheckTimeout:  providerHealthCheckTimeout,
+		})
+		logger.Printf("Provider failover: %s", strings.Join(routeOrder, " -> "))
+	}
+
+	return pl, nil
+}
+
+// newSafetyValidator builds the safety validator for a new processor. A rules
+// file is loaded as strictly as Reload loads it, so rules that Reload would
+// reject also stop startup; only a missing file falls back to the built-in
+// rules.
+func newSafetyValidator() (*validator.SafetyValidator, error) {
+	path := validator.ConfigPath()
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return validator.NewSafetyValidator(), nil
+	}
+	safetyValidator, err := validator.LoadSafetyValidator(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation rules: %w", err)
+	}
+	return safetyValidator, nil
+}
+
+// install sets the processor's pipeline fields. Callers other than the
+// constructor must hold the pipeline lock for writing.
+func (p *GenAIProcessor) install(pl *pipeline) {
+	p.safetyValidator = pl.safetyValidator
+	p.querySchema = pl.querySchema
+	p.promptValidation = pl.promptValidation
+	p.llmEngine = pl.defaultRoute.engine
+	p.RetryParser = pl.defaultRoute.retryParser
+	p.defaultModel = pl.defaultRoute.modelName
+	p.defaultProvider = pl.defaultRoute.name
+	p.providerTimeout = pl.defaultRoute.timeout
+	p.retryAttempts = pl.defaultRoute.retryAttempts
+	p.retryDelay = pl.defaultRoute.retryDelay
+	p.routes = pl.routes
+	p.routeOrder = pl.routeOrder
+	p.modelSelector = pl.modelSelector
+}
+
+// Reload replaces the provider routes, with their adapters, formatters and
+// examples, the prompt validation settings and the safety validator with ones
+// built from appConfig and the rules file, and prices later usage with the
+// models.yaml price table, without dropping sessions, caches or usage
+// totals. The configuration must pass AppConfig.Validate and the rules file
+// must load without problems; otherwise an error is returned and the current
+// pipeline keeps serving requests. Requests in flight finish with the
+// pipeline they started with.
+func (p *GenAIProcessor) Reload(appConfig *config.AppConfig) error {
+	if p.reload == nil {
+		return fmt.Errorf("processor does not support reloading")
+	}
+	if appConfig == nil {
+		return fmt.Errorf("appConfig cannot be nil")
+	}
+	p.reload.reloading.Lock()
+	defer p.reload.reloading.Unlock()
+
+	if v := appConfig.Validate(); !v.Valid {
+		return fmt.Errorf("invalid configuration: %v", v.Errors)
+	}
+	safetyValidator, err := validator.LoadSafetyValidator(validator.ConfigPath())
+	if err != nil {
+		return fmt.Errorf("invalid validation rules: %w", err)
+	}
+	pl, err := newPipeline(appConfig, safetyValidator, p.contextManager, p.logger)
+	if err != nil {
+		return fmt.Errorf("failed to build provider routes: %w", err)
+	}
+
+	p.reload.pipeline.Lock()
+	previous := p.modelSelector
+	p.install(pl)
+	if p.accountant != nil {
+		p.accountant.SetPrices(modelPrices(appConfig), appConfig.Models.Pricing.Currency)
+	}
+	p.reload.pipeline.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	p.logger.Printf("Configuration reloaded: default provider %s, %d example(s), %d active rule(s)",
+		pl.defaultRoute.name, len(appConfig.Prompts.Examples), len(safetyValidator.Registry().Active()))
+	return nil
+}
+
+// snapshot returns a copy of the processor that a request uses throughout,
+// so a concurrent reload cannot mix two configurations within one request.
+func (p *GenAIProcessor) snapshot() *GenAIProcessor {
+	if p.reload == nil {
+		return p
+	}
+	p.reload.pipeline.RLock()
+	defer p.reload.pipeline.RUnlock()
+	s := *p
+	return &s
+}
@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"genai-processing/internal/config"
+	"genai-processing/pkg/types"
+)
+
+const reloadTestRules = `
+safety_rules:
+  allowed_log_sources: ["kube-apiserver"]
+  allowed_resources: [%s]
+  required_fields: ["log_source"]
+`
+
+// writeReloadRules writes a rules file allowing resources
+func writeReloadRules(t *testing.T, path, resources string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadTestRules, resources)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reloadTestConfig() *config.AppConfig {
+	app := config.GetDefaultConfig()
+	c := app.Models.Providers["claude"]
+	c.APIKey = "test-key"
+	app.Models.Providers["claude"] = c
+	return app
+}
+
+func TestReload_SwapsPipeline(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	writeReloadRules(t, rulesPath, `"pods", "secrets"`)
+	t.Setenv("VALIDATION_RULES_FILE", rulesPath)
+
+	p, err := NewGenAIProcessorFromConfig(reloadTestConfig())
+	if err != nil {
+		t.Fatalf("NewGenAIProcessorFromConfig returned error: %v", err)
+	}
+	defer p.Close()
+	before := p.snapshot()
+
+	secrets := &types.StructuredQuery{LogSource: "kube-apiserver", Resource: *types.NewStringOrArray("secrets")}
+	if result, _ := p.safetyValidator.ValidateQuery(secrets); !result.IsValid {
+		t.Fatalf("expected secrets to be allowed before the reload, got %v", result.Errors)
+	}
+
+	writeReloadRules(t, rulesPath, `"pods"`)
+	app := reloadTestConfig()
+	app.Prompts.Validation.MaxInputLength = 42
+	app.Models.Pricing.Models["claude-3-5-sonnet-20241022"] = config.ModelPrice{InputPerMillion: 6, OutputPerMillion: 30}
+	app.Prompts.Examples = append(app.Prompts.Examples, types.Example{Input: "who read secrets", Output: `{"log_source":"kube-apiserver"}`})
+	if err := p.Reload(app); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+
+	if result, _ := p.safetyValidator.ValidateQuery(secrets); result.IsValid {
+		t.Error("expected the reloaded rules to reject secrets")
+	}
+	if p.promptValidation.MaxInputLength != 42 {
+		t.Errorf("expected the reloaded max input length, got %d", p.promptValidation.MaxInputLength)
+	}
+	if p.llmEngine == before.llmEngine || p.RetryParser == before.RetryParser {
+		t.Error("expected the provider route to be rebuilt")
+	}
+	if p.contextManager != before.contextManager || p.accountant != before.accountant {
+		t.Error("expected sessions and usage accounting to be kept")
+	}
+	if cost := p.accountant.Cost("claude-3-5-sonnet-20241022", 1000000, 0); cost != 6 {
+		t.Errorf("expected the reloaded price table, got a cost of %v", cost)
+	}
+
+	// Requests that took their snapshot before the reload keep the old pipeline
+	if result, _ := before.safetyValidator.ValidateQuery(secrets); !result.IsValid {
+		t.Error("expected the earlier snapshot to keep the rules it started with")
+	}
+}
+
+func TestReload_RollsBackOnInvalidConfiguration(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	writeReloadRules(t, rulesPath, `"pods"`)
+	t.Setenv("VALIDATION_RULES_FILE", rulesPath)
+
+	p, err := NewGenAIProcessorFromConfig(reloadTestConfig())
+	if err != nil {
+		t.Fatalf("NewGenAIProcessorFromConfig returned error: %v", err)
+	}
+	defer p.Close()
+	before := p.snapshot()
+
+	tests := []struct {
+		name    string
+		rules   string
+		config  func(*config.AppConfig)
+		wantErr string
+	}{
+		{
+			name:    "unknown default provider",
+			config:  func(app *config.AppConfig) { app.Models.DefaultProvider = "missing" },
+			wantErr: "invalid configuration",
+		},
+		{
+			name:    "unparseable rules",
+			rules:   "safety_rules: [",
+			wantErr: "invalid validation rules",
+		},
+		{
+			name:    "unregistered rule",
+			rules:   "safety_rules:\n  rules:\n    no_such_rule: {}\n",
+			wantErr: "no_such_rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeReloadRules(t, rulesPath, `"pods"`)
+			if tt.rules != "" {
+				if err := os.WriteFile(rulesPath, []byte(tt.rules), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			app := reloadTestConfig()
+			app.Prompts.Validation.MaxInputLength = 42
+			app.Models.Pricing.Models["claude-3-5-sonnet-20241022"] = config.ModelPrice{InputPerMillion: 6, OutputPerMillion: 30}
+			if tt.config != nil {
+				tt.config(app)
+			}
+
+			err := p.Reload(app)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error mentioning %q, got %v", tt.wantErr, err)
+			}
+			if p.safetyValidator != before.safetyValidator || p.llmEngine != before.llmEngine || p.promptValidation.MaxInputLength == 42 {
+				t.Error("expected the failed reload to keep the current pipeline")
+			}
+			if cost := p.accountant.Cost("claude-3-5-sonnet-20241022", 1000000, 0); cost != 3 {
+				t.Errorf("expected the failed reload to keep the price table, got a cost of %v", cost)
+			}
+		})
+	}
+}
+
+func TestNewGenAIProcessorFromConfig_RejectsInvalidRules(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	t.Setenv("VALIDATION_RULES_FILE", rulesPath)
+
+	// Rules that Reload rejects also stop startup
+	if err := os.WriteFile(rulesPath, []byte("safety_rules:\n  rules:\n    no_such_rule: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := NewGenAIProcessorFromConfig(reloadTestConfig()); err == nil || !strings.Contains(err.Error(), "no_such_rule") {
+		if p != nil {
+			p.Close()
+		}
+		t.Fatalf("expected an error mentioning no_such_rule, got %v", err)
+	}
+
+	// Without a rules file the built-in rules are used
+	if err := os.Remove(rulesPath); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewGenAIProcessorFromConfig(reloadTestConfig())
+	if err != nil {
+		t.Fatalf("expected the built-in rules without a rules file, got %v", err)
+	}
+	p.Close()
+}
@@ -0,0 +1,407 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"genai-processing/internal/config"
+	"genai-processing/internal/engine"
+	"genai-processing/internal/engine/adapters"
+	"genai-processing/internal/engine/providers"
+	"genai-processing/internal/parser/extractors"
+	"genai-processing/internal/parser/recovery"
+	promptformatters "genai-processing/internal/prompts/formatters"
+	"genai-processing/internal/schema"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// defaultHealthCheckInterval is used when models.yaml does not set health_check_interval.
+const defaultHealthCheckInterval = 5 * time.Minute
+
+// providerHealthCheckTimeout bounds each round of provider health checks.
+const providerHealthCheckTimeout = 10 * time.Second
+
+// providerRoute is one configured provider together with the input adapter
+// and output parsers matching its request and response formats.
+type providerRoute struct {
+	// name is the provider's key in models.yaml
+	name string
+
+	// provider and config are exposed to the model selector for health checks
+	provider interfaces.LLMProvider
+	config   *types.ProviderConfig
+
+	// engine pairs the provider with its input adapter
+	engine interfaces.LLMEngine
+
+	// retryParser parses responses, preferring the provider's extractor
+	retryParser *recovery.RetryParser
+
+	// modelName selects model-specific extraction in the parser
+	modelName string
+
+	// Provider execution controls
+	timeout       time.Duration
+	retryAttempts int
+	retryDelay    time.Duration
+}
+
+// defaultRoute describes the processor's own engine and parser, which serve
+// every request when no model selector is configured.
+func (p *GenAIProcessor) defaultRoute() *providerRoute {
+	return &providerRoute{
+		name:          p.defaultProvider,
+		engine:        p.llmEngine,
+		retryParser:   p.RetryParser,
+		modelName:     p.defaultModel,
+		timeout:       p.providerTimeout,
+		retryAttempts: p.retryAttempts,
+		retryDelay:    p.retryDelay,
+	}
+}
+
+// candidateRoutes returns the providers to try for a request, in order. The
+// model selector ranks the healthy ones, honouring the requested model type;
+// unhealthy providers follow as a last resort, since health checks can lag
+// behind a provider's recovery.
+func (p *GenAIProcessor) candidateRoutes(ctx context.Context, preferred string) []*providerRoute {
+	if p.modelSelector == nil {
+		return []*providerRoute{p.defaultRoute()}
+	}
+
+	var routes []*providerRoute
+	seen := make(map[string]bool)
+	ranked, err := p.modelSelector.RankProviders(ctx, &engine.SelectionRequest{PreferredModel: preferred})
+	if err != nil {
+		p.logger.Printf("Provider selection: %v; trying configured providers in order", err)
+	}
+	for _, selection := range ranked {
+		if route, ok := p.routes[selection.ProviderName]; ok && !seen[route.name] {
+			seen[route.name] = true
+			routes = append(routes, route)
+		}
+	}
+	for _, name := range p.routeOrder {
+		if route, ok := p.routes[name]; ok && !seen[name] {
+			seen[name] = true
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// newProviderRoute builds the provider, input adapter, engine and parsers
+// for one models.yaml provider entry. querySchema is embedded in the system
+// prompt and sent for schema-constrained output; nil uses the schema of the
+// StructuredQuery tags alone.
+func newProviderRoute(appConfig *config.AppConfig, name string, querySchema map[string]interface{}, contextManager interfaces.ContextManager, logger *log.Logger) (*providerRoute, error) {
+	mc, ok := appConfig.Models.Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider '%s' not found in providers", name)
+	}
+	if querySchema == nil {
+		querySchema = schema.StructuredQuery()
+	}
+	schemaPrompt, err := schema.PromptText(querySchema)
+	if err != nil {
+		return nil, err
+	}
+	providerCfg := &types.ProviderConfig{
+		APIKey:     mc.APIKey,
+		Endpoint:   mc.Endpoint,
+		ModelName:  mc.ModelName,
+		Parameters: providerParameters(mc),
+	}
+	providerType := mapProviderType(name, mc.Provider)
+
+	// Create concrete provider from the config
+	provider, err := providers.NewProviderFactory().CreateProviderWithConfig(providerType, providerCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider '%s': %w", providerType, err)
+	}
+
+	// Build input adapter based on config
+	var adapter interfaces.InputAdapter
+	switch mc.InputAdapter {
+	case "claude_input_adapter":
+		claude := adapters.NewClaudeInputAdapter(mc.APIKey)
+		claude.SetModelName(mc.ModelName)
+		_ = claude.SetMaxTokens(mc.MaxTokens)
+		_ = claude.SetTemperature(mc.Temperature)
+		// System prompt precedence: models.yaml parameters.system > prompts.yaml
+		if sys, ok := providerCfg.Parameters["system"].(string); ok && sys != "" {
+			claude.SetSystemPrompt(withQuerySchema(sys, schemaPrompt))
+			logger.Printf("system prompt: override from models.yaml parameters.system for provider %s", name)
+		} else if sp, key := systemPromptFor(appConfig, "claude"); sp != "" {
+			claude.SetSystemPrompt(withQuerySchema(sp, schemaPrompt))
+			logger.Printf("system prompt: selected '%s' for provider %s", key, name)
+		}
+		// Wire examples from prompts.yaml and the schema for tool calling
+		claude.SetExamples(appConfig.Prompts.Examples)
+		claude.SetJSONSchema(querySchema)
+		// Formatter (mc.PromptFormatter overrides provider default)
+		claude.SetFormatter(newPromptFormatter(appConfig, "claude", mc.PromptFormatter))
+		adapter = claude
+	case "openai_input_adapter":
+		openai := adapters.NewOpenAIInputAdapter(mc.APIKey)
+		openai.SetModelName(mc.ModelName)
+		_ = openai.SetMaxTokens(mc.MaxTokens)
+		_ = openai.SetTemperature(mc.Temperature)
+		if sys, ok := providerCfg.Parameters["system"].(string); ok && sys != "" {
+			openai.SetSystemPrompt(withQuerySchema(sys, schemaPrompt))
+			logger.Printf("system prompt: override from models.yaml parameters.system for provider %s", name)
+		} else if sp, key := systemPromptFor(appConfig, "openai"); sp != "" {
+			openai.SetSystemPrompt(withQuerySchema(sp, schemaPrompt))
+			logger.Printf("system prompt: selected '%s' for provider %s", key, name)
+		}
+		openai.SetExamples(appConfig.Prompts.Examples)
+		openai.SetJSONSchema(querySchema)
+		openai.SetFormatter(newPromptFormatter(appConfig, "openai", mc.PromptFormatter))
+		adapter = openai
+	case "ollama_input_adapter", "vllm_input_adapter":
+		ollama := adapters.NewOllamaInputAdapter()
+		ollama.SetModelName(mc.ModelName)
+		_ = ollama.SetMaxTokens(mc.MaxTokens)
+		_ = ollama.SetTemperature(mc.Temperature)
+		if sys, ok := providerCfg.Parameters["system"].(string); ok && sys != "" {
+			ollama.SetSystemPrompt(withQuerySchema(sys, schemaPrompt))
+			logger.Printf("system prompt: override from models.yaml parameters.system for provider %s", name)
+		} else if sp, key := systemPromptFor(appConfig, "ollama"); sp != "" {
+			ollama.SetSystemPrompt(withQuerySchema(sp, schemaPrompt))
+			logger.Printf("system prompt: selected '%s' for provider %s", key, name)
+		}
+		ollama.SetExamples(appConfig.Prompts.Examples)
+		ollama.SetJSONSchema(querySchema)
+		ollama.SetFormatter(newPromptFormatter(appConfig, "generic", mc.PromptFormatter))
+		adapter = ollama
+	default:
+		generic := adapters.NewGenericInputAdapter(mc.APIKey)
+		generic.SetModelName(mc.ModelName)
+		_ = generic.SetMaxTokens(mc.MaxTokens)
+		_ = generic.SetTemperature(mc.Temperature)
+		generic.SetExamples(appConfig.Prompts.Examples)
+		if sys, ok := providerCfg.Parameters["system"].(string); ok && sys != "" {
+			generic.SetSystemPrompt(withQuerySchema(sys, schemaPrompt))
+			logger.Printf("system prompt: override from models.yaml parameters.system for provider %s", name)
+		} else if sp, key := systemPromptFor(appConfig, "generic"); sp != "" {
+			generic.SetSystemPrompt(withQuerySchema(sp, schemaPrompt))
+			logger.Printf("system prompt: selected '%s' for provider %s", key, name)
+		}
+		generic.SetFormatter(newPromptFormatter(appConfig, "generic", mc.PromptFormatter))
+		adapter = generic
+	}
+
+	// Create LLM engine
+	llmEngine := engine.NewLLMEngine(provider, adapter)
+
+	// Retry parser configuration derives from model retry settings
+	retryConfig := &recovery.RetryConfig{
+		MaxRetries:          mc.RetryAttempts,
+		RetryDelay:          mc.RetryDelay,
+		ConfidenceThreshold: 0.7,
+		EnableReprompting:   true,
+		RepromptTemplate:    "The previous response was not in the expected JSON format. Please provide a valid JSON response for the following query: %s",
+	}
+	retryParser := recovery.NewRetryParser(retryConfig, llmEngine, contextManager)
+	// Enforce LLM output length from prompts validation if specified
+	if appConfig.Prompts.Validation.MaxOutputLength > 0 {
+		retryParser.SetMaxOutputLength(appConfig.Prompts.Validation.MaxOutputLength)
+	}
+
+	// Parser preferences based on OutputParser using factory
+	claudeExtractor := extractors.NewClaudeExtractor()
+	openaiExtractor := extractors.NewOpenAIExtractor()
+	ollamaExtractor := extractors.NewOllamaExtractor()
+	genericExtractor := extractors.NewGenericExtractor()
+
+	extractorFactory := extractors.NewExtractorFactory()
+	extractorFactory.Register("claude", claudeExtractor, "anthropic")
+	extractorFactory.Register("openai", openaiExtractor)
+	extractorFactory.Register("ollama", ollamaExtractor, "vllm")
+	extractorFactory.SetGeneric(genericExtractor)
+
+	switch mc.OutputParser {
+	case "claude_extractor":
+		retryParser.RegisterParser(recovery.StrategySpecific, claudeExtractor)
+	case "openai_extractor":
+		retryParser.RegisterParser(recovery.StrategySpecific, openaiExtractor)
+	case "ollama_extractor", "vllm_extractor":
+		retryParser.RegisterParser(recovery.StrategySpecific, ollamaExtractor)
+	default:
+		// Delegate by model type via extractor factory
+		retryParser.RegisterParser(recovery.StrategySpecific, extractorFactory.CreateDelegatingParser())
+	}
+	retryParser.RegisterParser(recovery.StrategyGeneric, genericExtractor)
+
+	// Configure fallback handler for retry parser
+	retryParser.SetFallbackHandler(recovery.NewFallbackHandler())
+
+	// Log prompt formatter selection (now active via formatters package)
+	if mc.PromptFormatter != "" {
+		logger.Printf("prompt_formatter active for %s: %s", name, mc.PromptFormatter)
+	}
+
+	return &providerRoute{
+		name:          name,
+		provider:      provider,
+		config:        providerCfg,
+		engine:        llmEngine,
+		retryParser:   retryParser,
+		modelName:     mc.ModelName,
+		timeout:       mc.Timeout,
+		retryAttempts: mc.RetryAttempts,
+		retryDelay:    mc.RetryDelay,
+	}, nil
+}
+
+// providerParameters converts ModelConfig.Parameters (map[string]string) to
+// map[string]interface{}, parsing numeric-looking values.
+func providerParameters(mc config.ModelConfig) map[string]interface{} {
+	result := map[string]interface{}{}
+	if mc.Parameters != nil {
+		for k, v := range mc.Parameters {
+			// Try to parse int, then float; durations such as keep_alive "10m"
+			// must not match, so the whole value has to parse
+			if intVal, err := strconv.Atoi(v); err == nil {
+				result[k] = intVal
+				continue
+			}
+			if floatVal, err := strconv.ParseFloat(v, 64); err == nil {
+				result[k] = floatVal
+				continue
+			}
+			// fallback to string
+			result[k] = v
+		}
+	}
+	// Ensure core numeric params present from top-level too
+	if mc.MaxTokens > 0 {
+		result["max_tokens"] = mc.MaxTokens
+	}
+	if mc.Temperature >= 0 {
+		result["temperature"] = mc.Temperature
+	}
+	return result
+}
+
+// systemPromptFor chooses the system prompt from prompts.yaml for a provider
+// type, falling back to the base prompt. It returns the prompt and its key.
+func systemPromptFor(appConfig *config.AppConfig, providerType string) (string, string) {
+	sys := ""
+	key := ""
+	if appConfig != nil && appConfig.Prompts.SystemPrompts != nil {
+		switch providerType {
+		case "claude":
+			key = "claude_specific"
+			sys = appConfig.Prompts.SystemPrompts[key]
+		case "openai":
+			key = "openai_specific"
+			sys = appConfig.Prompts.SystemPrompts[key]
+		case "generic":
+			key = "generic_specific"
+			sys = appConfig.Prompts.SystemPrompts[key]
+		case "ollama":
+			// Self-hosted models share the generic prompt unless given their own
+			key = "ollama_specific"
+			sys = appConfig.Prompts.SystemPrompts[key]
+			if sys == "" {
+				key = "generic_specific"
+				sys = appConfig.Prompts.SystemPrompts[key]
+			}
+		}
+		if sys == "" {
+			key = "base"
+			sys = appConfig.Prompts.SystemPrompts[key]
+		}
+	}
+	return sys, key
+}
+
+// withQuerySchema appends the query schema instructions to a system prompt.
+func withQuerySchema(prompt, schemaPrompt string) string {
+	return strings.TrimRight(prompt, "\n") + "\n\n" + schemaPrompt
+}
+
+// newPromptFormatter selects the formatter implementation based on the
+// configured prompt_formatter or, failing that, the provider type. Falls back
+// gracefully when templates are missing.
+func newPromptFormatter(appConfig *config.AppConfig, providerType string, formatterName string) interfaces.PromptFormatter {
+	// Prefer explicit formatterName if provided
+	normalized := strings.ToLower(formatterName)
+	kind := providerType
+	if strings.Contains(normalized, "openai") {
+		kind = "openai"
+	} else if strings.Contains(normalized, "claude") {
+		kind = "claude"
+	} else if strings.Contains(normalized, "generic") {
+		kind = "generic"
+	}
+	switch kind {
+	case "claude":
+		return promptformatters.NewClaudeFormatter(appConfig.Prompts.Formats.Claude.Template)
+	case "openai":
+		of := appConfig.Prompts.Formats.OpenAI
+		return promptformatters.NewOpenAIFormatter(of.Template, of.SystemMessage, of.UserMessage)
+	default:
+		return promptformatters.NewGenericFormatter(appConfig.Prompts.Formats.Generic.Template)
+	}
+}
+
+// routeFactory exposes the processor's routes to engine.ModelSelector under
+// their models.yaml names, so health checks and selection apply to the same
+// provider instances that serve requests.
+type routeFactory struct {
+	routes map[string]*providerRoute
+}
+
+// RegisterProvider is not supported; routes are fixed when the processor is built.
+func (f *routeFactory) RegisterProvider(providerType string, config *types.ProviderConfig) error {
+	return fmt.Errorf("cannot register provider '%s': routes are fixed at startup", providerType)
+}
+
+// CreateProvider returns the provider of the named route.
+func (f *routeFactory) CreateProvider(modelType string) (interfaces.LLMProvider, error) {
+	route, ok := f.routes[modelType]
+	if !ok || route.provider == nil {
+		return nil, fmt.Errorf("unsupported provider type: %s", modelType)
+	}
+	return route.provider, nil
+}
+
+// GetSupportedProviders returns the route names, sorted.
+func (f *routeFactory) GetSupportedProviders() []string {
+	names := make([]string, 0, len(f.routes))
+	for name := range f.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProviderConfig returns the provider configuration of the named route.
+func (f *routeFactory) GetProviderConfig(providerType string) (*types.ProviderConfig, error) {
+	route, ok := f.routes[providerType]
+	if !ok || route.config == nil {
+		return nil, fmt.Errorf("provider type not found: %s", providerType)
+	}
+	return route.config, nil
+}
+
+// ValidateProvider checks that a route exists.
+func (f *routeFactory) ValidateProvider(providerType string) error {
+	if _, ok := f.routes[providerType]; !ok {
+		return fmt.Errorf("provider type not registered: %s", providerType)
+	}
+	return nil
+}
+
+// CreateProviderWithConfig is not supported; routes are fixed when the processor is built.
+func (f *routeFactory) CreateProviderWithConfig(providerType string, config *types.ProviderConfig) (interfaces.LLMProvider, error) {
+	return nil, fmt.Errorf("cannot create provider '%s': routes are fixed at startup", providerType)
+}
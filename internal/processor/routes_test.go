@@ -0,0 +1,212 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/internal/config"
+	"genai-processing/internal/engine"
+	pkgerrors "genai-processing/pkg/errors"
+	"genai-processing/pkg/types"
+)
+
+// routeProvider is an LLM provider with scripted failures for failover tests
+type routeProvider struct {
+	name      string
+	err       error
+	healthErr error
+	calls     int
+}
+
+func (r *routeProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &types.RawResponse{Content: `{"log_source": "kube-apiserver", "limit": 20}`}, nil
+}
+
+func (r *routeProvider) GetModelInfo() types.ModelInfo {
+	return types.ModelInfo{Name: r.name + "-model", Provider: r.name}
+}
+
+func (r *routeProvider) SupportsStreaming() bool { return false }
+
+func (r *routeProvider) ValidateConnection() error { return r.healthErr }
+
+// newFailoverProcessor builds a processor that prefers claude and fails over to openai
+func newFailoverProcessor(t *testing.T, claude, openai *routeProvider) *GenAIProcessor {
+	t.Helper()
+	routes := map[string]*providerRoute{}
+	for _, provider := range []*routeProvider{claude, openai} {
+		routes[provider.name] = &providerRoute{
+			name:        provider.name,
+			provider:    provider,
+			config:      &types.ProviderConfig{ModelName: provider.name + "-model"},
+			engine:      &engineWithProvider{provider: provider},
+			retryParser: newMockRetryParser(),
+			modelName:   provider.name + "-model",
+		}
+	}
+	order := []string{"claude", "openai"}
+	selector := engine.NewModelSelector(&routeFactory{routes: routes}, &engine.SelectorConfig{
+		DefaultProvider:     "claude",
+		Preferences:         order,
+		HealthCheckInterval: time.Hour,
+		HealthCheckTimeout:  time.Second,
+	})
+	t.Cleanup(selector.Stop)
+	if err := selector.ForceHealthCheck(context.Background()); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+
+	return &GenAIProcessor{
+		contextManager:  newMockContextManager(),
+		llmEngine:       routes["claude"].engine,
+		RetryParser:     routes["claude"].retryParser,
+		safetyValidator: newMockSafetyValidator(),
+		defaultModel:    "claude-model",
+		defaultProvider: "claude",
+		logger:          log.New(log.Writer(), "[TestProcessor] ", log.LstdFlags),
+		modelSelector:   selector,
+		routes:          routes,
+		routeOrder:      order,
+	}
+}
+
+func TestProcessQuery_FailsOverOnRetryableProviderError(t *testing.T) {
+	claude := &routeProvider{name: "claude", err: pkgerrors.NewProviderError("claude API error: overloaded_error - Overloaded", "provider", "anthropic", 529, "", true)}
+	openai := &routeProvider{name: "openai"}
+	p := newFailoverProcessor(t, claude, openai)
+
+	resp, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "failover-1"})
+	if err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if resp.Error != "" {
+		t.Fatalf("expected failover to succeed, got error: %s", resp.Error)
+	}
+	if resp.Provider != "openai" {
+		t.Errorf("expected provider 'openai', got '%s'", resp.Provider)
+	}
+	if claude.calls != 1 || openai.calls != 1 {
+		t.Errorf("expected one call to each provider, got claude=%d openai=%d", claude.calls, openai.calls)
+	}
+
+	// The failed provider is skipped until its next successful health check
+	if _, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "failover-2"}); err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if claude.calls != 1 {
+		t.Errorf("expected unhealthy claude to be skipped, got %d calls", claude.calls)
+	}
+}
+
+func TestProcessQuery_SkipsUnhealthyProvider(t *testing.T) {
+	claude := &routeProvider{name: "claude", healthErr: pkgerrors.NewProviderError("connection test failed", "provider", "anthropic", 503, "", true)}
+	openai := &routeProvider{name: "openai"}
+	p := newFailoverProcessor(t, claude, openai)
+
+	resp, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "unhealthy-1"})
+	if err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if resp.Error != "" || resp.Provider != "openai" {
+		t.Fatalf("expected openai to serve the request, got provider=%q error=%q", resp.Provider, resp.Error)
+	}
+	if claude.calls != 0 {
+		t.Errorf("expected unhealthy claude not to be called, got %d calls", claude.calls)
+	}
+}
+
+func TestProcessQuery_HonoursRequestedModelType(t *testing.T) {
+	claude := &routeProvider{name: "claude"}
+	openai := &routeProvider{name: "openai"}
+	p := newFailoverProcessor(t, claude, openai)
+
+	resp, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "preferred-1", ModelType: "openai"})
+	if err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if resp.Provider != "openai" || claude.calls != 0 {
+		t.Errorf("expected the requested provider to be used, got provider=%q claude calls=%d", resp.Provider, claude.calls)
+	}
+}
+
+func TestProcessQuery_DoesNotFailOverOnNonRetryableError(t *testing.T) {
+	claude := &routeProvider{name: "claude", err: pkgerrors.NewProviderError("claude API error: authentication_error - invalid x-api-key", "provider", "anthropic", 401, "", false)}
+	openai := &routeProvider{name: "openai"}
+	p := newFailoverProcessor(t, claude, openai)
+
+	resp, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "nonretryable-1"})
+	if err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if !strings.Contains(resp.Error, "llm_processing_failed") {
+		t.Errorf("expected llm_processing_failed, got %q", resp.Error)
+	}
+	if openai.calls != 0 {
+		t.Errorf("expected no failover for a non-retryable error, got %d openai calls", openai.calls)
+	}
+}
+
+func TestNewGenAIProcessorFromConfig_BuildsFailoverRoutes(t *testing.T) {
+	app := config.GetDefaultConfig()
+	for _, name := range []string{"claude", "openai"} {
+		mc := app.Models.Providers[name]
+		mc.APIKey = "test-key"
+		mc.Endpoint = "http://127.0.0.1:1"
+		app.Models.Providers[name] = mc
+	}
+	app.Models.FallbackProviders = []string{"openai"}
+
+	p, err := NewGenAIProcessorFromConfig(app)
+	if err != nil {
+		t.Fatalf("NewGenAIProcessorFromConfig returned error: %v", err)
+	}
+	defer p.Close()
+
+	if p.modelSelector == nil {
+		t.Fatal("expected a model selector when fallback providers are configured")
+	}
+	if strings.Join(p.routeOrder, ",") != "claude,openai" {
+		t.Errorf("expected route order claude,openai, got %v", p.routeOrder)
+	}
+	if p.routes["openai"].modelName != app.Models.Providers["openai"].ModelName {
+		t.Errorf("expected openai route to use its own model, got %q", p.routes["openai"].modelName)
+	}
+}
+
+func TestNewProviderRoute_SelfHostedProviders(t *testing.T) {
+	app := config.GetDefaultConfig()
+	app.Models.Providers["local_vllm"] = config.ModelConfig{
+		Provider:     "vllm",
+		Endpoint:     "http://127.0.0.1:1/v1",
+		ModelName:    "meta-llama/Llama-3.1-8B-Instruct",
+		MaxTokens:    4000,
+		Temperature:  0.1,
+		InputAdapter: "vllm_input_adapter",
+		OutputParser: "vllm_extractor",
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"local_llama", "ollama"},
+		{"local_vllm", "vllm"},
+	}
+	for _, tt := range tests {
+		route, err := newProviderRoute(app, tt.name, nil, nil, log.New(io.Discard, "", 0))
+		if err != nil {
+			t.Fatalf("newProviderRoute(%q) returned error: %v", tt.name, err)
+		}
+		if got := route.provider.GetModelInfo().Provider; got != tt.want {
+			t.Errorf("newProviderRoute(%q) provider = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pkgerrors "genai-processing/pkg/errors"
+	"genai-processing/pkg/types"
+)
+
+// streamingProvider streams a fixed response in chunks
+type streamingProvider struct {
+	chunks        []string
+	streamCalls   int
+	generateCalls int
+}
+
+func (s *streamingProvider) GenerateResponse(ctx context.Context, request *types.ModelRequest) (*types.RawResponse, error) {
+	s.generateCalls++
+	return &types.RawResponse{Content: strings.Join(s.chunks, "")}, nil
+}
+
+func (s *streamingProvider) GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error) {
+	s.streamCalls++
+	for _, chunk := range s.chunks {
+		onChunk(chunk)
+	}
+	return &types.RawResponse{Content: strings.Join(s.chunks, "")}, nil
+}
+
+func (s *streamingProvider) GetModelInfo() types.ModelInfo {
+	return types.ModelInfo{Name: "stream-model", Provider: "stream"}
+}
+
+func (s *streamingProvider) SupportsStreaming() bool { return true }
+
+func (s *streamingProvider) ValidateConnection() error { return nil }
+
+func streamStages(events []types.StreamEvent) string {
+	stages := make([]string, 0, len(events))
+	for _, event := range events {
+		stages = append(stages, event.Stage)
+	}
+	return strings.Join(stages, ",")
+}
+
+func TestProcessQueryStream_EmitsStagesAndTokens(t *testing.T) {
+	provider := &streamingProvider{chunks: []string{`{"log_source": `, `"kube-apiserver"}`}}
+	p := NewGenAIProcessorWithDeps(newMockContextManager(), &engineWithProvider{provider: provider}, newMockRetryParser(), newMockSafetyValidator())
+
+	var events []types.StreamEvent
+	resp, err := p.ProcessQueryStream(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "stream-1"}, func(event types.StreamEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("ProcessQueryStream returned error: %v", err)
+	}
+	if resp.Error != "" {
+		t.Fatalf("unexpected error response: %s", resp.Error)
+	}
+
+	want := "context_resolved,model_called,tokens,tokens,parsed,validated"
+	if got := streamStages(events); got != want {
+		t.Errorf("stages = %s, want %s", got, want)
+	}
+	if events[0].Query != "who deleted pods" {
+		t.Errorf("expected resolved query in context_resolved event, got %q", events[0].Query)
+	}
+	if events[2].Text != provider.chunks[0] || events[3].Text != provider.chunks[1] {
+		t.Errorf("unexpected token events %+v %+v", events[2], events[3])
+	}
+	if provider.streamCalls != 1 || provider.generateCalls != 0 {
+		t.Errorf("expected the streaming call, got stream=%d generate=%d", provider.streamCalls, provider.generateCalls)
+	}
+}
+
+func TestProcessQuery_DoesNotStream(t *testing.T) {
+	provider := &streamingProvider{chunks: []string{`{"log_source": "kube-apiserver"}`}}
+	p := NewGenAIProcessorWithDeps(newMockContextManager(), &engineWithProvider{provider: provider}, newMockRetryParser(), newMockSafetyValidator())
+
+	if _, err := p.ProcessQuery(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "stream-2"}); err != nil {
+		t.Fatalf("ProcessQuery returned error: %v", err)
+	}
+	if provider.streamCalls != 0 || provider.generateCalls != 1 {
+		t.Errorf("expected a non-streaming call, got stream=%d generate=%d", provider.streamCalls, provider.generateCalls)
+	}
+}
+
+func TestProcessQueryStream_ReportsEachProviderCalled(t *testing.T) {
+	claude := &routeProvider{name: "claude", err: pkgerrors.NewProviderError("claude API error: overloaded_error - Overloaded", "provider", "anthropic", 529, "", true)}
+	openai := &routeProvider{name: "openai"}
+	p := newFailoverProcessor(t, claude, openai)
+
+	var called []string
+	resp, err := p.ProcessQueryStream(context.Background(), &types.ProcessingRequest{Query: "who deleted pods", SessionID: "stream-3"}, func(event types.StreamEvent) {
+		if event.Stage == types.StreamStageModelCalled {
+			called = append(called, event.Provider+"/"+event.Model)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ProcessQueryStream returned error: %v", err)
+	}
+	if resp.Provider != "openai" {
+		t.Errorf("expected provider 'openai', got '%s'", resp.Provider)
+	}
+	if strings.Join(called, ",") != "claude/claude-model,openai/openai-model" {
+		t.Errorf("unexpected model_called events %v", called)
+	}
+}
@@ -0,0 +1,240 @@
+// Package redisclient is a minimal client for servers speaking the Redis
+// serialization protocol (Redis, Valkey, KeyDB, ...). It supports the small
+// command set used by the session store and response cache without pulling
+// in an external dependency.
+package redisclient
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned for the RESP null reply, e.g. GET of a missing key.
+var ErrNil = errors.New("redis: nil")
+
+// Error is an error reply returned by the server.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Config configures a Client.
+type Config struct {
+	// Addr is the host:port of the Redis-protocol server
+	Addr string
+
+	// Password is sent with AUTH when non-empty
+	Password string
+
+	// DB is selected with SELECT when non-zero
+	DB int
+
+	// Timeout bounds dialing and each command round trip; defaults to 5s
+	Timeout time.Duration
+}
+
+// Client sends commands over a single connection guarded by a mutex and
+// reconnects after network errors. Commands are retried once after a
+// reconnect, so only idempotent commands should be sent.
+type Client struct {
+	config Config
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// New creates a Client and verifies the connection with PING.
+func New(config Config) (*Client, error) {
+	if strings.TrimSpace(config.Addr) == "" {
+		return nil, fmt.Errorf("redis address cannot be empty")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	c := &Client{config: config}
+	if _, err := c.Do("PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at '%s': %w", config.Addr, err)
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// Scan iterates keys matching pattern with SCAN and returns them all.
+func (c *Client) Scan(pattern string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.Do("SCAN", cursor, "MATCH", pattern, "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply")
+		}
+		cursor, _ = parts[0].(string)
+		batch, _ := parts[1].([]interface{})
+		for _, k := range batch {
+			if key, ok := k.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// Do sends a command and reads its reply, reconnecting once on network errors.
+func (c *Client) Do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reply, err := c.roundTrip(args)
+	if isConnError(err) {
+		c.closeConn()
+		reply, err = c.roundTrip(args)
+		if isConnError(err) {
+			c.closeConn()
+		}
+	}
+	return reply, err
+}
+
+// isConnError reports whether err left the connection in an unknown state,
+// as opposed to a nil or error reply from the server.
+func isConnError(err error) bool {
+	if err == nil || err == ErrNil {
+		return false
+	}
+	_, isReply := err.(Error)
+	return !isReply
+}
+
+func (c *Client) roundTrip(args []string) (interface{}, error) {
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return nil, err
+	}
+	if err := writeCommand(c.conn, args); err != nil {
+		return nil, err
+	}
+	return readReply(c.rd)
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", c.config.Addr, c.config.Timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.rd = bufio.NewReader(conn)
+
+	setup := [][]string{}
+	if c.config.Password != "" {
+		setup = append(setup, []string{"AUTH", c.config.Password})
+	}
+	if c.config.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.config.DB)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(args); err != nil {
+			c.closeConn()
+			return fmt.Errorf("%s failed: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) closeConn() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// writeCommand encodes args as a RESP array of bulk strings.
+func writeCommand(w io.Writer, args []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// readReply decodes a single RESP reply. Bulk and simple strings decode to
+// string, integers to int64 and arrays to []interface{}.
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readReply(rd)
+			if err != nil && err != ErrNil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,56 @@
+package redisclient
+
+import (
+	"testing"
+
+	"genai-processing/internal/redisclient/redistest"
+)
+
+func TestClient_Commands(t *testing.T) {
+	srv, err := redistest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start redis stand-in: %v", err)
+	}
+	defer srv.Close()
+	srv.RequirePassword("secret")
+
+	if _, err := New(Config{Addr: srv.Addr(), Password: "wrong"}); err == nil {
+		t.Fatal("expected AUTH failure with wrong password")
+	}
+	c, err := New(Config{Addr: srv.Addr(), Password: "secret", DB: 2})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do("GET", "missing"); err != ErrNil {
+		t.Errorf("expected ErrNil, got %v", err)
+	}
+	if _, err := c.Do("SET", "k1", "v\r\n1"); err != nil {
+		t.Fatalf("SET failed: %v", err)
+	}
+	if v, err := c.Do("GET", "k1"); err != nil || v != "v\r\n1" {
+		t.Errorf("GET returned %v, %v", v, err)
+	}
+	if n, err := c.Do("INCRBY", "n", "5"); err != nil || n != int64(5) {
+		t.Errorf("INCRBY returned %v, %v", n, err)
+	}
+	if _, err := c.Do("BOGUS"); err == nil {
+		t.Error("expected error reply for unknown command")
+	} else if _, ok := err.(Error); !ok {
+		t.Errorf("expected an Error reply, got %T", err)
+	}
+
+	keys, err := c.Scan("k*")
+	if err != nil || len(keys) != 1 || keys[0] != "k1" {
+		t.Errorf("Scan returned %v, %v", keys, err)
+	}
+
+	// The client reconnects after the connection is dropped
+	c.mu.Lock()
+	c.conn.Close()
+	c.mu.Unlock()
+	if v, err := c.Do("GET", "k1"); err != nil || v != "v\r\n1" {
+		t.Errorf("GET after reconnect returned %v, %v", v, err)
+	}
+}
@@ -0,0 +1,305 @@
+// Package redistest provides an in-process stand-in for a Redis-protocol
+// server, supporting the commands used by redisclient callers, for tests.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a minimal in-memory Redis-protocol server. It supports PING,
+// AUTH, SELECT, GET, SET (with EX/PX), DEL, EXPIRE, PEXPIRE, TTL, INCRBY,
+// KEYS and SCAN (single page). Keys expire lazily.
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	values   map[string]string
+	expiry   map[string]time.Time
+	password string
+	now      func() time.Time
+
+	// Commands counts the commands received, by name
+	Commands map[string]int
+}
+
+// NewServer starts a server on a random local port.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: l,
+		values:   make(map[string]string),
+		expiry:   make(map[string]time.Time),
+		now:      time.Now,
+		Commands: make(map[string]int),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the listening address.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// RequirePassword makes the server reject commands until AUTH succeeds.
+func (s *Server) RequirePassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+}
+
+// SetClock overrides the clock used for key expiry.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authed := false
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		reply := s.exec(args, &authed)
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(args []string, authed *bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+	cmd := strings.ToUpper(args[0])
+	s.Commands[cmd]++
+
+	if cmd == "AUTH" {
+		if len(args) == 2 && args[1] == s.password {
+			*authed = true
+			return "+OK\r\n"
+		}
+		return "-WRONGPASS invalid password\r\n"
+	}
+	if s.password != "" && !*authed {
+		return "-NOAUTH Authentication required.\r\n"
+	}
+
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 2 {
+			return wrongArgs(cmd)
+		}
+		v, ok := s.get(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(v)
+	case "SET":
+		if len(args) < 3 {
+			return wrongArgs(cmd)
+		}
+		key := args[1]
+		s.values[key] = args[2]
+		delete(s.expiry, key)
+		for i := 3; i+1 < len(args); i += 2 {
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return "-ERR value is not an integer\r\n"
+			}
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				s.expiry[key] = s.now().Add(time.Duration(n) * time.Second)
+			case "PX":
+				s.expiry[key] = s.now().Add(time.Duration(n) * time.Millisecond)
+			}
+		}
+		return "+OK\r\n"
+	case "DEL":
+		removed := 0
+		for _, key := range args[1:] {
+			if _, ok := s.get(key); ok {
+				removed++
+			}
+			delete(s.values, key)
+			delete(s.expiry, key)
+		}
+		return fmt.Sprintf(":%d\r\n", removed)
+	case "EXPIRE", "PEXPIRE":
+		if len(args) != 3 {
+			return wrongArgs(cmd)
+		}
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return "-ERR value is not an integer\r\n"
+		}
+		if _, ok := s.get(args[1]); !ok {
+			return ":0\r\n"
+		}
+		unit := time.Second
+		if cmd == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		s.expiry[args[1]] = s.now().Add(time.Duration(n) * unit)
+		return ":1\r\n"
+	case "TTL":
+		if len(args) != 2 {
+			return wrongArgs(cmd)
+		}
+		if _, ok := s.get(args[1]); !ok {
+			return ":-2\r\n"
+		}
+		exp, ok := s.expiry[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", int64(exp.Sub(s.now()).Seconds()))
+	case "INCRBY":
+		if len(args) != 3 {
+			return wrongArgs(cmd)
+		}
+		by, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return "-ERR value is not an integer\r\n"
+		}
+		v, _ := s.get(args[1])
+		cur, _ := strconv.ParseInt(v, 10, 64)
+		cur += by
+		s.values[args[1]] = strconv.FormatInt(cur, 10)
+		return fmt.Sprintf(":%d\r\n", cur)
+	case "KEYS":
+		if len(args) != 2 {
+			return wrongArgs(cmd)
+		}
+		return array(s.match(args[1]))
+	case "SCAN":
+		pattern := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		return "*2\r\n" + bulk("0") + array(s.match(pattern))
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func (s *Server) get(key string) (string, bool) {
+	v, ok := s.values[key]
+	if !ok {
+		return "", false
+	}
+	if exp, ok := s.expiry[key]; ok && !s.now().Before(exp) {
+		delete(s.values, key)
+		delete(s.expiry, key)
+		return "", false
+	}
+	return v, true
+}
+
+// match returns live keys matching a Redis glob pattern. Unlike path.Match,
+// '*' also matches '/'.
+func (s *Server) match(pattern string) []string {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	re := regexp.MustCompile("^" + expr + "$")
+
+	var keys []string
+	for key := range s.values {
+		if _, ok := s.get(key); !ok {
+			continue
+		}
+		if re.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSuffix(header, "\r\n")[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func bulk(v string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+}
+
+func array(items []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(items))
+	for _, item := range items {
+		b.WriteString(bulk(item))
+	}
+	return b.String()
+}
+
+func wrongArgs(cmd string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(cmd))
+}
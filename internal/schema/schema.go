@@ -0,0 +1,363 @@
+// Package schema generates a JSON Schema for types.StructuredQuery from the
+// Go type itself: field names come from json tags, required fields and
+// allowed values from validate tags, and the limits configured in rules.yaml
+// narrow those values further. The one schema drives the system prompts, the
+// tool calling and constrained decoding of providers that support them, and
+// the /schema endpoint used for client code generation.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"genai-processing/internal/validator"
+	"genai-processing/pkg/types"
+)
+
+// ToolName is the name of the tool (Claude) or function (OpenAI) whose input
+// schema is StructuredQuery. Forcing a call to it makes the model return the
+// query as the call's arguments.
+const ToolName = "structured_query"
+
+// ToolDescription describes the structured_query tool to the model.
+const ToolDescription = "Submit the structured OpenShift audit log query for the user's request."
+
+var (
+	stringOrArrayType = reflect.TypeOf(types.StringOrArray{})
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// descriptions describe each property, keyed by its dotted JSON path. Go doc
+// comments are not available through reflection, so they live here.
+var descriptions = map[string]string{
+	"log_source":                   "Source of audit logs",
+	"verb":                         "HTTP verb(s) to filter on",
+	"resource":                     "Kubernetes resource type(s) to filter on",
+	"namespace":                    "Namespace(s) to filter on",
+	"user":                         "User(s) to filter on",
+	"timeframe":                    "Time period to filter on, such as today, yesterday or 1_hour_ago",
+	"limit":                        "Maximum number of results to return",
+	"response_status":              "HTTP response status code(s) to filter on",
+	"exclude_users":                "User patterns to exclude from results",
+	"resource_name_pattern":        "Regex pattern for resource name matching",
+	"user_pattern":                 "Regex pattern for user matching",
+	"namespace_pattern":            "Regex pattern for namespace matching",
+	"request_uri_pattern":          "Request URI pattern matching",
+	"auth_decision":                "Authorization decision to filter on",
+	"source_ip":                    "Source IP address(es) to filter on",
+	"group_by":                     "Fields to group results by",
+	"sort_by":                      "Field to sort results by",
+	"sort_order":                   "Sort direction",
+	"subresource":                  "Kubernetes subresource",
+	"include_changes":              "Include before/after comparisons",
+	"time_range":                   "Custom time range with start and end timestamps",
+	"time_range.start":             "Start of the range in ISO 8601 format",
+	"time_range.end":               "End of the range in ISO 8601 format",
+	"business_hours":               "Business hours filtering",
+	"business_hours.outside_only":  "Match only events outside business hours",
+	"business_hours.start_hour":    "Business hours start hour",
+	"business_hours.end_hour":      "Business hours end hour",
+	"business_hours.timezone":      "Timezone for business hours (default UTC)",
+	"analysis":                     "Advanced analysis options",
+	"analysis.type":                "Type of analysis to perform",
+	"analysis.group_by":            "Fields to group results by",
+	"analysis.threshold":           "Threshold value, such as a number of events",
+	"analysis.time_window":         "Time window for the analysis",
+	"analysis.sort_by":             "Field to sort results by",
+	"analysis.sort_order":          "Sort direction",
+	"request_object_filter":        "Filter on request object content",
+	"exclude_resources":            "Resource patterns to exclude from results",
+	"authorization_reason_pattern": "Pattern for authorization reason matching",
+	"response_message_pattern":     "Pattern for response message matching",
+	"missing_annotation":           "Annotation that should be missing",
+}
+
+// StructuredQuery returns a JSON Schema for types.StructuredQuery built from
+// its tags alone. Each call returns a new map that the caller may modify.
+func StructuredQuery() map[string]interface{} {
+	return Generate(nil)
+}
+
+// Generate returns a JSON Schema for types.StructuredQuery. The allowed
+// values and limits in cfg, when given, replace those of the validate tags,
+// so the schema accepts what the safety validator accepts. Each call returns
+// a new map that the caller may modify.
+func Generate(cfg *validator.ValidationConfig) map[string]interface{} {
+	s := objectSchema(reflect.TypeOf(types.StructuredQuery{}), "")
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "StructuredQuery"
+	s["description"] = "Structured query for OpenShift audit log analysis"
+	if cfg != nil {
+		applyValidationConfig(s, cfg)
+	}
+	return s
+}
+
+// PromptText renders s for inclusion in a system prompt.
+func PromptText(s map[string]interface{}) (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query schema: %w", err)
+	}
+	return "Respond with a JSON object that conforms to this JSON Schema:\n" + string(data), nil
+}
+
+// objectSchema describes a struct type, prefixing property paths with path.
+func objectSchema(t reflect.Type, path string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := typeSchema(field.Type, path+name+".")
+		if description, ok := descriptions[path+name]; ok {
+			property["description"] = description
+		}
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			required = append(required, name)
+		}
+		properties[name] = property
+	}
+
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// typeSchema describes a field type. path is the prefix for the properties
+// of struct types.
+func typeSchema(t reflect.Type, path string) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == stringOrArrayType:
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return objectSchema(t, path)
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), path)}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// applyValidateTag adds the constraints of a validate tag to a property and
+// reports whether the tag marks the field required. It understands the
+// required, oneof, min and max validators.
+func applyValidateTag(property map[string]interface{}, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			setEnum(property, strings.Fields(arg))
+		case "min", "max":
+			if n, err := strconv.Atoi(arg); err == nil {
+				setBound(property, name, n)
+			}
+		}
+	}
+	return required
+}
+
+// setEnum restricts a property, or each value of a string-or-array property,
+// to values.
+func setEnum(property map[string]interface{}, values []string) {
+	for _, s := range stringSchemas(property) {
+		s["enum"] = values
+	}
+}
+
+// setBound sets a "min" or "max" bound: the value of an integer, or the
+// length of a string.
+func setBound(property map[string]interface{}, bound string, n int) {
+	valueKey, lengthKey := "minimum", "minLength"
+	if bound == "max" {
+		valueKey, lengthKey = "maximum", "maxLength"
+	}
+	if property["type"] == "integer" || property["type"] == "number" {
+		property[valueKey] = n
+		return
+	}
+	for _, s := range stringSchemas(property) {
+		s[lengthKey] = n
+	}
+}
+
+// setMaxItems limits the number of values of an array or string-or-array
+// property.
+func setMaxItems(property map[string]interface{}, n int) {
+	if property["type"] == "array" {
+		property["maxItems"] = n
+	}
+	if anyOf, ok := property["anyOf"].([]interface{}); ok {
+		for _, branch := range anyOf {
+			if s := branch.(map[string]interface{}); s["type"] == "array" {
+				s["maxItems"] = n
+			}
+		}
+	}
+}
+
+// stringSchemas returns the schemas a property's string values must match:
+// the property itself, its array items, or both branches of a string-or-array.
+func stringSchemas(property map[string]interface{}) []map[string]interface{} {
+	switch property["type"] {
+	case "string":
+		return []map[string]interface{}{property}
+	case "array":
+		if items, ok := property["items"].(map[string]interface{}); ok {
+			return stringSchemas(items)
+		}
+	}
+	var schemas []map[string]interface{}
+	if anyOf, ok := property["anyOf"].([]interface{}); ok {
+		for _, branch := range anyOf {
+			schemas = append(schemas, stringSchemas(branch.(map[string]interface{}))...)
+		}
+	}
+	return schemas
+}
+
+// applyValidationConfig replaces the tag constraints of s with the allowed
+// values and limits configured in rules.yaml. Settings that are absent leave
+// the tag constraints in place.
+func applyValidationConfig(s map[string]interface{}, cfg *validator.ValidationConfig) {
+	rules := cfg.SafetyRules
+
+	enums := map[string][]string{
+		"log_source":           rules.AllowedLogSources,
+		"verb":                 rules.AllowedVerbs,
+		"resource":             rules.AllowedResources,
+		"timeframe":            stringList(rules.TimeframeLimits["allowed_timeframes"]),
+		"auth_decision":        stringList(rules.AuthDecisions["allowed_decisions"]),
+		"response_status":      stringList(rules.ResponseStatus["allowed_status_codes"]),
+		"analysis.type":        stringList(rules.AnalysisLimits["allowed_analysis_types"]),
+		"analysis.time_window": stringList(rules.AnalysisLimits["allowed_time_windows"]),
+		"analysis.sort_by":     stringList(rules.AnalysisLimits["allowed_sort_fields"]),
+		"analysis.sort_order":  stringList(rules.AnalysisLimits["allowed_sort_orders"]),
+	}
+	for path, values := range enums {
+		if property := lookup(s, path); property != nil && len(values) > 0 {
+			setEnum(property, values)
+		}
+	}
+
+	bounds := []struct {
+		path, bound string
+		value       interface{}
+	}{
+		{"limit", "min", rules.TimeframeLimits["min_limit"]},
+		{"limit", "max", rules.TimeframeLimits["max_limit"]},
+		{"analysis.threshold", "min", rules.AnalysisLimits["min_threshold_value"]},
+		{"analysis.threshold", "max", rules.AnalysisLimits["max_threshold_value"]},
+		{"business_hours.start_hour", "min", rules.BusinessHours["min_hour_value"]},
+		{"business_hours.start_hour", "max", rules.BusinessHours["max_hour_value"]},
+		{"business_hours.end_hour", "min", rules.BusinessHours["min_hour_value"]},
+		{"business_hours.end_hour", "max", rules.BusinessHours["max_hour_value"]},
+	}
+	for _, b := range bounds {
+		if n, ok := intValue(b.value); ok {
+			if property := lookup(s, b.path); property != nil {
+				setBound(property, b.bound, n)
+			}
+		}
+	}
+
+	maxItems := map[string]string{
+		"verb":              "max_verb_array_size",
+		"resource":          "max_resource_array_size",
+		"namespace":         "max_namespace_array_size",
+		"user":              "max_user_array_size",
+		"response_status":   "max_response_status_array_size",
+		"source_ip":         "max_source_ip_array_size",
+		"exclude_users":     "max_exclude_users",
+		"exclude_resources": "max_exclude_resources",
+		"group_by":          "max_group_by_fields",
+		"analysis.group_by": "max_group_by_fields",
+	}
+	for path, key := range maxItems {
+		if n, ok := intValue(rules.QueryLimits[key]); ok {
+			if property := lookup(s, path); property != nil {
+				setMaxItems(property, n)
+			}
+		}
+	}
+}
+
+// lookup returns the property at a dotted path, or nil if there is none.
+func lookup(s map[string]interface{}, path string) map[string]interface{} {
+	for _, name := range strings.Split(path, ".") {
+		properties, _ := s["properties"].(map[string]interface{})
+		s, _ = properties[name].(map[string]interface{})
+		if s == nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// stringList converts a YAML list of strings.
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// intValue converts a YAML integer.
+func intValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"genai-processing/internal/validator"
+	"genai-processing/pkg/types"
+)
+
+func TestStructuredQuery_CoversEveryField(t *testing.T) {
+	properties := StructuredQuery()["properties"].(map[string]interface{})
+
+	queryType := reflect.TypeOf(types.StructuredQuery{})
+	for i := 0; i < queryType.NumField(); i++ {
+		name := strings.Split(queryType.Field(i).Tag.Get("json"), ",")[0]
+		if _, ok := properties[name]; !ok {
+			t.Errorf("schema is missing property %q", name)
+		}
+	}
+	if len(properties) != queryType.NumField() {
+		t.Errorf("schema has %d properties, StructuredQuery has %d fields", len(properties), queryType.NumField())
+	}
+}
+
+func TestStructuredQuery_IsValidJSON(t *testing.T) {
+	data, err := json.Marshal(StructuredQuery())
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+	if decoded["type"] != "object" {
+		t.Errorf("expected object schema, got %v", decoded["type"])
+	}
+}
+
+func TestStructuredQuery_FromTags(t *testing.T) {
+	s := StructuredQuery()
+
+	if required := s["required"].([]string); len(required) != 1 || required[0] != "log_source" {
+		t.Errorf("expected log_source to be the only required field, got %v", required)
+	}
+	if enum := lookup(s, "log_source")["enum"].([]string); len(enum) != 4 || enum[0] != "kube-apiserver" {
+		t.Errorf("unexpected log_source enum %v", enum)
+	}
+	limit := lookup(s, "limit")
+	if limit["type"] != "integer" || limit["minimum"] != 1 || limit["maximum"] != 1000 {
+		t.Errorf("unexpected limit schema %v", limit)
+	}
+	if start := lookup(s, "time_range.start"); start["format"] != "date-time" {
+		t.Errorf("expected time_range.start to be a date-time, got %v", start)
+	}
+	if required := lookup(s, "analysis")["required"].([]string); len(required) != 1 || required[0] != "type" {
+		t.Errorf("expected analysis.type to be required, got %v", required)
+	}
+	if hour := lookup(s, "business_hours.end_hour"); hour["minimum"] != 0 || hour["maximum"] != 23 {
+		t.Errorf("unexpected end_hour schema %v", hour)
+	}
+	if _, ok := lookup(s, "verb")["anyOf"]; !ok {
+		t.Error("expected verb to accept a string or an array")
+	}
+	for path, description := range descriptions {
+		if property := lookup(s, path); property == nil || property["description"] != description {
+			t.Errorf("expected property %q with its description", path)
+		}
+	}
+}
+
+func TestGenerate_AppliesValidationConfig(t *testing.T) {
+	cfg := &validator.ValidationConfig{}
+	cfg.SafetyRules.AllowedLogSources = []string{"kube-apiserver"}
+	cfg.SafetyRules.AllowedVerbs = []string{"get", "delete"}
+	cfg.SafetyRules.TimeframeLimits = map[string]interface{}{
+		"max_limit":          500,
+		"allowed_timeframes": []interface{}{"today", "yesterday"},
+	}
+	cfg.SafetyRules.QueryLimits = map[string]interface{}{"max_verb_array_size": 2, "max_exclude_users": 5}
+	cfg.SafetyRules.AnalysisLimits = map[string]interface{}{"allowed_analysis_types": []interface{}{"correlation"}}
+
+	s := Generate(cfg)
+
+	if enum := lookup(s, "log_source")["enum"].([]string); len(enum) != 1 {
+		t.Errorf("expected configured log sources, got %v", enum)
+	}
+	for _, branch := range lookup(s, "verb")["anyOf"].([]interface{}) {
+		verb := branch.(map[string]interface{})
+		if verb["type"] == "array" {
+			if verb["maxItems"] != 2 {
+				t.Errorf("expected maxItems 2, got %v", verb["maxItems"])
+			}
+			verb = verb["items"].(map[string]interface{})
+		}
+		if enum := verb["enum"].([]string); len(enum) != 2 || enum[1] != "delete" {
+			t.Errorf("expected configured verbs, got %v", enum)
+		}
+	}
+	if enum := lookup(s, "timeframe")["enum"].([]string); len(enum) != 2 {
+		t.Errorf("expected configured timeframes, got %v", enum)
+	}
+	if limit := lookup(s, "limit"); limit["minimum"] != 1 || limit["maximum"] != 500 {
+		t.Errorf("expected limit between 1 and 500, got %v", limit)
+	}
+	if excludeUsers := lookup(s, "exclude_users"); excludeUsers["maxItems"] != 5 {
+		t.Errorf("expected exclude_users maxItems 5, got %v", excludeUsers["maxItems"])
+	}
+	if enum := lookup(s, "analysis.type")["enum"].([]string); len(enum) != 1 || enum[0] != "correlation" {
+		t.Errorf("expected configured analysis types, got %v", enum)
+	}
+	// Unconfigured values keep the constraints of the validate tags
+	if enum := lookup(s, "auth_decision")["enum"].([]string); len(enum) != 3 {
+		t.Errorf("expected auth_decision enum from tags, got %v", enum)
+	}
+}
+
+func TestGenerate_RulesFile(t *testing.T) {
+	cfg, err := validator.LoadValidationConfig("../../configs/rules.yaml")
+	if err != nil {
+		t.Fatalf("failed to load rules.yaml: %v", err)
+	}
+	s := Generate(cfg)
+
+	var resources []string
+	for _, branch := range lookup(s, "resource")["anyOf"].([]interface{}) {
+		if resource := branch.(map[string]interface{}); resource["type"] == "string" {
+			resources = resource["enum"].([]string)
+		}
+	}
+	if len(resources) != len(cfg.SafetyRules.AllowedResources) {
+		t.Errorf("expected %d resources from rules.yaml, got %d", len(cfg.SafetyRules.AllowedResources), len(resources))
+	}
+	if threshold := lookup(s, "analysis.threshold"); threshold["maximum"] != 10000 {
+		t.Errorf("expected threshold maximum from rules.yaml, got %v", threshold["maximum"])
+	}
+}
+
+func TestPromptText(t *testing.T) {
+	text, err := PromptText(StructuredQuery())
+	if err != nil {
+		t.Fatalf("PromptText returned error: %v", err)
+	}
+	if !strings.Contains(text, "JSON Schema") || !strings.Contains(text, `"log_source"`) {
+		t.Errorf("expected schema instructions, got %q", text)
+	}
+}
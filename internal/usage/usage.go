@@ -0,0 +1,331 @@
+// Package usage accounts for the tokens providers consume, prices them from a
+// configured price table and enforces per-user daily and monthly budgets.
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+// ErrBudgetExceeded is returned by Check once a user's budget is spent.
+var ErrBudgetExceeded = errors.New("usage budget exceeded")
+
+// maxTrackedSessions bounds the sessions tracked before idle ones are dropped.
+const maxTrackedSessions = 10000
+
+// Price is the cost of a model's tokens per million.
+type Price struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Budget limits a user's usage per UTC day and calendar month. Zero fields
+// are unlimited.
+type Budget struct {
+	DailyCost     float64 `json:"daily_cost,omitempty"`
+	MonthlyCost   float64 `json:"monthly_cost,omitempty"`
+	DailyTokens   int     `json:"daily_tokens,omitempty"`
+	MonthlyTokens int     `json:"monthly_tokens,omitempty"`
+}
+
+// Totals aggregates the usage of a set of provider calls.
+type Totals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (t *Totals) add(u *types.TokenUsage) {
+	t.Requests++
+	t.PromptTokens += u.PromptTokens
+	t.CompletionTokens += u.CompletionTokens
+	t.TotalTokens += u.TotalTokens
+	t.Cost += u.EstimatedCost
+}
+
+// UserTotals is a user's usage in the current day and month and overall.
+type UserTotals struct {
+	Today     Totals `json:"today"`
+	ThisMonth Totals `json:"this_month"`
+	Total     Totals `json:"total"`
+}
+
+// Summary is the usage accounted since the accountant was created.
+type Summary struct {
+	Currency  string                `json:"currency"`
+	Total     Totals                `json:"total"`
+	Users     map[string]UserTotals `json:"users"`
+	Sessions  map[string]Totals     `json:"sessions,omitempty"`
+	Providers map[string]Totals     `json:"providers"`
+}
+
+type userUsage struct {
+	day, month                string
+	today, thisMonth, overall Totals
+}
+
+type sessionUsage struct {
+	totals Totals
+	last   time.Time
+}
+
+// Accountant records the token usage of provider calls, priced per model,
+// and aggregates it per user, session and provider. It is safe for
+// concurrent use. Usage is kept in memory and starts over on restart.
+type Accountant struct {
+	budget Budget
+	now    func() time.Time
+
+	mu        sync.Mutex
+	prices    map[string]Price
+	currency  string
+	total     Totals
+	users     map[string]*userUsage
+	sessions  map[string]*sessionUsage
+	providers map[string]*Totals
+}
+
+// NewAccountant creates an Accountant pricing models from prices, keyed by
+// model name, in the given currency. Models without a price cost nothing.
+func NewAccountant(prices map[string]Price, currency string, budget Budget) *Accountant {
+	if currency == "" {
+		currency = "USD"
+	}
+	return &Accountant{
+		prices:    prices,
+		currency:  currency,
+		budget:    budget,
+		now:       time.Now,
+		users:     make(map[string]*userUsage),
+		sessions:  make(map[string]*sessionUsage),
+		providers: make(map[string]*Totals),
+	}
+}
+
+// Budget returns the per-user budget.
+func (a *Accountant) Budget() Budget {
+	return a.budget
+}
+
+// Currency returns the currency costs are reported in.
+func (a *Accountant) Currency() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.currency
+}
+
+// SetPrices replaces the price table and currency, as when models.yaml is
+// reloaded. Usage already recorded keeps the cost it was priced at.
+func (a *Accountant) SetPrices(prices map[string]Price, currency string) {
+	if currency == "" {
+		currency = "USD"
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prices = prices
+	a.currency = currency
+}
+
+// Cost prices a model's tokens.
+func (a *Accountant) Cost(model string, promptTokens, completionTokens int) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cost(model, promptTokens, completionTokens)
+}
+
+func (a *Accountant) cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := a.prices[model]
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1e6
+}
+
+// Record accounts for a provider call made for a user and session, returning
+// its priced token usage.
+func (a *Accountant) Record(userID, sessionID, provider, model string, raw *types.RawResponse) *types.TokenUsage {
+	promptTokens, completionTokens := Tokens(raw)
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	u := &types.TokenUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		EstimatedCost:    a.cost(model, promptTokens, completionTokens),
+		Currency:         a.currency,
+		ModelName:        model,
+		Timestamp:        now,
+	}
+
+	a.total.add(u)
+	user := a.user(userID, now)
+	user.today.add(u)
+	user.thisMonth.add(u)
+	user.overall.add(u)
+	if p, ok := a.providers[provider]; ok {
+		p.add(u)
+	} else {
+		p = &Totals{}
+		p.add(u)
+		a.providers[provider] = p
+	}
+	if sessionID != "" {
+		s, ok := a.sessions[sessionID]
+		if !ok {
+			if len(a.sessions) >= maxTrackedSessions {
+				a.pruneSessions()
+			}
+			s = &sessionUsage{}
+			a.sessions[sessionID] = s
+		}
+		s.totals.add(u)
+		s.last = now
+	}
+	return u
+}
+
+// Check returns an error wrapping ErrBudgetExceeded when the user has spent
+// their daily or monthly budget.
+func (a *Accountant) Check(userID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.users[userID]; !ok {
+		return nil
+	}
+	user := a.user(userID, a.now())
+	name := userID
+	if name == "" {
+		name = "anonymous"
+	}
+	b := a.budget
+	switch {
+	case b.DailyCost > 0 && user.today.Cost >= b.DailyCost:
+		return fmt.Errorf("%w: user %s has spent %.4f of the daily budget of %.4f %s", ErrBudgetExceeded, name, user.today.Cost, b.DailyCost, a.currency)
+	case b.MonthlyCost > 0 && user.thisMonth.Cost >= b.MonthlyCost:
+		return fmt.Errorf("%w: user %s has spent %.4f of the monthly budget of %.4f %s", ErrBudgetExceeded, name, user.thisMonth.Cost, b.MonthlyCost, a.currency)
+	case b.DailyTokens > 0 && user.today.TotalTokens >= b.DailyTokens:
+		return fmt.Errorf("%w: user %s has used %d of the daily budget of %d tokens", ErrBudgetExceeded, name, user.today.TotalTokens, b.DailyTokens)
+	case b.MonthlyTokens > 0 && user.thisMonth.TotalTokens >= b.MonthlyTokens:
+		return fmt.Errorf("%w: user %s has used %d of the monthly budget of %d tokens", ErrBudgetExceeded, name, user.thisMonth.TotalTokens, b.MonthlyTokens)
+	}
+	return nil
+}
+
+// Summary returns the usage accounted so far.
+func (a *Accountant) Summary() Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	summary := Summary{
+		Currency:  a.currency,
+		Total:     a.total,
+		Users:     make(map[string]UserTotals, len(a.users)),
+		Sessions:  make(map[string]Totals, len(a.sessions)),
+		Providers: make(map[string]Totals, len(a.providers)),
+	}
+	for id := range a.users {
+		summary.Users[id] = a.userTotals(id, now)
+	}
+	for id, s := range a.sessions {
+		summary.Sessions[id] = s.totals
+	}
+	for name, p := range a.providers {
+		summary.Providers[name] = *p
+	}
+	return summary
+}
+
+// UserSummary returns one user's usage.
+func (a *Accountant) UserSummary(userID string) UserTotals {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.userTotals(userID, a.now())
+}
+
+// userTotals returns a user's totals without creating an entry. Callers hold a.mu.
+func (a *Accountant) userTotals(userID string, now time.Time) UserTotals {
+	if _, ok := a.users[userID]; !ok {
+		return UserTotals{}
+	}
+	user := a.user(userID, now)
+	return UserTotals{Today: user.today, ThisMonth: user.thisMonth, Total: user.overall}
+}
+
+// user returns a user's usage, starting a new day or month when the period
+// has changed. Callers hold a.mu.
+func (a *Accountant) user(userID string, now time.Time) *userUsage {
+	day, month := now.UTC().Format("2006-01-02"), now.UTC().Format("2006-01")
+	user, ok := a.users[userID]
+	if !ok {
+		user = &userUsage{day: day, month: month}
+		a.users[userID] = user
+	}
+	if user.day != day {
+		user.day, user.today = day, Totals{}
+	}
+	if user.month != month {
+		user.month, user.thisMonth = month, Totals{}
+	}
+	return user
+}
+
+// pruneSessions drops the least recently active half of the tracked
+// sessions. Callers hold a.mu.
+func (a *Accountant) pruneSessions() {
+	ids := make([]string, 0, len(a.sessions))
+	for id := range a.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return a.sessions[ids[i]].last.Before(a.sessions[ids[j]].last) })
+	for _, id := range ids[:len(ids)/2] {
+		delete(a.sessions, id)
+	}
+}
+
+// Tokens reads the prompt and completion token counts a provider reported in
+// a response, from the "token_usage" metadata every provider sets or from
+// "token_usage" or "usage" in the model info.
+func Tokens(raw *types.RawResponse) (promptTokens, completionTokens int) {
+	if raw == nil {
+		return 0, 0
+	}
+	for _, source := range []interface{}{raw.Metadata["token_usage"], raw.ModelInfo["token_usage"], raw.ModelInfo["usage"]} {
+		counts, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		prompt, okPrompt := intValue(counts["prompt_tokens"], counts["input_tokens"])
+		completion, okCompletion := intValue(counts["completion_tokens"], counts["output_tokens"])
+		if okPrompt || okCompletion {
+			return prompt, completion
+		}
+	}
+	return 0, 0
+}
+
+// intValue returns the first of values that is a number.
+func intValue(values ...interface{}) (int, bool) {
+	for _, v := range values {
+		switch n := v.(type) {
+		case int:
+			return n, true
+		case int64:
+			return int(n), true
+		case float64:
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/types"
+)
+
+func tokenResponse(prompt, completion int) *types.RawResponse {
+	return &types.RawResponse{
+		Metadata: map[string]interface{}{
+			"token_usage": map[string]interface{}{
+				"prompt_tokens":     prompt,
+				"completion_tokens": completion,
+				"total_tokens":      prompt + completion,
+			},
+		},
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            *types.RawResponse
+		wantPrompt     int
+		wantCompletion int
+	}{
+		{"metadata", tokenResponse(100, 20), 100, 20},
+		{"model info usage", &types.RawResponse{ModelInfo: map[string]interface{}{
+			"usage": map[string]interface{}{"input_tokens": 7.0, "output_tokens": 3.0},
+		}}, 7, 3},
+		{"none", &types.RawResponse{Content: "{}"}, 0, 0},
+		{"nil", nil, 0, 0},
+	}
+	for _, tt := range tests {
+		prompt, completion := Tokens(tt.raw)
+		if prompt != tt.wantPrompt || completion != tt.wantCompletion {
+			t.Errorf("%s: Tokens() = %d, %d, want %d, %d", tt.name, prompt, completion, tt.wantPrompt, tt.wantCompletion)
+		}
+	}
+}
+
+func TestAccountant_RecordAndSummary(t *testing.T) {
+	a := NewAccountant(map[string]Price{"claude-test": {InputPerMillion: 3, OutputPerMillion: 15}}, "", Budget{})
+
+	u := a.Record("alice", "s1", "claude", "claude-test", tokenResponse(1000, 100))
+	if u.TotalTokens != 1100 || math.Abs(u.EstimatedCost-0.0045) > 1e-9 || u.Currency != "USD" {
+		t.Errorf("unexpected usage %+v", u)
+	}
+	a.Record("alice", "s2", "local", "llama", tokenResponse(500, 50))
+	a.Record("bob", "s3", "claude", "claude-test", tokenResponse(2000, 0))
+
+	summary := a.Summary()
+	if summary.Total.Requests != 3 || summary.Total.TotalTokens != 3650 {
+		t.Errorf("unexpected total %+v", summary.Total)
+	}
+	if alice := summary.Users["alice"]; alice.Today.Requests != 2 || alice.Total.TotalTokens != 1650 {
+		t.Errorf("unexpected user totals %+v", alice)
+	}
+	if local := summary.Providers["local"]; local.Cost != 0 || local.TotalTokens != 550 {
+		t.Errorf("expected unpriced models to cost nothing, got %+v", local)
+	}
+	if claude := summary.Providers["claude"]; math.Abs(claude.Cost-0.0105) > 1e-9 {
+		t.Errorf("unexpected provider cost %+v", claude)
+	}
+	if s := summary.Sessions["s2"]; s.Requests != 1 {
+		t.Errorf("unexpected session totals %+v", s)
+	}
+}
+
+func TestAccountant_SetPrices(t *testing.T) {
+	a := NewAccountant(map[string]Price{"claude-test": {InputPerMillion: 3, OutputPerMillion: 15}}, "", Budget{})
+	a.Record("alice", "s1", "claude", "claude-test", tokenResponse(1000, 0))
+
+	a.SetPrices(map[string]Price{"claude-test": {InputPerMillion: 6, OutputPerMillion: 30}}, "EUR")
+	u := a.Record("alice", "s1", "claude", "claude-test", tokenResponse(1000, 0))
+	if math.Abs(u.EstimatedCost-0.006) > 1e-9 || u.Currency != "EUR" || a.Currency() != "EUR" {
+		t.Errorf("expected usage priced with the new table, got %+v", u)
+	}
+	if total := a.Summary().Total; math.Abs(total.Cost-0.009) > 1e-9 {
+		t.Errorf("expected earlier usage to keep its cost, got %+v", total)
+	}
+}
+
+func TestAccountant_Budgets(t *testing.T) {
+	current := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+	a := NewAccountant(map[string]Price{"m": {InputPerMillion: 1000, OutputPerMillion: 1000}}, "USD", Budget{DailyCost: 1, MonthlyCost: 1.5, DailyTokens: 10000})
+	a.now = func() time.Time { return current }
+
+	if err := a.Check("alice"); err != nil {
+		t.Fatalf("expected a new user to be within budget, got %v", err)
+	}
+	a.Record("alice", "", "p", "m", tokenResponse(1000, 0)) // 1.00
+	err := a.Check("alice")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the daily budget to be exceeded, got %v", err)
+	}
+	if err := a.Check("bob"); err != nil {
+		t.Errorf("expected budgets to be per user, got %v", err)
+	}
+
+	// A new day resets the daily budget, and a new month the monthly budget
+	current = current.Add(2 * time.Hour)
+	if err := a.Check("alice"); err != nil {
+		t.Errorf("expected a new day and month to reset the budget, got %v", err)
+	}
+	a.Record("alice", "", "p", "m", tokenResponse(600, 0))
+	current = current.Add(24 * time.Hour)
+	a.Record("alice", "", "p", "m", tokenResponse(900, 0))
+	current = current.Add(24 * time.Hour)
+	if err := a.Check("alice"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected the monthly budget to be exceeded, got %v", err)
+	}
+	if totals := a.UserSummary("alice"); totals.Today.Requests != 0 || totals.ThisMonth.Requests != 2 || totals.Total.Requests != 3 {
+		t.Errorf("unexpected user totals %+v", totals)
+	}
+}
+
+func TestAccountant_TokenBudget(t *testing.T) {
+	a := NewAccountant(nil, "USD", Budget{MonthlyTokens: 100})
+	a.Record("", "", "p", "m", tokenResponse(80, 30))
+	if err := a.Check(""); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected the token budget to be exceeded, got %v", err)
+	}
+}
@@ -22,8 +22,12 @@ type ValidationConfig struct {
 		AnalysisLimits     map[string]interface{}   `yaml:"analysis_limits"`
 		ResponseStatus     map[string]interface{}   `yaml:"response_status"`
 		AuthDecisions      map[string]interface{}   `yaml:"auth_decisions"`
+		Policies           map[string]interface{}   `yaml:"policies"`
 		SeverityLevels     []string                 `yaml:"severity_levels"`
 		RuleCategories     []string                 `yaml:"rule_categories"`
+		Environment        string                   `yaml:"environment"`
+		Rules              map[string]RuleSettings  `yaml:"rules"`
+		Remediation        RemediationSettings      `yaml:"remediation"`
 	} `yaml:"safety_rules"`
 }
 
@@ -39,11 +43,30 @@ func LoadValidationConfig(configPath string) (*ValidationConfig, error) {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
+	// The environment selects rules limited to environments
+	if env := os.Getenv("VALIDATION_ENVIRONMENT"); env != "" {
+		config.SafetyRules.Environment = env
+	}
+	if env := os.Getenv("VALIDATION_REMEDIATION"); env != "" {
+		config.SafetyRules.Remediation.Enabled = env == "true"
+	}
+
 	return &config, nil
 }
 
+// DefaultConfigPath is the rules file loaded when VALIDATION_RULES_FILE is not set
+const DefaultConfigPath = "configs/rules.yaml"
+
+// ConfigPath returns the rules file loaded by NewSafetyValidator: the
+// VALIDATION_RULES_FILE environment variable, or DefaultConfigPath
+func ConfigPath() string {
+	if path := os.Getenv("VALIDATION_RULES_FILE"); path != "" {
+		return path
+	}
+	return DefaultConfigPath
+}
+
 // LoadDefaultValidationConfig loads the default validation configuration
 func LoadDefaultValidationConfig() (*ValidationConfig, error) {
-	configPath := "configs/rules.yaml"
-	return LoadValidationConfig(configPath)
+	return LoadValidationConfig(ConfigPath())
 }
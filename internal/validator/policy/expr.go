@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Expression is a compiled policy expression written in the Common
+// Expression Language (CEL, https://cel.dev). Variables are dynamically
+// typed, so field selection is checked when the expression is evaluated:
+// selecting a field a map does not hold is an error, and has(x.field)
+// tests for it first. Optional field selection (x.?field.orValue(v)) is
+// enabled for free-form maps such as session metadata.
+type Expression struct {
+	source  string
+	program cel.Program
+}
+
+// Compile parses and type-checks an expression that may refer to the given
+// variables. Syntax errors and unknown variables, functions and methods are
+// reported here rather than when the expression is evaluated.
+func Compile(source string, variables ...string) (*Expression, error) {
+	options := []cel.EnvOption{cel.OptionalTypes()}
+	for _, v := range variables {
+		options = append(options, cel.Variable(v, cel.DynType))
+	}
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression yields %s, expected bool", t)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, program: program}, nil
+}
+
+// String returns the expression's source.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// EvalBool evaluates the expression with its variables bound to JSON-like
+// values. The expression must yield a bool.
+func (e *Expression) EvalBool(vars map[string]interface{}) (bool, error) {
+	v, _, err := e.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression yields %s, expected bool", v.Type().TypeName())
+	}
+	return b, nil
+}
@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpression_EvalBool(t *testing.T) {
+	vars := map[string]interface{}{
+		"query": map[string]interface{}{
+			"log_source": "kube-apiserver",
+			"verb":       []interface{}{"get", "list"},
+			"namespace":  []interface{}{"payments"},
+			"timeframe":  "",
+			"limit":      int64(20),
+			"analysis":   map[string]interface{}{"type": "privilege_escalation"},
+		},
+		"caller": map[string]interface{}{
+			"user_id": "alice",
+			"groups":  []interface{}{"developers", "security-team"},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`query.analysis.type == "privilege_escalation" && !("security-team" in caller.groups)`, false},
+		{`query.analysis.type == "privilege_escalation" && "security-team" in caller.groups`, true},
+		{`"list" in query.verb`, true},
+		{`"payments" in query.namespace`, true},
+		{`"pay" in query.namespace`, false},
+		{`"log_source" in query`, true},
+		{`has(query.analysis) && !has(query.time_range)`, true},
+		{`!has(query.time_range) || query.time_range.start > "2024"`, true},
+		{`query.?time_range.start.orValue("") == ""`, true},
+		{`query.timeframe == ""`, true},
+		{`query.limit > 10 && query.limit <= 20`, true},
+		{`query.limit * 2 + 1 == 41`, true},
+		{`size(query.verb) == 2 && query.verb.size() == 2`, true},
+		{`query.verb[1] == "list"`, true},
+		{`query["log_source"].startsWith("kube")`, true},
+		{`query.log_source.endsWith("server") && query.log_source.contains("api")`, true},
+		{`caller.user_id.matches("^a[a-z]+$")`, true},
+		{`caller.groups.exists(g, g.startsWith("security"))`, true},
+		{`caller.groups.all(g, g.startsWith("security"))`, false},
+		{`(caller.user_id == "alice" ? "mine" : "theirs") == "mine"`, true},
+		{`false && query.missing.field`, false},
+		{`query.missing.field || true`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Compile(tt.expr, "query", "caller")
+			if err != nil {
+				t.Fatalf("Compile returned error: %v", err)
+			}
+			got, err := expr.EvalBool(vars)
+			if err != nil {
+				t.Fatalf("EvalBool returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`query.verb ==`, "Syntax error"},
+		{`(query.verb`, "Syntax error"},
+		{`"unterminated`, "Syntax error"},
+		{`user.name == "x"`, "undeclared reference to 'user'"},
+		{`lower(query.verb)`, "undeclared reference to 'lower'"},
+		{`query.verb.exists("x", true)`, "argument must be a simple name"},
+		{`query.verb.exists(v, v == x)`, "undeclared reference to 'x'"},
+		{`1 + 1`, "expected bool"},
+		{`"secrets"`, "expected bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr, "query", "caller")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	vars := map[string]interface{}{
+		"query": map[string]interface{}{"verb": []interface{}{"get"}, "limit": int64(1)},
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`query.analysis.type == "correlation"`, "no such key: analysis"},
+		{`query.verb.startsWith("g")`, "no such overload"},
+		{`query.limit / 0 == 1`, "division by zero"},
+		{`query.limit`, "expected bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Compile(tt.expr, "query")
+			if err != nil {
+				t.Fatalf("Compile returned error: %v", err)
+			}
+			_, err = expr.EvalBool(vars)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+// Package policy evaluates policy-as-code guardrails over structured
+// queries. Policies are CEL expressions (see Expression) loaded from YAML
+// files in a policy directory:
+//
+//	policies:
+//	  - name: privilege-escalation-security-team-only
+//	    condition: has(query.analysis) && query.analysis.type == "privilege_escalation" && !("security-team" in caller.groups)
+//	    decision: deny
+//	    message: Privilege escalation analysis is restricted to the security team
+//	  - name: incident-responders
+//	    condition: '"incident-responders" in caller.groups'
+//	    decision: allow
+//	    exempts: [privilege-escalation-security-team-only]
+//
+// Expressions see the structured query as query (with its JSON field names),
+// the caller as caller (user_id, groups, authenticated) and the conversation
+// session as session (id, user_id, created_at, last_activity, turns,
+// metadata). Every top-level query field is set: fields that hold one value
+// or a list (verb, resource, namespace, user, ...) are always lists, and
+// unset fields hold their zero value. Only the nested time_range,
+// business_hours and analysis objects need has() before they are selected.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// Decisions a policy makes when its condition holds. A deny rejects the
+// query, a warn adds a warning and an allow exempts the query from the deny
+// policies it names in Exempts, and from no others.
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+	DecisionWarn  = "warn"
+)
+
+// Variables are the names policy expressions can refer to.
+var Variables = []string{"query", "caller", "session"}
+
+// Policy is a named guardrail: when Condition holds for a query, Decision
+// applies.
+type Policy struct {
+	// Name identifies the policy in messages and results
+	Name string `yaml:"name"`
+
+	// Description explains the policy to readers of the policy file
+	Description string `yaml:"description,omitempty"`
+
+	// Condition is the expression selecting the queries the policy applies to
+	Condition string `yaml:"condition"`
+
+	// Decision is allow, deny or warn
+	Decision string `yaml:"decision"`
+
+	// Message is reported when the policy applies
+	Message string `yaml:"message,omitempty"`
+
+	// Exempts names the deny policies an allow policy overrides
+	Exempts []string `yaml:"exempts,omitempty"`
+
+	// Source is the file the policy was loaded from
+	Source string `yaml:"-"`
+
+	condition *Expression
+}
+
+// Match is a policy that applied to a query.
+type Match struct {
+	Policy   string `json:"policy"`
+	Decision string `json:"decision"`
+	Message  string `json:"message"`
+
+	// Exempts names the deny policies an allow match overrides
+	Exempts []string `json:"exempts,omitempty"`
+}
+
+// Evaluation is the outcome of evaluating a Set against a query.
+type Evaluation struct {
+	// Matches are the policies whose condition held, in policy order
+	Matches []Match
+
+	// Errors are the policies that could not be evaluated. They fail
+	// closed: callers treat them like a deny that no allow overrides.
+	Errors []error
+}
+
+// Decision returns the combined decision: deny when a policy could not be
+// evaluated or a deny matched that no matching allow exempts, warn when a
+// warn matched, and allow otherwise.
+func (e *Evaluation) Decision() string {
+	if len(e.Errors) > 0 || len(e.Denied()) > 0 {
+		return DecisionDeny
+	}
+	for _, m := range e.Matches {
+		if m.Decision == DecisionWarn {
+			return DecisionWarn
+		}
+	}
+	return DecisionAllow
+}
+
+// Denied returns the deny matches that no allow match exempts.
+func (e *Evaluation) Denied() []Match {
+	exempt := make(map[string]bool)
+	for _, m := range e.Matches {
+		if m.Decision == DecisionAllow {
+			for _, name := range m.Exempts {
+				exempt[name] = true
+			}
+		}
+	}
+	var denied []Match
+	for _, m := range e.Matches {
+		if m.Decision == DecisionDeny && !exempt[m.Policy] {
+			denied = append(denied, m)
+		}
+	}
+	return denied
+}
+
+// Set is an ordered collection of compiled policies.
+type Set struct {
+	policies []*Policy
+}
+
+// NewSet compiles policies. Invalid policies, and allow policies naming a
+// policy that is not a valid deny, are reported in the error and left out
+// of the set.
+func NewSet(policies []Policy) (*Set, error) {
+	var compiled []*Policy
+	var errs []error
+	decisions := make(map[string]string, len(policies))
+	for i := range policies {
+		p := policies[i]
+		if err := p.compile(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := decisions[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("policy '%s'%s is defined twice", p.Name, p.location()))
+			continue
+		}
+		decisions[p.Name] = p.Decision
+		compiled = append(compiled, &p)
+	}
+
+	set := &Set{}
+	for _, p := range compiled {
+		if err := p.checkExempts(decisions); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		set.policies = append(set.policies, p)
+	}
+	return set, errors.Join(errs...)
+}
+
+// LoadDir loads the policies of the .yaml and .yml files in dir, in file
+// name order. Files and policies that cannot be loaded are reported in the
+// error; the set holds the others.
+func LoadDir(dir string) (*Set, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return &Set{}, fmt.Errorf("failed to read policy directory %s: %w", dir, err)
+	}
+
+	var policies []Policy
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read policy file %s: %w", path, err))
+			continue
+		}
+		var file struct {
+			Policies []Policy `yaml:"policies"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse policy file %s: %w", path, err))
+			continue
+		}
+		for _, p := range file.Policies {
+			p.Source = path
+			policies = append(policies, p)
+		}
+	}
+
+	set, err := NewSet(policies)
+	return set, errors.Join(append(errs, err)...)
+}
+
+// Policies returns the compiled policies in order.
+func (s *Set) Policies() []Policy {
+	policies := make([]Policy, len(s.policies))
+	for i, p := range s.policies {
+		policies[i] = *p
+	}
+	return policies
+}
+
+// Len returns the number of policies.
+func (s *Set) Len() int {
+	return len(s.policies)
+}
+
+// Evaluate evaluates every policy against a query generated for the caller
+// and session in vctx, which may be nil.
+func (s *Set) Evaluate(query *types.StructuredQuery, vctx *interfaces.ValidationContext) *Evaluation {
+	evaluation := &Evaluation{}
+	vars, err := bindings(query, vctx)
+	if err != nil {
+		evaluation.Errors = append(evaluation.Errors, err)
+		return evaluation
+	}
+	for _, p := range s.policies {
+		holds, err := p.condition.EvalBool(vars)
+		if err != nil {
+			evaluation.Errors = append(evaluation.Errors, fmt.Errorf("policy '%s' could not be evaluated: %w", p.Name, err))
+			continue
+		}
+		if holds {
+			evaluation.Matches = append(evaluation.Matches, Match{Policy: p.Name, Decision: p.Decision, Message: p.message(), Exempts: p.Exempts})
+		}
+	}
+	return evaluation
+}
+
+// compile checks the policy and compiles its condition.
+func (p *Policy) compile() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("policy%s has no name", p.location())
+	}
+	switch p.Decision {
+	case DecisionAllow, DecisionDeny, DecisionWarn:
+	default:
+		return fmt.Errorf("policy '%s'%s has decision '%s', expected %s, %s or %s", p.Name, p.location(), p.Decision, DecisionAllow, DecisionDeny, DecisionWarn)
+	}
+	condition, err := Compile(p.Condition, Variables...)
+	if err != nil {
+		return fmt.Errorf("policy '%s'%s has an invalid condition: %w", p.Name, p.location(), err)
+	}
+	p.condition = condition
+	return nil
+}
+
+// checkExempts checks that an allow policy names the deny policies it
+// exempts, and that only allow policies name any.
+func (p *Policy) checkExempts(decisions map[string]string) error {
+	if p.Decision != DecisionAllow {
+		if len(p.Exempts) > 0 {
+			return fmt.Errorf("policy '%s'%s has decision '%s' and cannot exempt other policies", p.Name, p.location(), p.Decision)
+		}
+		return nil
+	}
+	if len(p.Exempts) == 0 {
+		return fmt.Errorf("allow policy '%s'%s does not name the deny policies it exempts", p.Name, p.location())
+	}
+	for _, name := range p.Exempts {
+		if decisions[name] != DecisionDeny {
+			return fmt.Errorf("allow policy '%s'%s exempts '%s', which is not a deny policy", p.Name, p.location(), name)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) location() string {
+	if p.Source == "" {
+		return ""
+	}
+	return " in " + p.Source
+}
+
+func (p *Policy) message() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	return fmt.Sprintf("policy '%s' matched the query", p.Name)
+}
+
+// bindings builds the query, caller and session variables as JSON values.
+func bindings(query *types.StructuredQuery, vctx *interfaces.ValidationContext) (map[string]interface{}, error) {
+	queryValue, err := queryFields(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the query for policy evaluation: %w", err)
+	}
+
+	caller := map[string]interface{}{"user_id": "", "groups": []interface{}{}, "authenticated": false}
+	session := map[string]interface{}{"id": "", "user_id": "", "created_at": "", "last_activity": "", "turns": int64(0), "metadata": map[string]interface{}{}}
+	if vctx != nil {
+		groups := make([]interface{}, len(vctx.Groups))
+		for i, g := range vctx.Groups {
+			groups[i] = g
+		}
+		caller = map[string]interface{}{"user_id": vctx.UserID, "groups": groups, "authenticated": vctx.UserID != ""}
+
+		if s := vctx.Session; s != nil {
+			metadata, err := jsonValue(s.SessionMetadata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert the session metadata for policy evaluation: %w", err)
+			}
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+			session = map[string]interface{}{
+				"id":            s.SessionID,
+				"user_id":       s.UserID,
+				"created_at":    s.CreatedAt.UTC().Format(time.RFC3339),
+				"last_activity": s.LastActivity.UTC().Format(time.RFC3339),
+				"turns":         int64(len(s.ConversationHistory)),
+				"metadata":      metadata,
+			}
+		}
+	}
+
+	return map[string]interface{}{"query": queryValue, "caller": caller, "session": session}, nil
+}
+
+// queryFields converts the query to a map holding every top-level field.
+// One-or-many fields become lists and unset fields their zero value; unset
+// nested objects are left out.
+func queryFields(query *types.StructuredQuery) (map[string]interface{}, error) {
+	if query == nil {
+		query = &types.StructuredQuery{}
+	}
+	value, err := jsonValue(query)
+	if err != nil {
+		return nil, err
+	}
+	fields, _ := value.(map[string]interface{})
+
+	v := reflect.ValueOf(query).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		switch field := v.Field(i).Addr().Interface().(type) {
+		case *types.StringOrArray:
+			fields[name] = stringList(field.Values())
+		case *[]string:
+			fields[name] = stringList(*field)
+		default:
+			if _, ok := fields[name]; ok || v.Field(i).Kind() == reflect.Pointer {
+				continue
+			}
+			if fields[name], err = jsonValue(v.Field(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fields, nil
+}
+
+func stringList(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
+
+// jsonValue converts v to the null, bool, int, double, string, list and map
+// values expressions operate on. Whole numbers become ints so that they
+// combine with integer literals.
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return numbers(value), nil
+}
+
+// numbers replaces the json.Number values in v with int64 or float64.
+func numbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = numbers(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = numbers(v[k])
+		}
+	}
+	return v
+}
@@ -0,0 +1,269 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+const testPolicies = `
+policies:
+  - name: privilege-escalation-security-team-only
+    condition: has(query.analysis) && query.analysis.type == "privilege_escalation" && !("security-team" in caller.groups)
+    decision: deny
+    message: Privilege escalation analysis is restricted to the security team
+  - name: secrets-review
+    condition: '"secrets" in query.resource'
+    decision: warn
+    message: Secrets access is reviewed
+  - name: long-sessions
+    condition: session.turns > 2 && session.metadata.?channel.orValue("") == "cli"
+    decision: deny
+    message: Start a new session
+`
+
+func writePolicies(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSet_Evaluate(t *testing.T) {
+	set, err := LoadDir(writePolicies(t, map[string]string{"security.yaml": testPolicies, "README.md": "not a policy"}))
+	if err != nil {
+		t.Fatalf("LoadDir returned error: %v", err)
+	}
+	if set.Len() != 3 {
+		t.Fatalf("expected 3 policies, got %d", set.Len())
+	}
+
+	escalation := &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "privilege_escalation"}}
+	session := &types.ConversationContext{
+		SessionID:           "s1",
+		CreatedAt:           time.Now(),
+		ConversationHistory: make([]types.ConversationEntry, 3),
+		SessionMetadata:     map[string]interface{}{"channel": "cli"},
+	}
+
+	tests := []struct {
+		name     string
+		query    *types.StructuredQuery
+		vctx     *interfaces.ValidationContext
+		decision string
+		matched  []string
+	}{
+		{
+			name:     "denied without the group",
+			query:    escalation,
+			vctx:     &interfaces.ValidationContext{UserID: "alice", Groups: []string{"developers"}},
+			decision: DecisionDeny,
+			matched:  []string{"privilege-escalation-security-team-only"},
+		},
+		{
+			name:     "anonymous caller",
+			query:    escalation,
+			decision: DecisionDeny,
+			matched:  []string{"privilege-escalation-security-team-only"},
+		},
+		{
+			name:     "allowed with the group",
+			query:    escalation,
+			vctx:     &interfaces.ValidationContext{UserID: "bob", Groups: []string{"security-team"}},
+			decision: DecisionAllow,
+		},
+		{
+			name:     "warned",
+			query:    &types.StructuredQuery{LogSource: "kube-apiserver", Resource: *types.NewStringOrArray([]string{"pods", "secrets"})},
+			decision: DecisionWarn,
+			matched:  []string{"secrets-review"},
+		},
+		{
+			name:     "session metadata",
+			query:    &types.StructuredQuery{LogSource: "kube-apiserver"},
+			vctx:     &interfaces.ValidationContext{UserID: "alice", Session: session},
+			decision: DecisionDeny,
+			matched:  []string{"long-sessions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation := set.Evaluate(tt.query, tt.vctx)
+			if len(evaluation.Errors) > 0 {
+				t.Fatalf("unexpected evaluation errors: %v", evaluation.Errors)
+			}
+			if got := evaluation.Decision(); got != tt.decision {
+				t.Errorf("expected decision %s, got %s", tt.decision, got)
+			}
+			var matched []string
+			for _, m := range evaluation.Matches {
+				matched = append(matched, m.Policy)
+			}
+			if strings.Join(matched, ",") != strings.Join(tt.matched, ",") {
+				t.Errorf("expected matches %v, got %v", tt.matched, matched)
+			}
+		})
+	}
+}
+
+func TestEvaluation_AllowExemptsNamedDenies(t *testing.T) {
+	set, err := NewSet([]Policy{
+		{Name: "no-kube-system", Condition: `"kube-system" in query.namespace`, Decision: DecisionDeny},
+		{Name: "no-secrets", Condition: `"secrets" in query.resource`, Decision: DecisionDeny},
+		{Name: "admins", Condition: `"admins" in caller.groups`, Decision: DecisionAllow, Exempts: []string{"no-kube-system"}},
+	})
+	if err != nil {
+		t.Fatalf("NewSet returned error: %v", err)
+	}
+	kubeSystem := &types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("kube-system")}
+	secrets := &types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("kube-system"), Resource: *types.NewStringOrArray("secrets")}
+	admin := &interfaces.ValidationContext{UserID: "root", Groups: []string{"admins"}}
+
+	if got := set.Evaluate(kubeSystem, &interfaces.ValidationContext{UserID: "alice"}).Decision(); got != DecisionDeny {
+		t.Errorf("expected deny, got %s", got)
+	}
+	evaluation := set.Evaluate(kubeSystem, admin)
+	if got := evaluation.Decision(); got != DecisionAllow || len(evaluation.Denied()) != 0 {
+		t.Errorf("expected the allow policy to exempt no-kube-system, got %s", got)
+	}
+
+	evaluation = set.Evaluate(secrets, admin)
+	denied := evaluation.Denied()
+	if got := evaluation.Decision(); got != DecisionDeny || len(denied) != 1 || denied[0].Policy != "no-secrets" {
+		t.Errorf("expected no-secrets to deny despite the allow, got %s with %v", got, denied)
+	}
+}
+
+func TestNewSet_Exempts(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   string
+	}{
+		{"allow without exempts", Policy{Name: "admins", Condition: `true`, Decision: DecisionAllow}, "does not name the deny policies"},
+		{"unknown policy", Policy{Name: "admins", Condition: `true`, Decision: DecisionAllow, Exempts: []string{"missing"}}, "exempts 'missing', which is not a deny policy"},
+		{"warn policy", Policy{Name: "admins", Condition: `true`, Decision: DecisionAllow, Exempts: []string{"review"}}, "exempts 'review', which is not a deny policy"},
+		{"exempts on a deny", Policy{Name: "strict", Condition: `true`, Decision: DecisionDeny, Exempts: []string{"no-kube-system"}}, "cannot exempt other policies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewSet([]Policy{
+				{Name: "no-kube-system", Condition: `"kube-system" in query.namespace`, Decision: DecisionDeny},
+				{Name: "review", Condition: `true`, Decision: DecisionWarn},
+				tt.policy,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if set.Len() != 2 {
+				t.Errorf("expected the invalid policy to be left out, got %d policies", set.Len())
+			}
+		})
+	}
+}
+
+func TestEvaluation_ErrorsFailClosed(t *testing.T) {
+	set, err := NewSet([]Policy{
+		{Name: "namespace-prefix", Condition: `query.namespace.startsWith("openshift-")`, Decision: DecisionDeny},
+		{Name: "admins", Condition: `true`, Decision: DecisionAllow, Exempts: []string{"namespace-prefix"}},
+	})
+	if err != nil {
+		t.Fatalf("NewSet returned error: %v", err)
+	}
+
+	query := &types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray([]string{"a", "b"})}
+	evaluation := set.Evaluate(query, nil)
+	if len(evaluation.Errors) != 1 || !strings.Contains(evaluation.Errors[0].Error(), "namespace-prefix") {
+		t.Fatalf("expected an evaluation error for namespace-prefix, got %v", evaluation.Errors)
+	}
+	if got := evaluation.Decision(); got != DecisionDeny {
+		t.Errorf("expected evaluation errors to deny, got %s", got)
+	}
+}
+
+func TestLoadDir_Errors(t *testing.T) {
+	dir := writePolicies(t, map[string]string{
+		"a.yaml": testPolicies,
+		"b.yml": `
+policies:
+  - name: bad-decision
+    condition: "true"
+    decision: block
+  - name: bad-condition
+    condition: query.verb ==
+    decision: deny
+  - name: secrets-review
+    condition: "false"
+    decision: warn
+`,
+		"c.yaml": "policies: [",
+	})
+
+	set, err := LoadDir(dir)
+	if set.Len() != 3 {
+		t.Errorf("expected the 3 valid policies to load, got %d", set.Len())
+	}
+	if err == nil {
+		t.Fatal("expected LoadDir to report the invalid policies")
+	}
+	for _, want := range []string{"'bad-decision'", "'bad-condition'", "'secrets-review'", "is defined twice", "c.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %s, got %v", want, err)
+		}
+	}
+
+	if _, err := LoadDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestLoadDir_Examples(t *testing.T) {
+	set, err := LoadDir("../../../configs/policies")
+	if err != nil {
+		t.Fatalf("the shipped policies do not load: %v", err)
+	}
+	if set.Len() != 0 {
+		t.Errorf("expected the shipped examples to be commented out, got %d policies", set.Len())
+	}
+
+	data, err := os.ReadFile("../../../configs/policies/examples.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, examples, _ := strings.Cut(string(data), "policies: []\n")
+	lines := []string{"policies:"}
+	for _, line := range strings.Split(examples, "\n") {
+		lines = append(lines, strings.TrimPrefix(line, "#"))
+	}
+	set, err = LoadDir(writePolicies(t, map[string]string{"examples.yaml": strings.Join(lines, "\n")}))
+	if err != nil {
+		t.Fatalf("the commented out examples do not load: %v", err)
+	}
+	if set.Len() != 4 {
+		t.Errorf("expected the 4 examples to load, got %d", set.Len())
+	}
+
+	query := &types.StructuredQuery{LogSource: "kube-apiserver", Timeframe: "30_days_ago", Analysis: &types.AnalysisConfig{Type: "privilege_escalation"}}
+	evaluation := set.Evaluate(query, &interfaces.ValidationContext{UserID: "carol", Groups: []string{"platform-security-admins"}})
+	if len(evaluation.Errors) > 0 {
+		t.Fatalf("unexpected evaluation errors: %v", evaluation.Errors)
+	}
+	if denied := evaluation.Denied(); len(denied) != 0 {
+		t.Errorf("expected security-admins-exempt to exempt the caller, got %v", denied)
+	}
+	evaluation = set.Evaluate(query, nil)
+	if len(evaluation.Errors) > 0 || len(evaluation.Denied()) != 2 {
+		t.Errorf("expected both deny examples to apply to an anonymous caller, got %v and %v", evaluation.Denied(), evaluation.Errors)
+	}
+}
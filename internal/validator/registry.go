@@ -0,0 +1,255 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"genai-processing/pkg/interfaces"
+)
+
+// Rule modes: a failing blocking rule rejects the query, while a failing
+// advisory rule only reports its errors as warnings.
+const (
+	ModeBlocking = "blocking"
+	ModeAdvisory = "advisory"
+)
+
+// severityRank orders severities so the combined result reports the most
+// severe failure. Unknown severities rank lowest.
+var severityRank = map[string]int{
+	"info":     1,
+	"warning":  2,
+	"low":      3,
+	"medium":   4,
+	"high":     5,
+	"critical": 6,
+}
+
+// RuleSettings configures a registered rule from the rules section of
+// rules.yaml. Unset fields keep the rule's defaults.
+type RuleSettings struct {
+	// Enabled turns the rule off when false
+	Enabled *bool `yaml:"enabled"`
+
+	// Severity replaces the rule's own severity; it must be one of the
+	// configured severity_levels
+	Severity string `yaml:"severity"`
+
+	// Mode is blocking (the default) or advisory
+	Mode string `yaml:"mode"`
+
+	// Category replaces the category the rule was registered with
+	Category string `yaml:"category"`
+
+	// Order positions the rule; rules run in ascending order
+	Order *int `yaml:"order"`
+
+	// Environments limits the rule to the listed environments
+	Environments []string `yaml:"environments"`
+
+	// Config is passed to the factory of a rule registered with RegisterRuleFactory
+	Config map[string]interface{} `yaml:"config"`
+}
+
+// RegisteredRule is a validation rule with its registry settings.
+type RegisteredRule struct {
+	// Name identifies the rule in rules.yaml and in rule_results
+	Name string
+
+	// Category is one of the configured rule_categories
+	Category string
+
+	// Severity is reported by the rule's results
+	Severity string
+
+	// Mode is ModeBlocking or ModeAdvisory
+	Mode string
+
+	// Order positions the rule; rules run in ascending order
+	Order int
+
+	// Enabled is false when the rule is turned off by its settings, its
+	// environments or its category
+	Enabled bool
+
+	// Rule is the validation rule
+	Rule interfaces.ValidationRule
+}
+
+// RuleFactory builds a site-specific rule from its config in rules.yaml.
+type RuleFactory func(config map[string]interface{}) (interfaces.ValidationRule, error)
+
+type ruleFactory struct {
+	category string
+	build    RuleFactory
+}
+
+var (
+	factoriesMu sync.Mutex
+	factories   = make(map[string]ruleFactory)
+)
+
+// RegisterRuleFactory makes a site-specific rule available under name. Every
+// SafetyValidator whose rules.yaml has an entry for name builds the rule from
+// that entry's config and registers it in category. It is meant to be called
+// from an init function and panics if name is registered twice.
+func RegisterRuleFactory(name, category string, factory RuleFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("validator: RegisterRuleFactory factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("validator: RegisterRuleFactory called twice for rule " + name)
+	}
+	factories[name] = ruleFactory{category: category, build: factory}
+}
+
+// lookupRuleFactory returns the factory registered under name.
+func lookupRuleFactory(name string) (ruleFactory, bool) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	f, ok := factories[name]
+	return f, ok
+}
+
+// RuleRegistry holds the rules a SafetyValidator applies, with the settings
+// of the rules section of rules.yaml applied to them.
+type RuleRegistry struct {
+	settings       map[string]RuleSettings
+	environment    string
+	severityLevels []string
+	categories     []string
+
+	mu    sync.RWMutex
+	rules []*RegisteredRule
+}
+
+// NewRuleRegistry creates an empty registry applying the rule settings,
+// environment, severity levels and categories of config.
+func NewRuleRegistry(config *ValidationConfig) *RuleRegistry {
+	return &RuleRegistry{
+		settings:       config.SafetyRules.Rules,
+		environment:    config.SafetyRules.Environment,
+		severityLevels: config.SafetyRules.SeverityLevels,
+		categories:     config.SafetyRules.RuleCategories,
+	}
+}
+
+// Register adds a rule under a unique name and category. The rule runs after
+// those already registered unless its settings give an order. Invalid
+// settings are reported in the error and the rule is registered with its
+// defaults for them; a duplicate name is an error and is not registered.
+func (r *RuleRegistry) Register(name, category string, rule interfaces.ValidationRule) error {
+	if name == "" || rule == nil {
+		return fmt.Errorf("validation rule needs a name and an implementation")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.rules {
+		if existing.Name == name {
+			return fmt.Errorf("validation rule '%s' is already registered", name)
+		}
+	}
+
+	registered := &RegisteredRule{
+		Name:     name,
+		Category: category,
+		Severity: rule.GetSeverity(),
+		Mode:     ModeBlocking,
+		Order:    (len(r.rules) + 1) * 10,
+		Enabled:  true,
+		Rule:     rule,
+	}
+	err := r.apply(registered, r.settings[name])
+	r.rules = append(r.rules, registered)
+	sort.SliceStable(r.rules, func(i, j int) bool { return r.rules[i].Order < r.rules[j].Order })
+	return err
+}
+
+// apply applies a rule's settings, returning the first invalid one.
+func (r *RuleRegistry) apply(registered *RegisteredRule, settings RuleSettings) error {
+	var err error
+	if settings.Category != "" {
+		if len(r.categories) > 0 && !containsString(r.categories, settings.Category) {
+			err = fmt.Errorf("validation rule '%s' has category '%s', which is not in rule_categories", registered.Name, settings.Category)
+		} else {
+			registered.Category = settings.Category
+		}
+	}
+	if settings.Severity != "" {
+		if len(r.severityLevels) > 0 && !containsString(r.severityLevels, settings.Severity) {
+			if err == nil {
+				err = fmt.Errorf("validation rule '%s' has unknown severity '%s'", registered.Name, settings.Severity)
+			}
+		} else {
+			registered.Severity = settings.Severity
+		}
+	}
+	switch settings.Mode {
+	case "":
+	case ModeBlocking, ModeAdvisory:
+		registered.Mode = settings.Mode
+	default:
+		if err == nil {
+			err = fmt.Errorf("validation rule '%s' has unknown mode '%s', expected %s or %s", registered.Name, settings.Mode, ModeBlocking, ModeAdvisory)
+		}
+	}
+	if settings.Order != nil {
+		registered.Order = *settings.Order
+	}
+
+	if settings.Enabled != nil && !*settings.Enabled {
+		registered.Enabled = false
+	}
+	if len(settings.Environments) > 0 && !containsString(settings.Environments, r.environment) {
+		registered.Enabled = false
+	}
+	if len(r.categories) > 0 && !containsString(r.categories, registered.Category) {
+		registered.Enabled = false
+	}
+	return err
+}
+
+// Rule returns the rule registered under name, or nil.
+func (r *RuleRegistry) Rule(name string) *RegisteredRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, registered := range r.rules {
+		if registered.Name == name {
+			return registered
+		}
+	}
+	return nil
+}
+
+// Rules returns every registered rule in order.
+func (r *RuleRegistry) Rules() []*RegisteredRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*RegisteredRule(nil), r.rules...)
+}
+
+// Active returns the enabled rules in order.
+func (r *RuleRegistry) Active() []*RegisteredRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var active []*RegisteredRule
+	for _, registered := range r.rules {
+		if registered.Enabled && registered.Rule.IsEnabled() {
+			active = append(active, registered)
+		}
+	}
+	return active
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// protectedNamespaceRule is a site-specific rule rejecting queries that
+// filter on a protected namespace
+type protectedNamespaceRule struct {
+	namespaces []string
+}
+
+func (r *protectedNamespaceRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:  true,
+		RuleName: r.GetRuleName(),
+		Severity: r.GetSeverity(),
+		Message:  "Namespace is not protected",
+		Details:  map[string]interface{}{},
+	}
+	for _, ns := range query.Namespace.Values() {
+		if containsString(r.namespaces, ns) {
+			result.IsValid = false
+			result.Message = "Namespace is protected"
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s' is protected", ns))
+		}
+	}
+	return result
+}
+
+func (r *protectedNamespaceRule) GetRuleName() string        { return "protected_namespaces" }
+func (r *protectedNamespaceRule) GetRuleDescription() string { return "Rejects protected namespaces" }
+func (r *protectedNamespaceRule) IsEnabled() bool            { return true }
+func (r *protectedNamespaceRule) GetSeverity() string        { return "high" }
+
+func init() {
+	RegisterRuleFactory("protected_namespaces", "business_rules", func(config map[string]interface{}) (interfaces.ValidationRule, error) {
+		raw, ok := config["namespaces"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("namespaces must be a list")
+		}
+		rule := &protectedNamespaceRule{}
+		for _, ns := range raw {
+			rule.namespaces = append(rule.namespaces, fmt.Sprint(ns))
+		}
+		return rule, nil
+	})
+}
+
+func boolPtr(b bool) *bool { return &b }
+func intPtr(i int) *int    { return &i }
+
+// registryTestConfig allows the kube-apiserver log source and protects the
+// payments namespace
+func registryTestConfig() *ValidationConfig {
+	config := &ValidationConfig{}
+	config.SafetyRules.AllowedLogSources = []string{"kube-apiserver"}
+	config.SafetyRules.SeverityLevels = []string{"low", "medium", "high", "critical"}
+	config.SafetyRules.Rules = map[string]RuleSettings{
+		"protected_namespaces": {Config: map[string]interface{}{"namespaces": []interface{}{"payments"}}},
+	}
+	return config
+}
+
+func TestRuleRegistry_FactoryRule(t *testing.T) {
+	validator := NewSafetyValidatorWithConfig(registryTestConfig())
+	if errs := validator.ConfigErrors(); len(errs) > 0 {
+		t.Fatalf("unexpected config errors: %v", errs)
+	}
+	registered := validator.Registry().Rule("protected_namespaces")
+	if registered == nil || registered.Category != "business_rules" {
+		t.Fatalf("expected the factory rule in business_rules, got %+v", registered)
+	}
+
+	result, _ := validator.ValidateQuery(&types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("payments")})
+	if result.IsValid {
+		t.Fatal("expected the protected namespace to be rejected")
+	}
+	if result.Severity != "high" {
+		t.Errorf("expected severity high, got %s", result.Severity)
+	}
+	result, _ = validator.ValidateQuery(&types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("default")})
+	if !result.IsValid {
+		t.Errorf("expected other namespaces to pass, got %v", result.Errors)
+	}
+}
+
+func TestRuleRegistry_Settings(t *testing.T) {
+	query := &types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("payments")}
+
+	tests := []struct {
+		name         string
+		settings     RuleSettings
+		environment  string
+		categories   []string
+		expectValid  bool
+		expectActive bool
+		severity     string
+		configError  string
+	}{
+		{
+			name:         "defaults",
+			expectActive: true,
+			severity:     "high",
+		},
+		{
+			name:         "severity override",
+			settings:     RuleSettings{Severity: "critical"},
+			expectActive: true,
+			severity:     "critical",
+		},
+		{
+			name:         "unknown severity keeps the default",
+			settings:     RuleSettings{Severity: "urgent"},
+			expectActive: true,
+			severity:     "high",
+			configError:  "unknown severity 'urgent'",
+		},
+		{
+			name:         "advisory",
+			settings:     RuleSettings{Mode: ModeAdvisory},
+			expectValid:  true,
+			expectActive: true,
+		},
+		{
+			name:         "unknown mode",
+			settings:     RuleSettings{Mode: "strict"},
+			expectActive: true,
+			severity:     "high",
+			configError:  "unknown mode 'strict'",
+		},
+		{
+			name:        "disabled",
+			settings:    RuleSettings{Enabled: boolPtr(false)},
+			expectValid: true,
+		},
+		{
+			name:         "listed environment",
+			settings:     RuleSettings{Environments: []string{"production"}},
+			environment:  "production",
+			expectActive: true,
+			severity:     "high",
+		},
+		{
+			name:         "listed category",
+			settings:     RuleSettings{Category: "patterns"},
+			categories:   []string{"patterns", "business_rules"},
+			expectActive: true,
+			severity:     "high",
+		},
+		{
+			name:         "category outside rule_categories",
+			settings:     RuleSettings{Category: "bussiness_rules"},
+			categories:   []string{"patterns", "business_rules"},
+			expectActive: true,
+			severity:     "high",
+			configError:  "category 'bussiness_rules', which is not in rule_categories",
+		},
+		{
+			name:        "other environment",
+			settings:    RuleSettings{Environments: []string{"production"}},
+			environment: "staging",
+			expectValid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := registryTestConfig()
+			config.SafetyRules.Environment = tt.environment
+			config.SafetyRules.RuleCategories = tt.categories
+			settings := tt.settings
+			settings.Config = config.SafetyRules.Rules["protected_namespaces"].Config
+			config.SafetyRules.Rules["protected_namespaces"] = settings
+			validator := NewSafetyValidatorWithConfig(config)
+
+			errs := validator.ConfigErrors()
+			if tt.configError == "" && len(errs) > 0 {
+				t.Fatalf("unexpected config errors: %v", errs)
+			}
+			if tt.configError != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.configError)) {
+				t.Fatalf("expected config error %q, got %v", tt.configError, errs)
+			}
+
+			active := false
+			for _, registered := range validator.Registry().Active() {
+				active = active || registered.Name == "protected_namespaces"
+			}
+			if active != tt.expectActive {
+				t.Errorf("expected active %v, got %v", tt.expectActive, active)
+			}
+
+			result, _ := validator.ValidateQuery(query)
+			if result.IsValid != tt.expectValid {
+				t.Fatalf("expected IsValid %v, got %v", tt.expectValid, result.IsValid)
+			}
+			if !tt.expectValid && result.Severity != tt.severity {
+				t.Errorf("expected severity %s, got %s", tt.severity, result.Severity)
+			}
+			if tt.settings.Mode == ModeAdvisory && len(result.Warnings) == 0 {
+				t.Error("expected the advisory failure to be reported as a warning")
+			}
+		})
+	}
+}
+
+func TestRuleRegistry_Order(t *testing.T) {
+	config := registryTestConfig()
+	config.SafetyRules.Rules["protected_namespaces"] = RuleSettings{
+		Order:  intPtr(1),
+		Config: config.SafetyRules.Rules["protected_namespaces"].Config,
+	}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	rules := validator.Registry().Rules()
+	if len(rules) != 2 || rules[0].Name != "protected_namespaces" || rules[1].Name != "whitelist" {
+		t.Fatalf("expected protected_namespaces before whitelist, got %+v", rules)
+	}
+}
+
+func TestRuleRegistry_UnknownRule(t *testing.T) {
+	config := registryTestConfig()
+	config.SafetyRules.Rules["missing_rule"] = RuleSettings{Severity: "high"}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	errs := validator.ConfigErrors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "missing_rule") {
+		t.Fatalf("expected an error for the unknown rule, got %v", errs)
+	}
+}
+
+func TestSafetyValidator_RegisterRule(t *testing.T) {
+	validator := NewSafetyValidatorWithConfig(registryTestConfig())
+	rule := &protectedNamespaceRule{namespaces: []string{"kube-system"}}
+
+	if err := validator.RegisterRule("kube_system", "business_rules", rule); err != nil {
+		t.Fatalf("RegisterRule returned error: %v", err)
+	}
+	if err := validator.RegisterRule("kube_system", "business_rules", rule); err == nil {
+		t.Error("expected an error registering a duplicate rule")
+	}
+
+	result, _ := validator.ValidateQuery(&types.StructuredQuery{LogSource: "kube-apiserver", Namespace: *types.NewStringOrArray("kube-system")})
+	if result.IsValid {
+		t.Fatal("expected the registered rule to reject the query")
+	}
+	ruleResults := result.Details["rule_results"].(map[string]*interfaces.ValidationResult)
+	if _, ok := ruleResults["kube_system"]; !ok {
+		t.Error("expected the rule result under its registered name")
+	}
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// RemediationSettings configures the repair pass for rejected queries from
+// the remediation section of rules.yaml.
+type RemediationSettings struct {
+	// Enabled turns on repairing rejected queries; it is overridden by the
+	// VALIDATION_REMEDIATION environment variable
+	Enabled bool `yaml:"enabled"`
+}
+
+// RemediateQuery repairs a copy of a query with the safe, deterministic fixes
+// of the active rules implementing interfaces.RemediableValidationRule:
+// clamping the limit, shrinking the timeframe or time range, dropping verbs
+// outside the whitelist and escaping forbidden pattern characters when the
+// backslash is allowed. Fixes that would change what the query searches for,
+// such as removing regex operators from patterns, are never made. It returns
+// query unchanged when remediation is disabled or nothing changed.
+func (sv *SafetyValidator) RemediateQuery(query *types.StructuredQuery) (*types.StructuredQuery, []interfaces.QueryChange) {
+	if query == nil || !sv.config.SafetyRules.Remediation.Enabled {
+		return query, nil
+	}
+
+	repaired := *query
+	var changes []interfaces.QueryChange
+	for _, registered := range sv.registry.Active() {
+		if rule, ok := registered.Rule.(interfaces.RemediableValidationRule); ok {
+			changes = append(changes, rule.Remediate(&repaired)...)
+		}
+	}
+	if len(changes) == 0 {
+		return query, nil
+	}
+	return &repaired, changes
+}
@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// remediationTestConfig enables remediation with a 30 day, 100 result limit
+func remediationTestConfig() *ValidationConfig {
+	config := &ValidationConfig{}
+	config.SafetyRules.AllowedLogSources = []string{"kube-apiserver"}
+	config.SafetyRules.AllowedVerbs = []string{"get", "list"}
+	config.SafetyRules.AllowedResources = []string{"pods", "secrets"}
+	config.SafetyRules.TimeframeLimits = map[string]interface{}{
+		"max_days_back":      30,
+		"max_limit":          100,
+		"min_limit":          1,
+		"allowed_timeframes": []interface{}{"today", "7_days_ago", "30_days_ago", "90_days_ago"},
+	}
+	config.SafetyRules.Sanitization = map[string]interface{}{
+		"forbidden_chars": []interface{}{"$", "^", "\\", ";"},
+	}
+	config.SafetyRules.Remediation.Enabled = true
+	return config
+}
+
+func TestSafetyValidator_RemediateQuery(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		query   *types.StructuredQuery
+		field   string
+		check   func(*types.StructuredQuery) bool
+		repairs bool
+	}{
+		{
+			name:    "limit above maximum",
+			query:   &types.StructuredQuery{LogSource: "kube-apiserver", Limit: 5000},
+			field:   "limit",
+			check:   func(q *types.StructuredQuery) bool { return q.Limit == 100 },
+			repairs: true,
+		},
+		{
+			name:    "negative limit",
+			query:   &types.StructuredQuery{LogSource: "kube-apiserver", Limit: -3},
+			field:   "limit",
+			check:   func(q *types.StructuredQuery) bool { return q.Limit == 1 },
+			repairs: true,
+		},
+		{
+			name:    "timeframe beyond max days back",
+			query:   &types.StructuredQuery{LogSource: "kube-apiserver", Timeframe: "90_days_ago"},
+			field:   "timeframe",
+			check:   func(q *types.StructuredQuery) bool { return q.Timeframe == "30_days_ago" },
+			repairs: true,
+		},
+		{
+			name: "time range beyond max days back and in the future",
+			query: &types.StructuredQuery{LogSource: "kube-apiserver", TimeRange: &types.TimeRange{
+				Start: now.AddDate(0, 0, -60), End: now.Add(time.Hour),
+			}},
+			field: "time_range",
+			check: func(q *types.StructuredQuery) bool {
+				return !q.TimeRange.Start.Before(now.AddDate(0, 0, -30)) && !q.TimeRange.End.After(time.Now())
+			},
+			repairs: true,
+		},
+		{
+			name:  "disallowed verbs",
+			query: &types.StructuredQuery{LogSource: "kube-apiserver", Verb: *types.NewStringOrArray([]string{"get", "delete", "list"})},
+			field: "verb",
+			check: func(q *types.StructuredQuery) bool {
+				v := q.Verb.Values()
+				return len(v) == 2 && v[0] == "get" && v[1] == "list"
+			},
+			repairs: true,
+		},
+		{
+			name:  "every verb disallowed",
+			query: &types.StructuredQuery{LogSource: "kube-apiserver", Verb: *types.NewStringOrArray("delete")},
+		},
+		{
+			name:  "time range entirely too old",
+			query: &types.StructuredQuery{LogSource: "kube-apiserver", TimeRange: &types.TimeRange{Start: now.AddDate(0, 0, -60), End: now.AddDate(0, 0, -45)}},
+		},
+		{
+			name:  "unknown log source",
+			query: &types.StructuredQuery{LogSource: "node-logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewSafetyValidatorWithConfig(remediationTestConfig())
+			original := *tt.query
+
+			result, _ := validator.ValidateQuery(tt.query)
+			if result.IsValid {
+				t.Fatal("expected the query to fail validation")
+			}
+
+			repaired, changes := validator.RemediateQuery(tt.query)
+			if !tt.repairs {
+				if len(changes) != 0 || repaired != tt.query {
+					t.Fatalf("expected no repair, got %+v", changes)
+				}
+				return
+			}
+			if len(changes) != 1 || changes[0].Field != tt.field {
+				t.Fatalf("expected one %s change, got %+v", tt.field, changes)
+			}
+			if !tt.check(repaired) {
+				t.Errorf("unexpected repaired query: %+v", repaired)
+			}
+			if result, _ := validator.ValidateQuery(repaired); !result.IsValid {
+				t.Errorf("expected the repaired query to pass, got %v", result.Errors)
+			}
+			if tt.query.Limit != original.Limit || tt.query.Timeframe != original.Timeframe ||
+				tt.query.TimeRange != original.TimeRange || tt.query.UserPattern != original.UserPattern ||
+				len(tt.query.Verb.Values()) != len(original.Verb.Values()) {
+				t.Error("expected the original query to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestSafetyValidator_RemediateQuery_EscapesPatterns(t *testing.T) {
+	// The backslash is allowed, so forbidden characters can be escaped
+	config := remediationTestConfig()
+	config.SafetyRules.Sanitization = map[string]interface{}{
+		"forbidden_chars": []interface{}{"|", "*", ";"},
+	}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"kube|openshift", `kube\|openshift`},
+		{`app\|web*`, `app\|web\*`},
+	}
+	for _, tt := range tests {
+		query := &types.StructuredQuery{LogSource: "kube-apiserver", UserPattern: tt.pattern}
+		if result, _ := validator.ValidateQuery(query); result.IsValid {
+			t.Fatalf("expected %q to fail sanitization", tt.pattern)
+		}
+		repaired, changes := validator.RemediateQuery(query)
+		if len(changes) != 1 || changes[0].Field != "user_pattern" || repaired.UserPattern != tt.want {
+			t.Fatalf("expected %q to be escaped to %q, got %q %+v", tt.pattern, tt.want, repaired.UserPattern, changes)
+		}
+		if result, _ := validator.ValidateQuery(repaired); !result.IsValid {
+			t.Errorf("expected the escaped pattern %q to pass, got %v", repaired.UserPattern, result.Errors)
+		}
+	}
+}
+
+func TestSafetyValidator_RemediateQuery_KeepsPatternsRejected(t *testing.T) {
+	// The default sanitization config forbids regex operators and the backslash
+	config := remediationTestConfig()
+	config.SafetyRules.Sanitization = map[string]interface{}{}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	for _, pattern := range []string{"kube|openshift", "^admin-user$", "app-(a|b)*"} {
+		query := &types.StructuredQuery{LogSource: "kube-apiserver", NamespacePattern: pattern}
+		if result, _ := validator.ValidateQuery(query); result.IsValid {
+			t.Fatalf("expected %q to fail sanitization", pattern)
+		}
+		repaired, changes := validator.RemediateQuery(query)
+		if repaired != query || len(changes) != 0 || query.NamespacePattern != pattern {
+			t.Errorf("expected %q to be left unrepaired, got %+v", pattern, changes)
+		}
+	}
+}
+
+func TestSafetyValidator_RemediateQuery_Disabled(t *testing.T) {
+	config := remediationTestConfig()
+	config.SafetyRules.Remediation.Enabled = false
+	validator := NewSafetyValidatorWithConfig(config)
+
+	query := &types.StructuredQuery{LogSource: "kube-apiserver", Limit: 5000}
+	repaired, changes := validator.RemediateQuery(query)
+	if repaired != query || len(changes) != 0 {
+		t.Errorf("expected no repair with remediation disabled, got %+v", changes)
+	}
+	var _ interfaces.RemediatingSafetyValidator = validator
+}
@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// AnalysisLimitsRule implements the analysis_limits section: the threshold
+// range and the allowed analysis types, time windows and sort options
+type AnalysisLimitsRule struct {
+	minThreshold       int
+	maxThreshold       int
+	hasMinThreshold    bool
+	hasMaxThreshold    bool
+	allowedTypes       []string
+	allowedTimeWindows []string
+	allowedSortFields  []string
+	allowedSortOrders  []string
+	enabled            bool
+}
+
+// NewAnalysisLimitsRule creates a new analysis limits validation rule.
+// Settings that are absent are not enforced.
+func NewAnalysisLimitsRule(config map[string]interface{}) *AnalysisLimitsRule {
+	rule := &AnalysisLimitsRule{
+		allowedTypes:       configStrings(config, "allowed_analysis_types"),
+		allowedTimeWindows: configStrings(config, "allowed_time_windows"),
+		allowedSortFields:  configStrings(config, "allowed_sort_fields"),
+		allowedSortOrders:  configStrings(config, "allowed_sort_orders"),
+		enabled:            true,
+	}
+	rule.minThreshold, rule.hasMinThreshold = configInt(config, "min_threshold_value")
+	rule.maxThreshold, rule.hasMaxThreshold = configInt(config, "max_threshold_value")
+	return rule
+}
+
+// Validate applies analysis limits validation to the query
+func (a *AnalysisLimitsRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:         true,
+		RuleName:        "analysis_limits_validation",
+		Severity:        "medium",
+		Message:         "Analysis limits validation passed",
+		Details:         make(map[string]interface{}),
+		Recommendations: []string{},
+		Warnings:        []string{},
+		Errors:          []string{},
+		QuerySnapshot:   query,
+	}
+
+	if analysis := query.Analysis; analysis != nil {
+		// Validate threshold only if it's explicitly set (non-zero)
+		if analysis.Threshold != 0 {
+			if a.hasMinThreshold && analysis.Threshold < a.minThreshold {
+				result.IsValid = false
+				result.Errors = append(result.Errors,
+					fmt.Sprintf("Analysis threshold %d is below minimum allowed threshold of %d", analysis.Threshold, a.minThreshold))
+			}
+			if a.hasMaxThreshold && analysis.Threshold > a.maxThreshold {
+				result.IsValid = false
+				result.Errors = append(result.Errors,
+					fmt.Sprintf("Analysis threshold %d exceeds maximum allowed threshold of %d", analysis.Threshold, a.maxThreshold))
+			}
+		}
+
+		a.checkAllowed(result, "Analysis type", analysis.Type, a.allowedTypes)
+		a.checkAllowed(result, "Analysis time window", analysis.TimeWindow, a.allowedTimeWindows)
+		a.checkAllowed(result, "Analysis sort field", analysis.SortBy, a.allowedSortFields)
+		a.checkAllowed(result, "Analysis sort order", analysis.SortOrder, a.allowedSortOrders)
+	}
+
+	// Update message based on validation result
+	if !result.IsValid {
+		result.Message = "Analysis limits validation failed"
+		result.Recommendations = append(result.Recommendations,
+			"Use analysis types, time windows and sort options from the configuration")
+		if a.hasMinThreshold && a.hasMaxThreshold {
+			result.Recommendations = append(result.Recommendations,
+				fmt.Sprintf("Use analysis thresholds between %d and %d", a.minThreshold, a.maxThreshold))
+		}
+	}
+
+	return result
+}
+
+// GetRuleName returns the rule name
+func (a *AnalysisLimitsRule) GetRuleName() string {
+	return "analysis_limits_validation"
+}
+
+// GetRuleDescription returns the rule description
+func (a *AnalysisLimitsRule) GetRuleDescription() string {
+	return "Validates analysis thresholds, types, time windows and sort options"
+}
+
+// IsEnabled indicates if the rule is enabled
+func (a *AnalysisLimitsRule) IsEnabled() bool {
+	return a.enabled
+}
+
+// GetSeverity returns the rule severity
+func (a *AnalysisLimitsRule) GetSeverity() string {
+	return "medium"
+}
+
+// checkAllowed rejects a set value missing from a configured allowed list
+func (a *AnalysisLimitsRule) checkAllowed(result *interfaces.ValidationResult, name, value string, allowed []string) {
+	if value == "" || len(allowed) == 0 || contains(allowed, value) {
+		return
+	}
+	result.IsValid = false
+	result.Errors = append(result.Errors,
+		fmt.Sprintf("%s '%s' is not in allowed list: %s", name, value, strings.Join(allowed, ", ")))
+}
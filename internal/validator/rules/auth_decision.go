@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// AuthDecisionRule implements the auth_decisions section: authorization
+// decision filters must be one of the allowed decisions
+type AuthDecisionRule struct {
+	allowedDecisions []string
+	enabled          bool
+}
+
+// NewAuthDecisionRule creates a new authorization decision validation rule.
+// Decisions are compared in lower case, as audit events record them and as
+// the generated filter matches them
+func NewAuthDecisionRule(config map[string]interface{}) *AuthDecisionRule {
+	rule := &AuthDecisionRule{enabled: true}
+	for _, decision := range configStrings(config, "allowed_decisions") {
+		rule.allowedDecisions = append(rule.allowedDecisions, normalizeDecision(decision))
+	}
+	if len(rule.allowedDecisions) == 0 {
+		rule.allowedDecisions = []string{"allow", "error", "forbid"}
+	}
+	return rule
+}
+
+// normalizeDecision returns decision trimmed and in lower case
+func normalizeDecision(decision string) string {
+	return strings.ToLower(strings.TrimSpace(decision))
+}
+
+// Validate applies authorization decision validation to the query
+func (d *AuthDecisionRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:         true,
+		RuleName:        "auth_decision_validation",
+		Severity:        "medium",
+		Message:         "Authorization decision validation passed",
+		Details:         make(map[string]interface{}),
+		Recommendations: []string{},
+		Warnings:        []string{},
+		Errors:          []string{},
+		QuerySnapshot:   query,
+	}
+
+	if query.AuthDecision != "" && !contains(d.allowedDecisions, normalizeDecision(query.AuthDecision)) {
+		result.IsValid = false
+		result.Errors = append(result.Errors,
+			fmt.Sprintf("Authorization decision '%s' is not in allowed list: %s", query.AuthDecision, strings.Join(d.allowedDecisions, ", ")))
+		result.Message = "Authorization decision validation failed"
+		result.Recommendations = append(result.Recommendations,
+			"Use one of the configured authorization decisions")
+	}
+
+	return result
+}
+
+// GetRuleName returns the rule name
+func (d *AuthDecisionRule) GetRuleName() string {
+	return "auth_decision_validation"
+}
+
+// GetRuleDescription returns the rule description
+func (d *AuthDecisionRule) GetRuleDescription() string {
+	return "Validates authorization decision filters against the allowed decisions"
+}
+
+// IsEnabled indicates if the rule is enabled
+func (d *AuthDecisionRule) IsEnabled() bool {
+	return d.enabled
+}
+
+// GetSeverity returns the rule severity
+func (d *AuthDecisionRule) GetSeverity() string {
+	return "medium"
+}
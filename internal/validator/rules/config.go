@@ -0,0 +1,42 @@
+package rules
+
+import "fmt"
+
+// configInt reads an integer setting from a rules.yaml section.
+func configInt(config map[string]interface{}, key string) (int, bool) {
+	switch n := config[key].(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// configStrings reads a list setting from a rules.yaml section. Numbers in
+// the list, such as unquoted status codes, are converted to strings.
+func configStrings(config map[string]interface{}, key string) []string {
+	items, _ := config[key].([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			values = append(values, v)
+		case int, int64, float64:
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	return values
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
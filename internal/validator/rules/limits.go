@@ -0,0 +1,130 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// queryLimitKeys maps the query_limits settings to the fields they bound, in
+// the order fields are checked.
+var queryLimitKeys = []struct {
+	field string
+	key   string
+}{
+	{"verb", "max_verb_array_size"},
+	{"resource", "max_resource_array_size"},
+	{"namespace", "max_namespace_array_size"},
+	{"user", "max_user_array_size"},
+	{"response_status", "max_response_status_array_size"},
+	{"source_ip", "max_source_ip_array_size"},
+	{"exclude_users", "max_exclude_users"},
+	{"exclude_resources", "max_exclude_resources"},
+	{"group_by", "max_group_by_fields"},
+	{"analysis.group_by", "max_group_by_fields"},
+	{"sort_by", "max_sort_fields"},
+}
+
+// QueryLimitsRule implements the query_limits section: the maximum number of
+// values in each multi-valued filter, group-by and sort field
+type QueryLimitsRule struct {
+	maxValues map[string]int
+	enabled   bool
+}
+
+// NewQueryLimitsRule creates a new query limits validation rule. Fields
+// without a configured limit are not bounded.
+func NewQueryLimitsRule(config map[string]interface{}) *QueryLimitsRule {
+	rule := &QueryLimitsRule{
+		maxValues: make(map[string]int),
+		enabled:   true,
+	}
+	for _, limit := range queryLimitKeys {
+		if n, ok := configInt(config, limit.key); ok {
+			rule.maxValues[limit.field] = n
+		}
+	}
+	return rule
+}
+
+// Validate applies query limits validation to the query
+func (q *QueryLimitsRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:         true,
+		RuleName:        "query_limits_validation",
+		Severity:        "medium",
+		Message:         "Query limits validation passed",
+		Details:         make(map[string]interface{}),
+		Recommendations: []string{},
+		Warnings:        []string{},
+		Errors:          []string{},
+		QuerySnapshot:   query,
+	}
+
+	values := q.fieldValues(query)
+	for _, limit := range queryLimitKeys {
+		max, ok := q.maxValues[limit.field]
+		if !ok {
+			continue
+		}
+		if count := len(values[limit.field]); count > max {
+			result.IsValid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Field '%s' has %d values, exceeding the maximum of %d", limit.field, count, max))
+		}
+	}
+
+	// Update message based on validation result
+	if !result.IsValid {
+		result.Message = "Query limits validation failed"
+		result.Recommendations = append(result.Recommendations,
+			"Narrow the query to fewer values per filter",
+			"Split broad queries into several smaller ones")
+	}
+
+	return result
+}
+
+// GetRuleName returns the rule name
+func (q *QueryLimitsRule) GetRuleName() string {
+	return "query_limits_validation"
+}
+
+// GetRuleDescription returns the rule description
+func (q *QueryLimitsRule) GetRuleDescription() string {
+	return "Validates the number of values in multi-valued filters, group-by and sort fields"
+}
+
+// IsEnabled indicates if the rule is enabled
+func (q *QueryLimitsRule) IsEnabled() bool {
+	return q.enabled
+}
+
+// GetSeverity returns the rule severity
+func (q *QueryLimitsRule) GetSeverity() string {
+	return "medium"
+}
+
+// fieldValues returns the values of each bounded field
+func (q *QueryLimitsRule) fieldValues(query *types.StructuredQuery) map[string][]string {
+	values := map[string][]string{
+		"verb":              query.Verb.Values(),
+		"resource":          query.Resource.Values(),
+		"namespace":         query.Namespace.Values(),
+		"user":              query.User.Values(),
+		"response_status":   query.ResponseStatus.Values(),
+		"source_ip":         query.SourceIP.Values(),
+		"exclude_users":     query.ExcludeUsers,
+		"exclude_resources": query.ExcludeResources,
+		"group_by":          query.GroupBy.Values(),
+	}
+	if query.Analysis != nil && query.Analysis.GroupBy != nil {
+		values["analysis.group_by"] = query.Analysis.GroupBy.Values()
+	}
+	if query.SortBy != "" {
+		values["sort_by"] = strings.Split(query.SortBy, ",")
+	}
+	return values
+}
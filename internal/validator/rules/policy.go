@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+
+	"genai-processing/internal/validator/policy"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// DefaultPolicyDirectory is where policies are loaded from when the
+// policies section does not name a directory
+const DefaultPolicyDirectory = "configs/policies"
+
+// PolicyRule implements the policies section: the policy-as-code guardrails
+// of a policy directory. Deny decisions fail the rule and warn decisions add
+// warnings; policies that cannot be evaluated fail closed
+type PolicyRule struct {
+	directory string
+	policies  *policy.Set
+	enabled   bool
+}
+
+// NewPolicyRule creates a policy rule from the policies in the configured
+// directory, overridden by the VALIDATION_POLICY_DIR environment variable.
+// Policies that cannot be loaded are reported in the error and left out
+func NewPolicyRule(config map[string]interface{}) (*PolicyRule, error) {
+	directory := PolicyDirectory(config)
+	policies, err := policy.LoadDir(directory)
+	return NewPolicyRuleWithSet(directory, policies), err
+}
+
+// PolicyDirectory returns the directory policies are loaded from for a
+// policies section: VALIDATION_POLICY_DIR, the section's directory or
+// DefaultPolicyDirectory
+func PolicyDirectory(config map[string]interface{}) string {
+	if env := os.Getenv("VALIDATION_POLICY_DIR"); env != "" {
+		return env
+	}
+	if directory, _ := config["directory"].(string); directory != "" {
+		return directory
+	}
+	return DefaultPolicyDirectory
+}
+
+// NewPolicyRuleWithSet creates a policy rule from compiled policies
+func NewPolicyRuleWithSet(directory string, policies *policy.Set) *PolicyRule {
+	return &PolicyRule{
+		directory: directory,
+		policies:  policies,
+		enabled:   true,
+	}
+}
+
+// Validate applies the policies to a query without a caller or session
+func (p *PolicyRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	return p.ValidateWithContext(query, nil)
+}
+
+// ValidateWithContext applies the policies to a query generated for the
+// caller and session in vctx
+func (p *PolicyRule) ValidateWithContext(query *types.StructuredQuery, vctx *interfaces.ValidationContext) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:         true,
+		RuleName:        "policy_validation",
+		Severity:        "high",
+		Message:         "Policy validation passed",
+		Details:         make(map[string]interface{}),
+		Recommendations: []string{},
+		Warnings:        []string{},
+		Errors:          []string{},
+		QuerySnapshot:   query,
+	}
+
+	evaluation := p.policies.Evaluate(query, vctx)
+	result.Details["policies_evaluated"] = p.policies.Len()
+	result.Details["matched_policies"] = evaluation.Matches
+	result.Details["decision"] = evaluation.Decision()
+
+	for _, err := range evaluation.Errors {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	for _, match := range evaluation.Denied() {
+		result.Errors = append(result.Errors, fmt.Sprintf("Denied by policy '%s': %s", match.Policy, match.Message))
+	}
+	for _, match := range evaluation.Matches {
+		if match.Decision == policy.DecisionWarn {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Policy '%s': %s", match.Policy, match.Message))
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		result.IsValid = false
+		result.Message = "Policy validation failed"
+		result.Recommendations = append(result.Recommendations,
+			"Adjust the query to satisfy the policies in "+p.directory+" or ask an administrator for access")
+	}
+
+	return result
+}
+
+// GetRuleName returns the rule name
+func (p *PolicyRule) GetRuleName() string {
+	return "policy_validation"
+}
+
+// GetRuleDescription returns the rule description
+func (p *PolicyRule) GetRuleDescription() string {
+	return "Evaluates the policy-as-code guardrails of " + p.directory
+}
+
+// IsEnabled indicates if the rule is enabled
+func (p *PolicyRule) IsEnabled() bool {
+	return p.enabled
+}
+
+// GetSeverity returns the rule severity
+func (p *PolicyRule) GetSeverity() string {
+	return "high"
+}
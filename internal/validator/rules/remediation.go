@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// Remediate clamps the limit into the allowed range, replaces a timeframe
+// reaching further back than max_days_back with the longest allowed one and
+// shrinks a custom time range to the last max_days_back days
+func (t *TimeframeRule) Remediate(query *types.StructuredQuery) []interfaces.QueryChange {
+	var changes []interfaces.QueryChange
+
+	if query.Limit != 0 && (query.Limit > t.maxLimit || query.Limit < t.minLimit) {
+		limit, reason := t.maxLimit, fmt.Sprintf("limit exceeds the maximum of %d", t.maxLimit)
+		if query.Limit < t.minLimit {
+			limit, reason = t.minLimit, fmt.Sprintf("limit is below the minimum of %d", t.minLimit)
+		}
+		changes = append(changes, interfaces.QueryChange{Field: "limit", From: query.Limit, To: limit, Reason: reason})
+		query.Limit = limit
+	}
+
+	if query.Timeframe != "" && t.extractDaysFromTimeframe(query.Timeframe) > t.maxDaysBack {
+		if timeframe := t.longestTimeframe(); timeframe != "" {
+			changes = append(changes, interfaces.QueryChange{
+				Field:  "timeframe",
+				From:   query.Timeframe,
+				To:     timeframe,
+				Reason: fmt.Sprintf("timeframe reaches back more than %d days", t.maxDaysBack),
+			})
+			query.Timeframe = timeframe
+		}
+	}
+
+	if query.TimeRange != nil && t.validateTimeRange(query.TimeRange) != nil {
+		if repaired := t.shrinkTimeRange(*query.TimeRange, time.Now()); repaired != nil {
+			changes = append(changes, interfaces.QueryChange{
+				Field:  "time_range",
+				From:   query.TimeRange,
+				To:     repaired,
+				Reason: fmt.Sprintf("time range must lie within the last %d days", t.maxDaysBack),
+			})
+			query.TimeRange = repaired
+		}
+	}
+
+	return changes
+}
+
+// longestTimeframe returns the allowed timeframe reaching back furthest
+// within max_days_back
+func (t *TimeframeRule) longestTimeframe() string {
+	best, bestDays := "", 0
+	for _, timeframe := range t.allowedTimeframes {
+		if days := t.extractDaysFromTimeframe(timeframe); days <= t.maxDaysBack && days > bestDays {
+			best, bestDays = timeframe, days
+		}
+	}
+	return best
+}
+
+// shrinkTimeRange ends a time range no later than now and starts it within
+// max_days_back, or returns nil when no part of the range is allowed. The
+// start is rounded up to the minute so the repaired range still validates
+// a moment later
+func (t *TimeframeRule) shrinkTimeRange(timeRange types.TimeRange, now time.Time) *types.TimeRange {
+	if timeRange.Start.After(timeRange.End) || timeRange.Start.After(now) {
+		return nil
+	}
+	if timeRange.End.After(now) {
+		timeRange.End = now
+	}
+	earliest := now.AddDate(0, 0, -t.maxDaysBack).Truncate(time.Minute).Add(time.Minute)
+	if longest := timeRange.End.Add(-time.Duration(t.maxDaysBack) * 24 * time.Hour).Truncate(time.Minute).Add(time.Minute); longest.After(earliest) {
+		earliest = longest
+	}
+	if timeRange.Start.Before(earliest) {
+		timeRange.Start = earliest
+	}
+	if timeRange.Start.After(timeRange.End) {
+		return nil
+	}
+	return &timeRange
+}
+
+// Remediate drops verbs that are not in the whitelist, as long as at least
+// one allowed verb remains; removing every verb would widen the query to all
+// verbs
+func (w *WhitelistRule) Remediate(query *types.StructuredQuery) []interfaces.QueryChange {
+	verbs := query.Verb.Values()
+	if len(w.allowedVerbs) == 0 || len(verbs) == 0 {
+		return nil
+	}
+	var allowed []string
+	for _, verb := range verbs {
+		if w.isAllowedVerb(verb) {
+			allowed = append(allowed, verb)
+		}
+	}
+	if len(allowed) == len(verbs) || len(allowed) == 0 {
+		return nil
+	}
+	query.Verb = *types.NewStringOrArray(allowed)
+	return []interfaces.QueryChange{{
+		Field:  "verb",
+		From:   verbs,
+		To:     allowed,
+		Reason: "verbs outside the whitelist were dropped",
+	}}
+}
+
+// Remediate escapes forbidden characters in the regex pattern fields with a
+// backslash, so the pattern matches them literally. Patterns are left
+// unrepaired when the backslash is itself forbidden: dropping or replacing
+// the characters would change what the pattern matches
+func (s *SanitizationRule) Remediate(query *types.StructuredQuery) []interfaces.QueryChange {
+	if contains(s.forbiddenChars, `\`) {
+		return nil
+	}
+	var changes []interfaces.QueryChange
+	fields := []struct {
+		name    string
+		pattern *string
+	}{
+		{"resource_name_pattern", &query.ResourceNamePattern},
+		{"user_pattern", &query.UserPattern},
+		{"namespace_pattern", &query.NamespacePattern},
+		{"request_uri_pattern", &query.RequestURIPattern},
+		{"authorization_reason_pattern", &query.AuthorizationReasonPattern},
+		{"response_message_pattern", &query.ResponseMessagePattern},
+	}
+	for _, field := range fields {
+		escaped := s.escape(*field.pattern)
+		if escaped == *field.pattern {
+			continue
+		}
+		changes = append(changes, interfaces.QueryChange{
+			Field:  field.name,
+			From:   *field.pattern,
+			To:     escaped,
+			Reason: "forbidden characters were escaped",
+		})
+		*field.pattern = escaped
+	}
+	return changes
+}
+
+// escape prefixes each forbidden character of a pattern that is not already
+// escaped with a backslash
+func (s *SanitizationRule) escape(pattern string) string {
+	var b strings.Builder
+	for i, r := range pattern {
+		c := string(r)
+		if contains(s.forbiddenChars, c) && !isEscaped(pattern, i) {
+			b.WriteString(`\`)
+		}
+		b.WriteString(c)
+	}
+	return b.String()
+}
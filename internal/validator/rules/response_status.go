@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+
+	"genai-processing/internal/generator"
+	"genai-processing/pkg/interfaces"
+	"genai-processing/pkg/types"
+)
+
+// ResponseStatusRule implements the response_status section: response status
+// filters must be status codes in the configured range, and exact codes must
+// be in the allowed list. Comparisons such as ">=400" and classes such as
+// "5xx" are checked against the range only.
+type ResponseStatusRule struct {
+	allowedStatusCodes []string
+	minStatusCode      int
+	maxStatusCode      int
+	enabled            bool
+}
+
+// NewResponseStatusRule creates a new response status validation rule
+func NewResponseStatusRule(config map[string]interface{}) *ResponseStatusRule {
+	rule := &ResponseStatusRule{
+		allowedStatusCodes: configStrings(config, "allowed_status_codes"),
+		enabled:            true,
+	}
+
+	if minCode, ok := configInt(config, "min_status_code"); ok {
+		rule.minStatusCode = minCode
+	} else {
+		rule.minStatusCode = 100
+	}
+
+	if maxCode, ok := configInt(config, "max_status_code"); ok {
+		rule.maxStatusCode = maxCode
+	} else {
+		rule.maxStatusCode = 599
+	}
+
+	return rule
+}
+
+// Validate applies response status validation to the query
+func (r *ResponseStatusRule) Validate(query *types.StructuredQuery) *interfaces.ValidationResult {
+	result := &interfaces.ValidationResult{
+		IsValid:         true,
+		RuleName:        "response_status_validation",
+		Severity:        "medium",
+		Message:         "Response status validation passed",
+		Details:         make(map[string]interface{}),
+		Recommendations: []string{},
+		Warnings:        []string{},
+		Errors:          []string{},
+		QuerySnapshot:   query,
+	}
+
+	for _, status := range query.ResponseStatus.Values() {
+		matcher, err := generator.ParseStatusMatcher(status)
+		if err != nil {
+			result.IsValid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Response status '%s' is not a status code or status expression", status))
+			continue
+		}
+		low, high := matcher.Code, matcher.Code
+		if matcher.Class > 0 {
+			low, high = matcher.Class*100, matcher.Class*100+99
+		}
+		switch {
+		case high < r.minStatusCode || low > r.maxStatusCode:
+			result.IsValid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Response status '%s' is outside the allowed range %d-%d", status, r.minStatusCode, r.maxStatusCode))
+		case matcher.Class == 0 && matcher.Op == "==" && len(r.allowedStatusCodes) > 0 && !contains(r.allowedStatusCodes, strconv.Itoa(matcher.Code)):
+			result.IsValid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Response status '%s' is not in allowed list", status))
+		}
+	}
+
+	// Update message based on validation result
+	if !result.IsValid {
+		result.Message = "Response status validation failed"
+		result.Recommendations = append(result.Recommendations,
+			"Use HTTP status codes from the configured allowed list")
+	}
+
+	return result
+}
+
+// GetRuleName returns the rule name
+func (r *ResponseStatusRule) GetRuleName() string {
+	return "response_status_validation"
+}
+
+// GetRuleDescription returns the rule description
+func (r *ResponseStatusRule) GetRuleDescription() string {
+	return "Validates response status filters against the allowed HTTP status codes"
+}
+
+// IsEnabled indicates if the rule is enabled
+func (r *ResponseStatusRule) IsEnabled() bool {
+	return r.enabled
+}
+
+// GetSeverity returns the rule severity
+func (r *ResponseStatusRule) GetSeverity() string {
+	return "medium"
+}
@@ -157,10 +157,15 @@ func (s *SanitizationRule) checkForbiddenChars(query *types.StructuredQuery, res
 		query.RequestObjectFilter,
 	}
 
-	for _, pattern := range patterns {
+	// The regex pattern fields may hold forbidden characters escaped as
+	// literals when the backslash itself is allowed
+	regexFields := 6
+	escapable := !contains(s.forbiddenChars, `\`)
+
+	for i, pattern := range patterns {
 		if pattern != "" {
 			for _, forbidden := range s.forbiddenChars {
-				if strings.Contains(pattern, forbidden) {
+				if containsUnescaped(pattern, forbidden, escapable && i < regexFields) {
 					result.IsValid = false
 					result.Errors = append(result.Errors, 
 						fmt.Sprintf("Pattern contains forbidden character '%s': %s", forbidden, pattern))
@@ -195,6 +200,34 @@ func (s *SanitizationRule) checkForbiddenChars(query *types.StructuredQuery, res
 	}
 }
 
+// containsUnescaped reports whether pattern contains forbidden, ignoring
+// occurrences escaped with a backslash when escaped is allowed
+func containsUnescaped(pattern, forbidden string, allowEscaped bool) bool {
+	if !allowEscaped {
+		return strings.Contains(pattern, forbidden)
+	}
+	for offset := 0; ; {
+		i := strings.Index(pattern[offset:], forbidden)
+		if i < 0 {
+			return false
+		}
+		if !isEscaped(pattern, offset+i) {
+			return true
+		}
+		offset += i + len(forbidden)
+	}
+}
+
+// isEscaped reports whether the character at i follows an odd number of
+// backslashes
+func isEscaped(pattern string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && pattern[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
 func (s *SanitizationRule) checkPatternLengths(query *types.StructuredQuery, result *interfaces.ValidationResult) {
 	patterns := map[string]string{
 		"resource_name_pattern": query.ResourceNamePattern,
@@ -1,23 +1,26 @@
 package validator
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
+	"genai-processing/internal/metrics"
 	"genai-processing/internal/validator/rules"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
 )
 
 // SafetyValidator implements the SafetyValidator interface for validating
-// generated queries for safety and feasibility.
+// generated queries for safety and feasibility. It applies the rules of its
+// registry: the built-in rules for each rules.yaml section, site-specific
+// rules built by registered factories and rules added with RegisterRule.
 type SafetyValidator struct {
-	config         *ValidationConfig
-	rules          []interfaces.ValidationRule
-	whitelist      *rules.WhitelistRule
-	sanitization   *rules.SanitizationRule
-	timeframe      *rules.TimeframeRule
-	patterns       *rules.PatternsRule
-	requiredFields *rules.RequiredFieldsRule
+	config       *ValidationConfig
+	registry     *RuleRegistry
+	configErrors []error
 }
 
 // NewSafetyValidator creates a new instance of SafetyValidator.
@@ -50,10 +53,43 @@ func NewSafetyValidator() *SafetyValidator {
 
 		// Add default required fields
 		config.SafetyRules.RequiredFields = []string{"log_source"}
+
+		// Add default query, analysis, response status and decision limits
+		config.SafetyRules.QueryLimits = map[string]interface{}{
+			"max_exclude_users":              50,
+			"max_exclude_resources":          50,
+			"max_group_by_fields":            5,
+			"max_sort_fields":                3,
+			"max_verb_array_size":            10,
+			"max_resource_array_size":        20,
+			"max_namespace_array_size":       50,
+			"max_user_array_size":            100,
+			"max_response_status_array_size": 10,
+			"max_source_ip_array_size":       20,
+		}
+		config.SafetyRules.AnalysisLimits = map[string]interface{}{
+			"max_threshold_value":    10000,
+			"min_threshold_value":    1,
+			"allowed_analysis_types": []interface{}{"multi_namespace_access", "excessive_reads", "privilege_escalation", "anomaly_detection", "correlation"},
+			"allowed_time_windows":   []interface{}{"short", "medium", "long"},
+			"allowed_sort_fields":    []interface{}{"timestamp", "user", "resource", "count"},
+			"allowed_sort_orders":    []interface{}{"asc", "desc"},
+		}
+		config.SafetyRules.ResponseStatus = map[string]interface{}{
+			"allowed_status_codes": []interface{}{"200", "201", "204", "400", "401", "403", "404", "409", "422", "500", "502", "503", "504"},
+			"min_status_code":      100,
+			"max_status_code":      599,
+		}
+		config.SafetyRules.AuthDecisions = map[string]interface{}{
+			"allowed_decisions": []interface{}{"allow", "error", "forbid"},
+		}
+		config.SafetyRules.Environment = os.Getenv("VALIDATION_ENVIRONMENT")
+		config.SafetyRules.Remediation.Enabled = os.Getenv("VALIDATION_REMEDIATION") == "true"
 	}
 
 	validator := &SafetyValidator{
-		config: config,
+		config:   config,
+		registry: NewRuleRegistry(config),
 	}
 
 	// Initialize validation rules
@@ -65,7 +101,8 @@ func NewSafetyValidator() *SafetyValidator {
 // NewSafetyValidatorWithConfig creates a new instance of SafetyValidator with custom configuration.
 func NewSafetyValidatorWithConfig(config *ValidationConfig) *SafetyValidator {
 	validator := &SafetyValidator{
-		config: config,
+		config:   config,
+		registry: NewRuleRegistry(config),
 	}
 
 	// Initialize validation rules
@@ -74,6 +111,31 @@ func NewSafetyValidatorWithConfig(config *ValidationConfig) *SafetyValidator {
 	return validator
 }
 
+// PolicyDirectory returns the directory the policies section loads policies
+// from, or an empty string when the rules file has no policies section.
+func (c *ValidationConfig) PolicyDirectory() string {
+	if c.SafetyRules.Policies == nil {
+		return ""
+	}
+	return rules.PolicyDirectory(c.SafetyRules.Policies)
+}
+
+// LoadSafetyValidator creates a SafetyValidator from the rules file at
+// configPath. Unlike NewSafetyValidator it does not fall back to the built-in
+// rules: a file that cannot be loaded, or whose rules section has problems,
+// is an error, so configuration reloads can keep the rules they would replace.
+func LoadSafetyValidator(configPath string) (*SafetyValidator, error) {
+	config, err := LoadValidationConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	validator := NewSafetyValidatorWithConfig(config)
+	if len(validator.configErrors) > 0 {
+		return nil, errors.Join(validator.configErrors...)
+	}
+	return validator, nil
+}
+
 // ValidateQuery validates a structured query for safety and feasibility.
 // This implementation applies comprehensive validation rules including:
 // - Whitelist validation for log sources, verbs, and resources
@@ -82,8 +144,16 @@ func NewSafetyValidatorWithConfig(config *ValidationConfig) *SafetyValidator {
 // - Forbidden patterns and commands
 // - Query limits and business rules
 func (sv *SafetyValidator) ValidateQuery(query *types.StructuredQuery) (*interfaces.ValidationResult, error) {
+	return sv.ValidateQueryWithContext(query, nil)
+}
+
+// ValidateQueryWithContext validates a structured query generated for the
+// caller and session in vctx. Rules implementing
+// interfaces.ContextualValidationRule, such as the policy rule, see vctx.
+func (sv *SafetyValidator) ValidateQueryWithContext(query *types.StructuredQuery, vctx *interfaces.ValidationContext) (*interfaces.ValidationResult, error) {
 	// Handle nil query
 	if query == nil {
+		metrics.ValidationFailures.With("null_query_validation").Inc()
 		return &interfaces.ValidationResult{
 			IsValid:  false,
 			RuleName: "null_query_validation",
@@ -116,86 +186,44 @@ func (sv *SafetyValidator) ValidateQuery(query *types.StructuredQuery) (*interfa
 		QuerySnapshot:   query,
 	}
 
-	// Apply all validation rules
+	// Apply the active rules in order. Failing advisory rules only warn.
 	ruleResults := make(map[string]*interfaces.ValidationResult)
-
-	// Apply whitelist validation
-	if sv.whitelist != nil && sv.whitelist.IsEnabled() {
-		result := sv.whitelist.Validate(query)
-		ruleResults["whitelist"] = result
-		if !result.IsValid {
-			combinedResult.IsValid = false
-			combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
+	severity := ""
+	for _, registered := range sv.registry.Active() {
+		var result *interfaces.ValidationResult
+		if contextual, ok := registered.Rule.(interfaces.ContextualValidationRule); ok {
+			result = contextual.ValidateWithContext(query, vctx)
+		} else {
+			result = registered.Rule.Validate(query)
 		}
-		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
-	}
-
-	// Apply sanitization validation
-	if sv.sanitization != nil && sv.sanitization.IsEnabled() {
-		result := sv.sanitization.Validate(query)
-		ruleResults["sanitization"] = result
-		if !result.IsValid {
-			combinedResult.IsValid = false
-			combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
-		}
-		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
-	}
-
-	// Apply timeframe validation
-	if sv.timeframe != nil && sv.timeframe.IsEnabled() {
-		result := sv.timeframe.Validate(query)
-		ruleResults["timeframe"] = result
-		if !result.IsValid {
-			combinedResult.IsValid = false
-			combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
-		}
-		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
-	}
-
-	// Apply patterns validation
-	if sv.patterns != nil && sv.patterns.IsEnabled() {
-		result := sv.patterns.Validate(query)
-		ruleResults["patterns"] = result
-		if !result.IsValid {
-			combinedResult.IsValid = false
-			combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
+		if result == nil {
+			continue
 		}
-		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
-	}
-
-	// Apply required fields validation
-	if sv.requiredFields != nil && sv.requiredFields.IsEnabled() {
-		result := sv.requiredFields.Validate(query)
-		ruleResults["required_fields"] = result
+		result.Severity = registered.Severity
+		ruleResults[registered.Name] = result
 		if !result.IsValid {
-			combinedResult.IsValid = false
-			combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
-		}
-		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
-	}
-
-	// Apply additional validation rules
-	for _, rule := range sv.rules {
-		if rule.IsEnabled() {
-			result := rule.Validate(query)
-			ruleResults[rule.GetRuleName()] = result
-			if !result.IsValid {
+			metrics.ValidationFailures.With(registered.Name).Inc()
+			if registered.Mode == ModeAdvisory {
+				combinedResult.Warnings = append(combinedResult.Warnings, result.Errors...)
+			} else {
 				combinedResult.IsValid = false
 				combinedResult.Errors = append(combinedResult.Errors, result.Errors...)
+				if severityRank[registered.Severity] > severityRank[severity] {
+					severity = registered.Severity
+				}
 			}
-			combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
-			combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
 		}
+		combinedResult.Warnings = append(combinedResult.Warnings, result.Warnings...)
+		combinedResult.Recommendations = append(combinedResult.Recommendations, result.Recommendations...)
 	}
 
-	// Update severity based on validation results
+	// Update severity based on validation results: the most severe failed
+	// blocking rule
 	if !combinedResult.IsValid {
-		combinedResult.Severity = "critical"
+		combinedResult.Severity = severity
+		if combinedResult.Severity == "" {
+			combinedResult.Severity = "critical"
+		}
 		combinedResult.Message = "Query validation failed"
 	} else if len(combinedResult.Warnings) > 0 {
 		combinedResult.Severity = "warning"
@@ -212,89 +240,133 @@ func (sv *SafetyValidator) ValidateQuery(query *types.StructuredQuery) (*interfa
 
 // GetApplicableRules returns all validation rules that are currently active.
 func (sv *SafetyValidator) GetApplicableRules() []interfaces.ValidationRule {
-	var activeRules []interfaces.ValidationRule
-
-	// Add core rules
-	if sv.whitelist != nil && sv.whitelist.IsEnabled() {
-		activeRules = append(activeRules, sv.whitelist)
-	}
-	if sv.sanitization != nil && sv.sanitization.IsEnabled() {
-		activeRules = append(activeRules, sv.sanitization)
-	}
-	if sv.timeframe != nil && sv.timeframe.IsEnabled() {
-		activeRules = append(activeRules, sv.timeframe)
-	}
-	if sv.patterns != nil && sv.patterns.IsEnabled() {
-		activeRules = append(activeRules, sv.patterns)
-	}
-	if sv.requiredFields != nil && sv.requiredFields.IsEnabled() {
-		activeRules = append(activeRules, sv.requiredFields)
+	activeRules := []interfaces.ValidationRule{}
+	for _, registered := range sv.registry.Active() {
+		activeRules = append(activeRules, registered.Rule)
 	}
+	return activeRules
+}
 
-	// Add additional rules
-	for _, rule := range sv.rules {
-		if rule.IsEnabled() {
-			activeRules = append(activeRules, rule)
-		}
-	}
+// RegisterRule adds a rule to the validator under a unique name and
+// category, applying its settings from the rules section of rules.yaml.
+func (sv *SafetyValidator) RegisterRule(name, category string, rule interfaces.ValidationRule) error {
+	return sv.registry.Register(name, category, rule)
+}
 
-	return activeRules
+// Registry returns the validator's rule registry.
+func (sv *SafetyValidator) Registry() *RuleRegistry {
+	return sv.registry
 }
 
-// initializeRules initializes all validation rules from configuration
+// ConfigErrors returns the problems found in the rules section of rules.yaml
+// while building the validator: unknown rules, invalid settings and failing
+// rule factories. The affected rules keep their defaults or are skipped.
+func (sv *SafetyValidator) ConfigErrors() []error {
+	return sv.configErrors
+}
+
+// initializeRules registers the built-in rule of each configured rules.yaml
+// section, then the site-specific rules configured in the rules section.
 func (sv *SafetyValidator) initializeRules() {
-	// Initialize whitelist rule
-	if len(sv.config.SafetyRules.AllowedLogSources) > 0 ||
-		len(sv.config.SafetyRules.AllowedVerbs) > 0 ||
-		len(sv.config.SafetyRules.AllowedResources) > 0 {
-		sv.whitelist = rules.NewWhitelistRule(
-			sv.config.SafetyRules.AllowedLogSources,
-			sv.config.SafetyRules.AllowedVerbs,
-			sv.config.SafetyRules.AllowedResources,
-		)
+	safetyRules := sv.config.SafetyRules
+
+	if len(safetyRules.AllowedLogSources) > 0 ||
+		len(safetyRules.AllowedVerbs) > 0 ||
+		len(safetyRules.AllowedResources) > 0 {
+		sv.register("whitelist", "whitelist", rules.NewWhitelistRule(
+			safetyRules.AllowedLogSources,
+			safetyRules.AllowedVerbs,
+			safetyRules.AllowedResources,
+		))
 	}
-
-	// Initialize sanitization rule
-	if sv.config.SafetyRules.Sanitization != nil {
-		sv.sanitization = rules.NewSanitizationRule(sv.config.SafetyRules.Sanitization)
+	if safetyRules.Sanitization != nil {
+		sv.register("sanitization", "sanitization", rules.NewSanitizationRule(safetyRules.Sanitization))
 	}
-
-	// Initialize timeframe rule
-	if sv.config.SafetyRules.TimeframeLimits != nil {
-		sv.timeframe = rules.NewTimeframeRule(sv.config.SafetyRules.TimeframeLimits)
+	if safetyRules.TimeframeLimits != nil {
+		sv.register("timeframe", "timeframe", rules.NewTimeframeRule(safetyRules.TimeframeLimits))
 	}
-
-	// Initialize patterns rule
-	if len(sv.config.SafetyRules.ForbiddenPatterns) > 0 {
-		sv.patterns = rules.NewPatternsRule(sv.config.SafetyRules.ForbiddenPatterns)
+	if len(safetyRules.ForbiddenPatterns) > 0 {
+		sv.register("patterns", "patterns", rules.NewPatternsRule(safetyRules.ForbiddenPatterns))
 	}
-
-	// Initialize required fields rule
-	if len(sv.config.SafetyRules.RequiredFields) > 0 {
-		sv.requiredFields = rules.NewRequiredFieldsRule(sv.config.SafetyRules.RequiredFields)
+	if len(safetyRules.RequiredFields) > 0 {
+		sv.register("required_fields", "business_rules", rules.NewRequiredFieldsRule(safetyRules.RequiredFields))
+	}
+	if safetyRules.QueryLimits != nil {
+		sv.register("query_limits", "limits", rules.NewQueryLimitsRule(safetyRules.QueryLimits))
+	}
+	if safetyRules.AnalysisLimits != nil {
+		sv.register("analysis_limits", "limits", rules.NewAnalysisLimitsRule(safetyRules.AnalysisLimits))
+	}
+	if safetyRules.ResponseStatus != nil {
+		sv.register("response_status", "business_rules", rules.NewResponseStatusRule(safetyRules.ResponseStatus))
+	}
+	if safetyRules.AuthDecisions != nil {
+		sv.register("auth_decisions", "business_rules", rules.NewAuthDecisionRule(safetyRules.AuthDecisions))
+	}
+	if safetyRules.Policies != nil {
+		rule, err := rules.NewPolicyRule(safetyRules.Policies)
+		if err != nil {
+			sv.configErrors = append(sv.configErrors, err)
+		}
+		sv.register("policies", "policy", rule)
 	}
 
-	// Initialize additional rules based on configuration
 	sv.initializeAdditionalRules()
 }
 
-// initializeAdditionalRules initializes additional validation rules
+// initializeAdditionalRules builds the site-specific rules configured in the
+// rules section from the factories registered with RegisterRuleFactory.
+// Settings for names that are neither built-in nor registered are reported.
 func (sv *SafetyValidator) initializeAdditionalRules() {
-	// This method can be extended to add more validation rules
-	// based on the configuration or specific requirements
+	names := make([]string, 0, len(sv.config.SafetyRules.Rules))
+	for name := range sv.config.SafetyRules.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if sv.registry.Rule(name) != nil {
+			continue
+		}
+		factory, ok := lookupRuleFactory(name)
+		if !ok {
+			sv.configErrors = append(sv.configErrors, fmt.Errorf("validation rule '%s' is configured but not registered", name))
+			continue
+		}
+		rule, err := factory.build(sv.config.SafetyRules.Rules[name].Config)
+		if err != nil {
+			sv.configErrors = append(sv.configErrors, fmt.Errorf("failed to build validation rule '%s': %w", name, err))
+			continue
+		}
+		sv.register(name, factory.category, rule)
+	}
+}
+
+// register adds a rule to the registry, recording invalid settings.
+func (sv *SafetyValidator) register(name, category string, rule interfaces.ValidationRule) {
+	if err := sv.registry.Register(name, category, rule); err != nil {
+		sv.configErrors = append(sv.configErrors, err)
+	}
 }
 
 // GetValidationStats returns statistics about validation operations
 func (sv *SafetyValidator) GetValidationStats() map[string]interface{} {
 	stats := make(map[string]interface{})
 
-	activeRules := sv.GetApplicableRules()
+	activeRules := sv.registry.Active()
 	stats["total_active_rules"] = len(activeRules)
-	stats["whitelist_enabled"] = sv.whitelist != nil && sv.whitelist.IsEnabled()
-	stats["sanitization_enabled"] = sv.sanitization != nil && sv.sanitization.IsEnabled()
-	stats["timeframe_enabled"] = sv.timeframe != nil && sv.timeframe.IsEnabled()
-	stats["patterns_enabled"] = sv.patterns != nil && sv.patterns.IsEnabled()
-	stats["required_fields_enabled"] = sv.requiredFields != nil && sv.requiredFields.IsEnabled()
+	for _, name := range []string{"whitelist", "sanitization", "timeframe", "patterns", "required_fields"} {
+		stats[name+"_enabled"] = false
+	}
+	for _, registered := range activeRules {
+		stats[registered.Name+"_enabled"] = true
+	}
 
 	return stats
 }
+
+// GetConfig returns the validation configuration the rules were built from,
+// which is the built-in default when rules.yaml could not be loaded.
+func (sv *SafetyValidator) GetConfig() *ValidationConfig {
+	return sv.config
+}
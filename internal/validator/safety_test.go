@@ -1,8 +1,13 @@
 package validator
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"genai-processing/internal/metrics"
 	"genai-processing/pkg/interfaces"
 	"genai-processing/pkg/types"
 )
@@ -11,6 +16,7 @@ import (
 // properly implements the SafetyValidator interface.
 func TestSafetyValidator_InterfaceCompliance(t *testing.T) {
 	var _ interfaces.SafetyValidator = (*SafetyValidator)(nil)
+	var _ interfaces.ContextualSafetyValidator = (*SafetyValidator)(nil)
 }
 
 // TestNewSafetyValidator tests the constructor function.
@@ -22,6 +28,42 @@ func TestNewSafetyValidator(t *testing.T) {
 	}
 }
 
+// TestLoadSafetyValidator tests loading rules without the built-in fallback.
+func TestLoadSafetyValidator(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "valid", rules: "safety_rules:\n  allowed_log_sources: [\"kube-apiserver\"]\n"},
+		{name: "missing file", wantErr: "failed to read"},
+		{name: "invalid YAML", rules: "safety_rules: [", wantErr: "failed to parse"},
+		{name: "unregistered rule", rules: "safety_rules:\n  rules:\n    no_such_rule: {}\n", wantErr: "no_such_rule"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("rules-%d.yaml", i))
+			if tt.rules != "" {
+				if err := os.WriteFile(path, []byte(tt.rules), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			validator, err := LoadSafetyValidator(path)
+			if tt.wantErr == "" {
+				if err != nil || validator == nil {
+					t.Fatalf("LoadSafetyValidator returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 // TestSafetyValidator_ValidateQuery tests the ValidateQuery method
 // with various input scenarios.
 func TestSafetyValidator_ValidateQuery(t *testing.T) {
@@ -346,6 +388,247 @@ func TestSafetyValidator_WhitelistValidation(t *testing.T) {
 	}
 }
 
+// TestSafetyValidator_CountsFailuresByRule tests that failed rules are counted
+// in the validation failure metrics under their rule name
+func TestSafetyValidator_CountsFailuresByRule(t *testing.T) {
+	validator := NewSafetyValidator()
+	before := metrics.ValidationFailures.With("whitelist").Value()
+
+	result, err := validator.ValidateQuery(&types.StructuredQuery{LogSource: "invalid-source"})
+	if err != nil {
+		t.Fatalf("ValidateQuery returned error: %v", err)
+	}
+	if result.IsValid {
+		t.Fatal("expected the query to be rejected")
+	}
+	if got := metrics.ValidationFailures.With("whitelist").Value() - before; got != 1 {
+		t.Errorf("expected 1 whitelist failure to be counted, got %v", got)
+	}
+}
+
+// TestSafetyValidator_LimitsValidation tests the query_limits, analysis_limits,
+// response_status and auth_decisions rules
+func TestSafetyValidator_LimitsValidation(t *testing.T) {
+	config := &ValidationConfig{}
+	config.SafetyRules.QueryLimits = map[string]interface{}{
+		"max_verb_array_size": 2,
+		"max_group_by_fields": 1,
+		"max_exclude_users":   1,
+	}
+	config.SafetyRules.AnalysisLimits = map[string]interface{}{
+		"min_threshold_value":    1,
+		"max_threshold_value":    100,
+		"allowed_analysis_types": []interface{}{"excessive_reads"},
+	}
+	config.SafetyRules.ResponseStatus = map[string]interface{}{
+		"allowed_status_codes": []interface{}{"200", "403"},
+		"min_status_code":      100,
+		"max_status_code":      599,
+	}
+	config.SafetyRules.AuthDecisions = map[string]interface{}{
+		"allowed_decisions": []interface{}{"allow", "Forbid"},
+	}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	groupBy := types.NewStringOrArray([]string{"user", "namespace"})
+	tests := []struct {
+		name        string
+		query       *types.StructuredQuery
+		expectValid bool
+		failedRule  string
+	}{
+		{
+			name: "within limits",
+			query: &types.StructuredQuery{
+				LogSource:      "kube-apiserver",
+				Verb:           *types.NewStringOrArray([]string{"get", "list"}),
+				ResponseStatus: *types.NewStringOrArray("403"),
+				AuthDecision:   "forbid",
+				Analysis:       &types.AnalysisConfig{Type: "excessive_reads", Threshold: 50},
+			},
+			expectValid: true,
+		},
+		{
+			name:        "oversized verb filter",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", Verb: *types.NewStringOrArray([]string{"get", "list", "watch"})},
+			failedRule:  "query_limits",
+			expectValid: false,
+		},
+		{
+			name:        "too many excluded users",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", ExcludeUsers: []string{"system:a", "system:b"}},
+			failedRule:  "query_limits",
+			expectValid: false,
+		},
+		{
+			name:        "too many analysis group-by fields",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "excessive_reads", GroupBy: groupBy}},
+			failedRule:  "query_limits",
+			expectValid: false,
+		},
+		{
+			name:        "threshold above maximum",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "excessive_reads", Threshold: 101}},
+			failedRule:  "analysis_limits",
+			expectValid: false,
+		},
+		{
+			name:        "threshold below minimum",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "excessive_reads", Threshold: -5}},
+			failedRule:  "analysis_limits",
+			expectValid: false,
+		},
+		{
+			name:        "unknown analysis type",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "correlation"}},
+			failedRule:  "analysis_limits",
+			expectValid: false,
+		},
+		{
+			name:        "status code not allowed",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", ResponseStatus: *types.NewStringOrArray("404")},
+			failedRule:  "response_status",
+			expectValid: false,
+		},
+		{
+			name:        "status expressions",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", ResponseStatus: *types.NewStringOrArray([]string{">=400", "5xx", "==200"})},
+			expectValid: true,
+		},
+		{
+			name:        "status code out of range",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", ResponseStatus: *types.NewStringOrArray("999")},
+			failedRule:  "response_status",
+			expectValid: false,
+		},
+		{
+			name:        "non-numeric status code",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", ResponseStatus: *types.NewStringOrArray("ok")},
+			failedRule:  "response_status",
+			expectValid: false,
+		},
+		{
+			name:        "unknown auth decision",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", AuthDecision: "error"},
+			failedRule:  "auth_decisions",
+			expectValid: false,
+		},
+		{
+			name:        "auth decision in another case",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", AuthDecision: "ALLOW"},
+			expectValid: true,
+		},
+		{
+			name:        "unknown auth decision in another case",
+			query:       &types.StructuredQuery{LogSource: "kube-apiserver", AuthDecision: "Error"},
+			failedRule:  "auth_decisions",
+			expectValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ValidateQuery returned error: %v", err)
+			}
+			if result.IsValid != tt.expectValid {
+				t.Fatalf("Expected IsValid to be %v, got %v (errors: %v)", tt.expectValid, result.IsValid, result.Errors)
+			}
+			if tt.failedRule != "" {
+				ruleResults := result.Details["rule_results"].(map[string]*interfaces.ValidationResult)
+				if rule, ok := ruleResults[tt.failedRule]; !ok || rule.IsValid {
+					t.Errorf("Expected rule %s to fail", tt.failedRule)
+				}
+			}
+		})
+	}
+}
+
+// TestSafetyValidator_RuleCategories tests that rule_categories selects the
+// limit and business rules that are applied
+func TestSafetyValidator_RuleCategories(t *testing.T) {
+	config := &ValidationConfig{}
+	config.SafetyRules.QueryLimits = map[string]interface{}{"max_verb_array_size": 1}
+	config.SafetyRules.AuthDecisions = map[string]interface{}{"allowed_decisions": []interface{}{"allow"}}
+	config.SafetyRules.RuleCategories = []string{"business_rules"}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	names := map[string]bool{}
+	for _, registered := range validator.Registry().Active() {
+		names[registered.Name] = true
+	}
+	if names["query_limits"] {
+		t.Error("Expected the query limits rule to be skipped without the limits category")
+	}
+	if !names["auth_decisions"] {
+		t.Error("Expected the auth decision rule with the business_rules category")
+	}
+}
+
+// TestSafetyValidator_PolicyValidation tests that policy decisions see the
+// caller and merge into the validation result
+func TestSafetyValidator_PolicyValidation(t *testing.T) {
+	dir := t.TempDir()
+	policies := `
+policies:
+  - name: privilege-escalation-security-team-only
+    condition: has(query.analysis) && query.analysis.type == "privilege_escalation" && !("security-team" in caller.groups)
+    decision: deny
+    message: Privilege escalation analysis is restricted to the security team
+  - name: secrets-review
+    condition: '"secrets" in query.resource'
+    decision: warn
+    message: Secrets access is reviewed
+`
+	if err := os.WriteFile(filepath.Join(dir, "security.yaml"), []byte(policies), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := &ValidationConfig{}
+	config.SafetyRules.AllowedLogSources = []string{"kube-apiserver"}
+	config.SafetyRules.AllowedResources = []string{"pods", "secrets"}
+	config.SafetyRules.Policies = map[string]interface{}{"directory": dir}
+	validator := NewSafetyValidatorWithConfig(config)
+	if errs := validator.ConfigErrors(); len(errs) > 0 {
+		t.Fatalf("unexpected config errors: %v", errs)
+	}
+
+	escalation := &types.StructuredQuery{LogSource: "kube-apiserver", Analysis: &types.AnalysisConfig{Type: "privilege_escalation"}}
+
+	result, err := validator.ValidateQueryWithContext(escalation, &interfaces.ValidationContext{UserID: "alice", Groups: []string{"developers"}})
+	if err != nil {
+		t.Fatalf("ValidateQueryWithContext returned error: %v", err)
+	}
+	if result.IsValid || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "restricted to the security team") {
+		t.Fatalf("expected the policy to deny the query, got valid=%v errors=%v", result.IsValid, result.Errors)
+	}
+	if _, ok := result.Details["rule_results"].(map[string]*interfaces.ValidationResult)["policies"]; !ok {
+		t.Error("expected the policy result under rule_results")
+	}
+
+	result, _ = validator.ValidateQueryWithContext(escalation, &interfaces.ValidationContext{UserID: "bob", Groups: []string{"security-team"}})
+	if !result.IsValid {
+		t.Errorf("expected the security team to pass, got %v", result.Errors)
+	}
+
+	result, _ = validator.ValidateQuery(&types.StructuredQuery{LogSource: "kube-apiserver", Resource: *types.NewStringOrArray("secrets")})
+	if !result.IsValid || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Secrets access is reviewed") {
+		t.Errorf("expected a policy warning, got valid=%v warnings=%v errors=%v", result.IsValid, result.Warnings, result.Errors)
+	}
+}
+
+// TestSafetyValidator_PolicyConfigErrors tests that an unreadable policy
+// directory is reported
+func TestSafetyValidator_PolicyConfigErrors(t *testing.T) {
+	config := &ValidationConfig{}
+	config.SafetyRules.Policies = map[string]interface{}{"directory": filepath.Join(t.TempDir(), "missing")}
+	validator := NewSafetyValidatorWithConfig(config)
+
+	if errs := validator.ConfigErrors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "policy directory") {
+		t.Errorf("expected a policy directory error, got %v", errs)
+	}
+}
+
 // TestSafetyValidator_TimeframeValidation tests timeframe validation specifically
 func TestSafetyValidator_TimeframeValidation(t *testing.T) {
 	validator := NewSafetyValidator()
@@ -94,3 +94,23 @@ type LLMProvider interface {
 	//   - error: Any error that occurred during connection validation
 	ValidateConnection() error
 }
+
+// StreamingProvider is implemented by LLM providers that can stream their
+// responses. Callers check SupportsStreaming and type-assert to this interface
+// before using it, falling back to GenerateResponse otherwise.
+type StreamingProvider interface {
+	LLMProvider
+
+	// GenerateResponseStream sends a request to the LLM provider and streams the
+	// generated text as it arrives.
+	//
+	// Parameters:
+	//   - ctx: Context for request cancellation and timeout management
+	//   - request: The model-specific request to send to the provider
+	//   - onChunk: Called with each piece of generated text, in order
+	//
+	// Returns:
+	//   - RawResponse: The complete response, as GenerateResponse would return it
+	//   - error: Any error that occurred during the API call or the stream
+	GenerateResponseStream(ctx context.Context, request *types.ModelRequest, onChunk func(chunk string)) (*types.RawResponse, error)
+}
@@ -29,6 +29,105 @@ type SafetyValidator interface {
 	GetApplicableRules() []ValidationRule
 }
 
+// ContextualSafetyValidator is a SafetyValidator that can also take the
+// caller and session a query is generated for into account.
+type ContextualSafetyValidator interface {
+	SafetyValidator
+
+	// ValidateQueryWithContext validates a structured query like ValidateQuery,
+	// passing the validation context to the rules that use it.
+	//
+	// Parameters:
+	//   - query: The structured query to validate
+	//   - vctx: The caller and session the query is validated for
+	//
+	// Returns:
+	//   - ValidationResult: The validation outcome with details and recommendations
+	//   - error: Any error that occurred during validation
+	ValidateQueryWithContext(query *types.StructuredQuery, vctx *ValidationContext) (*ValidationResult, error)
+}
+
+// ValidationContext describes the request a query is validated for.
+// Rules that depend on who is asking, such as policy guardrails, use it.
+type ValidationContext struct {
+	// UserID is the authenticated caller, empty for anonymous requests.
+	UserID string `json:"user_id,omitempty"`
+
+	// Groups are the groups the caller belongs to.
+	Groups []string `json:"groups,omitempty"`
+
+	// Session is the caller's conversation session, if any.
+	Session *types.ConversationContext `json:"session,omitempty"`
+}
+
+// ContextualValidationRule is implemented by validation rules that also
+// consider the caller and session a query is validated for. Validators
+// call ValidateWithContext instead of Validate when they have a context.
+type ContextualValidationRule interface {
+	ValidationRule
+
+	// ValidateWithContext applies the validation rule to a structured query
+	// generated for the caller and session in vctx.
+	//
+	// Parameters:
+	//   - query: The structured query to validate
+	//   - vctx: The caller and session the query is validated for
+	//
+	// Returns:
+	//   - ValidationResult: The validation outcome for this specific rule
+	ValidateWithContext(query *types.StructuredQuery, vctx *ValidationContext) *ValidationResult
+}
+
+// RemediatingSafetyValidator is a SafetyValidator that can repair queries it
+// rejects with safe, deterministic fixes instead of only describing them.
+type RemediatingSafetyValidator interface {
+	SafetyValidator
+
+	// RemediateQuery returns a repaired copy of a query and the changes made.
+	// The query is returned unchanged, with no changes, when nothing could be
+	// repaired or remediation is disabled. Callers re-validate the copy.
+	//
+	// Parameters:
+	//   - query: The structured query that failed validation
+	//
+	// Returns:
+	//   - StructuredQuery: The repaired copy, or query when nothing changed
+	//   - []QueryChange: The changes made, in rule order
+	RemediateQuery(query *types.StructuredQuery) (*types.StructuredQuery, []QueryChange)
+}
+
+// RemediableValidationRule is implemented by validation rules that can
+// repair the problems they report, such as clamping an out-of-range limit.
+type RemediableValidationRule interface {
+	ValidationRule
+
+	// Remediate repairs a copy of the query in place and returns the changes
+	// made. Fields holding slices or pointers must be replaced, not modified,
+	// since they are shared with the original query.
+	//
+	// Parameters:
+	//   - query: A shallow copy of the structured query to repair
+	//
+	// Returns:
+	//   - []QueryChange: The changes made, none when nothing needed repair
+	Remediate(query *types.StructuredQuery) []QueryChange
+}
+
+// QueryChange describes one repair made to a query by remediation.
+type QueryChange struct {
+	// Field is the JSON name of the repaired query field.
+	Field string `json:"field"`
+
+	// From is the field's value before the repair.
+	From interface{} `json:"from"`
+
+	// To is the field's value after the repair.
+	To interface{} `json:"to"`
+
+	// Reason explains why the value was changed.
+	Reason string `json:"reason"`
+}
+
 // ValidationRule defines the interface for individual validation rules.
 // This interface allows for the implementation of specific validation logic
 // for different aspects of query safety and feasibility.
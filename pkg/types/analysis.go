@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+// AggregateGroup is one group_by bucket produced by aggregating audit events.
+type AggregateGroup struct {
+	// Key maps each canonical group_by field to its value for this group
+	Key map[string]string `json:"key"`
+
+	// Count is the number of events in the group
+	Count int `json:"count"`
+
+	// FirstSeen is the earliest request timestamp in the group
+	FirstSeen time.Time `json:"first_seen"`
+
+	// LastSeen is the latest request timestamp in the group
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// AnalysisFinding is a threshold-triggered result of an AnalysisConfig evaluation,
+// such as a user reading more secrets than allowed within the analysis time window.
+type AnalysisFinding struct {
+	// Type is the analysis type that produced the finding
+	Type string `json:"type"`
+
+	// Severity is one of the severity levels configured in rules.yaml
+	Severity string `json:"severity"`
+
+	// Subject identifies who or what triggered the finding (by default the user)
+	Subject map[string]string `json:"subject"`
+
+	// Count is the measured value compared against the threshold
+	Count int `json:"count"`
+
+	// Threshold is the threshold the count reached
+	Threshold int `json:"threshold"`
+
+	// Description explains the finding in plain English
+	Description string `json:"description"`
+
+	// WindowStart is the start of the time window in which the threshold was reached
+	WindowStart time.Time `json:"window_start"`
+
+	// WindowEnd is the end of the time window in which the threshold was reached
+	WindowEnd time.Time `json:"window_end"`
+
+	// Values lists the distinct values behind the count, such as namespaces accessed
+	Values []string `json:"values,omitempty"`
+
+	// AuditIDs references the audit events that contributed to the finding
+	AuditIDs []string `json:"audit_ids,omitempty"`
+}
+
+// AnalysisResult contains the aggregation and analysis output for a set of audit events.
+type AnalysisResult struct {
+	// GroupBy lists the canonical fields the groups are keyed by
+	GroupBy []string `json:"group_by,omitempty"`
+
+	// Groups contains the top-N groups after sorting and limiting
+	Groups []AggregateGroup `json:"groups,omitempty"`
+
+	// TotalGroups is the number of groups before the limit was applied
+	TotalGroups int `json:"total_groups,omitempty"`
+
+	// AnalysisType is the AnalysisConfig type that was evaluated, if any
+	AnalysisType string `json:"analysis_type,omitempty"`
+
+	// Findings contains threshold-triggered findings for the analysis type
+	Findings []AnalysisFinding `json:"findings,omitempty"`
+
+	// EventsAnalyzed is the number of events fed into the analysis
+	EventsAnalyzed int `json:"events_analyzed"`
+}
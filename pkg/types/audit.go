@@ -45,6 +45,27 @@ func (sa *StringOrArray) GetArray() []string {
 	return nil
 }
 
+// Values returns the non-empty, trimmed entries regardless of whether the
+// underlying value is a single string or an array. Returns nil when empty.
+func (sa *StringOrArray) Values() []string {
+	if sa == nil {
+		return nil
+	}
+	var raw []string
+	if str, ok := sa.value.(string); ok {
+		raw = []string{str}
+	} else if arr, ok := sa.value.([]string); ok {
+		raw = arr
+	}
+	var out []string
+	for _, s := range raw {
+		if v := strings.TrimSpace(s); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // GetValue returns the underlying value
 func (sa *StringOrArray) GetValue() interface{} {
 	return sa.value
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// AuditEvent represents a single Kubernetes/OpenShift API server audit event as
+// written to audit.log (audit.k8s.io/v1 Event). Only the fields used for
+// query evaluation are modelled; request and response bodies are kept raw.
+type AuditEvent struct {
+	// Level is the audit level at which the event was generated
+	Level string `json:"level,omitempty"`
+
+	// AuditID is the unique audit ID generated for each request
+	AuditID string `json:"auditID,omitempty"`
+
+	// Stage is the request handling stage at which the event was generated
+	Stage string `json:"stage,omitempty"`
+
+	// RequestURI is the request URI as sent by the client
+	RequestURI string `json:"requestURI,omitempty"`
+
+	// Verb is the Kubernetes verb associated with the request
+	Verb string `json:"verb,omitempty"`
+
+	// User contains the authenticated user information
+	User AuditUser `json:"user"`
+
+	// ImpersonatedUser contains the impersonated user information, if any
+	ImpersonatedUser *AuditUser `json:"impersonatedUser,omitempty"`
+
+	// SourceIPs lists the source IPs the request originated from
+	SourceIPs []string `json:"sourceIPs,omitempty"`
+
+	// UserAgent is the user agent string reported by the client
+	UserAgent string `json:"userAgent,omitempty"`
+
+	// ObjectRef identifies the object targeted by the request
+	ObjectRef *AuditObjectRef `json:"objectRef,omitempty"`
+
+	// ResponseStatus is the response status returned to the client
+	ResponseStatus *AuditResponseStatus `json:"responseStatus,omitempty"`
+
+	// RequestObject is the raw API object from the request body
+	RequestObject json.RawMessage `json:"requestObject,omitempty"`
+
+	// ResponseObject is the raw API object returned in the response
+	ResponseObject json.RawMessage `json:"responseObject,omitempty"`
+
+	// RequestReceivedTimestamp is when the request reached the API server
+	RequestReceivedTimestamp time.Time `json:"requestReceivedTimestamp"`
+
+	// StageTimestamp is when the request reached the current audit stage
+	StageTimestamp time.Time `json:"stageTimestamp,omitempty"`
+
+	// Annotations contains audit annotations such as authorization decisions
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AuditUser represents the user information recorded in an audit event.
+type AuditUser struct {
+	// Username is the name that uniquely identifies the user
+	Username string `json:"username,omitempty"`
+
+	// UID is the unique value that identifies the user across time
+	UID string `json:"uid,omitempty"`
+
+	// Groups lists the groups the user belongs to
+	Groups []string `json:"groups,omitempty"`
+}
+
+// AuditObjectRef identifies the object targeted by an audited request.
+type AuditObjectRef struct {
+	Resource    string `json:"resource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	UID         string `json:"uid,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+}
+
+// AuditResponseStatus captures the status returned for an audited request.
+type AuditResponseStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Audit annotation keys written by the Kubernetes authorizer.
+const (
+	AnnotationAuthorizationDecision = "authorization.k8s.io/decision"
+	AnnotationAuthorizationReason   = "authorization.k8s.io/reason"
+)
+
+// Field returns the value of a canonical query field for this event. Supported
+// names are user, namespace, resource, resource_name, verb, source_ip,
+// response_status, user_agent, subresource, auth_decision and timestamp.
+// Unknown fields and missing values yield an empty string.
+func (e *AuditEvent) Field(name string) string {
+	switch name {
+	case "user":
+		return e.User.Username
+	case "namespace":
+		if e.ObjectRef != nil {
+			return e.ObjectRef.Namespace
+		}
+	case "resource":
+		if e.ObjectRef != nil {
+			return e.ObjectRef.Resource
+		}
+	case "resource_name":
+		if e.ObjectRef != nil {
+			return e.ObjectRef.Name
+		}
+	case "subresource":
+		if e.ObjectRef != nil {
+			return e.ObjectRef.Subresource
+		}
+	case "verb":
+		return e.Verb
+	case "source_ip":
+		if len(e.SourceIPs) > 0 {
+			return e.SourceIPs[0]
+		}
+	case "response_status":
+		if e.ResponseStatus != nil && e.ResponseStatus.Code != 0 {
+			return strconv.Itoa(e.ResponseStatus.Code)
+		}
+	case "user_agent":
+		return e.UserAgent
+	case "auth_decision":
+		return e.Annotations[AnnotationAuthorizationDecision]
+	case "timestamp":
+		if !e.RequestReceivedTimestamp.IsZero() {
+			return e.RequestReceivedTimestamp.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return ""
+}
+
+// ExecutionResult contains the audit events matched by executing a StructuredQuery.
+type ExecutionResult struct {
+	// Events contains the matching audit events, after sorting and limiting
+	Events []AuditEvent `json:"events"`
+
+	// TotalScanned is the number of audit events read from the source
+	TotalScanned int `json:"total_scanned"`
+
+	// TotalMatched is the number of events that matched before applying the limit
+	TotalMatched int `json:"total_matched"`
+
+	// Truncated indicates that more events matched than were returned
+	Truncated bool `json:"truncated"`
+
+	// Analysis contains group_by aggregations and analysis findings, when requested
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+
+	// MalformedLines is the number of lines that could not be decoded as audit events
+	MalformedLines int `json:"malformed_lines,omitempty"`
+
+	// Sources lists the files (or "stdin") that were read
+	Sources []string `json:"sources,omitempty"`
+
+	// ExecutionTime is how long the execution took
+	ExecutionTime time.Duration `json:"execution_time"`
+}
@@ -341,6 +341,50 @@ func TestStringOrArray_IsEmpty(t *testing.T) {
 	}
 }
 
+func TestStringOrArray_Values(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected []string
+	}{
+		{
+			name:     "string value",
+			input:    " get ",
+			expected: []string{"get"},
+		},
+		{
+			name:     "array with blanks",
+			input:    []string{"get", " ", "list"},
+			expected: []string{"get", "list"},
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "nil value",
+			input:    nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &StringOrArray{value: tt.input}
+			result := sa.Values()
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Values() = %v, want %v", result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("Values()[%d] = %q, want %q", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
 func TestStringOrArray_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name     string
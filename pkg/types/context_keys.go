@@ -5,3 +5,6 @@ type ContextKey string
 
 // ContextKeyUserID is the key used to store authenticated user ID in context
 const ContextKeyUserID ContextKey = "user_id"
+
+// ContextKeyGroups is the key used to store the authenticated user's groups ([]string) in context
+const ContextKeyGroups ContextKey = "groups"
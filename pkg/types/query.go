@@ -28,10 +28,38 @@ type ProcessingResponse struct {
 	// ValidationInfo contains information about validation results and any warnings
 	ValidationInfo interface{} `json:"validation_info"`
 
+	// Command contains the compiled oc/jq pipeline for the structured query
+	Command *GeneratedCommand `json:"command,omitempty"`
+
+	// Explanation is a plain-English description of the structured query, set for previews
+	Explanation string `json:"explanation,omitempty"`
+
+	// Provider names the LLM provider that produced the structured query,
+	// which differs from the default provider after a failover
+	Provider string `json:"provider,omitempty"`
+
+	// RequestID identifies the request in the audit trail
+	RequestID string `json:"request_id,omitempty"`
+
+	// Usage is the priced token usage of the provider call; nil when the
+	// query was served without calling a provider
+	Usage *TokenUsage `json:"usage,omitempty"`
+
 	// Error contains error details if the processing failed
 	Error string `json:"error,omitempty"`
+
+	// Cache is CacheHit when the structured query was served from the
+	// response cache and CacheMiss when it was generated; empty when caching
+	// is disabled. HTTP handlers report it in the X-Cache header.
+	Cache string `json:"-"`
 }
 
+// Response cache outcomes reported in ProcessingResponse.Cache
+const (
+	CacheHit  = "HIT"
+	CacheMiss = "MISS"
+)
+
 // InternalRequest represents the internal processing request used within the system.
 // This struct is used for internal communication between different processing components.
 type InternalRequest struct {
@@ -61,9 +89,14 @@ type ModelRequest struct {
 // RawResponse represents the raw response received from language model APIs.
 // This struct captures the unprocessed response before any parsing or validation.
 type RawResponse struct {
-	// Content contains the raw response content from the model
+	// Content contains the raw response content from the model. When the model
+	// answered only with a tool call, it holds that call's arguments.
 	Content string `json:"content"`
 
+	// ToolCalls contains the tool or function calls the model made, such as
+	// the structured_query call requested by schema-constrained output
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
 	// ModelInfo contains information about the model that generated the response
 	ModelInfo map[string]interface{} `json:"model_info,omitempty"`
 
@@ -73,3 +106,49 @@ type RawResponse struct {
 	// Error contains error information if the model request failed
 	Error string `json:"error,omitempty"`
 }
+
+// ToolCall represents a tool (Claude) or function (OpenAI) call made by a model.
+type ToolCall struct {
+	// ID is the provider's identifier for the call
+	ID string `json:"id,omitempty"`
+
+	// Name is the name of the tool or function called
+	Name string `json:"name"`
+
+	// Arguments is the call's input as a JSON object
+	Arguments string `json:"arguments"`
+}
+
+// FindToolCall returns the first call to the named tool, or nil if the model
+// did not call it.
+func (r *RawResponse) FindToolCall(name string) *ToolCall {
+	for i := range r.ToolCalls {
+		if r.ToolCalls[i].Name == name {
+			return &r.ToolCalls[i]
+		}
+	}
+	return nil
+}
+
+// GeneratedCommand represents an executable audit log command compiled from a
+// StructuredQuery. It pairs the `oc adm node-logs` invocation with the jq
+// program that applies the query filters, grouping, sorting and limits.
+type GeneratedCommand struct {
+	// Command is the complete shell pipeline, ready to copy into a terminal
+	Command string `json:"command"`
+
+	// LogPath is the node-relative audit log path passed to --path
+	LogPath string `json:"log_path"`
+
+	// NodeLogsArgs contains the arguments passed to the oc binary
+	NodeLogsArgs []string `json:"node_logs_args"`
+
+	// JQArgs contains the flags passed to the jq binary
+	JQArgs []string `json:"jq_args"`
+
+	// JQFilter is the jq program applied to the audit log stream
+	JQFilter string `json:"jq_filter"`
+
+	// TimeWindow is the absolute time window resolved from timeframe or time_range
+	TimeWindow *TimeRange `json:"time_window,omitempty"`
+}
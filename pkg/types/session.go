@@ -133,3 +133,10 @@ func (cc *ConversationContext) GetRecentEntries(limit int) []ConversationEntry {
 
 	return cc.ConversationHistory[len(cc.ConversationHistory)-limit:]
 }
+
+// AccessibleBy reports whether userID may read or continue this session.
+// Sessions without an owner are shared; owned sessions are only accessible
+// to their owner.
+func (cc *ConversationContext) AccessibleBy(userID string) bool {
+	return cc.UserID == "" || cc.UserID == userID
+}
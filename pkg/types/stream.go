@@ -0,0 +1,51 @@
+package types
+
+// Stream stages reported while a query is processed, in pipeline order.
+const (
+	// StreamStageContextResolved reports that pronouns and references in the
+	// query have been resolved against the session
+	StreamStageContextResolved = "context_resolved"
+
+	// StreamStageModelCalled reports that a provider has been sent the query.
+	// It is repeated for each provider tried; tokens received from a provider
+	// that then failed should be discarded when the next one is called.
+	StreamStageModelCalled = "model_called"
+
+	// StreamStageTokens carries text generated by the provider as it arrives
+	StreamStageTokens = "tokens"
+
+	// StreamStageParsed reports that the provider's response was parsed into
+	// a structured query
+	StreamStageParsed = "parsed"
+
+	// StreamStageValidated reports that the structured query passed validation
+	StreamStageValidated = "validated"
+
+	// StreamStageResponse carries the final ProcessingResponse
+	StreamStageResponse = "response"
+
+	// StreamStageError ends a stream whose query could not be processed
+	StreamStageError = "error"
+)
+
+// StreamEvent reports the progress of a query through the processing
+// pipeline, for clients that stream results as they are produced.
+type StreamEvent struct {
+	// Stage is one of the StreamStage constants
+	Stage string `json:"stage"`
+
+	// Message is a human-readable description of the stage
+	Message string `json:"message,omitempty"`
+
+	// Query is the query after context resolution
+	Query string `json:"query,omitempty"`
+
+	// Provider names the LLM provider being called
+	Provider string `json:"provider,omitempty"`
+
+	// Model names the model being called
+	Model string `json:"model,omitempty"`
+
+	// Text is generated text from the provider, for the tokens stage
+	Text string `json:"text,omitempty"`
+}